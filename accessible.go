@@ -0,0 +1,168 @@
+package main
+
+import (
+    "fmt"
+    "io"
+    "strconv"
+    "strings"
+)
+
+// spokenEvents is an Observer narrating for screen readers: one plain
+// sentence per event, with no art, colour or control codes. The wording
+// is English and kept stable from release to release, so text-to-speech
+// setups and scripts can rely on it.
+type spokenEvents struct {
+    out io.Writer
+}
+
+func newSpokenEvents(out io.Writer) *spokenEvents {
+    return &spokenEvents{out: out}
+}
+
+var rollWords = [...]string{"zero", "one", "two", "three", "four", "five", "six"}
+
+// rollWord is a die's value as a word
+func rollWord(n int) string {
+    if n >= 0 && n < len(rollWords) {
+        return rollWords[n]
+    }
+    return fmt.Sprint(n)
+}
+
+func (s *spokenEvents) OnEvent(ev Event) {
+    if line := spokenSentence(ev); line != "" {
+        fmt.Fprintln(s.out, line)
+    }
+}
+
+// describeBoard tells the board and where everyone is in sentences, for
+// the linear theme, in msgs' language
+func describeBoard(out io.Writer, msgs Catalog, b Board, players []Player) {
+    var snakes, ladders, specials []string
+    for i := 1; i <= b.FinalSquare.Index; i++ {
+        switch sq := b.Square(i).(type) {
+        case Snake:
+            snakes = append(snakes, msgs.T(MsgBoardJump, i, sq.To.Index))
+        case Ladder:
+            ladders = append(ladders, msgs.T(MsgBoardJump, i, sq.To.Index))
+        case ItemSquare:
+            specials = append(specials, msgs.T(MsgBoardOn, sq.Item, i))
+        case ScriptSquare:
+            specials = append(specials, msgs.T(MsgBoardOn, strconv.Quote(sq.Script.Source), i))
+        case Normal:
+        default:
+            specials = append(specials, msgs.T(MsgBoardOn, strings.ReplaceAll(specialKind(sq), "_", " "), i))
+        }
+    }
+    fmt.Fprintln(out, msgs.T(MsgBoardSquares, b.FinalSquare.Index))
+    for _, list := range []struct {
+        what  MsgKey
+        items []string
+    }{{MsgBoardSnakes, snakes}, {MsgBoardLadders, ladders}, {MsgBoardSpecials, specials}} {
+        if len(list.items) > 0 {
+            fmt.Fprintln(out, msgs.T(list.what, strings.Join(list.items, ", ")))
+        }
+    }
+    for _, p := range players {
+        var at []string
+        for _, sq := range p.tokenSquares() {
+            if sq == OffBoard {
+                at = append(at, msgs.T(MsgBoardOff))
+            } else {
+                at = append(at, msgs.T(MsgBoardAt, sq.Index))
+            }
+        }
+        where := strings.Join(at, msgs.T(MsgBoardAnd))
+        if p.Clock > 0 {
+            fmt.Fprintln(out, msgs.T(MsgBoardClock, p.Name, where, formatClock(p.Clock)))
+        } else {
+            fmt.Fprintln(out, msgs.T(MsgBoardPlayer, p.Name, where))
+        }
+    }
+}
+
+// spokenSentence is the sentence telling ev, or "" for one not told
+func spokenSentence(ev Event) string {
+    switch ev.Kind {
+    case EventRoll:
+        return fmt.Sprintf("%s rolled %s.", ev.Player, rollWord(ev.Roll))
+    case EventMove:
+        token := ""
+        if ev.Token > 0 {
+            token = fmt.Sprintf(" token %d", ev.Token)
+        }
+        switch {
+        case ev.To == OffBoard.Index:
+            return fmt.Sprintf("%s needs a six to come on and stays off the board.", ev.Player)
+        case ev.From == OffBoard.Index:
+            return fmt.Sprintf("%s came on to the board at %d.", ev.Player, ev.To)
+        }
+        if ev.From == ev.To {
+            return fmt.Sprintf("%s could not move%s and stays on %d.", ev.Player, token, ev.To)
+        }
+        return fmt.Sprintf("%s moved%s from %d to %d.", ev.Player, token, ev.From, ev.To)
+    case EventSnake:
+        return fmt.Sprintf("%s hit a snake and slid down to %d.", ev.Player, ev.To)
+    case EventLadder:
+        return fmt.Sprintf("%s climbed a ladder up to %d.", ev.Player, ev.To)
+    case EventTeleport:
+        return fmt.Sprintf("%s teleported to %d.", ev.Player, ev.To)
+    case EventSwap:
+        return fmt.Sprintf("%s swapped places with %s and is now on %d.", ev.Player, ev.Other, ev.To)
+    case EventScript:
+        if ev.Other != "" {
+            return fmt.Sprintf("%s's square moved %s to %d.", ev.Other, ev.Player, ev.To)
+        }
+        return fmt.Sprintf("%s's square moved them to %d.", ev.Player, ev.To)
+    case EventItem:
+        return fmt.Sprintf("%s picked up %s.", ev.Player, ev.Item)
+    case EventShield:
+        return fmt.Sprintf("%s was protected from the snake by immunity.", ev.Player)
+    case EventCapture:
+        return fmt.Sprintf("%s captured %s, sending them to %d.", ev.Player, ev.Other, ev.To)
+    case EventSkipTurn:
+        return fmt.Sprintf("%s will miss the next turn.", ev.Player)
+    case EventExtraRoll:
+        return fmt.Sprintf("%s rolls again.", ev.Player)
+    case EventThreeSixes:
+        return fmt.Sprintf("%s rolled three sixes in a row, so the roll is forfeit, and is on %d.", ev.Player, ev.To)
+    case EventStart:
+        return fmt.Sprintf("New game. Players, in turn order: %s.", strings.Join(ev.Players, ", "))
+    case EventRestore:
+        return "Bookmark restored."
+    case EventOffer:
+        if len(ev.Choices) != 2 {
+            return ""
+        }
+        return fmt.Sprintf("%s can choose %s or %s.", ev.Player, rollWord(ev.Choices[0]), rollWord(ev.Choices[1]))
+    case EventUseItem:
+        return fmt.Sprintf("%s used %s.", ev.Player, ev.Item)
+    case EventSkipped:
+        if ev.Reason == "no_move" {
+            return fmt.Sprintf("%s has no move.", ev.Player)
+        }
+        return fmt.Sprintf("%s misses this turn.", ev.Player)
+    case EventLeave:
+        if ev.Reason == timeUpReason {
+            return fmt.Sprintf("%s ran out of time.", ev.Player)
+        }
+        return fmt.Sprintf("%s left the game.", ev.Player)
+    case EventJoin:
+        return fmt.Sprintf("%s took over %s's seat.", ev.Player, ev.Other)
+    case EventWin:
+        if ev.Team != "" {
+            return fmt.Sprintf("Team %s wins the game; %s finished.", ev.Team, ev.Player)
+        }
+        return fmt.Sprintf("%s wins the game.", ev.Player)
+    case EventDraw:
+        return "The game is a draw."
+    case EventAbort:
+        return "The game was abandoned."
+    case EventPause:
+        if ev.Reason == shutdownReason {
+            return "The server is shutting down; the game is saved."
+        }
+        return "The game is paused."
+    }
+    return ""
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "math"
+    "os"
+    "time"
+)
+
+// BoardStats is how a lone token fares on a board, worked out exactly from
+// the board's transition matrix rather than by playing games. Like
+// finishDistribution it covers snakes, ladders and teleports under the
+// classic movement rules; other squares count as normal ones.
+//
+// Slices are indexed by square; index 0 and the final square are unused.
+type BoardStats struct {
+    // ExpectedTurns is the mean number of rolls to finish from square 1,
+    // +Inf if the token can get stuck for good
+    ExpectedTurns float64
+    // Turns is the mean number of rolls to finish from each square, +Inf
+    // where the token might never finish
+    Turns []float64
+    // Finish is the chance of ever finishing from each square: the
+    // absorption probability of the final square. It is below 1 only on
+    // boards with a trap, a stretch every roll out of which leads back in.
+    Finish []float64
+    // Visits is how many of a game's turns, from square 1, are expected to
+    // start on each square. It is nil when ExpectedTurns is +Inf.
+    Visits []float64
+}
+
+// transitionMatrix gives p where p[from][to] is the chance one roll takes
+// a token on from to to, after any snake, ladder or teleport. Rows for 0
+// and the final square are left empty.
+func transitionMatrix(b Board) [][]float64 {
+    final := b.FinalSquare.Index
+    p := make([][]float64, final+1)
+    for sq := 1; sq < final; sq++ {
+        p[sq] = make([]float64, final+1)
+        for face := 1; face <= 6; face++ {
+            square := b.Square(landing(b, mustBP(sq), DieRoll{face}).Index)
+            if _, ok := square.(Teleport); ok {
+                for to := 1; to < final; to++ {
+                    p[sq][to] += 1 / 6.0 / float64(final-1)
+                }
+                continue
+            }
+            p[sq][square.Dest().Index] += 1 / 6.0
+        }
+    }
+    return p
+}
+
+// AnalyzeBoard treats b as an absorbing Markov chain with the final square
+// as the only absorbing state and solves it for BoardStats
+func AnalyzeBoard(b Board) BoardStats {
+    final := b.FinalSquare.Index
+    p := transitionMatrix(b)
+    st := BoardStats{
+        Turns:  make([]float64, final+1),
+        Finish: make([]float64, final+1),
+    }
+
+    // canFinish: squares with some path to the final square. safe: squares
+    // with no path to one that can't finish, so every game from them ends.
+    canFinish := reachBack(p, func(sq int) bool { return sq == final })
+    safe := reachBack(p, func(sq int) bool { return sq > 0 && sq < final && !canFinish[sq] })
+    for sq := range safe {
+        safe[sq] = !safe[sq]
+    }
+
+    // Finish on canFinish solves x = Qx + r, where r is the chance of
+    // finishing on the next roll
+    sqs := squaresWhere(final, canFinish)
+    r := make([]float64, len(sqs))
+    for i, sq := range sqs {
+        r[i] = p[sq][final]
+    }
+    for i, x := range solveChain(p, sqs, r, false) {
+        st.Finish[sqs[i]] = x
+    }
+
+    // Turns on safe solves t = Qt + 1
+    sqs = squaresWhere(final, safe)
+    ones := make([]float64, len(sqs))
+    for i := range ones {
+        ones[i] = 1
+    }
+    for sq := 1; sq < final; sq++ {
+        st.Turns[sq] = math.Inf(1)
+    }
+    for i, t := range solveChain(p, sqs, ones, false) {
+        st.Turns[sqs[i]] = t
+    }
+    st.ExpectedTurns = st.Turns[1]
+    if final == 1 {
+        st.ExpectedTurns = 0
+    }
+    if !safe[1] || final == 1 {
+        return st
+    }
+
+    // Visits is row 1 of the fundamental matrix (I-Q)^-1, so it solves
+    // (I-Q)^T v = e1
+    e1 := make([]float64, len(sqs))
+    for i, sq := range sqs {
+        if sq == 1 {
+            e1[i] = 1
+        }
+    }
+    st.Visits = make([]float64, final+1)
+    for i, v := range solveChain(p, sqs, e1, true) {
+        st.Visits[sqs[i]] = v
+    }
+    return st
+}
+
+// reachBack marks every non-final square from which some square
+// satisfying target can be reached, the targets included
+func reachBack(p [][]float64, target func(int) bool) []bool {
+    final := len(p) - 1
+    marked := make([]bool, final+1)
+    for sq := 1; sq <= final; sq++ {
+        marked[sq] = target(sq)
+    }
+    for changed := true; changed; {
+        changed = false
+        for sq := 1; sq < final; sq++ {
+            if marked[sq] {
+                continue
+            }
+            for to, pr := range p[sq] {
+                if pr > 0 && marked[to] {
+                    marked[sq], changed = true, true
+                    break
+                }
+            }
+        }
+    }
+    return marked
+}
+
+// squaresWhere lists the squares strictly between 0 and final that keep
+// marks
+func squaresWhere(final int, keep []bool) []int {
+    var sqs []int
+    for sq := 1; sq < final; sq++ {
+        if keep[sq] {
+            sqs = append(sqs, sq)
+        }
+    }
+    return sqs
+}
+
+// solveChain solves (I-Q)x = rhs, or (I-Q)^T x = rhs if transpose is set,
+// where Q is p restricted to sqs. The caller picks sqs so that I-Q is
+// invertible: from each of them the chain can leave sqs.
+func solveChain(p [][]float64, sqs []int, rhs []float64, transpose bool) []float64 {
+    n := len(sqs)
+    a := make([][]float64, n)
+    for i, from := range sqs {
+        a[i] = make([]float64, n+1)
+        for j, to := range sqs {
+            q := p[from][to]
+            if transpose {
+                q = p[to][from]
+            }
+            a[i][j] = -q
+        }
+        a[i][i]++
+        a[i][n] = rhs[i]
+    }
+    // Gaussian elimination with partial pivoting
+    for col := 0; col < n; col++ {
+        pivot := col
+        for row := col + 1; row < n; row++ {
+            if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+                pivot = row
+            }
+        }
+        a[col], a[pivot] = a[pivot], a[col]
+        for row := col + 1; row < n; row++ {
+            f := a[row][col] / a[col][col]
+            for k := col; k <= n; k++ {
+                a[row][k] -= f * a[col][k]
+            }
+        }
+    }
+    x := make([]float64, n)
+    for row := n - 1; row >= 0; row-- {
+        sum := a[row][n]
+        for k := row + 1; k < n; k++ {
+            sum -= a[row][k] * x[k]
+        }
+        x[row] = sum / a[row][row]
+    }
+    return x
+}
+
+// analyzeGames is how many games plain analyze plays for its estimate
+const analyzeGames = 20000
+
+// runAnalyze implements `analyze [--exact | --heatmap [--csv FILE]]
+// [board-file]`; color shades the heatmap, unless -no-color
+func runAnalyze(args []string, out io.Writer, color bool) error {
+    fs := flag.NewFlagSet("analyze", flag.ContinueOnError)
+    exact := fs.Bool("exact", false, "solve the board's Markov chain instead of simulating, with a per-square table")
+    heatmap := fs.Bool("heatmap", false, "count where the simulated games' rolls land, drawn as a shaded grid, with the snakes and ladders they hit most")
+    csvPath := fs.String("csv", "", "with -heatmap, also write the counts per square to this CSV file")
+    games := fs.Int("games", analyzeGames, "games to simulate without -exact")
+    seed := fs.Int64("seed", time.Now().UnixNano(), "random seed for the simulation")
+    dice := fs.String("dice", "fair", "dice for the simulation: fair, crypto, weights:W1,...,W6 or script:V1,V2,...")
+    workers := fs.Int("workers", 0, "goroutines to simulate on (0: one per CPU)")
+    noColor := fs.Bool("no-color", false, "never shade the heatmap (it is only shaded on a terminal anyway)")
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+    if fs.NArg() > 1 || *exact && *heatmap || *csvPath != "" && !*heatmap {
+        return errors.New("usage: analyze [--exact | --heatmap [--csv FILE]] [board-file]")
+    }
+    b := CreateStandardBoard()
+    if fs.NArg() == 1 {
+        var err error
+        if b, err = LoadBoard(fs.Arg(0)); err != nil {
+            return err
+        }
+    }
+    if *heatmap {
+        if *games < 1 {
+            return fmt.Errorf("-games %d must be positive", *games)
+        }
+        h, err := simulateHeat(b, *games, *seed, *dice, 10000)
+        if err != nil {
+            return err
+        }
+        fmt.Fprintf(out, "landings per game, over %d simulated games\n", h.Games)
+        renderHeat(out, b, h, color && !*noColor)
+        reportJumps(out, b, h)
+        if *csvPath == "" {
+            return nil
+        }
+        f, err := os.Create(*csvPath)
+        if err != nil {
+            return err
+        }
+        if err := writeHeatCSV(f, b, h); err != nil {
+            f.Close()
+            return err
+        }
+        return f.Close()
+    }
+    if !*exact {
+        if *games < 1 {
+            return fmt.Errorf("-games %d must be positive", *games)
+        }
+        cfg := SimConfig{Games: *games, Seed: *seed, Names: []string{"solo"}, MaxTurns: 10000, Dice: *dice, Workers: *workers}
+        sum, err := runSimulation(context.Background(), b, cfg, io.Discard, nil)
+        if err != nil {
+            return err
+        }
+        fmt.Fprintf(out, "about %.2f rolls to finish (%d simulated games)\n", sum.MeanTurns(), sum.Games)
+        if sum.Unfinished > 0 {
+            fmt.Fprintf(out, "%d games hadn't finished after 10000 rolls\n", sum.Unfinished)
+        }
+        return nil
+    }
+
+    st := AnalyzeBoard(b)
+    if math.IsInf(st.ExpectedTurns, 1) {
+        fmt.Fprintf(out, "a game from square 1 finishes with chance %.4f; the rest never do\n", st.Finish[1])
+    } else {
+        fmt.Fprintf(out, "%.4f rolls to finish, exactly\n", st.ExpectedTurns)
+    }
+    fmt.Fprintf(out, "%6s %10s %8s %8s\n", "square", "rolls left", "finish", "visits")
+    for sq := 1; sq < b.FinalSquare.Index; sq++ {
+        switch b.Square(sq).(type) {
+        case Snake, Ladder, Teleport:
+            continue // turns never end there
+        }
+        visits := "-"
+        if st.Visits != nil {
+            visits = fmt.Sprintf("%.3f", st.Visits[sq])
+        }
+        fmt.Fprintf(out, "%6d %10.3f %8.4f %8s\n", sq, st.Turns[sq], st.Finish[sq], visits)
+    }
+    return nil
+}
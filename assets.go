@@ -0,0 +1,266 @@
+package main
+
+import (
+    "bufio"
+    "bytes"
+    "crypto/sha256"
+    "embed"
+    "encoding/hex"
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "io/fs"
+    "os"
+    "path"
+    "path/filepath"
+    "sort"
+    "strings"
+)
+
+//go:generate go run . assets manifest assets
+
+// bundled holds the boards, locales and other data files shipped inside
+// the binary. assets/MANIFEST.sha256 records their checksums.
+//
+//go:embed assets
+var bundled embed.FS
+
+const manifestName = "MANIFEST.sha256"
+
+// bundledFS is the assets directory itself, so names read "boards/x.json"
+func bundledFS() fs.FS {
+    sub, err := fs.Sub(bundled, "assets")
+    if err != nil {
+        panic(err)
+    }
+    return sub
+}
+
+// assetOverrideDir is where files replacing bundled assets live; `assets
+// extract` copies the bundle there to be edited
+func assetOverrideDir() (string, error) {
+    return appPath("assets")
+}
+
+// readAsset returns the override copy of name if there is one, else the
+// bundled one
+func readAsset(name string) ([]byte, error) {
+    if dir, err := assetOverrideDir(); err == nil {
+        data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+        if err == nil {
+            return data, nil
+        }
+        if !errors.Is(err, fs.ErrNotExist) {
+            return nil, err
+        }
+    }
+    return fs.ReadFile(bundledFS(), name)
+}
+
+// assetNames lists the bundled and overriding files under dir, sorted
+func assetNames(dir string) ([]string, error) {
+    seen := map[string]bool{}
+    collect := func(fsys fs.FS) error {
+        return fs.WalkDir(fsys, dir, func(p string, d fs.DirEntry, err error) error {
+            if errors.Is(err, fs.ErrNotExist) && p == dir {
+                return fs.SkipDir
+            }
+            if err != nil {
+                return err
+            }
+            if !d.IsDir() && p != manifestName {
+                seen[p] = true
+            }
+            return nil
+        })
+    }
+    if err := collect(bundledFS()); err != nil {
+        return nil, err
+    }
+    if od, err := assetOverrideDir(); err == nil {
+        if err := collect(os.DirFS(od)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+            return nil, err
+        }
+    }
+    names := make([]string, 0, len(seen))
+    for n := range seen {
+        names = append(names, n)
+    }
+    sort.Strings(names)
+    return names, nil
+}
+
+// readManifest parses "checksum  name" lines
+func readManifest(data []byte) (map[string]string, error) {
+    sums := map[string]string{}
+    sc := bufio.NewScanner(bytes.NewReader(data))
+    for sc.Scan() {
+        sum, name, ok := strings.Cut(sc.Text(), "  ")
+        if !ok {
+            return nil, fmt.Errorf("bad manifest line %q", sc.Text())
+        }
+        sums[name] = sum
+    }
+    return sums, sc.Err()
+}
+
+func sha256Hex(data []byte) string {
+    sum := sha256.Sum256(data)
+    return hex.EncodeToString(sum[:])
+}
+
+// verifyBundle checks every bundled file against the manifest, reporting
+// every mismatch, missing and unlisted file
+func verifyBundle() error {
+    fsys := bundledFS()
+    data, err := fs.ReadFile(fsys, manifestName)
+    if err != nil {
+        return fmt.Errorf("no asset manifest: %w", err)
+    }
+    sums, err := readManifest(data)
+    if err != nil {
+        return err
+    }
+    var errs []error
+    err = fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+        if err != nil || d.IsDir() || p == manifestName {
+            return err
+        }
+        want, listed := sums[p]
+        delete(sums, p)
+        body, err := fs.ReadFile(fsys, p)
+        if err != nil {
+            return err
+        }
+        switch {
+        case !listed:
+            errs = append(errs, fmt.Errorf("%s: not in the manifest", p))
+        case sha256Hex(body) != want:
+            errs = append(errs, fmt.Errorf("%s: checksum mismatch", p))
+        }
+        return nil
+    })
+    if err != nil {
+        return err
+    }
+    for p := range sums {
+        errs = append(errs, fmt.Errorf("%s: in the manifest but not bundled", p))
+    }
+    return errors.Join(errs...)
+}
+
+// writeManifest records checksums for every file under dir on disk
+func writeManifest(dir string) error {
+    var b strings.Builder
+    fsys := os.DirFS(dir)
+    err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+        if err != nil || d.IsDir() || p == manifestName {
+            return err
+        }
+        body, err := fs.ReadFile(fsys, p)
+        if err != nil {
+            return err
+        }
+        fmt.Fprintf(&b, "%s  %s\n", sha256Hex(body), p)
+        return nil
+    })
+    if err != nil {
+        return err
+    }
+    return os.WriteFile(filepath.Join(dir, manifestName), []byte(b.String()), 0o644)
+}
+
+// extractAssets copies the bundled files matching names (all if none) to
+// dest, leaving existing files alone unless force is set
+func extractAssets(dest string, names []string, force bool, out io.Writer) error {
+    fsys := bundledFS()
+    want := func(p string) bool {
+        if len(names) == 0 {
+            return true
+        }
+        for _, n := range names {
+            if p == n || strings.HasPrefix(p, strings.TrimSuffix(n, "/")+"/") {
+                return true
+            }
+        }
+        return false
+    }
+    return fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+        if err != nil || d.IsDir() || p == manifestName || !want(p) {
+            return err
+        }
+        target := filepath.Join(dest, filepath.FromSlash(p))
+        if _, err := os.Stat(target); err == nil && !force {
+            fmt.Fprintf(out, "kept     %s (exists; -force to overwrite)\n", target)
+            return nil
+        }
+        body, err := fs.ReadFile(fsys, p)
+        if err != nil {
+            return err
+        }
+        if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+            return err
+        }
+        if err := os.WriteFile(target, body, 0o644); err != nil {
+            return err
+        }
+        fmt.Fprintf(out, "wrote    %s\n", target)
+        return nil
+    })
+}
+
+// runAssets implements `assets list|extract|verify|manifest`
+func runAssets(args []string, out io.Writer) error {
+    usage := errors.New("usage: assets list | extract [-force] [-to DIR] [NAME...] | verify | manifest DIR")
+    if len(args) == 0 {
+        return usage
+    }
+    switch args[0] {
+    case "list":
+        names, err := assetNames(".")
+        if err != nil {
+            return err
+        }
+        od, _ := assetOverrideDir()
+        for _, n := range names {
+            where := "bundled"
+            if _, err := os.Stat(filepath.Join(od, filepath.FromSlash(n))); err == nil {
+                where = "override"
+            }
+            fmt.Fprintf(out, "%-9s %s\n", where, n)
+        }
+        fmt.Fprintf(out, "overrides are read from %s\n", od)
+        return nil
+    case "extract":
+        flags := flag.NewFlagSet("assets extract", flag.ContinueOnError)
+        force := flags.Bool("force", false, "overwrite files that already exist")
+        od, _ := assetOverrideDir()
+        dest := flags.String("to", od, "directory to extract into (default: the override directory)")
+        if err := flags.Parse(args[1:]); err != nil {
+            return err
+        }
+        return extractAssets(*dest, flags.Args(), *force, out)
+    case "verify":
+        if err := verifyBundle(); err != nil {
+            return err
+        }
+        fmt.Fprintln(out, "bundled assets match the manifest")
+        return nil
+    case "manifest":
+        if len(args) != 2 {
+            return usage
+        }
+        return writeManifest(args[1])
+    }
+    return usage
+}
+
+// assetBoardName maps a -board argument naming a bundled board ("party")
+// to its asset path, or "" if arg looks like a file path
+func assetBoardName(arg string) string {
+    if strings.ContainsAny(arg, `/\.`) {
+        return ""
+    }
+    return path.Join("boards", arg+".json")
+}
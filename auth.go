@@ -0,0 +1,116 @@
+package main
+
+import (
+    "crypto/rand"
+    "crypto/subtle"
+    "encoding/hex"
+    "errors"
+    "sync"
+)
+
+var (
+    ErrBadToken  = errors.New("missing or wrong player token")
+    ErrSeatTaken = errors.New("that seat has already been claimed")
+)
+
+// seatTokens are the secrets network players prove their seats with, so
+// one client can't roll for another. A seat's token is issued to the
+// first to claim it and lasts until its player leaves.
+type seatTokens struct {
+    mu     sync.Mutex
+    tokens map[PlayerID]string
+}
+
+// claim issues id's token, unless someone already has it
+func (t *seatTokens) claim(id PlayerID) (string, error) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    if _, taken := t.tokens[id]; taken {
+        return "", ErrSeatTaken
+    }
+    return t.issueLocked(id), nil
+}
+
+// issue gives id a new token, voiding any old one
+func (t *seatTokens) issue(id PlayerID) string {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    return t.issueLocked(id)
+}
+
+func (t *seatTokens) issueLocked(id PlayerID) string {
+    if t.tokens == nil {
+        t.tokens = map[PlayerID]string{}
+    }
+    t.tokens[id] = newToken()
+    return t.tokens[id]
+}
+
+// revoke voids id's token, e.g. once they have left
+func (t *seatTokens) revoke(id PlayerID) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    delete(t.tokens, id)
+}
+
+// check reports ErrBadToken unless token is id's
+func (t *seatTokens) check(id PlayerID, token string) error {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    want, ok := t.tokens[id]
+    if !ok || !sameToken(want, token) {
+        return ErrBadToken
+    }
+    return nil
+}
+
+// seatOf is the seat token is for, if any. Every seat's token is
+// compared, so timing gives away neither the token nor the seat.
+func (t *seatTokens) seatOf(token string) (PlayerID, bool) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    found, ok := PlayerID(0), false
+    for id, want := range t.tokens {
+        if sameToken(want, token) {
+            found, ok = id, true
+        }
+    }
+    return found, ok
+}
+
+// newToken is a fresh secret for a seat
+func newToken() string {
+    var b [16]byte
+    if _, err := rand.Read(b[:]); err != nil {
+        panic(err) // crypto/rand never fails on supported platforms
+    }
+    return hex.EncodeToString(b[:])
+}
+
+// sameToken compares tokens in constant time, so timing gives nothing away
+func sameToken(want, got string) bool {
+    return subtle.ConstantTimeCompare([]byte(want), []byte(got)) == 1
+}
+
+// joinSeat seats name in g over the network, returning their seat and
+// token: the seat of that name if nobody has claimed it yet, or, when
+// takeSeats allows, one a player has left. ErrNoSeat means they can only
+// watch.
+func joinSeat(g *Game, t *seatTokens, name string, takeSeats bool) (PlayerID, string, error) {
+    if name == "" {
+        return 0, "", errors.New("a player needs a name")
+    }
+    gs := g.State()
+    if idx := seatOf(gs, name); idx >= 0 && !gs.Players[idx].Left {
+        token, err := t.claim(PlayerID(idx))
+        return PlayerID(idx), token, err
+    }
+    if !takeSeats {
+        return 0, "", ErrNoSeat
+    }
+    id, _, err := g.Join(name)
+    if err != nil {
+        return 0, "", err
+    }
+    return id, t.issue(id), nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+    "fmt"
+    "io"
+    "os"
+)
+
+// eventRecorder is an Observer keeping the whole game's events in memory,
+// for anything computed after the fact from the history
+type eventRecorder struct {
+    events []Event
+}
+
+func (r *eventRecorder) OnEvent(ev Event) {
+    if ev.Kind == EventStart {
+        r.events = r.events[:0]
+    }
+    r.events = append(r.events, ev)
+}
+
+func (r *eventRecorder) Events() []Event {
+    return r.events
+}
+
+// AwardKind names an end-of-game superlative
+type AwardKind string
+
+const (
+    AwardLuckiest    AwardKind = "luckiest"
+    AwardSnakeMagnet AwardKind = "snake_magnet"
+    AwardComeback    AwardKind = "comeback"
+)
+
+// Award is a superlative and the number that earned it: pips rolled above
+// expectation, snakes hit, or squares of deficit overcome
+type Award struct {
+    Kind   AwardKind
+    Player string
+    Score  float64
+}
+
+const expectedRoll = 3.5
+
+// computeAwards derives the superlatives from a game's events. Awards with
+// no worthy recipient (nobody above expectation, no snakes, winner never
+// behind) are left out.
+func computeAwards(evs []Event) []Award {
+    var order []string
+    luck := map[string]float64{}
+    snakes := map[string]int{}
+    pos := map[string]int{}
+    deficit := map[string]int{}
+    winner := ""
+
+    for _, ev := range evs {
+        switch ev.Kind {
+        case EventStart:
+            order = ev.Players
+            for _, p := range ev.Players {
+                pos[p] = 1
+            }
+        case EventRoll:
+            luck[ev.Player] += float64(ev.Roll) - expectedRoll
+        case EventSnake:
+            snakes[ev.Player]++
+        case EventWin:
+            winner = ev.Player
+        }
+        switch ev.Kind {
+        case EventMove, EventSnake, EventLadder, EventTeleport, EventSwap, EventCapture, EventThreeSixes, EventScript:
+            switch ev.Kind {
+            case EventSwap:
+                pos[ev.Other] = ev.From
+                pos[ev.Player] = ev.To
+            case EventCapture:
+                pos[ev.Other] = ev.To
+            default:
+                pos[ev.Player] = ev.To
+            }
+            lead := 0
+            for _, p := range pos {
+                lead = max(lead, p)
+            }
+            for p, at := range pos {
+                deficit[p] = max(deficit[p], lead-at)
+            }
+        }
+    }
+
+    var awards []Award
+    best := func(score func(string) float64) (string, float64) {
+        who, top := "", 0.0
+        for _, p := range order {
+            if s := score(p); s > top {
+                who, top = p, s
+            }
+        }
+        return who, top
+    }
+    if p, s := best(func(p string) float64 { return luck[p] }); p != "" {
+        awards = append(awards, Award{AwardLuckiest, p, s})
+    }
+    if p, s := best(func(p string) float64 { return float64(snakes[p]) }); p != "" {
+        awards = append(awards, Award{AwardSnakeMagnet, p, s})
+    }
+    if winner != "" && deficit[winner] > 0 {
+        awards = append(awards, Award{AwardComeback, winner, float64(deficit[winner])})
+    }
+    return awards
+}
+
+func (a Award) Describe(msgs Catalog) string {
+    switch a.Kind {
+    case AwardLuckiest:
+        return msgs.T(MsgAwardLuckiest, a.Player, a.Score)
+    case AwardSnakeMagnet:
+        return msgs.T(MsgAwardSnakeMagnet, a.Player, int(a.Score))
+    case AwardComeback:
+        return msgs.T(MsgAwardComeback, a.Player, int(a.Score))
+    }
+    return fmt.Sprintf("%s: %s", a.Kind, a.Player)
+}
+
+func printAwards(out io.Writer, awards []Award, msgs Catalog) {
+    if len(awards) == 0 {
+        return
+    }
+    fmt.Fprintln(out, msgs.T(MsgAwardsHeader))
+    for _, a := range awards {
+        fmt.Fprintln(out, "  "+a.Describe(msgs))
+    }
+}
+
+// writeSummaryCard writes a shareable Markdown card for a finished game
+func writeSummaryCard(path string, res GameResult, awards []Award, msgs Catalog) error {
+    f, err := os.Create(path)
+    if err != nil {
+        return err
+    }
+    fmt.Fprintf(f, "# %s\n\n", winLine(msgs, res.Winner, res.Team))
+    fmt.Fprintf(f, "%s · %s\n\n", res.EndedAt.Format("2006-01-02 15:04"), msgs.T(MsgTotalTurns, res.Turns))
+    for _, p := range res.Players {
+        fmt.Fprintf(f, "- **%s**: %d\n", p.Name, p.Position)
+    }
+    if len(awards) > 0 {
+        fmt.Fprintf(f, "\n## %s\n\n", msgs.T(MsgAwardsHeader))
+        for _, a := range awards {
+            fmt.Fprintf(f, "- %s\n", a.Describe(msgs))
+        }
+    }
+    return f.Close()
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "io"
+    "testing"
+)
+
+// runBench implements `bench`: it times the engine's hot paths with
+// testing.Benchmark and reports the time and allocations of each, so
+// changes to them can be measured on any machine with just the binary
+func runBench(args []string, out io.Writer) error {
+    if len(args) != 0 {
+        return errors.New("usage: bench")
+    }
+    b := CreateStandardBoard()
+    names := []string{"Alice", "Bob", "Carol"}
+    r := NewSeededRoller(1)
+    rolls := make([]DieRoll, 4096)
+    for i := range rolls {
+        rolls[i] = r.Roll()
+    }
+    // moves times playing rolls one after another, starting afresh each
+    // time someone finishes
+    moves := func(step func(gs *GameState, dr DieRoll)) func(*testing.B) {
+        return func(tb *testing.B) {
+            tb.ReportAllocs()
+            gs := newGameState(b, names)
+            for i := 0; i < tb.N; i++ {
+                idx := gs.CurrentPlayerIndex
+                step(&gs, rolls[i%len(rolls)])
+                if gs.Players[idx].Position == b.FinalSquare {
+                    gs = newGameState(b, names)
+                }
+            }
+        }
+    }
+    benches := []struct {
+        name string
+        fn   func(*testing.B)
+    }{
+        {"applyMove", moves(func(gs *GameState, dr DieRoll) { *gs = applyMove(*gs, dr) })},
+        {"Apply", moves(func(gs *GameState, dr DieRoll) { gs.Apply(dr) })},
+        {"stepInPlace", moves(stepInPlace)},
+        {"simulateGame", func(tb *testing.B) {
+            tb.ReportAllocs()
+            for i := 0; i < tb.N; i++ {
+                simulateGame(b, names, NewSeededRoller(int64(i)), uint64(i), 10000)
+            }
+        }},
+    }
+    for _, bench := range benches {
+        res := testing.Benchmark(bench.fn)
+        fmt.Fprintf(out, "%-14s %s\t%s\n", bench.name, res, res.MemString())
+    }
+    return nil
+}
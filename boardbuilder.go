@@ -0,0 +1,137 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "strings"
+)
+
+// BoardBuilder puts a board together a square at a time. Nothing it is
+// given can make it panic: each problem is noted and Build reports them
+// all, so a bad custom board fails with every reason at once.
+type BoardBuilder struct {
+    size    int
+    squares map[int]Square // the squares that aren't normal
+    on      map[int]string // what is on each of those, for messages
+    errs    []error
+}
+
+// NewBoardBuilder starts a board of size squares, the last the finish
+func NewBoardBuilder(size int) *BoardBuilder {
+    bb := &BoardBuilder{size: size, squares: map[int]Square{}, on: map[int]string{}}
+    if size < 2 || size > 100 {
+        bb.errs = append(bb.errs, fmt.Errorf("size %d must be between 2 and 100", size))
+    }
+    return bb
+}
+
+// AddSnake adds a snake down from square from to square to
+func (bb *BoardBuilder) AddSnake(from, to int) *BoardBuilder {
+    if bb.jump("snake", from, to) {
+        bb.squares[from] = Snake{BoardPos{from}, BoardPos{to}}
+    }
+    return bb
+}
+
+// AddLadder adds a ladder up from square from to square to
+func (bb *BoardBuilder) AddLadder(from, to int) *BoardBuilder {
+    if bb.jump("ladder", from, to) {
+        bb.squares[from] = Ladder{BoardPos{from}, BoardPos{to}}
+    }
+    return bb
+}
+
+// jump checks a snake or ladder and claims its first square
+func (bb *BoardBuilder) jump(kind string, from, to int) bool {
+    for _, sq := range []int{from, to} {
+        if sq < 1 || sq > bb.size {
+            bb.errs = append(bb.errs, fmt.Errorf("%s %d->%d: square %d is off the board", kind, from, to, sq))
+            return false
+        }
+    }
+    ok := true
+    switch {
+    case kind == "snake" && to >= from:
+        bb.errs = append(bb.errs, fmt.Errorf("snake %d->%d must go down", from, to))
+        ok = false
+    case kind == "ladder" && to <= from:
+        bb.errs = append(bb.errs, fmt.Errorf("ladder %d->%d must go up", from, to))
+        ok = false
+    }
+    if from == bb.size {
+        bb.errs = append(bb.errs, fmt.Errorf("%s %d->%d starts on the finish square", kind, from, to))
+        ok = false
+    }
+    if other, dup := bb.on[from]; dup {
+        bb.errs = append(bb.errs, fmt.Errorf("%s %d->%d starts on the same square as a %s", kind, from, to, other))
+        return false
+    }
+    bb.on[from] = kind
+    return ok
+}
+
+// AddSpecial makes square an effect square of kind, one of SpecialKinds
+func (bb *BoardBuilder) AddSpecial(square int, kind string) *BoardBuilder {
+    sq, ok := specialSquare(kind, BoardPos{square})
+    if !ok {
+        bb.errs = append(bb.errs, fmt.Errorf("special square %d: unknown kind %q (want one of %s)", square, kind, strings.Join(SpecialKinds, ", ")))
+    }
+    if bb.between(kind, square) && ok {
+        bb.squares[square] = sq
+    }
+    return bb
+}
+
+// AddItem puts item on square for whoever lands there to collect
+func (bb *BoardBuilder) AddItem(square int, item ItemKind) *BoardBuilder {
+    _, err := parseItem(string(item))
+    if err != nil {
+        bb.errs = append(bb.errs, fmt.Errorf("item on %d: %w", square, err))
+    }
+    if bb.between("item", square) && err == nil {
+        bb.squares[square] = ItemSquare{BoardPos{square}, item}
+    }
+    return bb
+}
+
+// AddScript makes square run the square script effect (see script.go)
+func (bb *BoardBuilder) AddScript(square int, effect string) *BoardBuilder {
+    s, err := CompileScript(effect)
+    if err != nil {
+        bb.errs = append(bb.errs, fmt.Errorf("script on %d: %w", square, err))
+    }
+    if bb.between("script", square) && err == nil {
+        bb.squares[square] = ScriptSquare{BoardPos{square}, s}
+    }
+    return bb
+}
+
+// between checks square, for a what, is free and strictly between the
+// start and the finish, claiming it
+func (bb *BoardBuilder) between(what string, square int) bool {
+    if square <= 1 || square >= bb.size {
+        bb.errs = append(bb.errs, fmt.Errorf("%s %d must be between the start and the finish", what, square))
+        return false
+    }
+    if other, dup := bb.on[square]; dup {
+        bb.errs = append(bb.errs, fmt.Errorf("%s %d is on the same square as a %s", what, square, other))
+        return false
+    }
+    bb.on[square] = what
+    return true
+}
+
+// Build is the board, or every problem found putting it together
+func (bb *BoardBuilder) Build() (Board, error) {
+    if len(bb.errs) > 0 {
+        return Board{}, errors.Join(bb.errs...)
+    }
+    squares := make([]Square, bb.size+1)
+    for i := 1; i <= bb.size; i++ {
+        squares[i] = Normal{BoardPos{i}}
+        if sq, ok := bb.squares[i]; ok {
+            squares[i] = sq
+        }
+    }
+    return Board{squares: squares, FinalSquare: BoardPos{bb.size}}, nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+    "bytes"
+    "encoding/csv"
+    "errors"
+    "fmt"
+    "io"
+    "strconv"
+    "strings"
+)
+
+// A board file ending in .csv lists the board a row at a time, as it
+// comes out of a spreadsheet:
+//
+//    type,from,to
+//    size,100
+//    snake,98,78
+//    ladder,4,14
+//    teleport,30
+//    item,20,boost
+//    script,55,back 2
+//
+// The type is size, name, snake, ladder, item, script or one of
+// SpecialKinds; to is a jump's end, an item's kind or a script's effect,
+// and left off for the rest. The
+// header row is optional, # starts a comment, and without a size row the
+// board has 100 squares. Every problem is reported with its line.
+
+// boardCSVHeader is the header row, if the file has one
+var boardCSVHeader = []string{"type", "from", "to"}
+
+// boardCSVRow is a row of a CSV board and the line it is on
+type boardCSVRow struct {
+    line     int
+    kind     string
+    from, to string
+}
+
+// parseBoardCSV reads a CSV board, checking it with the same
+// BoardBuilder Build uses so each problem is put down to its row
+func parseBoardCSV(data []byte) (BoardConfig, error) {
+    c := BoardConfig{Size: 100}
+    r := csv.NewReader(bytes.NewReader(data))
+    r.Comment, r.FieldsPerRecord, r.TrimLeadingSpace = '#', -1, true
+    var rows []boardCSVRow
+    var errs []error
+    sizeLine, first := 0, true
+    for {
+        rec, err := r.Read()
+        if err == io.EOF {
+            break
+        }
+        if err != nil {
+            return c, err // csv.ParseError gives the line
+        }
+        line, _ := r.FieldPos(0)
+        for i := range rec {
+            rec[i] = strings.TrimSpace(rec[i])
+        }
+        if first {
+            first = false
+            if strings.EqualFold(strings.Join(rec, ","), strings.Join(boardCSVHeader, ",")) {
+                continue
+            }
+        }
+        if len(rec) < 2 || len(rec) > 3 {
+            errs = append(errs, fmt.Errorf("line %d: want type,from,to; got %d fields", line, len(rec)))
+            continue
+        }
+        row := boardCSVRow{line: line, kind: strings.ToLower(rec[0]), from: rec[1]}
+        if len(rec) == 3 {
+            row.to = rec[2]
+        }
+        switch row.kind {
+        case "name":
+            c.Name = row.from
+        case "size":
+            if sizeLine != 0 {
+                errs = append(errs, fmt.Errorf("line %d: size already given on line %d", line, sizeLine))
+                continue
+            }
+            n, err := strconv.Atoi(row.from)
+            if err != nil {
+                errs = append(errs, fmt.Errorf("line %d: size %q isn't a number", line, row.from))
+                continue
+            }
+            c.Size, sizeLine = n, line
+        default:
+            rows = append(rows, row)
+        }
+    }
+    bb := NewBoardBuilder(c.Size)
+    errs = append(errs, lineErrs(sizeLine, bb.errs)...)
+    for _, row := range rows {
+        before := len(bb.errs)
+        if err := row.add(&c, bb); err != nil {
+            errs = append(errs, fmt.Errorf("line %d: %w", row.line, err))
+            continue
+        }
+        errs = append(errs, lineErrs(row.line, bb.errs[before:])...)
+    }
+    return c, errors.Join(errs...)
+}
+
+// add puts row on c, and on bb to check it
+func (row boardCSVRow) add(c *BoardConfig, bb *BoardBuilder) error {
+    _, special := specialSquare(row.kind, BoardPos{})
+    if !special && row.kind != "snake" && row.kind != "ladder" && row.kind != "item" && row.kind != "script" {
+        return fmt.Errorf("unknown type %q (want size, name, snake, ladder, item, script, %s)", row.kind, strings.Join(SpecialKinds, ", "))
+    }
+    from, err := strconv.Atoi(row.from)
+    if err != nil {
+        return fmt.Errorf("square %q isn't a number", row.from)
+    }
+    switch row.kind {
+    case "snake", "ladder":
+        to, err := strconv.Atoi(row.to)
+        if row.to == "" {
+            return fmt.Errorf("%s from %d needs a to square", row.kind, from)
+        }
+        if err != nil {
+            return fmt.Errorf("%s from %d: end %q isn't a number", row.kind, from, row.to)
+        }
+        if row.kind == "snake" {
+            c.Snakes = append(c.Snakes, JumpSpec{from, to})
+            bb.AddSnake(from, to)
+        } else {
+            c.Ladders = append(c.Ladders, JumpSpec{from, to})
+            bb.AddLadder(from, to)
+        }
+    case "item":
+        c.Items = append(c.Items, ItemSpec{from, ItemKind(row.to)})
+        bb.AddItem(from, ItemKind(row.to))
+    case "script":
+        c.Scripts = append(c.Scripts, ScriptSpec{from, row.to})
+        bb.AddScript(from, row.to)
+    default:
+        if row.to != "" {
+            return fmt.Errorf("%s on %d leads nowhere, so takes no to", row.kind, from)
+        }
+        c.Specials = append(c.Specials, SpecialSpec{from, row.kind})
+        bb.AddSpecial(from, row.kind)
+    }
+    return nil
+}
+
+// lineErrs puts errs down to line, if there is one
+func lineErrs(line int, errs []error) []error {
+    if line == 0 {
+        return errs
+    }
+    out := make([]error, len(errs))
+    for i, err := range errs {
+        out[i] = fmt.Errorf("line %d: %w", line, err)
+    }
+    return out
+}
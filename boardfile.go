@@ -0,0 +1,264 @@
+package main
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io/fs"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+)
+
+// BoardConfig is the on-disk board format (JSON). Squares not mentioned
+// are normal; the last square is the finish. Rules, if set, are the rules
+// the board is meant to be played with.
+type BoardConfig struct {
+    Name     string        `json:"name,omitempty"`
+    Size     int           `json:"size"`
+    Snakes   []JumpSpec    `json:"snakes,omitempty"`
+    Ladders  []JumpSpec    `json:"ladders,omitempty"`
+    Specials []SpecialSpec `json:"specials,omitempty"`
+    Items    []ItemSpec    `json:"items,omitempty"`
+    Scripts  []ScriptSpec  `json:"scripts,omitempty"`
+    Rules    *RuleSpec     `json:"rules,omitempty"`
+}
+
+// JumpSpec is one snake or ladder in a BoardConfig
+type JumpSpec struct {
+    From int `json:"from"`
+    To   int `json:"to"`
+}
+
+// SpecialSpec is one special effect square in a BoardConfig; Kind is one
+// of SpecialKinds
+type SpecialSpec struct {
+    Square int    `json:"square"`
+    Kind   string `json:"kind"`
+}
+
+// ItemSpec places an item to collect on a square
+type ItemSpec struct {
+    Square int      `json:"square"`
+    Item   ItemKind `json:"item"`
+}
+
+// ScriptSpec gives a square a square script Effect (see script.go)
+type ScriptSpec struct {
+    Square int    `json:"square"`
+    Effect string `json:"effect"`
+}
+
+// SpecialKinds are the effect square kinds a board file can use
+var SpecialKinds = []string{"skip_turn", "extra_roll", "teleport", "swap"}
+
+func specialSquare(kind string, pos BoardPos) (Square, bool) {
+    switch kind {
+    case "skip_turn":
+        return SkipTurn{pos}, true
+    case "extra_roll":
+        return ExtraRoll{pos}, true
+    case "teleport":
+        return Teleport{pos}, true
+    case "swap":
+        return Swap{pos}, true
+    }
+    return nil, false
+}
+
+func specialKind(sq Square) string {
+    switch sq.(type) {
+    case SkipTurn:
+        return "skip_turn"
+    case ExtraRoll:
+        return "extra_roll"
+    case Teleport:
+        return "teleport"
+    case Swap:
+        return "swap"
+    }
+    return ""
+}
+
+// Validate reports every problem with c, not just the first
+func (c BoardConfig) Validate() error {
+    _, err := c.Build()
+    return err
+}
+
+// Build validates c, its rules included, and turns it into a Board
+func (c BoardConfig) Build() (Board, error) {
+    bb := NewBoardBuilder(c.Size)
+    for _, s := range c.Snakes {
+        bb.AddSnake(s.From, s.To)
+    }
+    for _, l := range c.Ladders {
+        bb.AddLadder(l.From, l.To)
+    }
+    for _, s := range c.Specials {
+        bb.AddSpecial(s.Square, s.Kind)
+    }
+    for _, it := range c.Items {
+        bb.AddItem(it.Square, it.Item)
+    }
+    for _, s := range c.Scripts {
+        bb.AddScript(s.Square, s.Effect)
+    }
+    b, err := bb.Build()
+    if err != nil || c.Rules == nil {
+        return b, err
+    }
+    if _, err := c.BoardRules(b); err != nil {
+        return Board{}, err
+    }
+    return b, nil
+}
+
+// BoardRules are the rules c says to play b, its built board, with: the
+// zero Rules if it says none
+func (c BoardConfig) BoardRules(b Board) (Rules, error) {
+    var r Rules
+    if c.Rules == nil {
+        return r, nil
+    }
+    if err := errors.Join(c.Rules.Apply(&r), r.Check(b)); err != nil {
+        return r, fmt.Errorf("rules: %w", err)
+    }
+    return r, nil
+}
+
+// ConfigFromBoard is the inverse of Build
+func ConfigFromBoard(name string, b Board) BoardConfig {
+    c := BoardConfig{Name: name, Size: b.FinalSquare.Index}
+    for _, s := range b.Snakes() {
+        c.Snakes = append(c.Snakes, JumpSpec{s.From.Index, s.To.Index})
+    }
+    for _, l := range b.Ladders() {
+        c.Ladders = append(c.Ladders, JumpSpec{l.From.Index, l.To.Index})
+    }
+    for i := 1; i <= b.FinalSquare.Index; i++ {
+        if kind := specialKind(b.Square(i)); kind != "" {
+            c.Specials = append(c.Specials, SpecialSpec{i, kind})
+        }
+        if it, ok := b.Square(i).(ItemSquare); ok {
+            c.Items = append(c.Items, ItemSpec{i, it.Item})
+        }
+        if s, ok := b.Square(i).(ScriptSquare); ok {
+            c.Scripts = append(c.Scripts, ScriptSpec{i, s.Script.Source})
+        }
+    }
+    return c
+}
+
+// boardFingerprint identifies b's layout (not its name), for keying
+// results computed from it
+func boardFingerprint(b Board) string {
+    data, err := json.Marshal(ConfigFromBoard("", b))
+    if err != nil {
+        panic(err) // a BoardConfig always marshals
+    }
+    return sha256Hex(data)
+}
+
+// Warnings are legal but probably unintended features of c. Jumps ending
+// on others are left alone on a board whose rules chain them.
+func (c BoardConfig) Warnings() []string {
+    starts := map[int]bool{}
+    for _, j := range append(append([]JumpSpec(nil), c.Snakes...), c.Ladders...) {
+        starts[j.From] = true
+    }
+    for _, s := range c.Specials {
+        starts[s.Square] = true
+    }
+    for _, it := range c.Items {
+        starts[it.Square] = true
+    }
+    for _, s := range c.Scripts {
+        starts[s.Square] = true
+    }
+    var warns []string
+    var r Rules
+    chains := c.Rules != nil && c.Rules.Apply(&r) == nil && r.ChainJumps // the board means them to
+    for _, j := range append(append([]JumpSpec(nil), c.Snakes...), c.Ladders...) {
+        if starts[j.To] && !chains {
+            warns = append(warns, fmt.Sprintf("jump %d->%d ends on another jump or special square; it only chains with the chain_jumps rule", j.From, j.To))
+        }
+    }
+    sort.Strings(warns)
+    return warns
+}
+
+// LoadBoardConfig reads a board file, or a bundled board by name (e.g.
+// "party") when no such file exists
+func LoadBoardConfig(path string) (BoardConfig, error) {
+    var c BoardConfig
+    if name := assetBoardName(path); name != "" {
+        if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+            data, err := readAsset(name)
+            if err == nil {
+                err = json.Unmarshal(data, &c)
+            }
+            if err != nil {
+                return c, fmt.Errorf("%s: %w", path, err)
+            }
+            return c, nil
+        }
+    }
+    if strings.EqualFold(filepath.Ext(path), ".csv") {
+        data, err := os.ReadFile(path)
+        if err == nil {
+            c, err = parseBoardCSV(data)
+        }
+        if err != nil {
+            return c, fmt.Errorf("%s: %w", path, err)
+        }
+        return c, nil
+    }
+    if err := readJSONFile(path, &c); err != nil {
+        return c, fmt.Errorf("%s: %w", path, err)
+    }
+    if c.Size == 0 {
+        return c, fmt.Errorf("%s: missing or empty board file", path)
+    }
+    return c, nil
+}
+
+func SaveBoardConfig(path string, c BoardConfig) error {
+    if err := c.Validate(); err != nil {
+        return err
+    }
+    byFrom := func(js []JumpSpec) {
+        sort.Slice(js, func(i, j int) bool { return js[i].From < js[j].From })
+    }
+    byFrom(c.Snakes)
+    byFrom(c.Ladders)
+    sort.Slice(c.Specials, func(i, j int) bool { return c.Specials[i].Square < c.Specials[j].Square })
+    sort.Slice(c.Items, func(i, j int) bool { return c.Items[i].Square < c.Items[j].Square })
+    sort.Slice(c.Scripts, func(i, j int) bool { return c.Scripts[i].Square < c.Scripts[j].Square })
+    return writeJSONFile(path, c)
+}
+
+// LoadBoard reads and builds a board file, prefixing errors with its path
+func LoadBoard(path string) (Board, error) {
+    b, _, err := loadBoardRules(path)
+    return b, err
+}
+
+// loadBoardRules is LoadBoard, also returning the rules the file says to
+// play the board with
+func loadBoardRules(path string) (Board, Rules, error) {
+    c, err := LoadBoardConfig(path)
+    if err != nil {
+        return Board{}, Rules{}, err
+    }
+    b, err := c.Build()
+    var r Rules
+    if err == nil {
+        r, err = c.BoardRules(b)
+    }
+    if err != nil {
+        return Board{}, Rules{}, fmt.Errorf("%s: %s", path, strings.ReplaceAll(err.Error(), "\n", "; "))
+    }
+    return b, r, nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "sort"
+    "time"
+)
+
+// Snapshot is the serializable part of a game in progress. The board is
+// not stored; it is rebuilt and the snapshot is validated against it.
+type Snapshot struct {
+    Version            int // FormatVersion when written
+    Players            []Player
+    CurrentPlayerIndex int
+    RandState          uint64
+    Dice               DiceStream
+    Turn               int
+    SavedAt            time.Time
+}
+
+func takeSnapshot(gs GameState, turn int) Snapshot {
+    return Snapshot{
+        Version:            FormatVersion,
+        Players:            append([]Player(nil), gs.Players...),
+        CurrentPlayerIndex: gs.CurrentPlayerIndex,
+        RandState:          gs.RandState,
+        Dice:               gs.Dice,
+        Turn:               turn,
+        SavedAt:            time.Now(),
+    }
+}
+
+// Restore rebuilds the game state on b, rejecting snapshots that don't fit
+func (s Snapshot) Restore(b Board) (GameState, error) {
+    if len(s.Players) == 0 {
+        return GameState{}, errors.New("snapshot has no players")
+    }
+    if s.CurrentPlayerIndex < 0 || s.CurrentPlayerIndex >= len(s.Players) {
+        return GameState{}, fmt.Errorf("snapshot turn index %d out of range", s.CurrentPlayerIndex)
+    }
+    dice := s.Dice
+    if dice.Algorithm != DiceAlgorithm {
+        return GameState{}, fmt.Errorf("snapshot rolls dice with %q; this build only has %s", dice.Algorithm, DiceAlgorithm)
+    }
+    for _, p := range s.Players {
+        if p.Position == OffBoard {
+            continue // waiting to come on under enter_on_six
+        }
+        if _, err := NewBoardPos(p.Position.Index); err != nil || p.Position.Index > b.FinalSquare.Index {
+            return GameState{}, fmt.Errorf("snapshot puts %s on square %d", p.Name, p.Position.Index)
+        }
+    }
+    return GameState{
+        Board:              b,
+        Players:            append([]Player(nil), s.Players...),
+        CurrentPlayerIndex: s.CurrentPlayerIndex,
+        RandState:          s.RandState,
+        Dice:               dice,
+        Turns:              s.Turn,
+    }, nil
+}
+
+// saveDir is where autosaves and bookmarks live
+func saveDir() (string, error) {
+    return appPath("saves")
+}
+
+func bookmarksPath() (string, error) {
+    return appPath("saves", "bookmarks.json")
+}
+
+func loadBookmarks() (map[string]Snapshot, error) {
+    marks := bookmarkFile{}
+    path, err := bookmarksPath()
+    if err != nil {
+        return marks, err
+    }
+    if err := readJSONFile(path, &marks); err != nil {
+        return map[string]Snapshot{}, err
+    }
+    return marks, nil
+}
+
+// addBookmark stores snap under name, replacing any bookmark of that name
+func addBookmark(name string, snap Snapshot) error {
+    path, err := bookmarksPath()
+    if err != nil {
+        return err
+    }
+    marks := bookmarkFile{}
+    return updateJSONFile(path, &marks, func() error {
+        marks[name] = snap
+        return nil
+    })
+}
+
+func bookmarkNames(marks map[string]Snapshot) []string {
+    names := make([]string, 0, len(marks))
+    for n := range marks {
+        names = append(names, n)
+    }
+    sort.Strings(names)
+    return names
+}
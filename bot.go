@@ -0,0 +1,280 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "math/rand"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// BotLevel is how well a bot plays the decisions a game offers: which
+// item to use before a roll and which token to move after it, or in other
+// games how far it looks ahead (see pickMove)
+type BotLevel string
+
+const (
+    // BotEasy decides at random
+    BotEasy BotLevel = "easy"
+    // BotMedium is greedy: the move that gets furthest this turn, which
+    // steers around snakes
+    BotMedium BotLevel = "medium"
+    // BotHard plays each option out many times and takes the one that
+    // wins most often
+    BotHard BotLevel = "hard"
+)
+
+func ParseBotLevel(s string) (BotLevel, error) {
+    switch BotLevel(s) {
+    case BotEasy, BotMedium, BotHard:
+        return BotLevel(s), nil
+    case "":
+        return BotMedium, nil
+    }
+    return "", fmt.Errorf("unknown bot level %q (want easy, medium or hard)", s)
+}
+
+// parseBots reads "Robo:hard,Bob": bot players and their levels, medium
+// by default
+func parseBots(s string) ([]string, map[string]BotLevel, error) {
+    var names []string
+    levels := map[string]BotLevel{}
+    for _, part := range strings.Split(s, ",") {
+        name, lv, _ := strings.Cut(part, ":")
+        level, err := ParseBotLevel(strings.TrimSpace(lv))
+        if err != nil {
+            return nil, nil, fmt.Errorf("bot %s: %w", name, err)
+        }
+        names = append(names, strings.TrimSpace(name))
+        levels[strings.TrimSpace(name)] = level
+    }
+    checked, err := parseNameList(strings.Join(names, ","))
+    return checked, levels, err
+}
+
+// botController answers prompts for the computer at level, making its
+// random choices with rng (nil: the global source)
+type botController struct {
+    level BotLevel
+    rng   *rand.Rand
+}
+
+func (b botController) intn(n int) int {
+    if b.rng == nil {
+        return rand.Intn(n)
+    }
+    return b.rng.Intn(n)
+}
+
+func (b botController) int63() int64 {
+    if b.rng == nil {
+        return rand.Int63()
+    }
+    return b.rng.Int63()
+}
+
+func (b botController) WaitTurn(ctx context.Context, p Prompt, clock Clock, limit time.Duration, r Renderer) (string, bool, error) {
+    if err := ctx.Err(); err != nil {
+        return "", false, err
+    }
+    if p.Game != nil {
+        return b.pickMove(p.Game), true, nil
+    }
+    if len(p.Choices) > 0 {
+        return strconv.Itoa(b.pickDie(p.State, p.Choices) + 1), true, nil
+    }
+    if p.Roll.Value == 0 {
+        if item, ok := b.pickItem(p.State); ok {
+            return "use " + string(item), true, nil
+        }
+        return "", true, nil
+    }
+    return strconv.Itoa(b.pickToken(p.State, p.Roll) + 1), true, nil
+}
+
+// usableItems are the current player's items that would do something
+// now, without repeats
+func usableItems(gs GameState) []ItemKind {
+    cur := gs.Players[gs.CurrentPlayerIndex]
+    var items []ItemKind
+    seen := map[ItemKind]bool{}
+    for _, it := range cur.Items {
+        if seen[it] || (it == ItemImmunity && cur.Immune) {
+            continue
+        }
+        seen[it] = true
+        items = append(items, it)
+    }
+    return items
+}
+
+// pickItem chooses an item to use before rolling, if any
+func (b botController) pickItem(gs GameState) (ItemKind, bool) {
+    items := usableItems(gs)
+    if len(items) == 0 {
+        return "", false
+    }
+    switch b.level {
+    case BotEasy:
+        if b.intn(2) == 0 {
+            return items[b.intn(len(items))], true
+        }
+        return "", false
+    case BotHard:
+        seed := b.int63()
+        best, bestRate := ItemKind(""), winRateBeforeRoll(gs, seed)
+        for _, it := range items {
+            armed, err := useItem(gs, it)
+            if err != nil {
+                continue
+            }
+            if rate := winRateBeforeRoll(armed, seed); rate > bestRate {
+                best, bestRate = it, rate
+            }
+        }
+        return best, best != ""
+    }
+    best, bestGain := ItemKind(""), expectedGain(gs)
+    for _, it := range items {
+        armed, err := useItem(gs, it)
+        if err != nil {
+            continue
+        }
+        if gain := expectedGain(armed); gain > bestGain {
+            best, bestGain = it, gain
+        }
+    }
+    return best, best != ""
+}
+
+// pickToken chooses which token to move with roll
+func (b botController) pickToken(gs GameState, roll DieRoll) int {
+    ks := movableTokens(gs)
+    switch b.level {
+    case BotEasy:
+        return ks[b.intn(len(ks))]
+    case BotHard:
+        seed := b.int63()
+        best, bestRate := ks[0], -1.0
+        for _, k := range ks {
+            moved, _ := withToken(gs, k)
+            if rate := winRate(applyMove(moved, roll), gs.Players[gs.CurrentPlayerIndex], seed); rate > bestRate {
+                best, bestRate = k, rate
+            }
+        }
+        return best
+    }
+    best, bestGain := ks[0], -1<<31
+    for _, k := range ks {
+        moved, _ := withToken(gs, k)
+        if gain := tokenGain(moved, roll); gain > bestGain {
+            best, bestGain = k, gain
+        }
+    }
+    return best
+}
+
+// pickDie chooses which of the rolls offered under pick_die to take
+func (b botController) pickDie(gs GameState, choices []DieRoll) int {
+    switch b.level {
+    case BotEasy:
+        return b.intn(len(choices))
+    case BotHard:
+        seed := b.int63()
+        best, bestRate := 0, -1.0
+        for i, c := range choices {
+            moved, _ := withToken(gs, b.pickToken(gs, c))
+            if rate := winRate(applyMove(moved, c), gs.Players[gs.CurrentPlayerIndex], seed); rate > bestRate {
+                best, bestRate = i, rate
+            }
+        }
+        return best
+    }
+    best, bestGain := 0, -1<<31
+    for i, c := range choices {
+        moved, _ := withToken(gs, b.pickToken(gs, c))
+        if gain := tokenGain(moved, c); gain > bestGain {
+            best, bestGain = i, gain
+        }
+    }
+    return best
+}
+
+// tokenGain is how far roll takes the current player's active token,
+// after any snake or ladder (so negative for a snake)
+func tokenGain(gs GameState, roll DieRoll) int {
+    idx := gs.CurrentPlayerIndex
+    return applyMove(gs, roll).Players[idx].Position.Index - gs.Players[idx].Position.Index
+}
+
+// expectedGain is the current player's mean advance over the six faces,
+// moving their best token for each
+func expectedGain(gs GameState) float64 {
+    total := 0
+    for face := 1; face <= 6; face++ {
+        best := -1 << 31
+        for _, k := range movableTokens(gs) {
+            moved, _ := withToken(gs, k)
+            best = max(best, tokenGain(moved, DieRoll{face}))
+        }
+        total += best
+    }
+    return float64(total) / 6
+}
+
+// botPlayouts is how many games BotHard plays out per option
+const botPlayouts = 100
+
+// winRateBeforeRoll is winRate averaged over the six faces the current
+// player might roll, moving their first token not home
+func winRateBeforeRoll(gs GameState, seed int64) float64 {
+    me := gs.Players[gs.CurrentPlayerIndex]
+    moved, _ := withToken(gs, movableTokens(gs)[0])
+    total := 0.0
+    for face := 1; face <= 6; face++ {
+        total += winRate(applyMove(moved, DieRoll{face}), me, seed)
+    }
+    return total / 6
+}
+
+// winRate plays gs out botPlayouts times from seed and reports how often
+// me (or me's team) wins. Every option is scored from the same seed, so
+// they face the same dice.
+func winRate(gs GameState, me Player, seed int64) float64 {
+    if win, ok := checkOutcome(gs).(Win); ok {
+        if won(win, me) {
+            return 1
+        }
+        return 0
+    }
+    r := NewSeededRoller(seed)
+    wins := 0
+    for i := 0; i < botPlayouts; i++ {
+        if won(playout(gs, r, 2000), me) {
+            wins++
+        }
+    }
+    return float64(wins) / botPlayouts
+}
+
+func won(win Win, me Player) bool {
+    return win.Winner.Name == me.Name || (win.Team != "" && win.Team == me.Team)
+}
+
+// playout finishes a copy of gs with rolls from r, each player moving
+// their first token not home. It gives the zero Win if nobody has won
+// after maxTurns.
+func playout(gs GameState, r Roller, maxTurns int) Win {
+    gs.Players = append([]Player(nil), gs.Players...)
+    for turn := 0; turn < maxTurns; turn++ {
+        if len(gs.Players[gs.CurrentPlayerIndex].Tokens) > 0 {
+            gs, _ = withToken(gs, movableTokens(gs)[0])
+        }
+        stepInPlace(&gs, r.Roll())
+        if win, ok := checkOutcome(gs).(Win); ok {
+            return win
+        }
+    }
+    return Win{}
+}
@@ -0,0 +1,54 @@
+package main
+
+import "fmt"
+
+// Capture is the optional rule for landing on an opponent's square
+type Capture string
+
+const (
+    CaptureOff Capture = ""
+    // CaptureToStart sends the captured player back to square 1
+    CaptureToStart Capture = "start"
+    // CaptureToCheckpoint sends them back to their last checkpoint: the
+    // first square of the board row they are on
+    CaptureToCheckpoint Capture = "checkpoint"
+)
+
+func ParseCapture(s string) (Capture, error) {
+    switch s {
+    case "", "off":
+        return CaptureOff, nil
+    case string(CaptureToStart), string(CaptureToCheckpoint):
+        return Capture(s), nil
+    }
+    return "", fmt.Errorf("unknown capture rule %q (want off, start or checkpoint)", s)
+}
+
+// capture knocks back, as mode says, every opponent sharing the square
+// the player at idx ended their move on. Teammates, the start and the
+// finish are safe.
+func capture(gs *GameState, mode Capture, idx int) {
+    ps := gs.Players
+    at := ps[idx].Position
+    if at == OffBoard || at.Index == 1 || at == gs.Board.FinalSquare {
+        return
+    }
+    for j := range ps {
+        if j == idx || (ps[j].Team != "" && ps[j].Team == ps[idx].Team) {
+            continue
+        }
+        for k, sq := range ps[j].tokenSquares() {
+            if sq == at {
+                ps[j].setToken(k, captureDest(gs.Board, mode, at))
+            }
+        }
+    }
+}
+
+func captureDest(b Board, rule Capture, at BoardPos) BoardPos {
+    if rule == CaptureToCheckpoint {
+        w := GridFor(b).Width
+        return mustBP((at.Index-1)/w*w + 1)
+    }
+    return mustBP(1)
+}
@@ -0,0 +1,644 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "os"
+    "slices"
+    "strconv"
+    "strings"
+    "syscall"
+    "time"
+)
+
+// The command line is `snakesladders [command] [flags] [args]`. With no
+// command it plays a game at the terminal, taking the game's flags. Each
+// command defines its own FlagSet, so it takes only the flags it reads:
+// `simulate 500 -theme emoji` is a mistake rather than quietly ignored.
+// Its flags may come after its arguments too, unless those take flags of
+// their own (`play tictactoe -size 4`); commands marked own parse all
+// their arguments themselves (`analyze -exact`).
+
+// cliCommand is one of the command line's modes
+type cliCommand struct {
+    name    string
+    args    string // its arguments, for usage
+    summary string
+    // define sets up the command's flags on fs, returning what it runs
+    // once they are parsed, with its arguments in fs.Args()
+    define func(fs *flag.FlagSet) func(ctx context.Context) error
+    own    func(args []string) error // parses all its arguments itself instead
+    fixed  []string                  // flags the environment and config leave alone
+    nested bool                      // its flags end at its first argument, which has its own
+}
+
+// terminalGame is what runs with no command
+var terminalGame = cliCommand{name: "snakesladders", define: playCommand("")}
+
+var cliCommands = []cliCommand{
+    {name: "play", args: "[game [game flags]]", summary: "play snakes or another game (" + strings.Join(turnGameNames(), ", ") + ") turn by turn", define: playCommand("play"), nested: true},
+    {name: "resume", args: "[save]", summary: "carry on a paused game", define: playCommand("resume")},
+    {name: "daily", args: "[YYYY-MM-DD]", summary: "play the day's challenge: the same board and dice for everyone", define: playCommand("daily"), fixed: dailyFixedFlags},
+    {name: "saves", args: "[delete NAME]", summary: "list the paused games", define: cmdSaves},
+    {name: "player", args: "[NAME [color COLOR] [token MARK]]", summary: "list the player profiles, or show or change one", define: cmdPlayer},
+    {name: "simulate", args: "GAMES", summary: "simulate games and print statistics", define: cmdSimulate},
+    {name: "difftest", args: "GAMES", summary: "cross-check the simulation and applyMove paths over seeded games", define: cmdDiffTest},
+    {name: "fuzz", args: "GAMES", summary: "check the game's invariants over random boards, positions and rolls", define: cmdFuzz},
+    {name: "serve-http", args: "[addr]", summary: "host the game in web browsers (also: serve http)", define: cmdServeHTTP},
+    {name: "serve-grpc", args: "[addr]", summary: "host games over gRPC (also: serve grpc; needs -tags grpc)", define: cmdServeGRPC},
+    {name: "serve-ssh", args: "[addr]", summary: "host the game and lobby tables over SSH (also: serve ssh; needs -tags ssh)", define: cmdServeSSH},
+    {name: "join", args: "host:port name", summary: "take your seat in a game started with -remote", define: cmdJoin},
+    {name: "watch", args: "[host:port]", summary: "follow a game started with -spectate-addr", define: cmdWatch},
+    {name: "analyze", args: "[-exact | -heatmap] [board-file]", summary: "work out how a board plays", own: func(args []string) error {
+        return runAnalyze(args, os.Stdout, colorWanted(os.Stdout, false))
+    }},
+    {name: "validate", args: "[-strict] board-file...", summary: "check board files without playing", own: func(args []string) error {
+        return runValidate(args, os.Stdout)
+    }},
+    {name: "generate", args: "[flags]", summary: "make a random board", own: func(args []string) error {
+        return runGenerate(args, os.Stdout)
+    }},
+    {name: "edit", args: "board-file", summary: "edit a board file", define: cmdEdit},
+    {name: "history", args: "search [-limit N] words...", summary: "search the recorded games", define: cmdHistory, nested: true},
+    {name: "stats", summary: "totals over the recorded games", define: cmdStats},
+    {name: "ratings", summary: "the players' ladder", define: cmdRatings},
+    {name: "debug", args: "[game-record]", summary: "step back and forth through a game's states, branching what-ifs", define: cmdDebug},
+    {name: "verify", args: "game-record...", summary: "replay game records, checking every state", own: func(args []string) error {
+        return runVerify(args, os.Stdout)
+    }},
+    {name: "audit", args: "game-record", summary: "check a game's rolls against its dice", own: func(args []string) error {
+        return runAudit(args, os.Stdout)
+    }},
+    {name: "transcript", args: "game-record [json|csv|notation|summary]", summary: "print a game record as narration", define: cmdTranscript},
+    {name: "export", args: "[flags]", summary: "print a board or game for paper", own: func(args []string) error {
+        return runExport(args, os.Stdout)
+    }},
+    {name: "export-image", args: "[flags]", summary: "draw a board as SVG or PNG", own: runExportImage},
+    {name: "bench", args: "[flags]", summary: "time the engine", own: func(args []string) error {
+        return runBench(args, os.Stdout)
+    }},
+    {name: "assets", args: "[args]", summary: "list, extract or check the bundled assets", own: func(args []string) error {
+        return runAssets(args, os.Stdout)
+    }},
+    {name: "help", args: "[command]", summary: "show the commands, or one command's usage and flags"},
+}
+
+// findCommand is the command called name
+func findCommand(name string) (cliCommand, bool) {
+    for _, c := range cliCommands {
+        if c.name == name {
+            return c, true
+        }
+    }
+    return cliCommand{}, false
+}
+
+// usageError is a mistake in how a command was called, which exits 2
+// rather than 1
+type usageError struct{ error }
+
+func (e usageError) Unwrap() error { return e.error }
+
+// exitCode ends the program with its status, what went wrong having been
+// said already
+type exitCode int
+
+func (c exitCode) Error() string { return "exit status " + strconv.Itoa(int(c)) }
+
+// runCLI runs the command line args, os.Args without the program, and is
+// the status to exit with
+func runCLI(args []string) int {
+    cmd := terminalGame
+    if len(args) > 0 && !isFlagArg(args[0]) {
+        name := args[0]
+        args = args[1:]
+        if name == "serve" {
+            if len(args) == 0 || !slices.Contains([]string{"http", "grpc", "ssh"}, args[0]) {
+                fmt.Fprintln(os.Stderr, "usage: snakesladders serve http|grpc|ssh [flags] [addr]")
+                return 2
+            }
+            name, args = "serve-"+args[0], args[1:]
+        }
+        if name == "help" {
+            return exitStatus(name, runHelp(args, os.Stdout))
+        }
+        var ok bool
+        if cmd, ok = findCommand(name); !ok {
+            fmt.Fprintf(os.Stderr, "no command %q; run 'snakesladders help' for the list\n", name)
+            return 2
+        }
+    }
+    if cmd.own != nil {
+        return exitStatus(cmd.name, cmd.own(args))
+    }
+    fs := commandFlags(cmd)
+    run := cmd.define(fs)
+    if err := parseFlags(fs, args, cmd); errors.Is(err, flag.ErrHelp) {
+        return 0
+    } else if err != nil {
+        return exitStatus(cmd.name, err)
+    }
+
+    // Ctrl-C or SIGTERM stops whatever is running: an interactive game
+    // takes it as a pause (see play), servers save their games and shut
+    // down, anything else just sees ctx cancelled. Then the program exits
+    // as the signal would have, after the command's deferred closes.
+    ctx, cancel := context.WithCancelCause(context.Background())
+    defer exitOnSignal(ctx)
+    defer cancel(nil)
+    go pauseOnSignal(ctx, cancel, os.Interrupt, syscall.SIGTERM)
+    return exitStatus(cmd.name, run(ctx))
+}
+
+// exitStatus reports err, from the command called name, and is the
+// status to exit with
+func exitStatus(name string, err error) int {
+    var code exitCode
+    if err == nil {
+        return 0
+    }
+    if errors.As(err, &code) {
+        return int(code)
+    }
+    if name != terminalGame.name {
+        err = fmt.Errorf("%s: %w", name, err)
+    }
+    fmt.Fprintln(os.Stderr, err)
+    if errors.As(err, new(usageError)) {
+        return 2
+    }
+    return 1
+}
+
+// commandFlags is the FlagSet for cmd's flags, before it defines them
+func commandFlags(cmd cliCommand) *flag.FlagSet {
+    fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+    fs.String("config", "", "file of default flag settings, a line of name: value each (default: config.yaml under the config directory; \"none\" for no file)")
+    fs.Usage = func() { commandUsage(fs, cmd) }
+    return fs
+}
+
+// parseFlags parses args into fs, cmd's flags, then sets the flags they
+// didn't give from the environment and the config file
+func parseFlags(fs *flag.FlagSet, args []string, cmd cliCommand) error {
+    if err := parseArgs(fs, args, cmd.nested); err != nil {
+        if errors.Is(err, flag.ErrHelp) {
+            return err
+        }
+        return exitCode(2) // Parse has said what was wrong
+    }
+    known := cliFlags()
+    if err := applyEnvConfig(fs, os.Environ(), cmd.fixed, known); err != nil {
+        return usageError{fmt.Errorf("environment: %w", err)}
+    }
+    if err := applyUserConfig(fs, fs.Lookup("config").Value.String(), cmd.fixed, known); err != nil {
+        return usageError{fmt.Errorf("config: %w", err)}
+    }
+    return nil
+}
+
+// parseArgs parses args into fs, flags and arguments in any order unless
+// nested, when the flags stop at the first argument as in fs.Parse
+func parseArgs(fs *flag.FlagSet, args []string, nested bool) error {
+    if nested {
+        return fs.Parse(args)
+    }
+    var positional []string
+    for len(args) > 0 {
+        if err := fs.Parse(args); err != nil {
+            return err
+        }
+        rest := fs.Args()
+        if n := len(args) - len(rest); n > 0 && args[n-1] == "--" {
+            positional = append(positional, rest...) // no flags after --
+            break
+        }
+        if len(rest) == 0 {
+            break
+        }
+        positional, args = append(positional, rest[0]), rest[1:]
+    }
+    return fs.Parse(append([]string{"--"}, positional...))
+}
+
+// cliFlags is the name of every flag some command takes
+func cliFlags() map[string]bool {
+    names := map[string]bool{}
+    for _, cmd := range append([]cliCommand{terminalGame}, cliCommands...) {
+        if cmd.define == nil {
+            continue
+        }
+        fs := commandFlags(cmd)
+        cmd.define(fs)
+        fs.VisitAll(func(f *flag.Flag) { names[f.Name] = true })
+    }
+    return names
+}
+
+func isFlagArg(arg string) bool {
+    return len(arg) > 1 && arg[0] == '-'
+}
+
+// wantArgs checks the command was given from least to most arguments
+// after its flags, showing its usage if not
+func wantArgs(fs *flag.FlagSet, least, most int) error {
+    if n := fs.NArg(); n >= least && n <= most {
+        return nil
+    }
+    fs.Usage()
+    return exitCode(2)
+}
+
+// commandUsage prints cmd's usage and flags, defined on fs
+func commandUsage(fs *flag.FlagSet, cmd cliCommand) {
+    out := fs.Output()
+    if cmd.name == terminalGame.name {
+        cliUsage(fs, out)
+        return
+    }
+    fmt.Fprintf(out, "usage: snakesladders %s [flags] %s\n\n%s.\n", cmd.name, cmd.args, cmd.summary)
+    if strings.HasPrefix(cmd.name, "serve-") {
+        fmt.Fprintf(out, "\nWithout an addr, it listens on %sADDR if that is set.\n", envPrefix)
+    }
+    fmt.Fprintln(out, "\nFlags:")
+    fs.PrintDefaults()
+}
+
+// cliUsage prints the overall usage: the commands, then the game's flags,
+// defined on fs
+func cliUsage(fs *flag.FlagSet, out io.Writer) {
+    fmt.Fprintln(out, "usage: snakesladders [command] [flags] [args]")
+    fmt.Fprintln(out, "\nWith no command, plays a game at the terminal. Commands:")
+    for _, c := range cliCommands {
+        fmt.Fprintf(out, "  %-13s %s\n", c.name, c.summary)
+    }
+    fmt.Fprintln(out, "\nRun 'snakesladders help COMMAND' for a command's usage and flags.")
+    fmt.Fprintln(out, "Each flag can also be set in the environment, -players as "+envName("players")+",")
+    fmt.Fprintln(out, "or in config.yaml under the config directory (see -config).")
+    fmt.Fprintln(out, "\nFlags, for the game played with no command:")
+    fs.PrintDefaults()
+}
+
+// runHelp implements `help [command]`
+func runHelp(args []string, out io.Writer) error {
+    cmd := terminalGame
+    if len(args) > 0 {
+        name := strings.Join(args, "-") // help serve http is help serve-http
+        if len(args) > 2 || len(args) == 2 && args[0] != "serve" {
+            return usageError{errors.New("usage: help [command]")}
+        }
+        var ok bool
+        if cmd, ok = findCommand(name); !ok {
+            return usageError{fmt.Errorf("no command %q; run help for the list", name)}
+        }
+    }
+    if cmd.define == nil {
+        fmt.Fprintf(out, "usage: snakesladders %s %s\n\n%s.\n", cmd.name, cmd.args, cmd.summary)
+        if cmd.own != nil {
+            fmt.Fprintf(out, "\nRun 'snakesladders %s -h' for its flags.\n", cmd.name)
+        }
+        return nil
+    }
+    fs := commandFlags(cmd)
+    cmd.define(fs)
+    fs.SetOutput(out)
+    fs.Usage()
+    return nil
+}
+
+// gamesArg is the GAMES argument of simulate, difftest and fuzz
+func gamesArg(fs *flag.FlagSet) (int, error) {
+    if err := wantArgs(fs, 1, 1); err != nil {
+        return 0, err
+    }
+    games, err := strconv.Atoi(fs.Arg(0))
+    if err != nil || games < 1 {
+        return 0, usageError{fmt.Errorf("GAMES %q isn't a number of games", fs.Arg(0))}
+    }
+    return games, nil
+}
+
+func cmdSaves(fs *flag.FlagSet) func(context.Context) error {
+    storeSpec := addStoreFlag(fs)
+    lang := addLangFlag(fs)
+    return func(ctx context.Context) error {
+        store, err := openStore(*storeSpec)
+        if err != nil {
+            return usageError{err}
+        }
+        msgs, err := catalog(*lang)
+        if err != nil {
+            return err
+        }
+        return runSaves(fs.Args(), store, msgs, os.Stdout)
+    }
+}
+
+func cmdPlayer(fs *flag.FlagSet) func(context.Context) error {
+    storeSpec := addStoreFlag(fs)
+    lang := addLangFlag(fs)
+    return func(ctx context.Context) error {
+        profiles, err := openProfiles(*storeSpec)
+        if err != nil {
+            return usageError{err}
+        }
+        msgs, err := catalog(*lang)
+        if err != nil {
+            return err
+        }
+        return runPlayer(fs.Args(), profiles, msgs, os.Stdout)
+    }
+}
+
+func cmdDiffTest(fs *flag.FlagSet) func(context.Context) error {
+    boardPath := addBoardFlag(fs)
+    seed := addSeedFlag(fs, "the first game's RNG seed")
+    return func(ctx context.Context) error {
+        games, err := gamesArg(fs)
+        if err != nil {
+            return err
+        }
+        b, err := boardFlag(*boardPath)
+        if err != nil {
+            return err
+        }
+        return runDiffTest(b, []string{"Alice", "Bob", "Carol"}, *seed, games, 10000, os.Stdout)
+    }
+}
+
+func cmdFuzz(fs *flag.FlagSet) func(context.Context) error {
+    seed := addSeedFlag(fs, "the first game's RNG seed")
+    return func(ctx context.Context) error {
+        if _, err := gamesArg(fs); err != nil {
+            return err
+        }
+        return runFuzz(fs.Args(), *seed, os.Stdout)
+    }
+}
+
+func addIdleFlag(fs *flag.FlagSet) *time.Duration {
+    return fs.Duration("idle-timeout", defaultIdleTimeout, "how long a hosted game nobody plays is kept (0: for ever)")
+}
+
+func cmdServeHTTP(fs *flag.FlagSet) func(context.Context) error {
+    rules := addRulesFlags(fs)
+    logs := addLogFlags(fs)
+    players := fs.String("players", "Alice,Bob", "who is playing, in turn order")
+    storeSpec := addStoreFlag(fs)
+    sealDice := fs.Bool("seal-dice", false, "send players a hash of the dice seed instead of the seed, revealing it when the game ends")
+    tlsCert := fs.String("tls-cert", "", "serve HTTPS with this certificate file (PEM; needs -tls-key)")
+    tlsKey := fs.String("tls-key", "", "the private key file for -tls-cert")
+    takeSeats := fs.Bool("take-seats", false, "let latecomers take over the seats of players who left (otherwise they watch)")
+    rollRate := fs.Int("roll-rate", 5, "how many rolls a second each client may make (0: no limit)")
+    createRate := fs.Int("create-rate", 30, "how many games an hour each client may set up (0: no limit)")
+    idleTimeout := addIdleFlag(fs)
+    reconnectGrace := fs.Duration("reconnect-grace", time.Minute, "how long a disconnected player's seat waits for them to reconnect before they count as having left (0: for ever)")
+    telemetryURL := addTelemetryFlag(fs)
+    return func(ctx context.Context) error {
+        if err := wantArgs(fs, 0, 1); err != nil {
+            return err
+        }
+        if (*tlsCert == "") != (*tlsKey == "") {
+            return usageError{errors.New("-tls-cert and -tls-key go together")}
+        }
+        var opts Options
+        var err error
+        if opts.Store, err = openStore(*storeSpec); err != nil {
+            return usageError{err}
+        }
+        if err := rules.loadBoard(&opts); err != nil {
+            return err
+        }
+        names, err := parseNameList(*players)
+        if err == nil {
+            err = rules.apply(fs, &opts)
+        }
+        if err == nil {
+            err = startTelemetry(*telemetryURL)
+        }
+        if err != nil {
+            return usageError{err}
+        }
+        l, err := logs.open(&opts)
+        if err != nil {
+            return err
+        }
+        defer l.Close()
+        s, err := newWebServer(opts.Board, names, opts.Rules, opts.Deps, opts.Observers)
+        if err != nil {
+            return err
+        }
+        s.configure(*takeSeats, *reconnectGrace, *idleTimeout)
+        s.sealDice = *sealDice
+        s.tlsCert, s.tlsKey = *tlsCert, *tlsKey
+        s.store = opts.Store
+        s.limit(*rollRate, *createRate)
+        return withTelemetry(ctx, *telemetryURL, func() error {
+            return serveWeb(ctx, serverAddr(fs, ":8080"), s, os.Stdout)
+        })
+    }
+}
+
+func cmdServeGRPC(fs *flag.FlagSet) func(context.Context) error {
+    storeSpec := addStoreFlag(fs)
+    idleTimeout := addIdleFlag(fs)
+    metricsAddr := fs.String("metrics-addr", "", "serve Prometheus metrics at /metrics on this address")
+    telemetryURL := addTelemetryFlag(fs)
+    return func(ctx context.Context) error {
+        if err := wantArgs(fs, 0, 1); err != nil {
+            return err
+        }
+        store, err := openStore(*storeSpec)
+        if err == nil {
+            err = startTelemetry(*telemetryURL)
+        }
+        if err != nil {
+            return usageError{err}
+        }
+        if *metricsAddr != "" {
+            go func() {
+                if err := serveMetrics(ctx, *metricsAddr, serverMetrics); err != nil {
+                    fmt.Fprintln(os.Stderr, "serve-grpc: metrics:", err)
+                }
+            }()
+        }
+        return withTelemetry(ctx, *telemetryURL, func() error {
+            if grpcServe == nil {
+                return errNoGRPC
+            }
+            return grpcServe(ctx, serverAddr(fs, ":50051"), *idleTimeout, store)
+        })
+    }
+}
+
+func cmdServeSSH(fs *flag.FlagSet) func(context.Context) error {
+    f := addPlayFlags(fs)
+    hostKey := fs.String("ssh-host-key", "", "the server's private key file, made if missing (default: under the config directory)")
+    telemetryURL := addTelemetryFlag(fs)
+    return func(ctx context.Context) error {
+        if err := wantArgs(fs, 0, 1); err != nil {
+            return err
+        }
+        opts, err := f.options()
+        if err == nil {
+            err = startTelemetry(*telemetryURL)
+        }
+        if err != nil {
+            return err
+        }
+        s, err := f.session(ctx, fs, &opts)
+        if err != nil {
+            return err
+        }
+        defer s.Close()
+        if *f.bots != "" {
+            bots, levels, err := parseBots(*f.bots)
+            if err != nil {
+                return usageError{err}
+            }
+            opts.Controllers = map[string]PlayerController{}
+            for _, n := range bots {
+                opts.Controllers[n] = botController{level: levels[n]}
+            }
+        }
+        return withTelemetry(ctx, *telemetryURL, func() error {
+            if sshServe == nil {
+                return errNoSSH
+            }
+            return sshServe(ctx, serverAddr(fs, ":2222"), *hostKey, newSSHLobby(ctx, s.names, opts).Run)
+        })
+    }
+}
+
+func cmdJoin(fs *flag.FlagSet) func(context.Context) error {
+    return func(ctx context.Context) error {
+        if err := wantArgs(fs, 2, 2); err != nil {
+            return err
+        }
+        return runJoin(ctx, fs.Arg(0), fs.Arg(1), os.Stdin, os.Stdout)
+    }
+}
+
+func cmdWatch(fs *flag.FlagSet) func(context.Context) error {
+    return func(ctx context.Context) error {
+        if err := wantArgs(fs, 0, 1); err != nil {
+            return err
+        }
+        addr := fs.Arg(0)
+        if addr == "" {
+            addr = "localhost:7070"
+        }
+        return runWatch(ctx, addr, os.Stdout)
+    }
+}
+
+func cmdEdit(fs *flag.FlagSet) func(context.Context) error {
+    themeName := addThemeFlag(fs)
+    lang := addLangFlag(fs)
+    return func(ctx context.Context) error {
+        if err := wantArgs(fs, 1, 1); err != nil {
+            return err
+        }
+        msgs, err := catalog(*lang)
+        if err != nil {
+            return err
+        }
+        theme, err := ParseTheme(*themeName)
+        if err != nil {
+            return usageError{err}
+        }
+        return runEditor(fs.Arg(0), os.Stdin, os.Stdout, theme, msgs)
+    }
+}
+
+func cmdHistory(fs *flag.FlagSet) func(context.Context) error {
+    historyPath := addHistoryFlag(fs)
+    lang := addLangFlag(fs)
+    return func(ctx context.Context) error {
+        msgs, err := catalog(*lang)
+        if err != nil {
+            return err
+        }
+        return runHistory(fs.Args(), *historyPath, msgs, os.Stdout)
+    }
+}
+
+func cmdStats(fs *flag.FlagSet) func(context.Context) error {
+    historyPath := addHistoryFlag(fs)
+    lang := addLangFlag(fs)
+    return func(ctx context.Context) error {
+        if err := wantArgs(fs, 0, 0); err != nil {
+            return err
+        }
+        msgs, err := catalog(*lang)
+        if err != nil {
+            return err
+        }
+        return runStats(*historyPath, msgs, os.Stdout)
+    }
+}
+
+func cmdRatings(fs *flag.FlagSet) func(context.Context) error {
+    return func(ctx context.Context) error {
+        if err := wantArgs(fs, 0, 0); err != nil {
+            return err
+        }
+        r, err := loadRatings()
+        if err != nil {
+            return err
+        }
+        printLadder(os.Stdout, r)
+        return nil
+    }
+}
+
+func cmdDebug(fs *flag.FlagSet) func(context.Context) error {
+    themeName := addThemeFlag(fs)
+    lang := addLangFlag(fs)
+    rules := addRulesFlags(fs)
+    players := fs.String("players", "Alice,Bob", "who is playing, in turn order")
+    seed := addSeedFlag(fs, "the dice's seed")
+    return func(ctx context.Context) error {
+        if err := wantArgs(fs, 0, 1); err != nil {
+            return err
+        }
+        var opts Options
+        var err error
+        if opts.Msgs, err = catalog(*lang); err != nil {
+            return err
+        }
+        if opts.Theme, err = ParseTheme(*themeName); err != nil {
+            return usageError{err}
+        }
+        if err := rules.loadBoard(&opts); err != nil {
+            return err
+        }
+        names, err := parseNameList(*players)
+        if err != nil {
+            return usageError{err}
+        }
+        if err := rules.apply(fs, &opts); err != nil {
+            return err
+        }
+        gs := newGameState(opts.Board, names)
+        gs.RandState, gs.Dice = uint64(*seed), NewDiceStream(uint64(*seed))
+        gs.setRules(opts.Rules)
+        placeAtStart(&gs)
+        setupTokens(&gs, opts.Rules.Tokens)
+        return runDebug(fs.Args(), gs, opts.Theme, opts.Msgs, os.Stdin, os.Stdout)
+    }
+}
+
+func cmdTranscript(fs *flag.FlagSet) func(context.Context) error {
+    lang := addLangFlag(fs)
+    return func(ctx context.Context) error {
+        msgs, err := catalog(*lang)
+        if err != nil {
+            return err
+        }
+        return runTranscript(fs.Args(), os.Stdout, msgs)
+    }
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+    "crypto/rand"
+    "fmt"
+    mrand "math/rand"
+    "sort"
+    "sync"
+    "time"
+)
+
+// Clock is the engine's only source of time, so tests can drive timers
+// (turn clocks, timestamps) deterministically
+type Clock interface {
+    Now() time.Time
+    After(d time.Duration) <-chan time.Time
+}
+
+// IDGenerator hands out identifiers for games and other records
+type IDGenerator interface {
+    NewID() string
+}
+
+// Deps are the engine's outside-world dependencies. Zero fields fall back
+// to the wall clock, random UUIDs and a time-seeded Rand.
+type Deps struct {
+    Clock Clock
+    IDs   IDGenerator
+    // Rand drives the choices a game makes other than the dice, such as
+    // bots'. It is not safe for concurrent use: give each game its own.
+    Rand *mrand.Rand
+}
+
+func (d Deps) withDefaults() Deps {
+    if d.Clock == nil {
+        d.Clock = systemClock{}
+    }
+    if d.IDs == nil {
+        d.IDs = randomIDs{}
+    }
+    if d.Rand == nil {
+        d.Rand = mrand.New(mrand.NewSource(time.Now().UnixNano()))
+    }
+    return d
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// randomIDs generates version 4 UUIDs
+type randomIDs struct{}
+
+func (randomIDs) NewID() string {
+    var b [16]byte
+    if _, err := rand.Read(b[:]); err != nil {
+        panic(err) // crypto/rand never fails on supported platforms
+    }
+    b[6] = b[6]&0x0f | 0x40
+    b[8] = b[8]&0x3f | 0x80
+    return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// SequentialIDs yields Prefix1, Prefix2, ...
+type SequentialIDs struct {
+    Prefix string
+    mu     sync.Mutex
+    n      int
+}
+
+func (s *SequentialIDs) NewID() string {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.n++
+    return fmt.Sprintf("%s%d", s.Prefix, s.n)
+}
+
+// FakeClock only moves when told to. Timers from After fire as Advance
+// carries the clock past their deadline.
+type FakeClock struct {
+    mu      sync.Mutex
+    now     time.Time
+    waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+    at time.Time
+    ch chan time.Time
+}
+
+func NewFakeClock(start time.Time) *FakeClock {
+    return &FakeClock{now: start}
+}
+
+func (c *FakeClock) Now() time.Time {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    return c.now
+}
+
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    ch := make(chan time.Time, 1)
+    if d <= 0 {
+        ch <- c.now
+        return ch
+    }
+    c.waiters = append(c.waiters, fakeWaiter{c.now.Add(d), ch})
+    return ch
+}
+
+// Advance moves the clock forward and fires every timer now due, in
+// deadline order
+func (c *FakeClock) Advance(d time.Duration) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.now = c.now.Add(d)
+    sort.Slice(c.waiters, func(i, j int) bool { return c.waiters[i].at.Before(c.waiters[j].at) })
+    keep := c.waiters[:0]
+    for _, w := range c.waiters {
+        if w.at.After(c.now) {
+            keep = append(keep, w)
+            continue
+        }
+        w.ch <- w.at
+    }
+    c.waiters = keep
+}
+
+// Waiters reports how many timers are pending, so a test can wait until
+// the code under test is blocked on the clock before advancing it
+func (c *FakeClock) Waiters() int {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    return len(c.waiters)
+}
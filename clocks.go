@@ -0,0 +1,149 @@
+package main
+
+import (
+    "fmt"
+    "strings"
+    "time"
+)
+
+// Blitz clocks: with Rules.Clock set, every player has that much time for
+// the whole game, and theirs runs while it is their turn to roll. A player
+// whose clock runs out is out of the game, as if they had left (their
+// EventLeave gives the reason time_up); once only one player, or one team,
+// has time left, they win. Clocks follow the wall clock rather than the
+// moves, so stateHash leaves them out and a replay can't check them.
+
+// timeUpReason is the Reason on the EventLeave of a player out of time
+const timeUpReason = "time_up"
+
+// startClocks gives every player in gs the game's time
+func startClocks(gs *GameState) {
+    for i := range gs.Players {
+        gs.Players[i].Clock = gs.Rules.Clock
+    }
+}
+
+// flagFall is gs after the player at idx runs out of time
+func flagFall(gs GameState, idx int) GameState {
+    gs = leaveSeat(gs, idx)
+    gs.Players[idx].Clock = 0
+    return gs
+}
+
+// clockOutcome is how gs ends once someone has run out of time: the one
+// player or team with time left wins, and with nobody left the game is
+// abandoned. ok is false while nobody has, or several still play on.
+func clockOutcome(gs GameState) (o Outcome, ok bool) {
+    if gs.Rules.Clock <= 0 {
+        return nil, false
+    }
+    flagged := false
+    var standing []Player
+    for _, p := range gs.Players {
+        switch {
+        case !p.Left:
+            standing = append(standing, p)
+        case p.Clock <= 0:
+            flagged = true
+        }
+    }
+    if !flagged {
+        return nil, false
+    }
+    if len(standing) == 0 {
+        return Abandoned{gs, AbortPlayersLeft}, true
+    }
+    for _, p := range standing[1:] {
+        if p.Team == "" || p.Team != standing[0].Team {
+            return nil, false
+        }
+    }
+    return Win{Winner: standing[0], Team: standing[0].Team}, true
+}
+
+// timeUpEvent logs the player at idx running out of time, gs being the
+// state after
+func timeUpEvent(gs GameState, idx, turns int, now time.Time) Event {
+    return Event{Kind: EventLeave, Time: now, Turn: turns, Player: gs.Players[idx].Name, Reason: timeUpReason, Hash: stateHash(gs)}
+}
+
+// clockLine is each player's time left, as "Alice 2:31, Bob 3:00", or ""
+// when nobody's clock is running
+func clockLine(players []Player) string {
+    var parts []string
+    running := false
+    for _, p := range players {
+        running = running || p.Clock > 0
+        parts = append(parts, p.Name+" "+formatClock(p.Clock))
+    }
+    if !running {
+        return ""
+    }
+    return strings.Join(parts, ", ")
+}
+
+// formatClock shows d as minutes and seconds, rounding up so that a clock
+// only reads 0:00 once it has run out
+func formatClock(d time.Duration) string {
+    s := int(max(d+time.Second-1, 0) / time.Second)
+    return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
+
+// A Game's clocks start with its first roll: a hosted game's players may
+// not all have arrived before then.
+
+// startClock sets the current player's clock running, with a watch that
+// puts them out if it runs out before they roll
+func (g *Game) startClock(now time.Time) {
+    if g.state.Rules.Clock <= 0 {
+        return
+    }
+    g.ticking, g.tickSince = g.state.CurrentPlayerIndex, now
+    g.tickSeq++
+    seq, timer := g.tickSeq, g.clock.After(g.state.Players[g.ticking].Clock)
+    go func() {
+        <-timer
+        g.mu.Lock()
+        defer g.mu.Unlock()
+        if g.tickSeq == seq && g.ticking >= 0 && !g.over() {
+            g.timeUp(g.clock.Now())
+        }
+    }()
+}
+
+// stopClock takes the time since the running clock started off its
+// player's
+func (g *Game) stopClock(now time.Time) {
+    if g.ticking < 0 {
+        return
+    }
+    g.state.Players = append([]Player(nil), g.state.Players...)
+    g.state.Players[g.ticking].Clock -= now.Sub(g.tickSince)
+    g.ticking = -1
+}
+
+// outOfTime reports whether the running clock has run out by now
+func (g *Game) outOfTime(now time.Time) bool {
+    return g.ticking >= 0 && now.Sub(g.tickSince) >= g.state.Players[g.ticking].Clock
+}
+
+// timeUp puts the player whose clock has run out out of the game
+func (g *Game) timeUp(now time.Time) {
+    idx := g.ticking
+    g.ticking = -1
+    g.state = flagFall(g.state, idx)
+    evs := []Event{timeUpEvent(g.state, idx, g.turns, now)}
+    g.outcome = checkOutcome(g.state)
+    switch o := g.outcome.(type) {
+    case Win:
+        evs = append(evs, winEvent(o, g.state, g.turns, now))
+    case Abandoned:
+        g.outcome = Abandoned{g.copyState(), o.Reason}
+        evs = append(evs, Event{Kind: EventAbort, Time: now, Turn: g.turns, Reason: string(o.Reason), Hash: stateHash(g.state)})
+    case Ongoing:
+        g.startClock(now)
+    }
+    for _, ev := range evs {
+        notify(g.observers, ev)
+    }
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+    "fmt"
+    "io"
+)
+
+const (
+    // coachBigFall is how many squares a snake must cost to be worth a word
+    coachBigFall = 20
+    // coachLongShot is the win chance below which a comeback is remarkable
+    coachLongShot = 0.10
+)
+
+// coach is an Observer explaining the odds behind notable moments: big
+// snake falls, and winners who at some point were long shots. It follows
+// the game by replaying its events from their setup, so its odds are for
+// the game's own rules, tokens and position (see winOdds).
+type coach struct {
+    out    io.Writer
+    msgs   Catalog
+    game   replayer
+    lowest map[string]float64 // each player's worst win chance so far
+    lowAt  map[string]int     // and the turn it happened
+    from   int                // square the last move started on
+}
+
+func newCoach(out io.Writer, msgs Catalog) *coach {
+    return &coach{out: out, msgs: msgs}
+}
+
+func (c *coach) OnEvent(ev Event) {
+    // a game it can't follow leaves it quiet until the next setup
+    c.game.apply(ev)
+    switch ev.Kind {
+    case EventStart, EventRestore:
+        if ev.Kind == EventStart || c.lowest == nil {
+            c.lowest = map[string]float64{}
+            c.lowAt = map[string]int{}
+        }
+    case EventRoll:
+        if !c.game.playing {
+            return
+        }
+        gs := c.game.gs
+        for i, pr := range winOdds(gs) {
+            name := gs.Players[i].Name
+            if low, seen := c.lowest[name]; !seen || pr < low {
+                c.lowest[name], c.lowAt[name] = pr, ev.Turn
+            }
+        }
+    case EventMove:
+        c.from = ev.From
+    case EventSnake:
+        if ev.From-ev.To >= coachBigFall && c.from > 0 {
+            fmt.Fprintln(c.out, c.msgs.T(MsgCoachFall, ev.From, ev.To, c.from, 100*snakeChance(c.game.gs.Board, mustBP(c.from))))
+        }
+    case EventWin:
+        if low, seen := c.lowest[ev.Player]; seen && low <= coachLongShot {
+            fmt.Fprintln(c.out, c.msgs.T(MsgCoachLongShot, ev.Player, 100*low, c.lowAt[ev.Player]))
+        }
+    }
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+)
+
+// cmdResult says whether play carries on after a command
+type cmdResult int
+
+const (
+    cmdContinue cmdResult = iota
+    cmdQuit
+    cmdPause
+)
+
+// handleCommand runs a command typed at the roll prompt instead of Enter.
+// Commands may replace the game state (restoring a bookmark branches play
+// from that point, using an item arms it); events they cause go to emit.
+// It reports whether the player asked to quit or pause.
+func handleCommand(r Renderer, line string, state *GameState, turns *int, emit func(Event)) (res cmdResult) {
+    fields := strings.Fields(line)
+    switch fields[0] {
+    case "bookmark":
+        if len(fields) != 2 {
+            r.RenderNotice(MsgBookmarkUsage)
+            return
+        }
+        if err := addBookmark(fields[1], takeSnapshot(*state, *turns)); err != nil {
+            r.RenderNotice(MsgBookmarkFailed, err)
+            return
+        }
+        r.RenderNotice(MsgBookmarkSaved, fields[1])
+    case "restore":
+        if len(fields) != 2 {
+            r.RenderNotice(MsgRestoreUsage)
+            return
+        }
+        marks, err := loadBookmarks()
+        if err != nil {
+            r.RenderNotice(MsgBookmarkFailed, err)
+            return
+        }
+        snap, ok := marks[fields[1]]
+        if !ok {
+            r.RenderNotice(MsgNoBookmark, fields[1])
+            return
+        }
+        gs, err := snap.Restore(state.Board)
+        if err != nil {
+            r.RenderNotice(MsgBookmarkFailed, err)
+            return
+        }
+        gs.setRules(state.Rules)
+        *state, *turns = gs, snap.Turn
+        emit(Event{Kind: EventRestore, Reason: fields[1], Dice: &gs.Dice, Setup: setupOf(gs, snap.Turn), Hash: stateHash(gs)})
+        r.RenderNotice(MsgRestored, fields[1], snap.Turn)
+    case "quit":
+        return cmdQuit
+    case "pause":
+        return cmdPause
+    case "items":
+        cur := state.Players[state.CurrentPlayerIndex]
+        if len(cur.Items) == 0 {
+            r.RenderNotice(MsgNoItems, cur.Name)
+            return
+        }
+        names := make([]string, len(cur.Items))
+        for i, it := range cur.Items {
+            names[i] = string(it)
+        }
+        r.RenderNotice(MsgItems, cur.Name, strings.Join(names, ", "))
+    case "use":
+        if len(fields) != 2 {
+            r.RenderNotice(MsgUseUsage)
+            return
+        }
+        item, err := parseItem(fields[1])
+        if err == nil {
+            var gs GameState
+            if gs, err = useItem(*state, item); err == nil {
+                *state = gs
+            }
+        }
+        if err != nil {
+            r.RenderNotice(MsgItemFailed, err)
+            return
+        }
+        name := state.Players[state.CurrentPlayerIndex].Name
+        emit(Event{Kind: EventUseItem, Player: name, Item: string(item), Hash: stateHash(*state)})
+        r.RenderNotice(MsgItemUsed, name, item)
+    case "board":
+        r.RenderBoard(*state)
+    case "stats":
+        for _, p := range state.Players {
+            faces := make([]string, len(p.Stats.Faces))
+            for i, n := range p.Stats.Faces {
+                faces[i] = fmt.Sprintf("%d:%d", i+1, n)
+            }
+            st := p.Stats
+            r.RenderNotice(MsgStats, p.Name, st.Rolls, strings.Join(faces, " "), st.Snakes, st.Ladders, st.Traveled)
+        }
+    case "history":
+        // every roll so far, or NAME's
+        for _, p := range state.Players {
+            if len(fields) > 1 && !strings.EqualFold(p.Name, fields[1]) {
+                continue
+            }
+            if len(p.Stats.History) == 0 {
+                r.RenderNotice(MsgNoRolls, p.Name)
+                continue
+            }
+            rolls := make([]string, len(p.Stats.History))
+            for i, v := range p.Stats.History {
+                rolls[i] = strconv.Itoa(v)
+            }
+            r.RenderNotice(MsgRollHistory, p.Name, strings.Join(rolls, " "))
+        }
+    case "bookmarks":
+        marks, err := loadBookmarks()
+        if err != nil {
+            r.RenderNotice(MsgBookmarkFailed, err)
+            return
+        }
+        for _, n := range bookmarkNames(marks) {
+            r.RenderNotice(MsgBookmarkEntry, n, marks[n].Turn)
+        }
+    default:
+        r.RenderNotice(MsgUnknownCommand, fields[0])
+    }
+    return cmdContinue
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "fmt"
+)
+
+// Dice commit-reveal. A game's rolls all follow from its dice seed, so a
+// player who saw the seed could tell every roll to come. With sealing on,
+// the events players are sent carry a commitment to the seed in its
+// place, and the seed itself only once the game is over: they can then
+// check it matches what was committed to and audit every roll against it,
+// so the server could neither foresee nor change the dice without being
+// caught.
+
+// diceCommitment is the hex SHA-256 of the dice stream's algorithm and
+// seed
+func diceCommitment(d DiceStream) string {
+    sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%d", d.Algorithm, d.Seed)))
+    return hex.EncodeToString(sum[:])
+}
+
+// sealed is d without its seed
+func sealed(d DiceStream) *DiceStream {
+    d.Seed = 0
+    return &d
+}
+
+// diceSealer seals the dice in a stream of events, which it must see in
+// full and in order, and reveals them on the event that ends the game
+type diceSealer struct {
+    dice *DiceStream // the stream being kept secret
+}
+
+func (s *diceSealer) seal(ev Event) Event {
+    switch ev.Kind {
+    case EventStart, EventRestore:
+        if ev.Dice == nil {
+            return ev
+        }
+        d := *ev.Dice
+        s.dice = &d
+        ev.Commit, ev.Dice = diceCommitment(d), sealed(d)
+        if ev.Setup != nil {
+            setup := *ev.Setup
+            setup.State.Dice = *sealed(setup.State.Dice)
+            ev.Setup = &setup
+        }
+    case EventWin, EventDraw, EventAbort:
+        if s.dice != nil {
+            d := *s.dice
+            ev.Dice = &d
+        }
+    }
+    return ev
+}
+
+// unsealEvents puts the seed each game in evs reveals at its end back on
+// its start and restore events, checking it against their commitments;
+// events that were never sealed pass through as they are
+func unsealEvents(evs []Event) ([]Event, error) {
+    out := append([]Event(nil), evs...)
+    var pending []int // sealed events of the game being read
+    for i, ev := range out {
+        switch {
+        case ev.Commit != "":
+            pending = append(pending, i)
+        case ev.Kind == EventWin || ev.Kind == EventDraw || ev.Kind == EventAbort:
+            if len(pending) == 0 {
+                continue
+            }
+            if ev.Dice == nil {
+                return nil, fmt.Errorf("event %d: game ends without revealing the dice it committed to", i+1)
+            }
+            for _, j := range pending {
+                if got := diceCommitment(*ev.Dice); got != out[j].Commit {
+                    return nil, fmt.Errorf("event %d: revealed dice don't match the commitment made at event %d", i+1, j+1)
+                }
+                d := *out[j].Dice
+                d.Seed = ev.Dice.Seed
+                out[j].Dice = &d
+                if out[j].Setup != nil {
+                    setup := *out[j].Setup
+                    setup.State.Dice.Seed = ev.Dice.Seed
+                    out[j].Setup = &setup
+                }
+            }
+            pending = nil
+        }
+    }
+    if len(pending) > 0 {
+        return nil, fmt.Errorf("event %d: the dice are sealed until the game ends", pending[0]+1)
+    }
+    return out, nil
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+    "bufio"
+    "bytes"
+    "errors"
+    "flag"
+    "fmt"
+    "io/fs"
+    "os"
+    "slices"
+    "strings"
+)
+
+// The user config file (config.yaml in the config directory, or -config)
+// holds defaults for the shared flags, named as the flags are:
+//
+//    # my usual game
+//    players: [Alice, Bob, Carol]
+//    theme: emoji
+//    rules: exact_win,six_again
+//    keys: true
+//
+// It is read as a small part of YAML: one "name: value" a line, values
+// plain or quoted, lists in brackets or as "- item" lines under their
+// name (joined with commas, as the flags take them), and # comments. A
+// flag given on the command line wins over the file.
+//
+// Every flag can be set in the environment too, for servers run in
+// containers with no file to hand: -roll-rate as SNL_ROLL_RATE, and so
+// on, with SNL_ADDR for a server's address. The command line wins over
+// the environment, and the environment over the file.
+
+// configSetting is one name and value from the config file
+type configSetting struct {
+    line        int
+    name, value string
+}
+
+// parseUserConfig reads the settings in a config file
+func parseUserConfig(data []byte) ([]configSetting, error) {
+    var settings []configSetting
+    var errs []error
+    list := -1 // the setting "- item" lines add to, if any
+    sc := bufio.NewScanner(bytes.NewReader(data))
+    for n := 1; sc.Scan(); n++ {
+        line := stripConfigComment(sc.Text())
+        trimmed := strings.TrimSpace(line)
+        if trimmed == "" {
+            continue
+        }
+        if item, ok := strings.CutPrefix(trimmed, "- "); ok && line != trimmed && list >= 0 {
+            s := &settings[list]
+            s.value = strings.TrimPrefix(s.value+","+configScalar(item), ",")
+            continue
+        }
+        name, value, ok := strings.Cut(trimmed, ":")
+        if !ok || line != trimmed || strings.ContainsAny(name, " \t") {
+            errs = append(errs, fmt.Errorf("line %d: want name: value", n))
+            list = -1
+            continue
+        }
+        value = strings.TrimSpace(value)
+        if inner, ok := strings.CutPrefix(value, "["); ok && strings.HasSuffix(inner, "]") {
+            var items []string
+            for _, it := range strings.Split(strings.TrimSuffix(inner, "]"), ",") {
+                if it = configScalar(it); it != "" {
+                    items = append(items, it)
+                }
+            }
+            value = strings.Join(items, ",")
+        } else {
+            value = configScalar(value)
+        }
+        settings = append(settings, configSetting{n, name, value})
+        list = -1
+        if value == "" {
+            list = len(settings) - 1
+        }
+    }
+    if err := sc.Err(); err != nil {
+        return nil, err
+    }
+    return settings, errors.Join(errs...)
+}
+
+// stripConfigComment cuts a # comment off line, unless it is quoted
+func stripConfigComment(line string) string {
+    quote := rune(0)
+    for i, r := range line {
+        switch {
+        case quote != 0:
+            if r == quote {
+                quote = 0
+            }
+        case r == '"' || r == '\'':
+            quote = r
+        case r == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+            return line[:i]
+        }
+    }
+    return line
+}
+
+// configScalar is a plain or quoted value without its quotes
+func configScalar(s string) string {
+    s = strings.TrimSpace(s)
+    if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+        return s[1 : len(s)-1]
+    }
+    return s
+}
+
+// envPrefix starts the name of each environment variable setting a flag
+const envPrefix = "SNL_"
+
+// envName is the environment variable for the flag called name
+func envName(name string) string {
+    return envPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// applyEnvConfig sets the flags whose SNL_ variables environ, as from
+// os.Environ, has and the command line didn't give, leaving out those
+// skip rules out. A variable for a flag only other commands take, one
+// of known, is passed over; one naming no flag at all is a mistake, a
+// typo most likely.
+func applyEnvConfig(flags *flag.FlagSet, environ []string, skip []string, known map[string]bool) error {
+    byEnv := map[string]string{}
+    for name := range known {
+        byEnv[envName(name)] = name
+    }
+    given := map[string]bool{}
+    flags.Visit(func(f *flag.Flag) { given[f.Name] = true })
+    var errs []error
+    for _, kv := range environ {
+        env, value, _ := strings.Cut(kv, "=")
+        if !strings.HasPrefix(env, envPrefix) || env == envPrefix+"ADDR" {
+            continue
+        }
+        name, ok := byEnv[env]
+        switch {
+        case !ok || name == "config":
+            errs = append(errs, fmt.Errorf("%s names no flag", env))
+        case flags.Lookup(name) == nil || given[name] || slices.Contains(skip, name):
+        default:
+            if err := flags.Set(name, value); err != nil {
+                errs = append(errs, fmt.Errorf("%s: %w", env, err))
+            }
+        }
+    }
+    return errors.Join(errs...)
+}
+
+// serverAddr is where a server listens: the address its command was
+// given, else SNL_ADDR, else def
+func serverAddr(flags *flag.FlagSet, def string) string {
+    if addr := flags.Arg(0); addr != "" {
+        return addr
+    }
+    if addr := os.Getenv(envPrefix + "ADDR"); addr != "" {
+        return addr
+    }
+    return def
+}
+
+// applyUserConfig sets the flags named in the config file at path
+// ("" for the usual one, "none" for no file) and not already given on
+// the command line, leaving out those skip rules out and passing over
+// those only other commands take, the rest of known. A missing file is
+// no error.
+func applyUserConfig(flags *flag.FlagSet, path string, skip []string, known map[string]bool) error {
+    if path == "none" {
+        return nil
+    }
+    if path == "" {
+        var err error
+        if path, err = appPath("config.yaml"); err != nil {
+            return nil // no config directory, so no config
+        }
+    }
+    data, err := os.ReadFile(path)
+    if errors.Is(err, fs.ErrNotExist) {
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+    settings, err := parseUserConfig(data)
+    errs := []error{err}
+    given := map[string]bool{}
+    flags.Visit(func(f *flag.Flag) { given[f.Name] = true })
+    for _, s := range settings {
+        switch {
+        case s.name == "config" || !known[s.name]:
+            errs = append(errs, fmt.Errorf("line %d: no setting %q (settings are named as the flags are)", s.line, s.name))
+        case flags.Lookup(s.name) == nil || given[s.name] || slices.Contains(skip, s.name):
+        default:
+            if err := flags.Set(s.name, s.value); err != nil {
+                errs = append(errs, fmt.Errorf("line %d: %s: %w", s.line, s.name, err))
+            }
+        }
+    }
+    if err := errors.Join(errs...); err != nil {
+        return fmt.Errorf("%s: %w", path, err)
+    }
+    return nil
+}
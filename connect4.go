@@ -0,0 +1,147 @@
+package main
+
+import (
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "strconv"
+    "strings"
+)
+
+// ConnectFour is Connect Four for two: players take turns dropping a
+// piece down one of the grid's columns, where it falls to the lowest free
+// square, and the first with four in a line across, down or diagonally
+// wins. A full grid is a draw. Moves are column numbers from 1, left to
+// right.
+type ConnectFour struct {
+    names []string
+    rows  int
+    cols  int
+    cells []int // row by row from the top; 0 empty, else 1 + the player's index
+    cur   int
+    drops int
+    won   int // 1 + the winner's index
+}
+
+// connectFourPieces are how each seat's pieces are drawn
+var connectFourPieces = [2]string{"X", "O"}
+
+// connectFourRun is the line length that wins
+const connectFourRun = 4
+
+func NewConnectFour(names []string, rows, cols int) (*ConnectFour, error) {
+    if len(names) != 2 {
+        return nil, fmt.Errorf("Connect Four is for two players, not %d", len(names))
+    }
+    if rows < connectFourRun || cols < connectFourRun || rows > 20 || cols > 20 {
+        return nil, fmt.Errorf("a %dx%d grid won't do (want %d to 20 each way)", cols, rows, connectFourRun)
+    }
+    return &ConnectFour{names: names, rows: rows, cols: cols, cells: make([]int, rows*cols)}, nil
+}
+
+// newConnectFourFromArgs reads `play connect4 [-rows N] [-cols N]`
+func newConnectFourFromArgs(args, names []string, opts Options) (TurnGame, error) {
+    fs := flag.NewFlagSet("connect4", flag.ContinueOnError)
+    rows := fs.Int("rows", 6, "rows in the grid")
+    cols := fs.Int("cols", 7, "columns in the grid")
+    if err := fs.Parse(args); err != nil {
+        return nil, err
+    }
+    if fs.NArg() > 0 {
+        return nil, errors.New("usage: play connect4 [-rows N] [-cols N]")
+    }
+    return NewConnectFour(names, *rows, *cols)
+}
+
+func (g *ConnectFour) at(r, c int) int {
+    return g.cells[r*g.cols+c]
+}
+
+func (g *ConnectFour) Players() []string {
+    return append([]string(nil), g.names...)
+}
+
+func (g *ConnectFour) CurrentPlayer() PlayerID {
+    return PlayerID(g.cur)
+}
+
+// LegalMoves are the columns that aren't full
+func (g *ConnectFour) LegalMoves() []string {
+    if g.won != 0 {
+        return nil
+    }
+    var moves []string
+    for c := 0; c < g.cols; c++ {
+        if g.at(0, c) == 0 {
+            moves = append(moves, strconv.Itoa(c+1))
+        }
+    }
+    return moves
+}
+
+func (g *ConnectFour) Apply(move string) ([]Event, error) {
+    if _, ongoing := g.Outcome().(Ongoing); !ongoing {
+        return nil, ErrGameOver
+    }
+    col, err := strconv.Atoi(strings.TrimSpace(move))
+    if err != nil || col < 1 || col > g.cols {
+        return nil, fmt.Errorf("no column %q (want 1 to %d)", move, g.cols)
+    }
+    c := col - 1
+    r := g.rows - 1
+    for r >= 0 && g.at(r, c) != 0 {
+        r--
+    }
+    if r < 0 {
+        return nil, fmt.Errorf("column %d is full", col)
+    }
+    g.drops++
+    ev := Event{Kind: EventDrop, Turn: g.drops, Player: g.names[g.cur], To: col}
+    g.cells[r*g.cols+c] = g.cur + 1
+    if inARow(g.rows, g.cols, r, c, connectFourRun, g.at) {
+        g.won = g.cur + 1
+    }
+    g.cur = 1 - g.cur
+    return []Event{ev}, nil
+}
+
+func (g *ConnectFour) Outcome() Outcome {
+    switch {
+    case g.won != 0:
+        return Win{Winner: Player{Name: g.names[g.won-1]}}
+    case g.drops == len(g.cells):
+        return Draw{}
+    }
+    return Ongoing{}
+}
+
+// Render draws the grid with the column numbers underneath
+func (g *ConnectFour) Render(w io.Writer) {
+    width := len(strconv.Itoa(g.cols))
+    fmt.Fprintf(w, "%s is %s, %s is %s\n", g.names[0], connectFourPieces[0], g.names[1], connectFourPieces[1])
+    for r := 0; r < g.rows; r++ {
+        for c := 0; c < g.cols; c++ {
+            piece := "."
+            if m := g.at(r, c); m != 0 {
+                piece = connectFourPieces[m-1]
+            }
+            fmt.Fprintf(w, "|%*s", width, piece)
+        }
+        fmt.Fprintln(w, "|")
+    }
+    for c := 0; c < g.cols; c++ {
+        fmt.Fprintf(w, " %*d", width, c+1)
+    }
+    fmt.Fprintln(w)
+}
+
+func (g *ConnectFour) Clone() Searchable {
+    c := *g
+    c.cells = append([]int(nil), g.cells...)
+    return &c
+}
+
+func (g *ConnectFour) Score(p PlayerID) float64 {
+    return lineScore(g.rows, g.cols, connectFourRun, p, g.at)
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+    "bufio"
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "net"
+    "strings"
+    "sync"
+    "time"
+)
+
+// Prompt is what a player is being asked: to roll (Roll is zero and
+// Choices empty), which of Choices to take under the pick_die rule, or in
+// a game with several tokens each, which token to move with Roll. In a
+// game played through playTurnGame, only Game is set and the answer is a
+// move from its LegalMoves.
+type Prompt struct {
+    State   GameState
+    Roll    DieRoll
+    Choices []DieRoll
+    Game    TurnGame
+}
+
+// PlayerController takes one player's turns in an interactive game:
+// someone at this terminal, the computer (see botController), or someone
+// connected over the network.
+type PlayerController interface {
+    // WaitTurn waits for the player's answer to the prompt just rendered,
+    // as lineInput.WaitTurn does: a trimmed line ("" rolls, anything else
+    // is a command or token choice), or false if limit ran out first.
+    WaitTurn(ctx context.Context, p Prompt, clock Clock, limit time.Duration, r Renderer) (string, bool, error)
+}
+
+// localController is a player typing at this terminal
+type localController struct {
+    in *lineInput
+}
+
+func (l localController) WaitTurn(ctx context.Context, p Prompt, clock Clock, limit time.Duration, r Renderer) (string, bool, error) {
+    return l.in.WaitTurn(ctx, clock, limit, r)
+}
+
+// autoController answers every prompt straight away with the default: it
+// rolls, takes the first die on offer and moves the first token that can
+// go, so a game runs without anyone at the keyboard (-auto)
+type autoController struct{}
+
+func (autoController) WaitTurn(ctx context.Context, p Prompt, clock Clock, limit time.Duration, r Renderer) (string, bool, error) {
+    return "", true, ctx.Err()
+}
+
+// remoteController reads a player's answers from their connection. What
+// they type out of turn is thrown away, and once they disconnect their
+// turns roll themselves.
+type remoteController struct {
+    in *lineInput
+}
+
+func (c remoteController) WaitTurn(ctx context.Context, p Prompt, clock Clock, limit time.Duration, r Renderer) (string, bool, error) {
+    for drained := false; !drained; {
+        select {
+        case _, ok := <-c.in.lines:
+            drained = !ok
+        default:
+            drained = true
+        }
+    }
+    return c.in.WaitTurn(ctx, clock, limit, r)
+}
+
+// parseNameList reads "Alice,Bob", rejecting blanks and repeats
+func parseNameList(s string) ([]string, error) {
+    var names []string
+    seen := map[string]bool{}
+    for _, n := range strings.Split(s, ",") {
+        n = strings.TrimSpace(n)
+        if n == "" {
+            return nil, fmt.Errorf("empty player name in %q", s)
+        }
+        if seen[n] {
+            return nil, fmt.Errorf("player %s is listed twice", n)
+        }
+        seen[n] = true
+        names = append(names, n)
+    }
+    return names, nil
+}
+
+// seatKinds maps each of bots and remotes to its kind, checking they are
+// all seated and none is both
+func seatKinds(names, bots, remotes []string) (map[string]string, error) {
+    seated := map[string]bool{}
+    for _, n := range names {
+        seated[n] = true
+    }
+    kinds := map[string]string{}
+    for _, group := range []struct {
+        kind  string
+        names []string
+    }{{"bot", bots}, {"remote", remotes}} {
+        for _, n := range group.names {
+            if !seated[n] {
+                return nil, fmt.Errorf("%s player %s isn't in the game", group.kind, n)
+            }
+            if k, dup := kinds[n]; dup {
+                return nil, fmt.Errorf("player %s can't be both %s and %s", n, k, group.kind)
+            }
+            kinds[n] = group.kind
+        }
+    }
+    return kinds, nil
+}
+
+// broadcastWriter copies narration to the local terminal and every
+// remote player. A remote that can't keep up is dropped; its player's
+// turns then roll themselves.
+type broadcastWriter struct {
+    mu    sync.Mutex
+    local io.Writer
+    conns []net.Conn
+}
+
+func (b *broadcastWriter) Add(c net.Conn) {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    b.conns = append(b.conns, c)
+}
+
+func (b *broadcastWriter) Write(p []byte) (int, error) {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    live := b.conns[:0]
+    for _, c := range b.conns {
+        c.SetWriteDeadline(time.Now().Add(5 * time.Second))
+        if _, err := c.Write(p); err != nil {
+            c.Close()
+            continue
+        }
+        live = append(live, c)
+    }
+    b.conns = live
+    return b.local.Write(p)
+}
+
+// Close hangs up on every remote player
+func (b *broadcastWriter) Close() {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    for _, c := range b.conns {
+        c.Close()
+    }
+    b.conns = nil
+}
+
+// joinTimeout bounds how long a new connection has to say who it is
+const joinTimeout = 10 * time.Second
+
+// acceptRemotes waits on ln until every one of names has connected and
+// named themselves (see runJoin), returning their controllers. Their
+// connections also start receiving everything written to out.
+func acceptRemotes(ctx context.Context, ln net.Listener, names []string, out *broadcastWriter, msgs Catalog) (map[string]PlayerController, error) {
+    stop := context.AfterFunc(ctx, func() { ln.Close() })
+    defer stop()
+    waiting := map[string]bool{}
+    for _, n := range names {
+        waiting[n] = true
+        fmt.Fprintln(out, msgs.T(MsgWaitingRemote, n, ln.Addr()))
+    }
+    ctls := map[string]PlayerController{}
+    for len(waiting) > 0 {
+        conn, err := ln.Accept()
+        if err != nil {
+            if ctx.Err() != nil {
+                return nil, ctx.Err()
+            }
+            return nil, err
+        }
+        conn.SetReadDeadline(time.Now().Add(joinTimeout))
+        r := bufio.NewReader(conn)
+        hello, err := r.ReadString('\n')
+        name := strings.TrimSpace(hello)
+        if err != nil || !waiting[name] {
+            fmt.Fprintf(conn, "no open seat for %q\n", name)
+            conn.Close()
+            continue
+        }
+        conn.SetReadDeadline(time.Time{})
+        delete(waiting, name)
+        ctls[name] = remoteController{newChatInput(r, func(text string) {
+            fmt.Fprint(out, chatLine(msgs, name, text))
+        })}
+        fmt.Fprintln(conn, msgs.T(MsgChatHint))
+        out.Add(conn)
+        fmt.Fprintln(out, msgs.T(MsgRemoteJoined, name))
+    }
+    return ctls, nil
+}
+
+// runJoin takes a remote seat in a game hosted at addr: it names the
+// player, then shows the game and sends what they type until the host
+// hangs up
+func runJoin(ctx context.Context, addr, name string, in io.Reader, out io.Writer) error {
+    var d net.Dialer
+    conn, err := d.DialContext(ctx, "tcp", addr)
+    if err != nil {
+        return err
+    }
+    defer conn.Close()
+    stop := context.AfterFunc(ctx, func() { conn.Close() })
+    defer stop()
+    if _, err := fmt.Fprintln(conn, name); err != nil {
+        return err
+    }
+    go io.Copy(conn, in)
+    _, err = io.Copy(out, conn)
+    if ctx.Err() != nil {
+        return ctx.Err()
+    }
+    if errors.Is(err, net.ErrClosed) {
+        return nil
+    }
+    return err
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+    "encoding/csv"
+    "io"
+    "strconv"
+)
+
+// csvEvents is an Observer writing one CSV row per roll: who rolled what,
+// where they moved from and finally to, and what they landed on
+type csvEvents struct {
+    w   *csv.Writer
+    row *csvTurn // the roll being played, written once the next begins
+}
+
+type csvTurn struct {
+    turn     int
+    player   string
+    roll     int
+    from, to int // 0 when the roll didn't move anyone
+    square   string
+}
+
+var csvHeader = []string{"turn", "player", "roll", "from", "to", "square"}
+
+func newCSVEvents(w io.Writer) *csvEvents {
+    c := &csvEvents{w: csv.NewWriter(w)}
+    c.w.Write(csvHeader)
+    c.w.Flush()
+    return c
+}
+
+func (c *csvEvents) OnEvent(ev Event) {
+    switch ev.Kind {
+    case EventRoll:
+        c.flush()
+        c.row = &csvTurn{turn: ev.Turn, player: ev.Player, roll: ev.Roll}
+    case EventWin, EventDraw, EventAbort, EventPause:
+        if c.row != nil && ev.Kind == EventWin && c.row.player == ev.Player && c.row.square == "normal" {
+            c.row.square = "finish"
+        }
+        c.flush()
+    }
+    if c.row == nil || ev.Player != c.row.player {
+        return
+    }
+    switch ev.Kind {
+    case EventMove:
+        c.row.from, c.row.to, c.row.square = ev.From, ev.To, "normal"
+    case EventSnake, EventLadder, EventTeleport, EventSwap, EventThreeSixes, EventScript:
+        c.row.to, c.row.square = ev.To, string(ev.Kind)
+    case EventItem, EventShield, EventCapture:
+        c.row.square = string(ev.Kind)
+    case EventSkipped:
+        c.row.square = "skipped"
+    }
+}
+
+func (c *csvEvents) flush() {
+    if c.row == nil {
+        return
+    }
+    r := c.row
+    c.row = nil
+    cell := func(n int) string {
+        if n == 0 {
+            return ""
+        }
+        return strconv.Itoa(n)
+    }
+    c.w.Write([]string{strconv.Itoa(r.turn), r.player, strconv.Itoa(r.roll), cell(r.from), cell(r.to), r.square})
+    c.w.Flush()
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+    "fmt"
+    "hash/fnv"
+    "time"
+)
+
+// dailyLayout is the shape of every daily challenge board
+var dailyLayout = GenerateOptions{Size: 100, Snakes: 8, Ladders: 8, MinJump: 5, MaxJump: 40, Attempts: 200}
+
+// dailyFixedFlags are the flags that would change the daily challenge
+var dailyFixedFlags = []string{"board", "preset", "rules", "tokens", "capture", "max-turns", "turn-limit", "teams", "team-win"}
+
+// dailyDay is the day a `daily [YYYY-MM-DD]` argument names, today if
+// none. Days are in UTC, so the whole world is on the same one.
+func dailyDay(arg string, now time.Time) (time.Time, error) {
+    if arg == "" {
+        y, m, d := now.UTC().Date()
+        return time.Date(y, m, d, 0, 0, 0, 0, time.UTC), nil
+    }
+    day, err := time.Parse(time.DateOnly, arg)
+    if err != nil {
+        return time.Time{}, fmt.Errorf("%q isn't a day (want YYYY-MM-DD)", arg)
+    }
+    return day, nil
+}
+
+// dailyChallenge is the board and seed for the dice and square effects
+// that everyone playing on day gets, so they can compare turn counts.
+// Both come from the date alone.
+func dailyChallenge(day time.Time) (Board, uint64, error) {
+    h := fnv.New64a()
+    fmt.Fprintf(h, "snakesladders daily %s", day.Format(time.DateOnly))
+    seed := h.Sum64()
+    opts := dailyLayout
+    opts.Seed = int64(seed >> 1)
+    cfg, _, err := GenerateBoard(opts)
+    if err != nil {
+        return Board{}, 0, err
+    }
+    cfg.Name = "daily " + day.Format(time.DateOnly)
+    b, err := cfg.Build()
+    return b, seed, err
+}
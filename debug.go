@@ -0,0 +1,322 @@
+package main
+
+import (
+    "bufio"
+    "errors"
+    "fmt"
+    "io"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// debugger steps through a game one state at a time, for trying out rules.
+// It keeps every state the game passes through, so stepping back is just
+// moving along the line. Rolling something else from a past state starts
+// a new line, a "what if", leaving the one it branched from as it was.
+type debugger struct {
+    lines []debugLine
+    line  int // the line being looked at
+    at    int // its step being looked at
+    theme Theme
+    msgs  Catalog
+    out   io.Writer
+}
+
+// debugLine is one course of the game. Lines after the first share the
+// steps of the line they branched from up to the branch.
+type debugLine struct {
+    steps []debugStep
+    from  int // the line branched from, and the step it branched after
+    after int
+}
+
+// debugStep is a state the game reached, on its turn'th roll, and what
+// happened to get there
+type debugStep struct {
+    state GameState
+    turn  int
+    what  []string
+}
+
+// newGameDebugger starts a debugger at a fresh game's first state; it
+// rolls its dice to go further
+func newGameDebugger(gs GameState, theme Theme, msgs Catalog, out io.Writer) *debugger {
+    return &debugger{lines: []debugLine{{steps: []debugStep{{state: gs, what: []string{msgs.T(MsgDebugStart)}}}}}, theme: theme, msgs: msgs, out: out}
+}
+
+// recordDebugger starts a debugger at the first game in a game record,
+// replaying it to keep every state it went through
+func recordDebugger(evs []Event, theme Theme, msgs Catalog, out io.Writer) (*debugger, error) {
+    var r replayer
+    var steps []debugStep
+    var what []string
+    for i, ev := range evs {
+        if err := r.apply(ev); err != nil {
+            return nil, fmt.Errorf("event %d: %w", i+1, err)
+        }
+        if !r.playing {
+            if len(steps) > 0 {
+                break
+            }
+            continue
+        }
+        what = append(what, describeEvent(ev))
+        switch ev.Kind {
+        case EventStart, EventRestore, EventMove, EventUseItem, EventLeave, EventJoin:
+            steps = append(steps, debugStep{state: r.gs, turn: ev.Turn, what: what})
+            what = nil
+        }
+    }
+    if len(steps) == 0 {
+        return nil, errors.New(msgs.T(MsgDebugNoRecord))
+    }
+    return &debugger{lines: []debugLine{{steps: steps}}, theme: theme, msgs: msgs, out: out}, nil
+}
+
+func (d *debugger) steps() []debugStep { return d.lines[d.line].steps }
+func (d *debugger) step() debugStep    { return d.steps()[d.at] }
+
+// roll plays on from step at on the line being looked at, with the dice's
+// next roll or, with a value from 1 to 6, that instead. The dice are
+// drawn either way, so the rolls after it are the ones the game would
+// have had.
+func (d *debugger) roll(value int) (debugStep, error) {
+    st := d.step()
+    gs := st.state
+    if _, ongoing := checkOutcome(gs).(Ongoing); !ongoing {
+        return debugStep{}, errors.New(d.msgs.T(MsgDebugOver))
+    }
+    dr, _ := drawRoll(&gs.Dice, gs.Rules, 1)
+    if value != 0 {
+        dr = DieRoll{value}
+    }
+    evs := turnEvents(gs, dr, st.turn+1, time.Time{})
+    next, err := ApplyMove(gs, dr)
+    if err != nil {
+        return debugStep{}, err
+    }
+    var what []string
+    for _, ev := range evs {
+        what = append(what, describeEvent(ev))
+    }
+    return debugStep{state: next, turn: st.turn + 1, what: what}, nil
+}
+
+// forward moves n steps on, rolling the dice past the end of the line
+func (d *debugger) forward(n int) error {
+    for ; n > 0; n-- {
+        if d.at+1 == len(d.steps()) {
+            st, err := d.roll(0)
+            if err != nil {
+                return err
+            }
+            d.lines[d.line].steps = append(d.steps(), st)
+        }
+        d.at++
+    }
+    return nil
+}
+
+// branch starts a new line from the step being looked at, rolling value
+func (d *debugger) branch(value int) error {
+    st, err := d.roll(value)
+    if err != nil {
+        return err
+    }
+    steps := append(append([]debugStep(nil), d.steps()[:d.at+1]...), st)
+    d.lines = append(d.lines, debugLine{steps: steps, from: d.line, after: d.at})
+    d.line, d.at = len(d.lines)-1, d.at+1
+    return nil
+}
+
+// atTurn is the last step on the line by the end of turn
+func (d *debugger) atTurn(turn int) (int, error) {
+    found := -1
+    for i, st := range d.steps() {
+        if st.turn <= turn {
+            found = i
+        }
+    }
+    if found < 0 || turn > d.steps()[len(d.steps())-1].turn {
+        return 0, errors.New(d.msgs.T(MsgDebugNoTurn, turn, d.steps()[0].turn, d.steps()[len(d.steps())-1].turn))
+    }
+    return found, nil
+}
+
+func (d *debugger) show() {
+    st := d.step()
+    fmt.Fprintln(d.out, d.msgs.T(MsgDebugStep, d.line+1, d.at+1, len(d.steps()), st.turn, strings.Join(st.what, "; ")))
+    for i, p := range st.state.Players {
+        marker := " "
+        if i == st.state.CurrentPlayerIndex {
+            marker = ">"
+        }
+        fmt.Fprintf(d.out, "%s %-12s %3d", marker, p.Name, p.Position.Index)
+        if len(p.Tokens) > 0 {
+            fmt.Fprint(d.out, d.msgs.T(MsgDebugTokens, p.tokenSquares()))
+        }
+        if p.SkipTurns > 0 {
+            fmt.Fprint(d.out, d.msgs.T(MsgDebugSkips, p.SkipTurns))
+        }
+        if len(p.Items) > 0 {
+            fmt.Fprint(d.out, d.msgs.T(MsgDebugItems, p.Items))
+        }
+        fmt.Fprintln(d.out)
+    }
+    switch o := checkOutcome(st.state).(type) {
+    case Win:
+        fmt.Fprintln(d.out, d.msgs.T(MsgDebugWon, o.Winner.Name))
+    case Draw:
+        fmt.Fprintln(d.out, d.msgs.T(MsgDebugDrawn))
+    }
+}
+
+// run reads commands from in until it ends or says quit
+func (d *debugger) run(in io.Reader) error {
+    d.show()
+    sc := bufio.NewScanner(in)
+    for fmt.Fprint(d.out, d.msgs.T(MsgDebugPrompt)); sc.Scan(); fmt.Fprint(d.out, d.msgs.T(MsgDebugPrompt)) {
+        fields := strings.Fields(sc.Text())
+        if len(fields) == 0 {
+            fields = []string{"next"}
+        }
+        if fields[0] == "quit" {
+            return nil
+        }
+        if err := d.command(fields); err != nil {
+            fmt.Fprintln(d.out, err)
+        }
+    }
+    fmt.Fprintln(d.out)
+    return sc.Err()
+}
+
+func (d *debugger) command(fields []string) error {
+    arg := func(i, def int) (int, error) {
+        if len(fields) <= i {
+            return def, nil
+        }
+        return strconv.Atoi(fields[i])
+    }
+    switch fields[0] {
+    case "next", "n":
+        n, err := arg(1, 1)
+        if err != nil {
+            return err
+        }
+        // stop wherever the game ends, showing how it got there
+        err = d.forward(n)
+        d.show()
+        return err
+    case "back", "b":
+        n, err := arg(1, 1)
+        if err != nil {
+            return err
+        }
+        d.at = max(d.at-n, 0)
+        d.show()
+    case "turn":
+        t, err := arg(1, -1)
+        if err == nil && len(fields) != 2 {
+            err = errors.New(d.msgs.T(MsgDebugTurnUsage))
+        }
+        at := 0
+        if err == nil {
+            at, err = d.atTurn(t)
+        }
+        if err != nil {
+            return err
+        }
+        d.at = at
+        d.show()
+    case "where":
+        if len(fields) < 2 || len(fields) > 3 {
+            return errors.New(d.msgs.T(MsgDebugWhereUsage))
+        }
+        at := d.at
+        if len(fields) == 3 {
+            t, err := strconv.Atoi(fields[2])
+            if err != nil {
+                return err
+            }
+            if at, err = d.atTurn(t); err != nil {
+                return err
+            }
+        }
+        st := d.steps()[at]
+        idx := seatOf(st.state, fields[1])
+        if idx < 0 {
+            return errors.New(d.msgs.T(MsgDebugNoPlayer, fields[1]))
+        }
+        p := st.state.Players[idx]
+        fmt.Fprintln(d.out, d.msgs.T(MsgDebugWhere, p.Name, p.Position.Index, st.turn))
+    case "roll":
+        v, err := arg(1, 0)
+        if err == nil && (len(fields) != 2 || v < 1 || v > 6) {
+            err = errors.New(d.msgs.T(MsgDebugRollUsage))
+        }
+        if err == nil {
+            err = d.branch(v)
+        }
+        if err != nil {
+            return err
+        }
+        d.show()
+    case "lines":
+        for i, l := range d.lines {
+            mark := " "
+            if i == d.line {
+                mark = "*"
+            }
+            last := l.steps[len(l.steps)-1]
+            if i == 0 {
+                fmt.Fprintln(d.out, d.msgs.T(MsgDebugLineMain, mark, i+1, last.turn))
+                continue
+            }
+            fmt.Fprintln(d.out, d.msgs.T(MsgDebugLineBranch,
+                mark, i+1, l.from+1, l.steps[l.after].turn, l.steps[l.after+1].what[0], last.turn))
+        }
+    case "line":
+        l, err := arg(1, 0)
+        if err == nil && (len(fields) != 2 || l < 1 || l > len(d.lines)) {
+            err = errors.New(d.msgs.T(MsgDebugLineUsage, len(d.lines)))
+        }
+        if err != nil {
+            return err
+        }
+        d.line = l - 1
+        d.at = min(d.at, len(d.steps())-1)
+        d.show()
+    case "board":
+        st := d.step()
+        d.theme.RenderBoard(d.out, st.state.Board, st.state.Players)
+    case "help":
+        fmt.Fprintln(d.out, d.msgs.T(MsgDebugHelp))
+    default:
+        return errors.New(d.msgs.T(MsgDebugUnknown, fields[0]))
+    }
+    return nil
+}
+
+// runDebug implements `debug [game-record]`: stepping through the record's
+// first game, or, without one, a new game the dice play as it is stepped
+func runDebug(args []string, gs GameState, theme Theme, msgs Catalog, in io.Reader, out io.Writer) error {
+    var d *debugger
+    theme = theme.withMessages(msgs)
+    switch len(args) {
+    case 0:
+        d = newGameDebugger(gs, theme, msgs, out)
+    case 1:
+        evs, err := openGameRecord(args[0])
+        if err != nil {
+            return err
+        }
+        if d, err = recordDebugger(evs, theme, msgs, out); err != nil {
+            return err
+        }
+    default:
+        return errors.New(msgs.T(MsgDebugUsage))
+    }
+    return d.run(in)
+}
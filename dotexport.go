@@ -0,0 +1,41 @@
+package main
+
+import (
+    "fmt"
+    "io"
+    "strings"
+)
+
+// ExportDOT writes b as a Graphviz digraph: a node per square, plain edges
+// from each square to the next, and bold coloured edges for snakes (green,
+// as printed) and ladders (brown). `dot -Tsvg` lays it out.
+func ExportDOT(b Board, w io.Writer) error {
+    var sb strings.Builder
+    sb.WriteString("digraph board {\n")
+    sb.WriteString("    rankdir=LR;\n")
+    sb.WriteString("    node [shape=box, fontname=\"sans-serif\"];\n")
+    final := b.FinalSquare.Index
+    for sq := 1; sq <= final; sq++ {
+        label := fmt.Sprint(sq)
+        if l := printLabel(b.Square(sq)); l != "" {
+            label += "\n" + l
+        }
+        attrs := ""
+        if sq == 1 || sq == final {
+            attrs = ", style=filled, fillcolor=\"#f4f4f4\""
+        }
+        fmt.Fprintf(&sb, "    %d [label=%q%s];\n", sq, label, attrs)
+    }
+    for sq := 1; sq < final; sq++ {
+        fmt.Fprintf(&sb, "    %d -> %d [color=\"#999999\"];\n", sq, sq+1)
+    }
+    for _, s := range b.Snakes() {
+        fmt.Fprintf(&sb, "    %d -> %d [label=\"snake\", color=\"#2e7d32\", fontcolor=\"#2e7d32\", style=bold, constraint=false];\n", s.From.Index, s.To.Index)
+    }
+    for _, l := range b.Ladders() {
+        fmt.Fprintf(&sb, "    %d -> %d [label=\"ladder\", color=\"#8d6e63\", fontcolor=\"#8d6e63\", style=bold, constraint=false];\n", l.From.Index, l.To.Index)
+    }
+    sb.WriteString("}\n")
+    _, err := io.WriteString(w, sb.String())
+    return err
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+    "bufio"
+    "errors"
+    "fmt"
+    "io"
+    "io/fs"
+    "os"
+    "strconv"
+    "strings"
+)
+
+// runEditor edits the board file at path, starting blank if it doesn't
+// exist yet, reading commands from in and showing the board in theme and
+// its messages in msgs' language
+func runEditor(path string, in io.Reader, out io.Writer, theme Theme, msgs Catalog) error {
+    cfg := BoardConfig{Size: 100}
+    if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+        fmt.Fprintln(out, msgs.T(MsgEditorNew, path))
+    } else if err != nil {
+        return err
+    } else if cfg, err = LoadBoardConfig(path); err != nil {
+        return err
+    }
+    fmt.Fprintln(out, msgs.T(MsgEditorHelp))
+    sc := bufio.NewScanner(in)
+    dirty := false
+    for {
+        fmt.Fprint(out, msgs.T(MsgEditorPrompt))
+        if !sc.Scan() {
+            fmt.Fprintln(out)
+            return sc.Err()
+        }
+        f := strings.Fields(sc.Text())
+        if len(f) == 0 {
+            continue
+        }
+        changed := true
+        switch {
+        case (f[0] == "snake" || f[0] == "ladder") && len(f) == 3:
+            from, err1 := strconv.Atoi(f[1])
+            to, err2 := strconv.Atoi(f[2])
+            if err1 != nil || err2 != nil {
+                fmt.Fprintln(out, msgs.T(MsgEditorSquaresNaN))
+                continue
+            }
+            cfg = cfg.without(from)
+            if f[0] == "snake" {
+                cfg.Snakes = append(cfg.Snakes, JumpSpec{from, to})
+            } else {
+                cfg.Ladders = append(cfg.Ladders, JumpSpec{from, to})
+            }
+        case f[0] == "special" && len(f) == 3:
+            sq, err := strconv.Atoi(f[1])
+            if err != nil {
+                fmt.Fprintln(out, msgs.T(MsgEditorSquareNaN))
+                continue
+            }
+            cfg = cfg.without(sq)
+            cfg.Specials = append(cfg.Specials, SpecialSpec{sq, f[2]})
+        case f[0] == "item" && len(f) == 3:
+            sq, err := strconv.Atoi(f[1])
+            if err != nil {
+                fmt.Fprintln(out, msgs.T(MsgEditorSquareNaN))
+                continue
+            }
+            cfg = cfg.without(sq)
+            cfg.Items = append(cfg.Items, ItemSpec{sq, ItemKind(f[2])})
+        case f[0] == "script" && len(f) >= 3:
+            sq, err := strconv.Atoi(f[1])
+            if err != nil {
+                fmt.Fprintln(out, msgs.T(MsgEditorSquareNaN))
+                continue
+            }
+            cfg = cfg.without(sq)
+            cfg.Scripts = append(cfg.Scripts, ScriptSpec{sq, strings.Join(f[2:], " ")})
+        case f[0] == "remove" && len(f) == 2:
+            sq, err := strconv.Atoi(f[1])
+            if err != nil {
+                fmt.Fprintln(out, msgs.T(MsgEditorSquareNaN))
+                continue
+            }
+            cfg = cfg.without(sq)
+        case f[0] == "size" && len(f) == 2:
+            n, err := strconv.Atoi(f[1])
+            if err != nil {
+                fmt.Fprintln(out, msgs.T(MsgEditorSizeNaN))
+                continue
+            }
+            cfg.Size = n
+        case f[0] == "name" && len(f) >= 2:
+            cfg.Name = strings.Join(f[1:], " ")
+        case f[0] == "reset" && len(f) == 2 && f[1] == "blank":
+            cfg = BoardConfig{Name: cfg.Name, Size: 100}
+        case f[0] == "reset" && len(f) == 2 && f[1] == "standard":
+            cfg = ConfigFromBoard("standard", CreateStandardBoard())
+        case f[0] == "show":
+            changed = false
+            if b, err := cfg.Build(); err == nil {
+                theme.withMessages(msgs).RenderBoard(out, b, nil)
+            }
+        case f[0] == "save":
+            changed = false
+            target := path
+            if len(f) == 2 {
+                target = f[1]
+            }
+            if err := SaveBoardConfig(target, cfg); err != nil {
+                fmt.Fprintln(out, msgs.T(MsgEditorNotSaved, err))
+                continue
+            }
+            fmt.Fprintln(out, msgs.T(MsgEditorSaved, target))
+            dirty = false
+        case f[0] == "quit":
+            if dirty {
+                fmt.Fprintln(out, msgs.T(MsgEditorDiscard))
+            }
+            return nil
+        default:
+            changed = false
+            fmt.Fprintln(out, msgs.T(MsgEditorHelp))
+        }
+        if changed {
+            dirty = true
+        }
+        if err := cfg.Validate(); err != nil {
+            fmt.Fprintln(out, msgs.T(MsgEditorProblems))
+            for _, line := range strings.Split(err.Error(), "\n") {
+                fmt.Fprintln(out, "  "+line)
+            }
+        }
+        for _, w := range cfg.Warnings() {
+            fmt.Fprintln(out, msgs.T(MsgEditorWarning, w))
+        }
+    }
+}
+
+// without drops anything starting on sq
+func (c BoardConfig) without(sq int) BoardConfig {
+    keep := func(js []JumpSpec) []JumpSpec {
+        var out []JumpSpec
+        for _, j := range js {
+            if j.From != sq {
+                out = append(out, j)
+            }
+        }
+        return out
+    }
+    c.Snakes, c.Ladders = keep(c.Snakes), keep(c.Ladders)
+    var specials []SpecialSpec
+    for _, s := range c.Specials {
+        if s.Square != sq {
+            specials = append(specials, s)
+        }
+    }
+    c.Specials = specials
+    var items []ItemSpec
+    for _, it := range c.Items {
+        if it.Square != sq {
+            items = append(items, it)
+        }
+    }
+    c.Items = items
+    var scripts []ScriptSpec
+    for _, s := range c.Scripts {
+        if s.Square != sq {
+            scripts = append(scripts, s)
+        }
+    }
+    c.Scripts = scripts
+    return c
+}
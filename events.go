@@ -0,0 +1,233 @@
+package main
+
+import "time"
+
+// EventKind tags what happened in an Event
+type EventKind string
+
+const (
+    EventStart   EventKind = "start"
+    EventRoll    EventKind = "roll"
+    EventMove    EventKind = "move"
+    EventSnake   EventKind = "snake"
+    EventLadder  EventKind = "ladder"
+    EventWin     EventKind = "win"
+    EventDraw    EventKind = "draw" // the turn limit ended the game with nobody winning
+    EventAbort   EventKind = "abort"
+    EventPause   EventKind = "pause" // Reason shutdown when a server put the game away
+    EventRestore EventKind = "restore"
+    EventOffer   EventKind = "offer"
+
+    EventSkipTurn  EventKind = "skip_turn"
+    EventExtraRoll EventKind = "extra_roll"
+    EventTeleport  EventKind = "teleport"
+    EventSwap      EventKind = "swap"
+    EventSkipped   EventKind = "skipped"
+
+    EventItem    EventKind = "item"
+    EventUseItem EventKind = "use_item"
+    EventShield  EventKind = "shield"
+
+    EventCapture EventKind = "capture"
+
+    // EventScript is a square script (see script.go) moving Player From
+    // To; when it moved someone other than the player who set it off,
+    // Other names that player
+    EventScript EventKind = "script"
+
+    // EventThreeSixes is Player's third six in a row forfeiting the roll,
+    // From the square they rolled on and To where that leaves them
+    EventThreeSixes EventKind = "three_sixes"
+
+    // EventMark is a mark placed in a grid game such as tic-tac-toe, on
+    // square To
+    EventMark EventKind = "mark"
+    // EventDrop is a piece dropped down column To in Connect Four
+    EventDrop EventKind = "drop"
+
+    // EventLeave is Player leaving a network game part-way through, or
+    // with Reason time_up running out of time, and EventJoin Player taking
+    // over the seat Other left
+    EventLeave EventKind = "leave"
+    EventJoin  EventKind = "join"
+
+    // EventChat is Player saying Reason to the table. Chat goes out on a
+    // network game's event streams alongside its play, but is no part of
+    // the game: it never reaches the game's observers or its log.
+    EventChat EventKind = "chat"
+)
+
+// Event is one thing that happened during a game. Which fields are set
+// depends on Kind: rolls carry Roll, moves and jumps carry From/To, and
+// swaps and captures name the Other player involved (a capture's From/To
+// are theirs), item events name the Item.
+type Event struct {
+    Kind    EventKind   `json:"kind"`
+    Time    time.Time   `json:"time"`
+    Turn    int         `json:"turn"`
+    Player  string      `json:"player,omitempty"`
+    Players []string    `json:"players,omitempty"`
+    Roll    int         `json:"roll,omitempty"`
+    From    int         `json:"from,omitempty"`
+    To      int         `json:"to,omitempty"`
+    Reason  string      `json:"reason,omitempty"`
+    Other   string      `json:"other,omitempty"`
+    Item    string      `json:"item,omitempty"`
+    Team    string      `json:"team,omitempty"`
+    Token   int         `json:"token,omitempty"`   // 1-based, in games with several tokens each
+    Dice    *DiceStream `json:"dice,omitempty"`    // start and restore events: the dice as play (re)starts
+    Draw    uint64      `json:"draw,omitempty"`    // roll events: the last draw of the dice stream it used
+    Choices []int       `json:"choices,omitempty"` // offer events, and roll events under pick_die: the two rolls on offer
+    Version int         `json:"version,omitempty"` // start events: the log's FormatVersion
+    Setup   *GameSetup  `json:"setup,omitempty"`   // start and restore events: the position play (re)starts from
+    Hash    string      `json:"hash,omitempty"`    // stateHash of the game after the event; on a roll, after the whole turn
+    Commit  string      `json:"commit,omitempty"`  // start and restore events with sealed dice: the diceCommitment, revealed as Dice on the ending event (see commit.go)
+}
+
+// Observer receives every event a game produces, in order
+type Observer interface {
+    OnEvent(Event)
+}
+
+func notify(obs []Observer, ev Event) {
+    for _, o := range obs {
+        o.OnEvent(ev)
+    }
+}
+
+// startEvent opens the log of a game starting (or resuming) from gs after
+// turn rolls
+func startEvent(gs GameState, turn int, now time.Time) Event {
+    names := make([]string, len(gs.Players))
+    for i, p := range gs.Players {
+        names[i] = p.Name
+    }
+    ev := Event{Kind: EventStart, Time: now, Turn: turn, Players: names, Version: FormatVersion, Setup: setupOf(gs, turn), Hash: stateHash(gs)}
+    if gs.Dice.Algorithm != "" {
+        dice := gs.Dice
+        ev.Dice = &dice
+    }
+    return ev
+}
+
+// winEvent ends the log of a game won in gs
+func winEvent(win Win, gs GameState, turn int, now time.Time) Event {
+    return Event{Kind: EventWin, Time: now, Turn: turn, Player: win.Winner.Name, Team: win.Team, Hash: stateHash(gs)}
+}
+
+func drawEvent(gs GameState, turn int, now time.Time) Event {
+    return Event{Kind: EventDraw, Time: now, Turn: turn, Hash: stateHash(gs)}
+}
+
+// scriptedPlayers are gs's players once the square script on sq has run
+// for the current player, who rolled dr and landed on land
+func scriptedPlayers(gs GameState, sq ScriptSquare, land BoardPos, dr DieRoll) []Player {
+    gs.Players = append([]Player(nil), gs.Players...)
+    gs.Players[gs.CurrentPlayerIndex].Position = land
+    sq.Script.run(&gs, gs.CurrentPlayerIndex, dr)
+    for j := range gs.Players {
+        gs.Players[j].syncActive()
+    }
+    return gs.Players
+}
+
+// jumpEvents are the snakes and ladders taken from sq, chained if the
+// rules say so
+func jumpEvents(gs GameState, player string, sq Square, turn int, now time.Time) []Event {
+    var evs []Event
+    for _, j := range gs.ruleUnits().jumpChain(gs.Board, sq) {
+        switch j := j.(type) {
+        case Snake:
+            evs = append(evs, Event{Kind: EventSnake, Time: now, Turn: turn, Player: player, From: j.From.Index, To: j.To.Index})
+        case Ladder:
+            evs = append(evs, Event{Kind: EventLadder, Time: now, Turn: turn, Player: player, From: j.From.Index, To: j.To.Index})
+        }
+    }
+    return evs
+}
+
+// turnEvents describes what applying dr to gs will do: the roll, the move
+// to the landing square, any snake, ladder, item or special square effect
+// there, anyone captured, and anyone whose turn is then skipped
+func turnEvents(gs GameState, dr DieRoll, turn int, now time.Time) []Event {
+    after := applyMove(gs, dr)
+    idx := gs.CurrentPlayerIndex
+    cur := gs.Players[gs.CurrentPlayerIndex]
+    land := playerLanding(gs, cur, dr)
+    if _, forfeit := gs.ruleUnits().beforeRoll(&cur, dr); forfeit {
+        return []Event{
+            {Kind: EventRoll, Time: now, Turn: turn, Player: cur.Name, Roll: dr.Value, Draw: gs.Dice.Draws, Hash: stateHash(after)},
+            {Kind: EventMove, Time: now, Turn: turn, Player: cur.Name, Token: tokenNumber(cur), From: cur.Position.Index, To: cur.Position.Index},
+            {Kind: EventThreeSixes, Time: now, Turn: turn, Player: cur.Name, From: cur.Position.Index, To: after.Players[idx].tokenSquares()[cur.Active].Index},
+        }
+    }
+    swapped := -1
+    before := gs.Players // where everyone is, for captures, before the rules act
+    evs := []Event{
+        {Kind: EventRoll, Time: now, Turn: turn, Player: cur.Name, Roll: dr.Value, Draw: gs.Dice.Draws, Hash: stateHash(after)},
+        {Kind: EventMove, Time: now, Turn: turn, Player: cur.Name, From: cur.Position.Index, To: land.Index},
+    }
+    evs[1].Token = tokenNumber(cur)
+    switch sq := gs.Board.Square(land.Index).(type) {
+    case Snake:
+        if cur.Immune {
+            evs = append(evs, Event{Kind: EventShield, Time: now, Turn: turn, Player: cur.Name, From: land.Index, To: land.Index})
+            break
+        }
+        evs = append(evs, jumpEvents(gs, cur.Name, sq, turn, now)...)
+    case Ladder:
+        evs = append(evs, jumpEvents(gs, cur.Name, sq, turn, now)...)
+    case ItemSquare:
+        evs = append(evs, Event{Kind: EventItem, Time: now, Turn: turn, Player: cur.Name, Item: string(sq.Item), From: land.Index, To: land.Index})
+    case SkipTurn:
+        evs = append(evs, Event{Kind: EventSkipTurn, Time: now, Turn: turn, Player: cur.Name, From: land.Index, To: land.Index})
+    case ExtraRoll:
+        evs = append(evs, Event{Kind: EventExtraRoll, Time: now, Turn: turn, Player: cur.Name, From: land.Index, To: land.Index})
+    case Teleport:
+        evs = append(evs, Event{Kind: EventTeleport, Time: now, Turn: turn, Player: cur.Name, From: land.Index, To: after.Players[idx].Position.Index})
+    case Swap:
+        if j := leadingOpponent(gs.Board, gs.Players, idx); j >= 0 {
+            swapped = j
+            evs = append(evs, Event{Kind: EventSwap, Time: now, Turn: turn, Player: cur.Name, Other: gs.Players[j].Name, From: land.Index, To: after.Players[idx].Position.Index})
+        }
+    case ScriptSquare:
+        before = scriptedPlayers(gs, sq, land, dr)
+        evs = append(evs, Event{Kind: EventScript, Time: now, Turn: turn, Player: cur.Name, From: land.Index, To: before[idx].Position.Index})
+        for j, p := range gs.Players {
+            if j != idx && before[j].Position != p.Position {
+                evs = append(evs, Event{Kind: EventScript, Time: now, Turn: turn, Player: p.Name, Other: cur.Name, From: p.Position.Index, To: before[j].Position.Index})
+            }
+        }
+        if before[idx].SkipTurns > cur.SkipTurns {
+            evs = append(evs, Event{Kind: EventSkipTurn, Time: now, Turn: turn, Player: cur.Name, From: land.Index, To: land.Index})
+        }
+        for _, it := range before[idx].Items[len(cur.Items):] {
+            evs = append(evs, Event{Kind: EventItem, Time: now, Turn: turn, Player: cur.Name, Item: string(it), From: land.Index, To: land.Index})
+        }
+    }
+    for j, p := range before {
+        if j == idx || j == swapped {
+            continue
+        }
+        moved := after.Players[j].tokenSquares()
+        for k, sq := range p.tokenSquares() {
+            if moved[k] != sq {
+                ev := Event{Kind: EventCapture, Time: now, Turn: turn, Player: cur.Name, Other: p.Name, From: sq.Index, To: moved[k].Index}
+                if len(p.Tokens) > 0 {
+                    ev.Token = k + 1
+                }
+                evs = append(evs, ev)
+            }
+        }
+    }
+    if _, square := gs.Board.Square(land.Index).(ExtraRoll); !square && after.CurrentPlayerIndex == idx && len(gs.Players) > 1 {
+        // a rule gave them another turn
+        evs = append(evs, Event{Kind: EventExtraRoll, Time: now, Turn: turn, Player: cur.Name, From: land.Index, To: land.Index})
+    }
+    for j, p := range gs.Players {
+        if j != idx && after.Players[j].SkipTurns < p.SkipTurns {
+            evs = append(evs, Event{Kind: EventSkipped, Time: now, Turn: turn, Player: p.Name})
+        }
+    }
+    return evs
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+    "os"
+    "path/filepath"
+)
+
+// withFileLock runs fn while holding an exclusive advisory lock tied to
+// path (on a sibling path+".lock" file, so the data file itself can still
+// be replaced by rename). Two instances sharing a config directory
+// serialize their read-modify-write cycles through it.
+func withFileLock(path string, fn func() error) error {
+    if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+        return err
+    }
+    unlock, err := lockFile(path + ".lock")
+    if err != nil {
+        return err
+    }
+    defer unlock()
+    return fn()
+}
+
+// updateJSONFile reads path into v, lets fn modify it and writes it back,
+// all under the file's lock so concurrent instances don't lose updates
+func updateJSONFile(path string, v any, fn func() error) error {
+    return withFileLock(path, func() error {
+        if err := readJSONFile(path, v); err != nil {
+            return err
+        }
+        if err := fn(); err != nil {
+            return err
+        }
+        return writeJSONFile(path, v)
+    })
+}
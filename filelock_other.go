@@ -0,0 +1,39 @@
+//go:build !unix
+
+package main
+
+import (
+    "errors"
+    "fmt"
+    "io/fs"
+    "os"
+    "time"
+)
+
+// staleLock is how old a lock file must be before it is assumed to belong
+// to a crashed process
+const staleLock = 30 * time.Second
+
+// lockFile claims path by creating it exclusively, retrying while another
+// process holds it. Used where flock isn't available.
+func lockFile(path string) (func(), error) {
+    deadline := time.Now().Add(2 * staleLock)
+    for {
+        f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+        if err == nil {
+            f.Close()
+            return func() { os.Remove(path) }, nil
+        }
+        if !errors.Is(err, fs.ErrExist) {
+            return nil, err
+        }
+        if fi, err := os.Stat(path); err == nil && time.Since(fi.ModTime()) > staleLock {
+            os.Remove(path)
+            continue
+        }
+        if time.Now().After(deadline) {
+            return nil, fmt.Errorf("timed out waiting for lock %s", path)
+        }
+        time.Sleep(50 * time.Millisecond)
+    }
+}
@@ -0,0 +1,462 @@
+package main
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "net"
+    "os"
+    "slices"
+    "strings"
+    "time"
+)
+
+// The flags several commands share, in groups by what they set up. Each
+// add function defines its group on a command's FlagSet and returns where
+// the values land; a command defines only the groups it reads, so a flag
+// it would ignore is an error rather than quietly dropped.
+
+func addThemeFlag(fs *flag.FlagSet) *string {
+    return fs.String("theme", "classic", "how to draw the board: "+strings.Join(themeNames(), ", "))
+}
+
+func addLangFlag(fs *flag.FlagSet) *string {
+    return fs.String("lang", defaultLang(), "language for game messages ("+strings.Join(Languages(), ", ")+")")
+}
+
+func addStoreFlag(fs *flag.FlagSet) *string {
+    return fs.String("store", "", "where saved games are kept: file:DIR, memory or sqlite:PATH (default: files under the config directory)")
+}
+
+func addHistoryFlag(fs *flag.FlagSet) *string {
+    return fs.String("history-db", defaultHistoryPath(), "SQLite database recording game transcripts (needs a -tags sqlite build)")
+}
+
+func addSeedFlag(fs *flag.FlagSet, usage string) *int64 {
+    return fs.Int64("seed", 1, usage)
+}
+
+func addBoardFlag(fs *flag.FlagSet) *string {
+    return fs.String("board", "", "play on the board in this file, JSON (see the edit command) or .csv rows of type,from,to, or a bundled board: standard, quick, party, classic, moksha, kids, snakepit")
+}
+
+func addTelemetryFlag(fs *flag.FlagSet) *string {
+    return fs.String("telemetry", "", "opt in to posting anonymous usage counts (games, how long they took, rules presets; no names or addresses) to this URL as JSON hourly and at shutdown")
+}
+
+// catalog is the messages in lang
+func catalog(lang string) (Catalog, error) {
+    msgs, err := NewCatalog(lang)
+    if err != nil {
+        return msgs, usageError{err}
+    }
+    return msgs, nil
+}
+
+// boardFlag is the board -board names, the standard one if none
+func boardFlag(path string) (Board, error) {
+    if path == "" {
+        return CreateStandardBoard(), nil
+    }
+    b, _, err := loadBoardRules(path)
+    return b, err
+}
+
+// startTelemetry starts counting the server's games if the operator gave
+// a URL to send the counts to; withTelemetry sends them
+func startTelemetry(endpoint string) error {
+    if endpoint == "" {
+        return nil
+    }
+    if err := checkTelemetryURL(endpoint); err != nil {
+        return usageError{err}
+    }
+    serverTelemetry = newTelemetry(time.Now())
+    return nil
+}
+
+// lookFlags say how a game is shown
+type lookFlags struct {
+    noColor, accessible *bool
+    theme, lang         *string
+}
+
+func addLookFlags(fs *flag.FlagSet) *lookFlags {
+    return &lookFlags{
+        noColor:    fs.Bool("no-color", false, "never colour the output (it is only coloured on a terminal anyway)"),
+        accessible: fs.Bool("accessible", false, "screen-reader mode: no board art or colour, one plain sentence per event"),
+        theme:      addThemeFlag(fs),
+        lang:       addLangFlag(fs),
+    }
+}
+
+// apply sets opts' messages and theme
+func (f *lookFlags) apply(opts *Options) error {
+    var err error
+    if opts.Msgs, err = catalog(*f.lang); err != nil {
+        return err
+    }
+    if opts.Theme, err = ParseTheme(*f.theme); err != nil {
+        return usageError{err}
+    }
+    if *f.accessible {
+        opts.Accessible, opts.Theme = true, linearTheme
+    }
+    return nil
+}
+
+// rulesFlags say what a game is played on and by which rules
+type rulesFlags struct {
+    board, preset, rules, capture, turnLimit *string
+    tokens, maxTurns                         *int
+    clock                                    *time.Duration
+}
+
+func addRulesFlags(fs *flag.FlagSet) *rulesFlags {
+    return &rulesFlags{
+        board:     addBoardFlag(fs),
+        preset:    fs.String("preset", "", "a named set of rules to play with: "+strings.Join(rulePresetNames(), ", ")+" (-rules adds to it)"),
+        rules:     fs.String("rules", "", "extra movement rules, comma-separated: "+strings.Join(RuleNames, ", ")),
+        capture:   fs.String("capture", "off", "landing on an opponent sends them back: off, start (square 1) or checkpoint (start of their row)"),
+        tokens:    fs.Int("tokens", 1, "tokens per player; with more than one, choose which to move after each roll and win by bringing all home"),
+        maxTurns:  fs.Int("max-turns", 0, "end the game after this many rolls if nobody has won (0: no limit)"),
+        turnLimit: fs.String("turn-limit", "draw", "how a game that hits -max-turns ends: draw, or closest (nearest the finish wins)"),
+        clock:     fs.Duration("clock", 0, "blitz: each player's time for the whole game (e.g. 3m), running while it's their turn; running out puts them out (0: no clocks)"),
+    }
+}
+
+// loadBoard sets opts' board, and the rules that come with it, from -board
+func (f *rulesFlags) loadBoard(opts *Options) error {
+    opts.Board = CreateStandardBoard()
+    if *f.board == "" {
+        return nil
+    }
+    b, r, err := loadBoardRules(*f.board)
+    if err != nil {
+        return err
+    }
+    opts.Board, opts.Rules = b, r
+    return nil
+}
+
+// apply sets opts' rules for its board: the board's own rules, then
+// -preset and -rules on top, with -capture, -max-turns and -turn-limit
+// overriding all of them if fs was given them
+func (f *rulesFlags) apply(fs *flag.FlagSet, opts *Options) error {
+    given := map[string]bool{}
+    fs.Visit(func(fl *flag.Flag) { given[fl.Name] = true })
+    opts.Rules.Tokens = *f.tokens
+    opts.Rules.Clock = max(*f.clock, 0)
+    err := RuleSpec{Preset: *f.preset, Enable: strings.Split(*f.rules, ",")}.Apply(&opts.Rules)
+    if err == nil && given["capture"] {
+        opts.Rules.Capture, err = ParseCapture(*f.capture)
+    }
+    if given["max-turns"] {
+        opts.Rules.MaxTurns = max(*f.maxTurns, 0)
+    }
+    if err == nil && (given["turn-limit"] || opts.Rules.TurnLimit == "") {
+        opts.Rules.TurnLimit, err = ParseTurnLimit(*f.turnLimit)
+    }
+    if err == nil {
+        err = opts.Rules.Check(opts.Board)
+    }
+    if err != nil {
+        return usageError{err}
+    }
+    return nil
+}
+
+// logFlags say where a game's events are recorded
+type logFlags struct {
+    jsonEvents, transcript, csv, log, historyDB *string
+}
+
+func addLogFlags(fs *flag.FlagSet) *logFlags {
+    return &logFlags{
+        jsonEvents: fs.String("json-events", "", "write every event as a JSON line to this file (\"-\" for stdout, moving narration to stderr)"),
+        transcript: fs.String("transcript", "", "write the canonical JSON transcript of the games to this file"),
+        csv:        fs.String("csv", "", "write every roll as a CSV row (turn, player, roll, from, to, square) to this file"),
+        log:        fs.String("log", "", "append a timestamped record of every event to this file"),
+        historyDB:  addHistoryFlag(fs),
+    }
+}
+
+// gameLogs are the records logFlags open, until Close
+type gameLogs struct {
+    transcript  *transcriptWriter   // -transcript's, if given
+    transcripts *transcriptRecorder // into the history database, if there is one
+    db          *sql.DB
+    files       []io.Closer
+}
+
+// open starts the records the flags ask for, adding their observers to
+// opts. With -json-events - the events take stdout, so the narration
+// moves to stderr.
+func (f *logFlags) open(opts *Options) (*gameLogs, error) {
+    l := &gameLogs{}
+    err := l.open(f, opts)
+    if err != nil {
+        l.Close()
+        return nil, err
+    }
+    return l, nil
+}
+
+func (l *gameLogs) open(f *logFlags, opts *Options) error {
+    switch *f.jsonEvents {
+    case "":
+    case "-":
+        opts.Out = os.Stderr
+        opts.Observers = append(opts.Observers, newJSONEvents(os.Stdout))
+    default:
+        file, err := os.Create(*f.jsonEvents)
+        if err != nil {
+            return err
+        }
+        l.files = append(l.files, file)
+        opts.Observers = append(opts.Observers, newJSONEvents(file))
+    }
+    if *f.transcript != "" {
+        l.transcript = newTranscriptWriter(*f.transcript)
+        opts.Observers = append(opts.Observers, l.transcript)
+    }
+    if *f.csv != "" {
+        file, err := os.Create(*f.csv)
+        if err != nil {
+            return err
+        }
+        l.files = append(l.files, file)
+        opts.Observers = append(opts.Observers, newCSVEvents(file))
+    }
+    if *f.log != "" {
+        file, gl, err := openGameLog(*f.log)
+        if err != nil {
+            return err
+        }
+        l.files = append(l.files, file)
+        opts.Observers = append(opts.Observers, gl)
+    }
+    if historyDriver != "" {
+        db, err := openHistory(*f.historyDB)
+        if err != nil {
+            fmt.Fprintln(os.Stderr, opts.Msgs.T(MsgWarnHistory, err))
+            return nil
+        }
+        l.db = db
+        l.files = append(l.files, db)
+        l.transcripts = newTranscriptRecorder(db)
+        opts.Observers = append(opts.Observers, l.transcripts)
+    }
+    return nil
+}
+
+func (l *gameLogs) Close() {
+    for _, f := range slices.Backward(l.files) {
+        f.Close()
+    }
+}
+
+// playFlags set up games played here: how they look, their rules and
+// records, who plays them and how, and at what pace
+type playFlags struct {
+    look  *lookFlags
+    rules *rulesFlags
+    logs  *logFlags
+
+    players, teams, teamWin, bots, remote, remoteAddr, store, ui *string
+    speed, profile, hook, spectateAddr, summary, card            *string
+    setup, keys, auto, fast, odds, coach, rollOff, shuffle       *bool
+    turnTimeout, gameTimeout, delay, animate, spectateDelay      *time.Duration
+    match                                                        *int
+}
+
+func addPlayFlags(fs *flag.FlagSet) *playFlags {
+    return &playFlags{
+        look:          addLookFlags(fs),
+        rules:         addRulesFlags(fs),
+        logs:          addLogFlags(fs),
+        players:       fs.String("players", "Alice,Bob", "who is playing, in turn order (ignored with -teams); left out at a terminal, setup asks"),
+        teams:         fs.String("teams", "", "play in teams, e.g. \"red=Alice,Carol;blue=Bob,Dave\" (turns alternate between teams)"),
+        teamWin:       fs.String("team-win", "any", "what a team needs to win: any (first member home) or all (every member home)"),
+        setup:         fs.Bool("setup", false, "ask who is playing and which of them are bots before the game, even when not at a terminal"),
+        keys:          fs.Bool("keys", false, "single-keypress input: any key rolls, b shows the board, q quits (digits choose tokens and dice)"),
+        auto:          fs.Bool("auto", false, "play every player not a bot automatically, never reading the keyboard (for demos and piping)"),
+        bots:          fs.String("bots", "", "players the computer takes turns for, with an optional level (easy, medium or hard), e.g. \"Bob:hard\""),
+        remote:        fs.String("remote", "", "players who join over the network with 'join host:port NAME', e.g. \"Carol\""),
+        remoteAddr:    fs.String("remote-addr", ":7071", "where -remote players connect"),
+        turnTimeout:   fs.Duration("turn-timeout", 0, "auto-roll if a player hasn't pressed Enter within this time (e.g. 30s)"),
+        gameTimeout:   fs.Duration("game-timeout", 0, "abandon the game after this long"),
+        speed:         fs.String("speed", "", "pacing preset: instant, fast, normal or cinematic (remembered per profile)"),
+        fast:          fs.Bool("fast", false, "no delays or animation at all this run, whatever the speed preset"),
+        delay:         fs.Duration("delay", 0, "pause this long between events and turns this run (overrides the speed preset)"),
+        animate:       fs.Duration("animate", 0, "animate each dice roll for this long this run; 0 turns the animation off"),
+        profile:       fs.String("profile", "default", "profile whose saved preferences to use"),
+        hook:          fs.String("result-hook", "", "shell command run with the result JSON on stdin when a game ends (remembered per profile; \"none\" clears it)"),
+        spectateAddr:  fs.String("spectate-addr", "", "let spectators follow the game from this address (e.g. :7070; attach with 'watch host:port')"),
+        spectateDelay: fs.Duration("spectate-delay", 0, "show spectators each event only this long after it happens (e.g. 30s), so they can't coach"),
+        summary:       fs.String("summary", "", "also write the post-game summary to this file"),
+        card:          fs.String("summary-card", "", "write a Markdown summary card with the result and awards to this file"),
+        odds:          fs.Bool("odds", false, "after every turn, show each player's chance of winning from there"),
+        coach:         fs.Bool("coach", false, "explain the odds after notable moments (big snake falls, long-shot wins)"),
+        match:         fs.Int("match", 0, "play a match: games continue until someone has this many wins, starting player alternating"),
+        store:         addStoreFlag(fs),
+        ui:            fs.String("ui", "text", "how to show the game: text, json (one JSON object a line, for other programs) or tui (the board redrawn each turn)"),
+        rollOff:       fs.Bool("roll-off", false, "before each game, everyone rolls for the order of play, highest first and ties rolling again"),
+        shuffle:       fs.Bool("shuffle-order", false, "play in a random order rather than the order the players are named in"),
+    }
+}
+
+// options sets up opts as far as the flags go before the board is
+// settled: how the game looks and where it is kept, with the board from
+// -board
+func (f *playFlags) options() (Options, error) {
+    var opts Options
+    opts.TurnTimeout = *f.turnTimeout
+    if err := f.look.apply(&opts); err != nil {
+        return opts, err
+    }
+    if !slices.Contains(uiNames, *f.ui) {
+        return opts, usageError{fmt.Errorf("unknown -ui %q (want %s)", *f.ui, strings.Join(uiNames, ", "))}
+    }
+    if *f.look.accessible && *f.ui != "text" {
+        return opts, usageError{errors.New("-accessible needs -ui text")}
+    }
+    opts.UI = *f.ui
+    var err error
+    if opts.Store, err = openStore(*f.store); err != nil {
+        return opts, usageError{err}
+    }
+    if opts.Profiles, err = openProfiles(*f.store); err != nil {
+        return opts, usageError{err}
+    }
+    return opts, f.rules.loadBoard(&opts)
+}
+
+// session is what games played here have open, until Close
+type session struct {
+    names   []string
+    prefs   ProfilePrefs
+    history *eventRecorder // every event, for the summary and awards
+    logs    *gameLogs
+    hub     *spectatorHub
+}
+
+// session sets up the rest of opts, now that its board is settled: pace,
+// records, spectators, odds and rules, and the players' names
+func (f *playFlags) session(ctx context.Context, fs *flag.FlagSet, opts *Options) (*session, error) {
+    s := &session{history: &eventRecorder{}}
+    var err error
+    if s.prefs, err = f.pacing(fs, opts); err != nil {
+        return nil, err
+    }
+    if s.logs, err = f.logs.open(opts); err != nil {
+        return nil, err
+    }
+    narration := os.Stdout
+    if *f.logs.jsonEvents == "-" {
+        narration = os.Stderr
+    }
+    opts.Color = colorWanted(narration, *f.look.noColor || *f.look.accessible)
+    if *f.look.accessible {
+        opts.Observers = append(opts.Observers, newSpokenEvents(narration))
+    }
+    if *f.spectateAddr != "" {
+        ln, err := net.Listen("tcp", *f.spectateAddr)
+        if err != nil {
+            s.Close()
+            return nil, err
+        }
+        s.hub = newSpectatorHub()
+        s.hub.SetDelay(opts.Deps.withDefaults().Clock, *f.spectateDelay)
+        go serveSpectators(ctx, ln, s.hub)
+        opts.Observers = append(opts.Observers, s.hub)
+    }
+    opts.Odds = *f.odds
+    if *f.coach {
+        out := opts.Out
+        if out == nil {
+            out = os.Stdout
+        }
+        opts.Observers = append(opts.Observers, newCoach(out, opts.Msgs))
+    }
+    opts.Observers = append(opts.Observers, s.history)
+    if s.names, err = parseNameList(*f.players); err != nil {
+        err = usageError{err}
+    } else {
+        err = f.rules.apply(fs, opts)
+    }
+    if err != nil {
+        s.Close()
+        return nil, err
+    }
+    return s, nil
+}
+
+func (s *session) Close() {
+    if s.hub != nil {
+        s.hub.Close(2 * time.Second)
+    }
+    if s.logs != nil {
+        s.logs.Close()
+    }
+}
+
+// pacing sets opts' speed from -speed, or the profile's preferences, and
+// any one-off -fast, -delay and -animate, returning the profile's
+// preferences with -speed and -result-hook remembered
+func (f *playFlags) pacing(fs *flag.FlagSet, opts *Options) (ProfilePrefs, error) {
+    prefs, err := loadPrefs()
+    if err != nil {
+        fmt.Fprintln(os.Stderr, opts.Msgs.T(MsgWarnLoadPref, err))
+    }
+    pp := prefs[*f.profile]
+    changed := false
+    if *f.speed != "" {
+        sp, err := ParseSpeed(*f.speed)
+        if err != nil {
+            return pp, usageError{err}
+        }
+        pp.Speed = sp
+        changed = true
+    }
+    switch *f.hook {
+    case "":
+    case "none":
+        pp.ResultHook, changed = "", true
+    default:
+        pp.ResultHook, changed = *f.hook, true
+    }
+    if changed {
+        set := pp
+        pp, err = updatePrefs(*f.profile, func(p *ProfilePrefs) {
+            if *f.speed != "" {
+                p.Speed = set.Speed
+            }
+            if *f.hook != "" {
+                p.ResultHook = set.ResultHook
+            }
+        })
+        if err != nil {
+            pp = set
+            fmt.Fprintln(os.Stderr, opts.Msgs.T(MsgWarnSavePref, err))
+        }
+    }
+    opts.Speed = pp.Speed
+    var delaySet, animateSet *time.Duration
+    fs.Visit(func(fl *flag.Flag) {
+        switch fl.Name {
+        case "delay":
+            delaySet = f.delay
+        case "animate":
+            animateSet = f.animate
+        }
+    })
+    if *f.fast || delaySet != nil || animateSet != nil {
+        p := opts.Speed.Pacing().override(*f.fast, delaySet, animateSet)
+        opts.Pacing = &p
+    }
+    return pp, nil
+}
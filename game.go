@@ -0,0 +1,536 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "io"
+    "slices"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+)
+
+// PlayerID identifies a seat in a Game: its index in the starting order
+type PlayerID int
+
+var (
+    ErrNotYourTurn = errors.New("not your turn")
+    ErrGameOver    = errors.New("game is over")
+    ErrMustPick    = errors.New("this game offers two rolls each turn: use Offer and Pick")
+    ErrNoPick      = errors.New("this game has no choice of rolls")
+    ErrNoSeat      = errors.New("no seat free: nobody has left")
+    ErrTimeUp      = errors.New("out of time")
+    ErrNoToken     = errors.New("none of your tokens can move")
+)
+
+// Game wraps a GameState for use from several goroutines, e.g. one per
+// network connection. All reads and writes go through its methods, which
+// serialize on an internal lock; observers are called with it held so they
+// see events in order.
+type Game struct {
+    id        string
+    clock     Clock
+    mu        sync.Mutex
+    state     GameState
+    turns     int
+    outcome   Outcome
+    observers []Observer
+    theme     Theme
+
+    // the running clock under Rules.Clock: whose it is (-1 for nobody's),
+    // since when, and a count of turns it has run for, so a watch set for
+    // an earlier one knows it is stale
+    ticking   int
+    tickSince time.Time
+    tickSeq   int
+}
+
+func NewGame(board Board, names []string, deps Deps, obs ...Observer) (*Game, error) {
+    return NewGameWithRules(board, names, Rules{}, deps, obs...)
+}
+
+// NewGameWithRules is NewGame for a game with optional rules
+func NewGameWithRules(board Board, names []string, rules Rules, deps Deps, obs ...Observer) (*Game, error) {
+    if len(names) == 0 {
+        return nil, errors.New("a game needs at least one player")
+    }
+    if err := rules.Check(board); err != nil {
+        return nil, err
+    }
+    deps = deps.withDefaults()
+    g := &Game{
+        id:        deps.IDs.NewID(),
+        clock:     deps.Clock,
+        state:     newGameState(board, names),
+        observers: obs,
+        ticking:   -1,
+    }
+    g.state.setRules(rules)
+    g.state.Dice = randomDice()
+    placeAtStart(&g.state)
+    setupTokens(&g.state, rules.Tokens)
+    startClocks(&g.state)
+    g.outcome = checkOutcome(g.state)
+    notify(g.observers, startEvent(g.state, 0, g.clock.Now()))
+    return g, nil
+}
+
+// ID is the game's identifier from its IDGenerator
+func (g *Game) ID() string {
+    return g.id
+}
+
+// Roll takes id's turn with the next roll of the game's dice, moving their
+// first token that isn't home
+func (g *Game) Roll(id PlayerID) ([]Event, error) {
+    return g.roll(id, DieRoll{}, -1, 0)
+}
+
+// RollToken takes id's turn moving their token (counting from 0) in a
+// game with several tokens per player
+func (g *Game) RollToken(id PlayerID, token int) ([]Event, error) {
+    return g.roll(id, DieRoll{}, token, 0)
+}
+
+// RollWith takes id's turn with a given roll
+func (g *Game) RollWith(id PlayerID, dr DieRoll) ([]Event, error) {
+    return g.roll(id, dr, -1, 0)
+}
+
+// Offer shows id the two rolls they choose between this turn, in a game
+// with the pick_die rule. Asking again gives the same pair: the dice only
+// move on once one is picked.
+func (g *Game) Offer(id PlayerID) ([]DieRoll, error) {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    if err := g.checkTurn(id); err != nil {
+        return nil, err
+    }
+    if !g.state.Rules.PickDie {
+        return nil, ErrNoPick
+    }
+    choices := g.state.Dice.Peek(2)
+    notify(g.observers, Event{Kind: EventOffer, Time: g.clock.Now(), Turn: g.turns, Player: g.state.Players[id].Name, Choices: dieValues(choices)})
+    return choices, nil
+}
+
+// Pick takes id's turn with the which-th (from 1) of the offered rolls,
+// moving token as RollToken does (negative for the first not home)
+func (g *Game) Pick(id PlayerID, which, token int) ([]Event, error) {
+    if which != 1 && which != 2 {
+        return nil, fmt.Errorf("no roll %d to pick (want 1 or 2)", which)
+    }
+    return g.roll(id, DieRoll{}, token, which)
+}
+
+func (g *Game) checkTurn(id PlayerID) error {
+    if g.over() {
+        return ErrGameOver
+    }
+    if int(id) < 0 || int(id) >= len(g.state.Players) {
+        return fmt.Errorf("no player %d", id)
+    }
+    if int(id) != g.state.CurrentPlayerIndex {
+        return ErrNotYourTurn
+    }
+    return nil
+}
+
+// roll takes id's turn, moving token, or the first token not home if
+// token is negative. A zero dr draws from the game's dice, or with the
+// pick_die rule takes the pick-th of the two rolls on offer.
+func (g *Game) roll(id PlayerID, dr DieRoll, token, pick int) ([]Event, error) {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    return g.rollLocked(id, dr, token, pick)
+}
+
+// rollLocked is roll with g.mu already held
+func (g *Game) rollLocked(id PlayerID, dr DieRoll, token, pick int) ([]Event, error) {
+    if err := g.checkTurn(id); err != nil {
+        return nil, err
+    }
+    if now := g.clock.Now(); g.outOfTime(now) {
+        g.timeUp(now)
+        return nil, ErrTimeUp
+    }
+    switch {
+    case dr.Value != 0:
+    case pick > 0 && !g.state.Rules.PickDie:
+        return nil, ErrNoPick
+    case pick == 0 && g.state.Rules.PickDie:
+        return nil, ErrMustPick
+    }
+    if dr.Value != 0 {
+        if err := checkMove(g.state, dr); err != nil {
+            return nil, err
+        }
+    }
+    if token < 0 {
+        ks := movableTokens(g.state)
+        if len(ks) == 0 {
+            return nil, ErrNoToken
+        }
+        token = ks[0]
+    }
+    gs, err := withToken(g.state, token)
+    if err != nil {
+        return nil, err
+    }
+    g.state = gs
+    var choices []DieRoll
+    if dr.Value == 0 {
+        dr, choices = drawRoll(&g.state.Dice, g.state.Rules, pick)
+    }
+    g.turns++
+    now := g.clock.Now()
+    g.stopClock(now)
+    evs := turnEvents(g.state, dr, g.turns, now)
+    evs[0].Choices = dieValues(choices)
+    g.state = applyMove(g.state, dr)
+    g.outcome = checkOutcome(g.state)
+    switch o := g.outcome.(type) {
+    case Win:
+        evs = append(evs, winEvent(o, g.state, g.turns, now))
+    case Draw:
+        evs = append(evs, drawEvent(g.state, g.turns, now))
+    case Ongoing:
+        g.startClock(now)
+    }
+    for _, ev := range evs {
+        notify(g.observers, ev)
+    }
+    return evs, nil
+}
+
+// UseItem spends one of id's items ahead of their roll
+func (g *Game) UseItem(id PlayerID, item ItemKind) (Event, error) {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    return g.useItemLocked(id, item)
+}
+
+// useItemLocked is UseItem with g.mu already held
+func (g *Game) useItemLocked(id PlayerID, item ItemKind) (Event, error) {
+    if g.over() {
+        return Event{}, ErrGameOver
+    }
+    if int(id) < 0 || int(id) >= len(g.state.Players) {
+        return Event{}, fmt.Errorf("no player %d", id)
+    }
+    if int(id) != g.state.CurrentPlayerIndex {
+        return Event{}, ErrNotYourTurn
+    }
+    gs, err := useItem(g.state, item)
+    if err != nil {
+        return Event{}, err
+    }
+    g.state = gs
+    ev := Event{Kind: EventUseItem, Time: g.clock.Now(), Turn: g.turns, Player: gs.Players[id].Name, Item: string(item), Hash: stateHash(gs)}
+    notify(g.observers, ev)
+    return ev, nil
+}
+
+// Leave takes id out of the game part-way through: their tokens stay
+// where they are and their turns are passed over until someone Joins in
+// their place. Once nobody is left with turns to take, the game is
+// abandoned.
+func (g *Game) Leave(id PlayerID) ([]Event, error) {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    if g.over() {
+        return nil, ErrGameOver
+    }
+    if int(id) < 0 || int(id) >= len(g.state.Players) {
+        return nil, fmt.Errorf("no player %d", id)
+    }
+    if g.state.Players[id].Left {
+        return nil, fmt.Errorf("%s has already left", g.state.Players[id].Name)
+    }
+    now := g.clock.Now()
+    ticking := g.ticking == int(id)
+    if ticking {
+        g.stopClock(now)
+    }
+    g.state = leaveSeat(g.state, int(id))
+    evs := []Event{{Kind: EventLeave, Time: now, Turn: g.turns, Player: g.state.Players[id].Name, Hash: stateHash(g.state)}}
+    if !stillPlaying(g.state) {
+        g.outcome = Abandoned{g.copyState(), AbortPlayersLeft}
+        evs = append(evs, Event{Kind: EventAbort, Time: now, Turn: g.turns, Reason: string(AbortPlayersLeft), Hash: stateHash(g.state)})
+    } else if ticking {
+        g.startClock(now)
+    }
+    for _, ev := range evs {
+        notify(g.observers, ev)
+    }
+    return evs, nil
+}
+
+// Join seats name in place of a player who has left: their own old seat
+// if they had one, else the first left. Its tokens carry on from where
+// they were. It returns ErrNoSeat when nobody has left.
+func (g *Game) Join(name string) (PlayerID, Event, error) {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    if g.over() {
+        return 0, Event{}, ErrGameOver
+    }
+    if name == "" {
+        return 0, Event{}, errors.New("a player needs a name")
+    }
+    idx := seatOf(g.state, name)
+    switch {
+    case idx >= 0 && !g.state.Players[idx].Left:
+        return 0, Event{}, fmt.Errorf("%s is already playing", name)
+    case idx < 0:
+        for i, p := range g.state.Players {
+            if p.Left {
+                idx = i
+                break
+            }
+        }
+    }
+    if idx < 0 {
+        return 0, Event{}, ErrNoSeat
+    }
+    old := g.state.Players[idx].Name
+    g.state = takeSeat(g.state, idx, name)
+    ev := Event{Kind: EventJoin, Time: g.clock.Now(), Turn: g.turns, Player: name, Other: old, Hash: stateHash(g.state)}
+    notify(g.observers, ev)
+    return PlayerID(idx), ev, nil
+}
+
+// seatOf is the index of the player called name in gs, or -1
+func seatOf(gs GameState, name string) int {
+    for i, p := range gs.Players {
+        if p.Name == name {
+            return i
+        }
+    }
+    return -1
+}
+
+// stillPlaying reports whether anyone in gs has turns left to take
+func stillPlaying(gs GameState) bool {
+    for _, p := range gs.Players {
+        if !p.Left && !p.home(gs.Board.FinalSquare) {
+            return true
+        }
+    }
+    return false
+}
+
+// leaveSeat is gs after the player at idx leaves, passing the turn on if
+// it was theirs
+func leaveSeat(gs GameState, idx int) GameState {
+    gs.Players = append([]Player(nil), gs.Players...)
+    gs.Players[idx].Left = true
+    if gs.CurrentPlayerIndex == idx {
+        passTurn(&gs, idx)
+    }
+    return gs
+}
+
+// takeSeat is gs after name takes over the seat at idx. The turn stays
+// where it is: a seat coming back into play waits for it to come round.
+func takeSeat(gs GameState, idx int, name string) GameState {
+    gs.Players = append([]Player(nil), gs.Players...)
+    gs.Players[idx].Name, gs.Players[idx].Left = name, false
+    return gs
+}
+
+// SetTheme sets how Render draws the board
+func (g *Game) SetTheme(t Theme) {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    g.theme = t
+}
+
+// State returns a copy of the current state that is safe to keep, with
+// the running clock showing the time left now
+func (g *Game) State() GameState {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    gs := g.copyState()
+    if g.ticking >= 0 {
+        gs.Players[g.ticking].Clock -= g.clock.Now().Sub(g.tickSince)
+    }
+    return gs
+}
+
+func (g *Game) copyState() GameState {
+    gs := g.state
+    gs.Players = append([]Player(nil), gs.Players...)
+    for i := range gs.Players {
+        gs.Players[i].Items = append([]ItemKind(nil), gs.Players[i].Items...)
+        gs.Players[i].Tokens = append([]BoardPos(nil), gs.Players[i].Tokens...)
+    }
+    return gs
+}
+
+// Abort ends the game without a winner. Aborting a finished game is an
+// error, so a late abort can't overwrite a real result.
+func (g *Game) Abort(reason AbortReason) error {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    if g.over() {
+        return ErrGameOver
+    }
+    g.outcome = Abandoned{g.copyState(), reason}
+    notify(g.observers, Event{Kind: EventAbort, Time: g.clock.Now(), Turn: g.turns, Reason: string(reason), Hash: stateHash(g.state)})
+    return nil
+}
+
+// Suspend announces with a pause event, giving reason, that the game is
+// being put away unfinished to be restored from a save, as when its
+// server shuts down. Suspending a finished game is an error.
+func (g *Game) Suspend(reason string) error {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    if g.over() {
+        return ErrGameOver
+    }
+    notify(g.observers, Event{Kind: EventPause, Time: g.clock.Now(), Turn: g.turns, Reason: reason, Hash: stateHash(g.state)})
+    return nil
+}
+
+func (g *Game) over() bool {
+    switch g.outcome.(type) {
+    case Win, Draw, Abandoned:
+        return true
+    }
+    return false
+}
+
+// Outcome reports Win, Draw or Abandoned once the game is over, Ongoing until then
+func (g *Game) Outcome() Outcome {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    if g.over() {
+        return g.outcome
+    }
+    return Ongoing{g.copyState()}
+}
+
+// Turns is the number of rolls taken so far
+func (g *Game) Turns() int {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    return g.turns
+}
+
+// Rolls is every roll id has taken, in order, forfeited ones included
+func (g *Game) Rolls(id PlayerID) ([]int, error) {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    if int(id) < 0 || int(id) >= len(g.state.Players) {
+        return nil, fmt.Errorf("no player %d", id)
+    }
+    return slices.Clone(g.state.Players[id].Stats.History), nil
+}
+
+func (g *Game) Players() []string {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    names := make([]string, len(g.state.Players))
+    for i, p := range g.state.Players {
+        names[i] = p.Name
+    }
+    return names
+}
+
+func (g *Game) CurrentPlayer() PlayerID {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    return PlayerID(g.state.CurrentPlayerIndex)
+}
+
+func (g *Game) LegalMoves() []string {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    if g.over() {
+        return nil
+    }
+    rolls := []string{"roll"}
+    if g.state.Rules.PickDie {
+        rolls = []string{"pick 1", "pick 2"}
+    }
+    var moves []string
+    for _, r := range rolls {
+        if len(g.state.Players[g.state.CurrentPlayerIndex].Tokens) == 0 {
+            moves = append(moves, r)
+            continue
+        }
+        for _, k := range movableTokens(g.state) {
+            moves = append(moves, fmt.Sprintf("%s %d", r, k+1))
+        }
+    }
+    for _, it := range usableItems(g.state) {
+        moves = append(moves, "use "+string(it))
+    }
+    return moves
+}
+
+// Apply makes the current player's move: "roll", or "pick 1" and "pick 2"
+// under the pick_die rule, each followed by the token to move in a game
+// with several per player ("roll 2"), or "use ITEM" before the roll. The
+// turn is checked and taken under one hold of the lock, so the move can't
+// go to whoever's turn comes next.
+func (g *Game) Apply(move string) ([]Event, error) {
+    f := strings.Fields(move)
+    if len(f) == 0 {
+        return nil, errors.New("empty move")
+    }
+    args := make([]int, len(f)-1)
+    if f[0] != "use" {
+        for i, a := range f[1:] {
+            n, err := strconv.Atoi(a)
+            if err != nil {
+                return nil, fmt.Errorf("%q isn't a number", a)
+            }
+            args[i] = n
+        }
+    }
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    id := PlayerID(g.state.CurrentPlayerIndex)
+    switch {
+    case f[0] == "roll" && len(args) <= 1:
+        token := -1
+        if len(args) == 1 {
+            token = args[0] - 1
+        }
+        return g.rollLocked(id, DieRoll{}, token, 0)
+    case f[0] == "pick" && (len(args) == 1 || len(args) == 2):
+        if args[0] != 1 && args[0] != 2 {
+            return nil, fmt.Errorf("no roll %d to pick (want 1 or 2)", args[0])
+        }
+        token := -1
+        if len(args) == 2 {
+            token = args[1] - 1
+        }
+        return g.rollLocked(id, DieRoll{}, token, args[0])
+    case f[0] == "use" && len(f) == 2:
+        ev, err := g.useItemLocked(id, ItemKind(f[1]))
+        if err != nil {
+            return nil, err
+        }
+        return []Event{ev}, nil
+    }
+    return nil, fmt.Errorf("unknown move %q", move)
+}
+
+// Render draws the board with everyone on it, and under the pick_die rule
+// the two rolls on offer, in the language of the theme's messages
+func (g *Game) Render(w io.Writer) {
+    gs := g.State()
+    g.mu.Lock()
+    theme := g.theme
+    g.mu.Unlock()
+    theme.RenderBoard(w, gs.Board, gs.Players)
+    if _, ongoing := g.Outcome().(Ongoing); ongoing && gs.Rules.PickDie {
+        offer := gs.Dice.Peek(2)
+        fmt.Fprintln(w, theme.msgs.T(MsgOnOffer, offer[0].Value, offer[1].Value))
+    }
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "sync"
+)
+
+// ActionKind says what an Action asks the game to do
+type ActionKind string
+
+const (
+    ActionRoll    ActionKind = "roll"
+    ActionUseItem ActionKind = "use_item"
+    ActionAbort   ActionKind = "abort"
+    // with the pick_die rule, ActionOffer shows the two rolls on offer (as
+    // an offer event) and ActionPick takes one
+    ActionOffer ActionKind = "offer"
+    ActionPick  ActionKind = "pick"
+)
+
+// Action is a request sent to a game started with StartGame
+type Action struct {
+    Kind   ActionKind
+    Player PlayerID
+    Token  int         // for ActionRoll and ActionPick with several tokens each: which to move, counting from 1 (0 = first not home)
+    Die    int         // for ActionPick: which roll to take, 1 or 2
+    Item   ItemKind    // for ActionUseItem
+    Reason AbortReason // for ActionAbort; defaults to player_quit
+    // Err, if set, receives the result (nil on success). It must have room
+    // for one value: the game never waits for it to be read.
+    Err chan<- error
+}
+
+// StartGame runs a game in its own goroutine and hands back its events and
+// an actions channel to drive it, for callers that want to select on them
+// alongside other work rather than block in a loop.
+//
+// Every event is delivered in order, however far behind the reader is, and
+// events is closed once the game is won or abandoned. Cancelling ctx
+// abandons the game; events still queued at that point may be dropped.
+// Actions sent after the game is over are never received, so senders
+// should select on ctx or events as well.
+func StartGame(ctx context.Context, board Board, names []string, rules Rules, deps Deps, obs ...Observer) (<-chan Event, chan<- Action, error) {
+    q := newEventQueue()
+    g, err := NewGameWithRules(board, names, rules, deps, append([]Observer{q}, obs...)...)
+    if err != nil {
+        return nil, nil, err
+    }
+    events := make(chan Event)
+    actions := make(chan Action)
+    go q.pump(ctx, events)
+    go func() {
+        defer q.finish()
+        for {
+            if _, ongoing := g.Outcome().(Ongoing); !ongoing {
+                return
+            }
+            select {
+            case <-ctx.Done():
+                g.Abort(abortReasonFor(ctx.Err()))
+                return
+            case a := <-actions:
+                err := g.apply(a)
+                if a.Err != nil {
+                    select {
+                    case a.Err <- err:
+                    default:
+                    }
+                }
+            }
+        }
+    }()
+    return events, actions, nil
+}
+
+func (g *Game) apply(a Action) error {
+    switch a.Kind {
+    case ActionRoll:
+        _, err := g.roll(a.Player, DieRoll{}, a.Token-1, 0)
+        return err
+    case ActionOffer:
+        _, err := g.Offer(a.Player)
+        return err
+    case ActionPick:
+        _, err := g.Pick(a.Player, a.Die, a.Token-1)
+        return err
+    case ActionUseItem:
+        _, err := g.UseItem(a.Player, a.Item)
+        return err
+    case ActionAbort:
+        if a.Reason == "" {
+            a.Reason = AbortPlayerQuit
+        }
+        return g.Abort(a.Reason)
+    }
+    return fmt.Errorf("unknown action %q", a.Kind)
+}
+
+// eventQueue is an unbounded Observer buffer, so a slow reader never
+// holds up the game (whose lock is held while observers run)
+type eventQueue struct {
+    mu    sync.Mutex
+    evs   []Event
+    done  bool
+    ready chan struct{} // poked whenever evs grows or done is set
+}
+
+func newEventQueue() *eventQueue {
+    return &eventQueue{ready: make(chan struct{}, 1)}
+}
+
+func (q *eventQueue) OnEvent(ev Event) {
+    q.mu.Lock()
+    q.evs = append(q.evs, ev)
+    q.mu.Unlock()
+    q.poke()
+}
+
+func (q *eventQueue) finish() {
+    q.mu.Lock()
+    q.done = true
+    q.mu.Unlock()
+    q.poke()
+}
+
+func (q *eventQueue) poke() {
+    select {
+    case q.ready <- struct{}{}:
+    default:
+    }
+}
+
+// pump feeds queued events to out, closing it after the last one
+func (q *eventQueue) pump(ctx context.Context, out chan<- Event) {
+    defer close(out)
+    for {
+        q.mu.Lock()
+        if len(q.evs) == 0 {
+            done := q.done
+            q.mu.Unlock()
+            if done {
+                return
+            }
+            <-q.ready
+            continue
+        }
+        ev := q.evs[0]
+        q.evs = q.evs[1:]
+        q.mu.Unlock()
+        select {
+        case out <- ev:
+        case <-ctx.Done():
+            return
+        }
+    }
+}
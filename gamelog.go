@@ -0,0 +1,114 @@
+package main
+
+import (
+    "fmt"
+    "io"
+    "os"
+    "strings"
+    "time"
+)
+
+// gameLog is an Observer appending a timestamped line per event
+type gameLog struct {
+    w io.Writer
+}
+
+func openGameLog(path string) (*os.File, *gameLog, error) {
+    f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+    if err != nil {
+        return nil, nil, err
+    }
+    return f, &gameLog{f}, nil
+}
+
+func (l *gameLog) OnEvent(ev Event) {
+    fmt.Fprintf(l.w, "%s turn %d: %s\n", ev.Time.Format(time.RFC3339), ev.Turn, describeEvent(ev))
+}
+
+// describeEvent is the plain English line used by logs and transcripts
+func describeEvent(ev Event) string {
+    switch ev.Kind {
+    case EventStart:
+        if how, ok := orderHow[ev.Reason]; ok {
+            return fmt.Sprintf("game started: %s (order %s)", strings.Join(ev.Players, ", "), how)
+        }
+        return "game started: " + strings.Join(ev.Players, ", ")
+    case EventRoll:
+        if len(ev.Choices) == 2 {
+            return fmt.Sprintf("%s took %d of %d and %d", ev.Player, ev.Roll, ev.Choices[0], ev.Choices[1])
+        }
+        return fmt.Sprintf("%s rolled %d", ev.Player, ev.Roll)
+    case EventMove:
+        if ev.Token > 0 {
+            return fmt.Sprintf("%s moved token %d %d -> %d", ev.Player, ev.Token, ev.From, ev.To)
+        }
+        return fmt.Sprintf("%s moved %d -> %d", ev.Player, ev.From, ev.To)
+    case EventSnake:
+        return fmt.Sprintf("%s hit a snake %d -> %d", ev.Player, ev.From, ev.To)
+    case EventLadder:
+        return fmt.Sprintf("%s climbed a ladder %d -> %d", ev.Player, ev.From, ev.To)
+    case EventSkipTurn:
+        return fmt.Sprintf("%s will miss a turn", ev.Player)
+    case EventExtraRoll:
+        return fmt.Sprintf("%s rolls again", ev.Player)
+    case EventTeleport:
+        return fmt.Sprintf("%s teleported %d -> %d", ev.Player, ev.From, ev.To)
+    case EventSwap:
+        return fmt.Sprintf("%s swapped with %s %d -> %d", ev.Player, ev.Other, ev.From, ev.To)
+    case EventScript:
+        if ev.Other != "" {
+            return fmt.Sprintf("%s was moved by %s's square script %d -> %d", ev.Player, ev.Other, ev.From, ev.To)
+        }
+        return fmt.Sprintf("%s's square script moved them %d -> %d", ev.Player, ev.From, ev.To)
+    case EventThreeSixes:
+        return fmt.Sprintf("%s rolled three sixes in a row, forfeit %d -> %d", ev.Player, ev.From, ev.To)
+    case EventSkipped:
+        if ev.Reason == "no_move" {
+            return fmt.Sprintf("%s has no move", ev.Player)
+        }
+        return fmt.Sprintf("%s missed a turn", ev.Player)
+    case EventItem:
+        return fmt.Sprintf("%s picked up %s on %d", ev.Player, ev.Item, ev.From)
+    case EventUseItem:
+        return fmt.Sprintf("%s used %s", ev.Player, ev.Item)
+    case EventShield:
+        return fmt.Sprintf("%s's immunity blocked the snake on %d", ev.Player, ev.From)
+    case EventCapture:
+        return fmt.Sprintf("%s captured %s %d -> %d", ev.Player, ev.Other, ev.From, ev.To)
+    case EventWin:
+        if ev.Team != "" {
+            return fmt.Sprintf("team %s won (%s finished)", ev.Team, ev.Player)
+        }
+        return fmt.Sprintf("%s won", ev.Player)
+    case EventDraw:
+        return "the game is a draw"
+    case EventAbort:
+        return "game abandoned: " + ev.Reason
+    case EventPause:
+        if ev.Reason == shutdownReason {
+            return "game saved: the server is shutting down"
+        }
+        return "game paused"
+    case EventOffer:
+        return fmt.Sprintf("%s was offered %d or %d", ev.Player, ev.Choices[0], ev.Choices[1])
+    case EventRestore:
+        return "restored bookmark " + ev.Reason
+    case EventMark:
+        return fmt.Sprintf("%s marked %d", ev.Player, ev.To)
+    case EventDrop:
+        return fmt.Sprintf("%s dropped a piece in column %d", ev.Player, ev.To)
+    case EventLeave:
+        if ev.Reason == timeUpReason {
+            return fmt.Sprintf("%s ran out of time", ev.Player)
+        }
+        return fmt.Sprintf("%s left the game", ev.Player)
+    case EventJoin:
+        if ev.Player == ev.Other {
+            return fmt.Sprintf("%s rejoined the game", ev.Player)
+        }
+        return fmt.Sprintf("%s took over %s's seat", ev.Player, ev.Other)
+    case EventChat:
+        return fmt.Sprintf("%s: %s", ev.Player, ev.Reason)
+    }
+    return string(ev.Kind)
+}
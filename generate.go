@@ -0,0 +1,150 @@
+package main
+
+import (
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "math"
+    "math/rand"
+    "time"
+)
+
+// GenerateOptions constrain GenerateBoard
+type GenerateOptions struct {
+    Size        int
+    Snakes      int
+    Ladders     int
+    MinJump     int     // shortest snake or ladder, in squares
+    MaxJump     int     // longest
+    TargetTurns float64 // wanted mean rolls for one player to finish; 0 accepts any
+    Tolerance   float64 // how far from TargetTurns is close enough
+    Seed        int64
+    Attempts    int // boards to try before giving up on the target
+}
+
+// simCheckGames is how many games the quick length check plays
+const simCheckGames = 2000
+
+// GenerateBoard places snakes and ladders at random within opts and, if a
+// target length is set, keeps drawing until a board's simulated mean game
+// length is within tolerance. On failure it returns the closest board it
+// found along with the error.
+func GenerateBoard(opts GenerateOptions) (BoardConfig, float64, error) {
+    if opts.MinJump < 1 || opts.MaxJump < opts.MinJump {
+        return BoardConfig{}, 0, fmt.Errorf("jump lengths %d..%d are not a valid range", opts.MinJump, opts.MaxJump)
+    }
+    // squares 1 and Size never start a jump; each jump needs a start and an end
+    if 2*(opts.Snakes+opts.Ladders) > opts.Size-2 {
+        return BoardConfig{}, 0, fmt.Errorf("%d snakes and %d ladders don't fit on %d squares", opts.Snakes, opts.Ladders, opts.Size)
+    }
+    attempts := max(opts.Attempts, 1)
+    rng := rand.New(rand.NewSource(opts.Seed))
+
+    var best BoardConfig
+    bestMean, bestDist := 0.0, math.Inf(1)
+    for a := 0; a < attempts; a++ {
+        cfg, ok := placeJumps(rng, opts)
+        if !ok {
+            continue
+        }
+        b, err := cfg.Build()
+        if err != nil {
+            continue
+        }
+        mean := quickMeanTurns(b, rng.Int63())
+        dist := math.Abs(mean - opts.TargetTurns)
+        if opts.TargetTurns == 0 || dist <= opts.Tolerance {
+            return cfg, mean, nil
+        }
+        if dist < bestDist {
+            best, bestMean, bestDist = cfg, mean, dist
+        }
+    }
+    if bestDist == math.Inf(1) {
+        return BoardConfig{}, 0, errors.New("could not place the jumps within those constraints")
+    }
+    return best, bestMean, fmt.Errorf("no board within %.1f of %.1f turns after %d attempts (closest %.1f)",
+        opts.Tolerance, opts.TargetTurns, attempts, bestMean)
+}
+
+// placeJumps draws one random layout, or false if it painted itself into
+// a corner
+func placeJumps(rng *rand.Rand, opts GenerateOptions) (BoardConfig, bool) {
+    cfg := BoardConfig{Size: opts.Size}
+    used := map[int]bool{1: true, opts.Size: true}
+    pick := func(down bool) (JumpSpec, bool) {
+        for try := 0; try < 100; try++ {
+            length := opts.MinJump + rng.Intn(opts.MaxJump-opts.MinJump+1)
+            from := 2 + rng.Intn(opts.Size-2)
+            to := from + length
+            if down {
+                to = from - length
+            }
+            if to < 1 || to > opts.Size || used[from] || used[to] {
+                continue
+            }
+            used[from], used[to] = true, true
+            return JumpSpec{from, to}, true
+        }
+        return JumpSpec{}, false
+    }
+    for i := 0; i < opts.Snakes; i++ {
+        j, ok := pick(true)
+        if !ok {
+            return cfg, false
+        }
+        cfg.Snakes = append(cfg.Snakes, j)
+    }
+    for i := 0; i < opts.Ladders; i++ {
+        j, ok := pick(false)
+        if !ok {
+            return cfg, false
+        }
+        cfg.Ladders = append(cfg.Ladders, j)
+    }
+    return cfg, true
+}
+
+// quickMeanTurns is the mean number of rolls a lone player needs on b
+func quickMeanTurns(b Board, seed int64) float64 {
+    r := NewSeededRoller(seed)
+    total := 0
+    for i := 0; i < simCheckGames; i++ {
+        total += simulateGame(b, []string{"solo"}, r, uint64(seed)+uint64(i), 10000).Turns
+    }
+    return float64(total) / simCheckGames
+}
+
+// runGenerate implements `generate [flags] <board-file>`
+func runGenerate(args []string, out io.Writer) error {
+    fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+    opts := GenerateOptions{}
+    fs.IntVar(&opts.Size, "size", 100, "number of squares")
+    fs.IntVar(&opts.Snakes, "snakes", 8, "number of snakes")
+    fs.IntVar(&opts.Ladders, "ladders", 8, "number of ladders")
+    fs.IntVar(&opts.MinJump, "min-jump", 5, "shortest snake or ladder")
+    fs.IntVar(&opts.MaxJump, "max-jump", 40, "longest snake or ladder")
+    fs.Float64Var(&opts.TargetTurns, "target-turns", 0, "wanted mean rolls for one player to finish (0 = any)")
+    fs.Float64Var(&opts.Tolerance, "tolerance", 3, "acceptable distance from -target-turns")
+    fs.Int64Var(&opts.Seed, "seed", time.Now().UnixNano(), "random seed")
+    fs.IntVar(&opts.Attempts, "attempts", 200, "boards to try before giving up on the target")
+    name := fs.String("name", "", "name stored in the board file")
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+    if fs.NArg() != 1 {
+        return errors.New("usage: generate [flags] <board-file>")
+    }
+    cfg, mean, err := GenerateBoard(opts)
+    if err != nil {
+        return err
+    }
+    cfg.Name = *name
+    if err := SaveBoardConfig(fs.Arg(0), cfg); err != nil {
+        return err
+    }
+    fmt.Fprintf(out, "wrote %s: %d snakes, %d ladders, about %.1f rolls to finish alone\n",
+        fs.Arg(0), len(cfg.Snakes), len(cfg.Ladders), mean)
+    return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import "fmt"
+
+// Grid lays square numbers out boustrophedon style: square 1 is bottom
+// left, the bottom row runs left to right, the next right to left, and so
+// on. Rows are numbered as drawn, 0 at the top; a short top row (squares
+// not a multiple of width) is filled from its run's starting side.
+type Grid struct {
+    Width   int
+    Squares int
+}
+
+func NewGrid(width, squares int) (Grid, error) {
+    if width < 1 || squares < 1 {
+        return Grid{}, fmt.Errorf("grid needs a positive width and square count, got %d and %d", width, squares)
+    }
+    return Grid{width, squares}, nil
+}
+
+// GridFor is the standard 10-wide layout for b
+func GridFor(b Board) Grid {
+    return Grid{10, b.FinalSquare.Index}
+}
+
+func (g Grid) Rows() int {
+    return (g.Squares + g.Width - 1) / g.Width
+}
+
+// Cell is where square sq is drawn
+func (g Grid) Cell(sq int) (row, col int, err error) {
+    if sq < 1 || sq > g.Squares {
+        return 0, 0, fmt.Errorf("square %d not on a %d-square grid", sq, g.Squares)
+    }
+    fromBottom := (sq - 1) / g.Width
+    col = (sq - 1) % g.Width
+    if fromBottom%2 == 1 {
+        col = g.Width - 1 - col
+    }
+    return g.Rows() - 1 - fromBottom, col, nil
+}
+
+// Square is the number drawn at (row, col), or false if that cell is
+// outside the grid or past the last square
+func (g Grid) Square(row, col int) (int, bool) {
+    if row < 0 || row >= g.Rows() || col < 0 || col >= g.Width {
+        return 0, false
+    }
+    fromBottom := g.Rows() - 1 - row
+    if fromBottom%2 == 1 {
+        col = g.Width - 1 - col
+    }
+    sq := fromBottom*g.Width + col + 1
+    if sq > g.Squares {
+        return 0, false
+    }
+    return sq, true
+}
+
+// PosCell is the (row, column) pos is drawn at on a board of squares
+// squares laid out width to a row as Grid does: the one mapping every
+// renderer draws from
+func PosCell(pos BoardPos, width, squares int) (row, col int, err error) {
+    g, err := NewGrid(width, squares)
+    if err != nil {
+        return 0, 0, err
+    }
+    return g.Cell(pos.Index)
+}
+
+// CellPos is PosCell the other way: the square drawn at (row, col), or
+// false if none is
+func CellPos(row, col, width, squares int) (BoardPos, bool) {
+    g, err := NewGrid(width, squares)
+    if err != nil {
+        return OffBoard, false
+    }
+    sq, ok := g.Square(row, col)
+    return BoardPos{sq}, ok
+}
@@ -0,0 +1,15 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "time"
+)
+
+// grpcServe runs the gRPC API until ctx is done, collecting games idle
+// for idle (0: never) and saving those still being played to store. It
+// is nil unless the server is compiled in (go
+// build -tags grpc, see proto/snakes.proto).
+var grpcServe func(ctx context.Context, addr string, idle time.Duration, store GameStore) error
+
+var errNoGRPC = errors.New("built without gRPC support; rebuild with -tags grpc")
@@ -0,0 +1,377 @@
+//go:build grpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative proto/snakes.proto
+
+package main
+
+import (
+    "context"
+    "errors"
+    "net"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+
+    pb "github.com/Shaenfre/tictactoe/proto"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+)
+
+func init() {
+    grpcServe = serveGRPC
+}
+
+// hostedGame is a Game plus the hub its event streams hang off
+type hostedGame struct {
+    game      *Game
+    hub       *spectatorHub
+    takeSeats bool // JoinGame may seat latecomers where players left
+    tokens    seatTokens
+    presence  *seatPresence
+    active    *activity
+    started   time.Time
+}
+
+// closeIfOver ends the game's event streams, once they have had its last
+// event, if it has been won, drawn or abandoned
+func (hg *hostedGame) closeIfOver() {
+    switch hg.game.Outcome().(type) {
+    case Win, Draw, Abandoned:
+        hg.hub.Close(0)
+    }
+}
+
+type grpcServer struct {
+    pb.UnimplementedSnakesServer
+    board Board
+    deps  Deps
+    store GameStore // where unfinished games go when collected
+    mu    sync.Mutex
+    games map[string]*hostedGame
+}
+
+func serveGRPC(ctx context.Context, addr string, idle time.Duration, store GameStore) error {
+    ln, err := net.Listen("tcp", addr)
+    if err != nil {
+        return err
+    }
+    gs := &grpcServer{
+        board: CreateStandardBoard(),
+        deps:  Deps{}.withDefaults(),
+        store: store,
+        games: map[string]*hostedGame{},
+    }
+    s := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+        defer func(start time.Time) { serverMetrics.took(info.FullMethod, time.Since(start)) }(time.Now())
+        return handler(ctx, req)
+    }))
+    pb.RegisterSnakesServer(s, gs)
+    if idle > 0 {
+        go sweepIdle(ctx, gs.deps.Clock, idle, func(now time.Time) { gs.sweep(now, idle) })
+    }
+    stopped := make(chan struct{})
+    go func() {
+        defer close(stopped)
+        <-ctx.Done()
+        gs.sweep(time.Time{}, 0)
+        s.GracefulStop()
+    }()
+    if err := s.Serve(ln); err != nil {
+        return err
+    }
+    <-stopped // Serve returns as GracefulStop starts; wait for the streams to end
+    return nil
+}
+
+// sweep collects the games, as of now, idle for idle, ending their
+// streams and saving those still being played; a zero now collects all,
+// suspending those saved as the server shuts down
+func (s *grpcServer) sweep(now time.Time, idle time.Duration) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    for id, hg := range s.games {
+        if !now.IsZero() && hg.active.idle(now) < idle {
+            continue
+        }
+        delete(s.games, id)
+        if s.store != nil && statusOf(hg.game) == GameInProgress {
+            // best effort: nowhere to report
+            if s.store.Save(id, saveGame(hg.game, hg.started)) == nil && now.IsZero() {
+                hg.game.Suspend(shutdownReason)
+            }
+        }
+        hg.hub.Close(0)
+    }
+}
+
+func (s *grpcServer) lookup(id string) (*hostedGame, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    hg, ok := s.games[id]
+    if !ok {
+        return nil, status.Errorf(codes.NotFound, "no game %q", id)
+    }
+    return hg, nil
+}
+
+// hosts reports whether hg is still the game hosted as id
+func (s *grpcServer) hosts(id string, hg *hostedGame) bool {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.games[id] == hg
+}
+
+func (s *grpcServer) CreateGame(ctx context.Context, req *pb.CreateGameRequest) (*pb.GameState, error) {
+    hub := newSpectatorHub()
+    hub.SetDelay(s.deps.Clock, time.Duration(req.GetSpectatorDelaySeconds())*time.Second)
+    active := newActivity(s.deps.Clock.Now())
+    g, err := NewGame(s.board, req.GetPlayers(), s.deps, append([]Observer{active, serverMetrics.observer(), hub}, serverTelemetry.observers()...)...)
+    if err != nil {
+        return nil, status.Error(codes.InvalidArgument, err.Error())
+    }
+    s.mu.Lock()
+    hg := &hostedGame{game: g, hub: hub, takeSeats: req.GetTakeSeats(), active: active, started: s.deps.Clock.Now()}
+    hg.presence = newSeatPresence(s.deps.Clock, time.Duration(req.GetReconnectGraceSeconds())*time.Second, func(id PlayerID) {
+        if _, err := g.Leave(id); err == nil {
+            hg.tokens.revoke(id)
+            hg.closeIfOver()
+        }
+    })
+    s.games[g.ID()] = hg
+    s.mu.Unlock()
+    return stateToPB(g), nil
+}
+
+func (s *grpcServer) Roll(ctx context.Context, req *pb.RollRequest) (*pb.RollResponse, error) {
+    hg, err := s.lookup(req.GetGameId())
+    if err != nil {
+        return nil, err
+    }
+    if err := hg.tokens.check(PlayerID(req.GetPlayerId()), req.GetPlayerToken()); err != nil {
+        return nil, status.Error(codes.PermissionDenied, err.Error())
+    }
+    evs, err := hg.game.roll(PlayerID(req.GetPlayerId()), DieRoll{}, int(req.GetToken())-1, 0)
+    switch {
+    case errors.Is(err, ErrNotYourTurn), errors.Is(err, ErrGameOver), errors.Is(err, ErrTokenHome), errors.Is(err, ErrNoToken):
+        return nil, status.Error(codes.FailedPrecondition, err.Error())
+    case err != nil:
+        return nil, status.Error(codes.InvalidArgument, err.Error())
+    }
+    resp := &pb.RollResponse{State: stateToPB(hg.game)}
+    for _, ev := range evs {
+        if ev.Kind == EventRoll {
+            resp.Roll = &pb.Roll{Value: int32(ev.Roll)}
+        }
+        resp.Events = append(resp.Events, eventToPB(ev))
+    }
+    hg.closeIfOver()
+    return resp, nil
+}
+
+func (s *grpcServer) AbortGame(ctx context.Context, req *pb.AbortGameRequest) (*pb.GameState, error) {
+    hg, err := s.lookup(req.GetGameId())
+    if err != nil {
+        return nil, err
+    }
+    reason := AbortReason(req.GetReason())
+    if reason == "" {
+        reason = AbortAdmin
+    }
+    if err := hg.game.Abort(reason); err != nil {
+        return nil, status.Error(codes.FailedPrecondition, err.Error())
+    }
+    hg.hub.Close(0)
+    return stateToPB(hg.game), nil
+}
+
+func (s *grpcServer) UseItem(ctx context.Context, req *pb.UseItemRequest) (*pb.GameState, error) {
+    hg, err := s.lookup(req.GetGameId())
+    if err != nil {
+        return nil, err
+    }
+    if err := hg.tokens.check(PlayerID(req.GetPlayerId()), req.GetPlayerToken()); err != nil {
+        return nil, status.Error(codes.PermissionDenied, err.Error())
+    }
+    item, err := parseItem(req.GetItem())
+    if err != nil {
+        return nil, status.Error(codes.InvalidArgument, err.Error())
+    }
+    _, err = hg.game.UseItem(PlayerID(req.GetPlayerId()), item)
+    switch {
+    case errors.Is(err, ErrNotYourTurn), errors.Is(err, ErrGameOver), errors.Is(err, ErrNoItem), errors.Is(err, ErrItemInUse):
+        return nil, status.Error(codes.FailedPrecondition, err.Error())
+    case err != nil:
+        return nil, status.Error(codes.InvalidArgument, err.Error())
+    }
+    return stateToPB(hg.game), nil
+}
+
+func (s *grpcServer) LeaveGame(ctx context.Context, req *pb.LeaveGameRequest) (*pb.GameState, error) {
+    hg, err := s.lookup(req.GetGameId())
+    if err != nil {
+        return nil, err
+    }
+    id := PlayerID(req.GetPlayerId())
+    if err := hg.tokens.check(id, req.GetPlayerToken()); err != nil {
+        return nil, status.Error(codes.PermissionDenied, err.Error())
+    }
+    _, err = hg.game.Leave(id)
+    switch {
+    case errors.Is(err, ErrGameOver):
+        return nil, status.Error(codes.FailedPrecondition, err.Error())
+    case err != nil:
+        return nil, status.Error(codes.InvalidArgument, err.Error())
+    }
+    hg.tokens.revoke(id)
+    hg.closeIfOver()
+    return stateToPB(hg.game), nil
+}
+
+func (s *grpcServer) JoinGame(ctx context.Context, req *pb.JoinGameRequest) (*pb.JoinGameResponse, error) {
+    hg, err := s.lookup(req.GetGameId())
+    if err != nil {
+        return nil, err
+    }
+    id, token, err := joinSeat(hg.game, &hg.tokens, strings.TrimSpace(req.GetName()), hg.takeSeats)
+    switch {
+    case errors.Is(err, ErrNoSeat), errors.Is(err, ErrGameOver):
+        return &pb.JoinGameResponse{PlayerId: -1, Spectator: true, State: stateToPB(hg.game)}, nil
+    case errors.Is(err, ErrSeatTaken):
+        return nil, status.Error(codes.AlreadyExists, err.Error())
+    case err != nil:
+        return nil, status.Error(codes.InvalidArgument, err.Error())
+    }
+    return &pb.JoinGameResponse{PlayerId: int32(id), PlayerToken: token, State: stateToPB(hg.game)}, nil
+}
+
+func (s *grpcServer) ListGames(ctx context.Context, req *pb.ListGamesRequest) (*pb.ListGamesResponse, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    out := &pb.ListGamesResponse{}
+    for _, hg := range s.games {
+        out.Games = append(out.Games, stateToPB(hg.game))
+    }
+    sort.Slice(out.Games, func(i, j int) bool { return out.Games[i].GameId < out.Games[j].GameId })
+    return out, nil
+}
+
+func (s *grpcServer) GetState(ctx context.Context, req *pb.GetStateRequest) (*pb.GameState, error) {
+    hg, err := s.lookup(req.GetGameId())
+    if err != nil {
+        return nil, err
+    }
+    return stateToPB(hg.game), nil
+}
+
+func (s *grpcServer) StreamEvents(req *pb.StreamEventsRequest, stream pb.Snakes_StreamEventsServer) error {
+    hg, err := s.lookup(req.GetGameId())
+    if err != nil {
+        return err
+    }
+    if req.GetPlayerToken() != "" {
+        id := PlayerID(req.GetPlayerId())
+        if err := hg.tokens.check(id, req.GetPlayerToken()); err != nil {
+            return status.Error(codes.PermissionDenied, err.Error())
+        }
+        defer hg.presence.attach(id)()
+    }
+    defer serverMetrics.connected()()
+    past, ch, cancel := hg.hub.Subscribe()
+    defer cancel()
+    seen := int(req.GetSince())
+    if seen > 0 {
+        if err := stream.Send(&pb.Event{Kind: "snapshot", Snapshot: stateToPB(hg.game)}); err != nil {
+            return err
+        }
+    }
+    n := 0
+    send := func(ev Event) error {
+        if n++; n <= seen {
+            return nil
+        }
+        return stream.Send(eventToPB(ev))
+    }
+    for _, ev := range past {
+        if err := send(ev); err != nil {
+            return err
+        }
+    }
+    for {
+        select {
+        case ev, ok := <-ch:
+            if !ok {
+                if _, ongoing := hg.game.Outcome().(Ongoing); ongoing && s.hosts(req.GetGameId(), hg) {
+                    // cut off for falling behind: tell the client to
+                    // come back with since, rather than that it's over
+                    return status.Errorf(codes.Unavailable, "stream fell %d events behind; reconnect with since", spectatorBuffer)
+                }
+                return nil
+            }
+            if err := send(ev); err != nil {
+                return err
+            }
+        case <-stream.Context().Done():
+            return stream.Context().Err()
+        }
+    }
+}
+
+func stateToPB(g *Game) *pb.GameState {
+    gs := g.State()
+    out := &pb.GameState{
+        GameId:        g.ID(),
+        Board:         boardToPB(gs.Board),
+        CurrentPlayer: int32(gs.CurrentPlayerIndex),
+        Turns:         int32(g.Turns()),
+        StateHash:     stateHash(gs),
+        Status:        string(statusOf(g)),
+    }
+    for i, p := range gs.Players {
+        pp := &pb.Player{Id: int32(i), Name: p.Name, Position: int32(p.Position.Index), Left: p.Left}
+        for _, it := range p.Items {
+            pp.Items = append(pp.Items, string(it))
+        }
+        for _, sq := range p.Tokens {
+            pp.Tokens = append(pp.Tokens, int32(sq.Index))
+        }
+        out.Players = append(out.Players, pp)
+    }
+    switch o := g.Outcome().(type) {
+    case Win:
+        out.Winner = o.Winner.Name
+    case Abandoned:
+        out.AbortReason = string(o.Reason)
+    }
+    return out
+}
+
+func boardToPB(b Board) *pb.Board {
+    out := &pb.Board{FinalSquare: int32(b.FinalSquare.Index)}
+    for _, s := range b.Snakes() {
+        out.Snakes = append(out.Snakes, &pb.Jump{From: int32(s.From.Index), To: int32(s.To.Index)})
+    }
+    for _, l := range b.Ladders() {
+        out.Ladders = append(out.Ladders, &pb.Jump{From: int32(l.From.Index), To: int32(l.To.Index)})
+    }
+    return out
+}
+
+func eventToPB(ev Event) *pb.Event {
+    return &pb.Event{
+        Kind:          string(ev.Kind),
+        TimeUnixNanos: ev.Time.UnixNano(),
+        Turn:          int32(ev.Turn),
+        Player:        ev.Player,
+        Players:       ev.Players,
+        Roll:          int32(ev.Roll),
+        From:          int32(ev.From),
+        To:            int32(ev.To),
+        Reason:        ev.Reason,
+        Other:         ev.Other,
+        Item:          ev.Item,
+        Token:         int32(ev.Token),
+        StateHash:     ev.Hash,
+    }
+}
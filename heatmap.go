@@ -0,0 +1,128 @@
+package main
+
+import (
+    "encoding/csv"
+    "fmt"
+    "io"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+// SquareHeat is how often simulated games landed on each square of a
+// board. A roll counts where it lands, before any snake, ladder or other
+// square sends the token on, so a snake's head counts every bite.
+type SquareHeat struct {
+    Games    int
+    Landings []int // indexed by square; 0 is unused
+}
+
+// PerGame is how many times a game lands on sq, on average
+func (h SquareHeat) PerGame(sq int) float64 {
+    if h.Games == 0 {
+        return 0
+    }
+    return float64(h.Landings[sq]) / float64(h.Games)
+}
+
+// simulateHeat plays games lone-token games on b, game i rolling dice
+// seeded with seed+i, and counts where their rolls land. A game stops
+// after maxTurns rolls if it hasn't finished.
+func simulateHeat(b Board, games int, seed int64, dice string, maxTurns int) (SquareHeat, error) {
+    h := SquareHeat{Games: games, Landings: make([]int, b.FinalSquare.Index+1)}
+    for i := 0; i < games; i++ {
+        r, err := ParseRoller(dice, seed+int64(i))
+        if err != nil {
+            return h, err
+        }
+        gs := newGameState(b, []string{"solo"})
+        gs.RandState = uint64(seed + int64(i))
+        for turn := 0; turn < maxTurns && gs.Players[0].Position != b.FinalSquare; turn++ {
+            dr := r.Roll()
+            h.Landings[playerLanding(gs, gs.Players[0], dr).Index]++
+            stepInPlace(&gs, dr)
+        }
+    }
+    return h, nil
+}
+
+// heatColors shade heatmap cells from the coldest to the hottest
+var heatColors = []string{"44", "46", "42", "43", "41"}
+
+// renderHeat draws h on b's grid, each cell giving its square, its mark
+// and how often a game lands there, shaded by that if color is set
+func renderHeat(out io.Writer, b Board, h SquareHeat, color bool) {
+    g := GridFor(b)
+    hottest := 0
+    for _, n := range h.Landings[1:] {
+        hottest = max(hottest, n)
+    }
+    for row := 0; row < g.Rows(); row++ {
+        var line strings.Builder
+        for col := 0; col < g.Width; col++ {
+            if col > 0 {
+                line.WriteByte(' ')
+            }
+            sq, ok := g.Square(row, col)
+            if !ok {
+                line.WriteString(strings.Repeat(" ", 10))
+                continue
+            }
+            cell := fmt.Sprintf("%3d%s %5.2f", sq, classicTheme.mark(b.Square(sq)), h.PerGame(sq))
+            if color && hottest > 0 {
+                shade := heatColors[h.Landings[sq]*(len(heatColors)-1)/hottest]
+                cell = "\x1b[30;" + shade + "m" + cell + ansiReset
+            }
+            line.WriteString(cell)
+        }
+        fmt.Fprintln(out, strings.TrimRight(line.String(), " "))
+    }
+}
+
+// reportJumps lists b's snakes and ladders by how often games land on
+// them, busiest first: the ones that decide games, and the ones that
+// hardly ever come into play
+func reportJumps(out io.Writer, b Board, h SquareHeat) {
+    var jumps []int
+    for sq := 1; sq < b.FinalSquare.Index; sq++ {
+        switch b.Square(sq).(type) {
+        case Snake, Ladder:
+            jumps = append(jumps, sq)
+        }
+    }
+    sort.SliceStable(jumps, func(i, j int) bool { return h.Landings[jumps[i]] > h.Landings[jumps[j]] })
+    for _, sq := range jumps {
+        kind, to := heatKind(b.Square(sq))
+        fmt.Fprintf(out, "%-6s %3d to %3d  %5.2f per game\n", kind, sq, to, h.PerGame(sq))
+    }
+}
+
+// writeHeatCSV writes h as one row per square: what is there, where it
+// leads (0 for nowhere), its landings and its landings per game
+func writeHeatCSV(w io.Writer, b Board, h SquareHeat) error {
+    cw := csv.NewWriter(w)
+    cw.Write([]string{"square", "kind", "to", "landings", "per_game"})
+    for sq := 1; sq <= b.FinalSquare.Index; sq++ {
+        kind, to := heatKind(b.Square(sq))
+        cw.Write([]string{strconv.Itoa(sq), kind, strconv.Itoa(to), strconv.Itoa(h.Landings[sq]), strconv.FormatFloat(h.PerGame(sq), 'f', 4, 64)})
+    }
+    cw.Flush()
+    return cw.Error()
+}
+
+// heatKind names what sq is and where it sends a token, if anywhere
+func heatKind(sq Square) (string, int) {
+    switch s := sq.(type) {
+    case Snake:
+        return "snake", s.To.Index
+    case Ladder:
+        return "ladder", s.To.Index
+    case ItemSquare:
+        return "item", 0
+    case ScriptSquare:
+        return "script", 0
+    case Normal:
+        return "normal", 0
+    }
+    return specialKind(sq), 0
+}
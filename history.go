@@ -0,0 +1,204 @@
+package main
+
+import (
+    "database/sql"
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "strings"
+    "time"
+)
+
+// historyDriver is the database/sql driver for the history database. It is
+// empty unless a driver is compiled in (go build -tags sqlite).
+var historyDriver = ""
+
+var errNoHistory = errors.New("built without SQLite support; rebuild with -tags sqlite")
+
+const historySchema = `
+CREATE TABLE IF NOT EXISTS games (
+    id        INTEGER PRIMARY KEY,
+    played_at TEXT NOT NULL,
+    players   TEXT NOT NULL,
+    winner    TEXT,
+    turns     INTEGER NOT NULL
+);
+CREATE VIRTUAL TABLE IF NOT EXISTS transcripts USING fts5(game_id UNINDEXED, body);
+`
+
+func defaultHistoryPath() string {
+    path, err := appPath("history.db")
+    if err != nil {
+        return "history.db"
+    }
+    return path
+}
+
+func openHistory(path string) (*sql.DB, error) {
+    if historyDriver == "" {
+        return nil, errNoHistory
+    }
+    if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+        return nil, err
+    }
+    db, err := sql.Open(historyDriver, path)
+    if err != nil {
+        return nil, err
+    }
+    // WAL lets one instance read while another writes; the busy timeout
+    // makes a second writer wait rather than fail
+    if _, err := db.Exec(`PRAGMA journal_mode = WAL; PRAGMA busy_timeout = 5000;`); err != nil {
+        db.Close()
+        return nil, err
+    }
+    if _, err := db.Exec(historySchema + resultsSchema); err != nil {
+        db.Close()
+        return nil, fmt.Errorf("history schema: %w", err)
+    }
+    if err := addColumnIfMissing(db, "results", "abort_reason", "TEXT"); err != nil {
+        db.Close()
+        return nil, fmt.Errorf("history schema: %w", err)
+    }
+    return db, nil
+}
+
+// addColumnIfMissing upgrades databases created before a column existed
+func addColumnIfMissing(db *sql.DB, table, column, decl string) error {
+    var n int
+    err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&n)
+    if err != nil || n > 0 {
+        return err
+    }
+    _, err = db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, decl))
+    return err
+}
+
+// transcriptRecorder is an Observer that collects a game's events and
+// stores the transcript, indexed for full-text search, once someone wins
+type transcriptRecorder struct {
+    db      *sql.DB
+    started time.Time
+    players []string
+    lines   []string
+    err     error
+}
+
+func newTranscriptRecorder(db *sql.DB) *transcriptRecorder {
+    return &transcriptRecorder{db: db}
+}
+
+func (t *transcriptRecorder) OnEvent(ev Event) {
+    if ev.Kind == EventStart {
+        t.started, t.players, t.lines = ev.Time, ev.Players, nil
+    }
+    t.lines = append(t.lines, fmt.Sprintf("turn %d: %s", ev.Turn, describeEvent(ev)))
+    if ev.Kind == EventWin || ev.Kind == EventDraw || ev.Kind == EventAbort {
+        t.err = t.store(ev.Player, ev.Turn)
+    }
+}
+
+// Err reports the last failure to store a transcript
+func (t *transcriptRecorder) Err() error {
+    return t.err
+}
+
+func (t *transcriptRecorder) store(winner string, turns int) error {
+    tx, err := t.db.Begin()
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+    res, err := tx.Exec(`INSERT INTO games (played_at, players, winner, turns) VALUES (?, ?, ?, ?)`,
+        t.started.UTC().Format(time.RFC3339), strings.Join(t.players, ", "), sql.NullString{String: winner, Valid: winner != ""}, turns)
+    if err != nil {
+        return err
+    }
+    id, err := res.LastInsertId()
+    if err != nil {
+        return err
+    }
+    if _, err := tx.Exec(`INSERT INTO transcripts (game_id, body) VALUES (?, ?)`, id, strings.Join(t.lines, "\n")); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+// HistoryHit is one game matching a transcript search
+type HistoryHit struct {
+    GameID   int64
+    PlayedAt string
+    Players  string
+    Winner   string
+    Snippet  string
+}
+
+func searchHistory(db *sql.DB, query string, limit int) ([]HistoryHit, error) {
+    rows, err := db.Query(`
+        SELECT g.id, g.played_at, g.players, COALESCE(g.winner, ''),
+               snippet(transcripts, 1, '[', ']', '...', 12)
+        FROM transcripts JOIN games g ON g.id = transcripts.game_id
+        WHERE transcripts MATCH ?
+        ORDER BY rank LIMIT ?`, ftsQuery(query), limit)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    var hits []HistoryHit
+    for rows.Next() {
+        var h HistoryHit
+        if err := rows.Scan(&h.GameID, &h.PlayedAt, &h.Players, &h.Winner, &h.Snippet); err != nil {
+            return nil, err
+        }
+        hits = append(hits, h)
+    }
+    return hits, rows.Err()
+}
+
+// ftsQuery quotes each word so user input like "98" or "snake->" is taken
+// literally rather than as FTS5 query syntax
+func ftsQuery(q string) string {
+    words := strings.Fields(q)
+    for i, w := range words {
+        words[i] = `"` + strings.ReplaceAll(w, `"`, `""`) + `"`
+    }
+    return strings.Join(words, " ")
+}
+
+// runHistory implements `history search <words>`
+func runHistory(args []string, dbPath string, msgs Catalog, out io.Writer) error {
+    fs := flag.NewFlagSet("history", flag.ContinueOnError)
+    limit := fs.Int("limit", 20, "maximum number of games to list")
+    if len(args) == 0 || args[0] != "search" {
+        return errors.New(msgs.T(MsgHistoryUsage))
+    }
+    if err := fs.Parse(args[1:]); err != nil {
+        return err
+    }
+    if fs.NArg() == 0 {
+        return errors.New(msgs.T(MsgHistoryUsage))
+    }
+    db, err := openHistory(dbPath)
+    if err != nil {
+        return err
+    }
+    defer db.Close()
+    hits, err := searchHistory(db, strings.Join(fs.Args(), " "), *limit)
+    if err != nil {
+        return err
+    }
+    if len(hits) == 0 {
+        fmt.Fprintln(out, msgs.T(MsgHistoryNone))
+    }
+    for _, h := range hits {
+        winner := h.Winner
+        if winner == "" {
+            winner = msgs.T(MsgHistoryAbandoned)
+        }
+        fmt.Fprintln(out, msgs.T(MsgHistoryHit, h.GameID, h.PlayedAt, h.Players, winner,
+            strings.ReplaceAll(h.Snippet, "\n", " | ")))
+    }
+    return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "os"
+    "os/exec"
+    "runtime"
+    "time"
+)
+
+// GameResult is the end-of-game summary handed to result hooks
+type GameResult struct {
+    GameID      string         `json:"game_id"`
+    Outcome     string         `json:"outcome"` // OutcomeWin, OutcomeDraw, OutcomeAbandoned or OutcomePaused
+    Winner      string         `json:"winner,omitempty"`
+    Team        string         `json:"team,omitempty"` // winning team in team games
+    AbortReason AbortReason    `json:"abort_reason,omitempty"`
+    Players   []PlayerResult `json:"players"`
+    Turns     int            `json:"turns"`
+    StartedAt time.Time      `json:"started_at"`
+    EndedAt   time.Time      `json:"ended_at"`
+}
+
+type PlayerResult struct {
+    Name     string `json:"name"`
+    Position int    `json:"position"`
+    Team     string `json:"team,omitempty"`
+}
+
+const (
+    OutcomeWin       = "win"
+    OutcomeDraw      = "draw"
+    OutcomeAbandoned = "abandoned"
+    OutcomePaused    = "paused"
+)
+
+// newGameResult summarizes a finished game; out must not be Ongoing
+func newGameResult(id string, gs GameState, out Outcome, turns int, started, ended time.Time) GameResult {
+    res := GameResult{
+        GameID:    id,
+        Turns:     turns,
+        StartedAt: started,
+        EndedAt:   ended,
+    }
+    switch o := out.(type) {
+    case Win:
+        res.Outcome, res.Winner, res.Team = OutcomeWin, o.Winner.Name, o.Team
+    case Draw:
+        res.Outcome = OutcomeDraw
+    case Abandoned:
+        res.Outcome, res.AbortReason = OutcomeAbandoned, o.Reason
+    case Paused:
+        res.Outcome = OutcomePaused
+    }
+    for _, p := range gs.Players {
+        res.Players = append(res.Players, PlayerResult{p.Name, p.Position.Index, p.Team})
+    }
+    return res
+}
+
+const hookTimeout = 30 * time.Second
+
+// runResultHook runs command through the shell with the result as JSON on
+// its stdin. The hook's own output goes to stderr so it can't be mistaken
+// for game output.
+func runResultHook(command string, res GameResult) error {
+    data, err := json.Marshal(res)
+    if err != nil {
+        return err
+    }
+    ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+    defer cancel()
+
+    var cmd *exec.Cmd
+    if runtime.GOOS == "windows" {
+        cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+    } else {
+        cmd = exec.CommandContext(ctx, "sh", "-c", command)
+    }
+    cmd.Stdin = bytes.NewReader(data)
+    cmd.Stdout = os.Stderr
+    cmd.Stderr = os.Stderr
+    if err := cmd.Run(); err != nil {
+        return fmt.Errorf("result hook %q: %w", command, err)
+    }
+    return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+    "context"
+    "sync"
+    "time"
+)
+
+// GameStatus is where a game hosted by a server is in its life. Servers
+// collect games, finished or not, once they have been idle for their
+// idle timeout.
+type GameStatus string
+
+const (
+    GameCreated    GameStatus = "created" // nobody has moved yet
+    GameInProgress GameStatus = "in_progress"
+    GameFinished   GameStatus = "finished" // won or abandoned
+)
+
+// defaultIdleTimeout is how long servers keep a game nobody is playing
+const defaultIdleTimeout = 30 * time.Minute
+
+func statusOf(g *Game) GameStatus {
+    if _, ongoing := g.Outcome().(Ongoing); !ongoing {
+        return GameFinished
+    }
+    if g.Turns() == 0 {
+        return GameCreated
+    }
+    return GameInProgress
+}
+
+// activity is an Observer noting when a game last had an event, so its
+// server can tell how long it has been idle
+type activity struct {
+    mu   sync.Mutex
+    last time.Time
+}
+
+func newActivity(now time.Time) *activity {
+    return &activity{last: now}
+}
+
+func (a *activity) OnEvent(ev Event) {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    if ev.Time.After(a.last) {
+        a.last = ev.Time
+    }
+}
+
+// idle is how long it has been, at now, since the last activity
+func (a *activity) idle(now time.Time) time.Duration {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    return now.Sub(a.last)
+}
+
+// sweepIdle calls sweep every so often, as the time of the sweep, until
+// ctx is done, so a server can collect games idle longer than idle
+func sweepIdle(ctx context.Context, clock Clock, idle time.Duration, sweep func(now time.Time)) {
+    every := min(idle, time.Minute)
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-clock.After(every):
+            sweep(clock.Now())
+        }
+    }
+}
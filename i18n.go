@@ -0,0 +1,677 @@
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+    "path"
+    "sort"
+    "strings"
+    "sync"
+)
+
+// MsgKey names a user-facing message in the catalog
+type MsgKey string
+
+const (
+    MsgTurnPrompt   MsgKey = "turn_prompt"
+    MsgTimeUp       MsgKey = "time_up"
+    MsgCountdown    MsgKey = "countdown"
+    MsgRolling      MsgKey = "rolling"
+    MsgRolled       MsgKey = "rolled"
+    MsgMovesTo      MsgKey = "moves_to"
+    MsgWins         MsgKey = "wins"
+    MsgWarnLoadPref MsgKey = "warn_load_prefs"
+    MsgWarnSavePref MsgKey = "warn_save_prefs"
+    MsgWarnHook     MsgKey = "warn_hook"
+    MsgBookmarkUsage  MsgKey = "bookmark_usage"
+    MsgRestoreUsage   MsgKey = "restore_usage"
+    MsgBookmarkSaved  MsgKey = "bookmark_saved"
+    MsgBookmarkFailed MsgKey = "bookmark_failed"
+    MsgNoBookmark     MsgKey = "no_bookmark"
+    MsgRestored       MsgKey = "restored"
+    MsgBookmarkEntry  MsgKey = "bookmark_entry"
+    MsgUnknownCommand MsgKey = "unknown_command"
+    MsgCancelled      MsgKey = "cancelled"
+    MsgWarnHistory    MsgKey = "warn_history"
+    MsgWarnRatings    MsgKey = "warn_ratings"
+    MsgAwardsHeader     MsgKey = "awards_header"
+    MsgAwardLuckiest    MsgKey = "award_luckiest"
+    MsgAwardSnakeMagnet MsgKey = "award_snake_magnet"
+    MsgAwardComeback    MsgKey = "award_comeback"
+    MsgWarnCard         MsgKey = "warn_card"
+    MsgTotalTurns       MsgKey = "total_turns"
+    MsgCoachFall        MsgKey = "coach_fall"
+    MsgCoachLongShot    MsgKey = "coach_long_shot"
+    MsgQuit             MsgKey = "quit"
+    MsgSkipTurn         MsgKey = "skip_turn"
+    MsgExtraRoll        MsgKey = "extra_roll"
+    MsgTeleported       MsgKey = "teleported"
+    MsgSwapped          MsgKey = "swapped"
+    MsgSkipped          MsgKey = "skipped"
+    MsgItemFound        MsgKey = "item_found"
+    MsgShield           MsgKey = "shield"
+    MsgItems            MsgKey = "items"
+    MsgNoItems          MsgKey = "no_items"
+    MsgUseUsage         MsgKey = "use_usage"
+    MsgItemUsed         MsgKey = "item_used"
+    MsgItemFailed       MsgKey = "item_failed"
+    MsgTeamWins         MsgKey = "team_wins"
+    MsgCaptured         MsgKey = "captured"
+    MsgChooseToken      MsgKey = "choose_token"
+    MsgTokenDefault     MsgKey = "token_default"
+    MsgTokenMovesTo     MsgKey = "token_moves_to"
+    MsgMatchScore       MsgKey = "match_score"
+    MsgMatchWon         MsgKey = "match_won"
+    MsgNextGame         MsgKey = "next_game"
+    MsgPaused           MsgKey = "paused"
+    MsgPauseFailed      MsgKey = "pause_failed"
+    MsgResumed          MsgKey = "resumed"
+    MsgWaitingRemote    MsgKey = "waiting_remote"
+    MsgRemoteJoined     MsgKey = "remote_joined"
+    MsgPickDie          MsgKey = "pick_die"
+    MsgDieTaken         MsgKey = "die_taken"
+    MsgDieDefault       MsgKey = "die_default"
+    MsgYourMove         MsgKey = "your_move"
+    MsgBadMove          MsgKey = "bad_move"
+    MsgDraw             MsgKey = "draw"
+    MsgTurnPromptKey    MsgKey = "turn_prompt_key"
+    MsgSetupCount       MsgKey = "setup_count"
+    MsgSetupBadCount    MsgKey = "setup_bad_count"
+    MsgSetupName        MsgKey = "setup_name"
+    MsgSetupBadName     MsgKey = "setup_bad_name"
+    MsgSetupNameTaken   MsgKey = "setup_name_taken"
+    MsgSetupBot         MsgKey = "setup_bot"
+    MsgSetupBadBot      MsgKey = "setup_bad_bot"
+    MsgStats            MsgKey = "stats"
+    MsgSummaryHeader    MsgKey = "summary_header"
+    MsgSummaryPlayer    MsgKey = "summary_player"
+    MsgSummarySetback   MsgKey = "summary_setback"
+    MsgSummaryCaptured  MsgKey = "summary_captured"
+    MsgSummaryLadder    MsgKey = "summary_ladder"
+    MsgWarnSummary      MsgKey = "warn_summary"
+    MsgWarnTranscript   MsgKey = "warn_transcript"
+    MsgDaily            MsgKey = "daily"
+    MsgOffBoard         MsgKey = "off_board"
+    MsgThreeSixes       MsgKey = "three_sixes"
+    MsgTableOpen        MsgKey = "table_open"
+    MsgTableWaiting     MsgKey = "table_waiting"
+    MsgChat             MsgKey = "chat"
+    MsgChatHint         MsgKey = "chat_hint"
+    MsgClocks           MsgKey = "clocks"
+    MsgFlagFell         MsgKey = "flag_fell"
+    MsgOdds             MsgKey = "odds"
+    MsgCrashFound       MsgKey = "crash_found"
+    MsgWarnCrashSave    MsgKey = "warn_crash_save"
+    MsgWarnCrashLoad    MsgKey = "warn_crash_load"
+    MsgScript           MsgKey = "script"
+    MsgScriptOther      MsgKey = "script_other"
+    MsgAchievement      MsgKey = "achievement"
+    MsgWarnProfiles     MsgKey = "warn_profiles"
+    MsgRollOff          MsgKey = "roll_off"
+    MsgRollOffRoll      MsgKey = "roll_off_roll"
+    MsgRollOffTie       MsgKey = "roll_off_tie"
+    MsgPlayOrder        MsgKey = "play_order"
+    MsgRollHistory      MsgKey = "roll_history"
+    MsgNoRolls          MsgKey = "no_rolls"
+
+    MsgEditorHelp       MsgKey = "editor_help"
+    MsgEditorNew        MsgKey = "editor_new"
+    MsgEditorPrompt     MsgKey = "editor_prompt"
+    MsgEditorSquaresNaN MsgKey = "editor_squares_nan"
+    MsgEditorSquareNaN  MsgKey = "editor_square_nan"
+    MsgEditorSizeNaN    MsgKey = "editor_size_nan"
+    MsgEditorNotSaved   MsgKey = "editor_not_saved"
+    MsgEditorSaved      MsgKey = "editor_saved"
+    MsgEditorDiscard    MsgKey = "editor_discard"
+    MsgEditorProblems   MsgKey = "editor_problems"
+    MsgEditorWarning    MsgKey = "editor_warning"
+    MsgDebugHelp        MsgKey = "debug_help"
+    MsgDebugPrompt      MsgKey = "debug_prompt"
+    MsgDebugStart       MsgKey = "debug_start"
+    MsgDebugStep        MsgKey = "debug_step"
+    MsgDebugTokens      MsgKey = "debug_tokens"
+    MsgDebugSkips       MsgKey = "debug_skips"
+    MsgDebugItems       MsgKey = "debug_items"
+    MsgDebugWon         MsgKey = "debug_won"
+    MsgDebugDrawn       MsgKey = "debug_drawn"
+    MsgDebugOver        MsgKey = "debug_over"
+    MsgDebugNoRecord    MsgKey = "debug_no_record"
+    MsgDebugNoTurn      MsgKey = "debug_no_turn"
+    MsgDebugWhere       MsgKey = "debug_where"
+    MsgDebugNoPlayer    MsgKey = "debug_no_player"
+    MsgDebugLineMain    MsgKey = "debug_line_main"
+    MsgDebugLineBranch  MsgKey = "debug_line_branch"
+    MsgDebugTurnUsage   MsgKey = "debug_turn_usage"
+    MsgDebugWhereUsage  MsgKey = "debug_where_usage"
+    MsgDebugRollUsage   MsgKey = "debug_roll_usage"
+    MsgDebugLineUsage   MsgKey = "debug_line_usage"
+    MsgDebugUnknown     MsgKey = "debug_unknown"
+    MsgDebugUsage       MsgKey = "debug_usage"
+    MsgHistoryUsage     MsgKey = "history_usage"
+    MsgHistoryNone      MsgKey = "history_none"
+    MsgHistoryAbandoned MsgKey = "history_abandoned"
+    MsgHistoryHit       MsgKey = "history_hit"
+
+    MsgStatsNone       MsgKey = "stats_none"
+    MsgStatsFinished   MsgKey = "stats_finished"
+    MsgStatsAbandoned  MsgKey = "stats_abandoned"
+    MsgStatsColumns    MsgKey = "stats_columns"
+    MsgStatsHeadToHead MsgKey = "stats_head_to_head"
+    MsgStatsMeeting    MsgKey = "stats_meeting"
+
+    MsgNoSaves        MsgKey = "no_saves"
+    MsgSaveUnreadable MsgKey = "save_unreadable"
+    MsgSaveEntry      MsgKey = "save_entry"
+
+    MsgBoardSquares  MsgKey = "board_squares"
+    MsgBoardSnakes   MsgKey = "board_snakes"
+    MsgBoardLadders  MsgKey = "board_ladders"
+    MsgBoardSpecials MsgKey = "board_specials"
+    MsgBoardJump     MsgKey = "board_jump"
+    MsgBoardOn       MsgKey = "board_on"
+    MsgBoardAt       MsgKey = "board_at"
+    MsgBoardOff      MsgKey = "board_off"
+    MsgBoardAnd      MsgKey = "board_and"
+    MsgBoardPlayer   MsgKey = "board_player"
+    MsgBoardClock    MsgKey = "board_clock"
+
+    MsgOnOffer MsgKey = "on_offer"
+
+    MsgNoProfiles          MsgKey = "no_profiles"
+    MsgProfileColumns      MsgKey = "profile_columns"
+    MsgProfileRecord       MsgKey = "profile_record"
+    MsgProfileRating       MsgKey = "profile_rating"
+    MsgProfileLook         MsgKey = "profile_look"
+    MsgProfileInitial      MsgKey = "profile_initial"
+    MsgProfileSeatColor    MsgKey = "profile_seat_color"
+    MsgProfileStats        MsgKey = "profile_stats"
+    MsgProfileAwards       MsgKey = "profile_awards"
+    MsgProfileAchievements MsgKey = "profile_achievements"
+)
+
+// catalogs maps language -> key -> fmt format string. English is the
+// reference; other languages may leave keys out and fall back to it.
+var catalogs = map[string]map[MsgKey]string{
+    "en": {
+        MsgTurnPrompt:   "%s's turn. Press Enter to roll...",
+        MsgTimeUp:       "Time's up, rolling automatically.",
+        MsgCountdown:    "Auto-roll in %2ds ",
+        MsgRolling:      "Rolling... %d",
+        MsgRolled:       "Rolled: %d",
+        MsgMovesTo:      "%s moves to %d",
+        MsgWins:         "%s wins the game!",
+        MsgWarnLoadPref: "warning: could not load preferences: %v",
+        MsgWarnSavePref: "warning: could not save preferences: %v",
+        MsgWarnHook:     "warning: %v",
+        MsgBookmarkUsage:  "usage: bookmark <name>",
+        MsgRestoreUsage:   "usage: restore <name>",
+        MsgBookmarkSaved:  "Bookmarked as %q.",
+        MsgBookmarkFailed: "Bookmark error: %v",
+        MsgNoBookmark:     "No bookmark named %q (try 'bookmarks').",
+        MsgRestored:       "Restored %q, back to turn %d.",
+        MsgBookmarkEntry:  "  %-20s turn %d",
+        MsgUnknownCommand: "Unknown command %q. Press Enter to roll.",
+        MsgCancelled:      "Game stopped: %v",
+        MsgWarnHistory:    "warning: game history not recorded: %v",
+        MsgWarnRatings:    "warning: ratings not updated: %v",
+        MsgAwardsHeader:     "Awards",
+        MsgAwardLuckiest:    "Luckiest Player: %s (%+.1f pips above the average roll)",
+        MsgAwardSnakeMagnet: "Snake Magnet: %s (snakes hit: %d)",
+        MsgAwardComeback:    "Comeback of the Game: %s (was %d squares behind)",
+        MsgWarnCard:         "warning: summary card not written: %v",
+        MsgTotalTurns:       "%d turns",
+        MsgCoachFall:        "Coach: sliding from %d to %d hurts! From square %d, %.0f%% of rolls land on a snake.",
+        MsgCoachLongShot:    "Coach: %s won despite only a %.1f%% chance of winning at turn %d.",
+        MsgQuit:             "Game abandoned.",
+        MsgSkipTurn:         "%s will miss their next turn!",
+        MsgExtraRoll:        "%s gets another roll!",
+        MsgTeleported:       "%s is teleported from %d to %d!",
+        MsgSwapped:          "%s swaps places with %s!",
+        MsgSkipped:          "%s misses this turn.",
+        MsgItemFound:        "%s picks up %s! (type 'use %[2]s' before a roll)",
+        MsgShield:           "%s's immunity shrugs off the snake on %d!",
+        MsgItems:            "%s's items: %s",
+        MsgNoItems:          "%s has no items.",
+        MsgUseUsage:         "usage: use immunity|boost",
+        MsgItemUsed:         "%s uses %s.",
+        MsgItemFailed:       "Can't use that: %v",
+        MsgTeamWins:         "Team %s wins the game! (%s finished)",
+        MsgCaptured:         "%s captures %s, who goes back to %d!",
+        MsgChooseToken:      "Move which token? %s",
+        MsgTokenDefault:     "Moving token %d.",
+        MsgTokenMovesTo:     "%s moves token %d to %d",
+        MsgMatchScore:       "Match score after %d games: %s (first to %d)",
+        MsgMatchWon:         "%s wins the match!",
+        MsgNextGame:         "=== Game %d ===",
+        MsgPaused:           "Game paused and saved. Run 'resume' to carry on.",
+        MsgPauseFailed:      "Could not save the game: %v",
+        MsgResumed:          "Resuming at turn %d; %s to play.",
+        MsgWaitingRemote:    "Waiting for %s to join at %s...",
+        MsgRemoteJoined:     "%s has joined.",
+        MsgPickDie:          "Rolled %d and %d. Which do you take? (1 or 2)",
+        MsgDieTaken:         "Taking the %d.",
+        MsgDieDefault:       "Taking the first roll, %d.",
+        MsgYourMove:         "%s to move (%s; Enter plays the first):",
+        MsgBadMove:          "Can't play %q: %v",
+        MsgDraw:             "It's a draw.",
+        MsgTurnPromptKey:    "%s's turn. Press any key to roll (b: board, q: quit)...",
+        MsgSetupCount:       "How many players? (2 to %d)",
+        MsgSetupBadCount:    "Please enter a number from 2 to %d.",
+        MsgSetupName:        "Name of player %d:",
+        MsgSetupBadName:     "Names can't be empty or use , : ; or =.",
+        MsgSetupNameTaken:   "%s is already playing; pick another name.",
+        MsgSetupBot:         "Does the computer play %s? (Enter for no, or easy, medium or hard)",
+        MsgSetupBadBot:      "Please answer no, yes, easy, medium or hard.",
+        MsgStats:            "%s: %d rolls (%s), %d snakes, %d ladders, %d squares moved",
+        MsgSummaryHeader:    "Summary (%s)",
+        MsgSummaryPlayer:    "%s finished on %s; rolled %s",
+        MsgSummarySetback:   "Biggest setback: %s, %d -> %d on turn %d",
+        MsgSummaryCaptured:  "Biggest setback: %s, captured by %s, %d -> %d on turn %d",
+        MsgSummaryLadder:    "Luckiest ladder: %s, %d -> %d on turn %d",
+        MsgWarnSummary:      "warning: post-game summary not written: %v",
+        MsgWarnTranscript:   "warning: transcript not written: %v",
+        MsgDaily:            "Daily challenge for %s: everyone playing it gets this board and these dice",
+        MsgOffBoard:         "%s is still off the board; a six brings them on",
+        MsgThreeSixes:       "Three sixes in a row! %s forfeits the roll and is on %d",
+        MsgTableOpen:        "Table %s is open; others sit down at it with: join %s",
+        MsgTableWaiting:     "Waiting for %d more to sit down (type leave to get up)...",
+        MsgChat:             "%s says: %s",
+        MsgChatHint:         "Type say and a message, at any time, to talk to the table.",
+        MsgClocks:           "Time left: %s",
+        MsgFlagFell:         "%s has run out of time and is out of the game!",
+        MsgOdds:             "Chances to win: %s",
+        MsgCrashFound:       "A game was cut off at turn %d (%s; %s to play). Carry on with it? (Enter for yes, n for a new game)",
+        MsgWarnCrashSave:    "warning: the game isn't being saved against crashes: %v",
+        MsgWarnCrashLoad:    "warning: the game a crash cut off can't be resumed: %v",
+        MsgScript:           "%s's square sends them to %d!",
+        MsgScriptOther:      "%s's square sends %s to %d!",
+        MsgAchievement:      "%s earned an achievement: %s",
+        MsgWarnProfiles:     "warning: player profiles: %v",
+        MsgRollOff:          "Rolling for the order of play:",
+        MsgRollOffRoll:      "%s rolls %d",
+        MsgRollOffTie:       "%s tie on %d and roll again",
+        MsgPlayOrder:        "Order of play: %s",
+        MsgRollHistory:      "%s has rolled %s",
+        MsgNoRolls:          "%s hasn't rolled yet",
+
+        MsgEditorHelp:       `commands:
+  snake FROM TO     add a snake (replaces whatever starts on FROM)
+  ladder FROM TO    add a ladder
+  special SQUARE KIND
+                    make SQUARE a skip_turn, extra_roll, teleport or swap square
+  item SQUARE ITEM  put an immunity or boost item on SQUARE
+  script SQUARE EFFECT
+                    give SQUARE a scripted effect, such as: back 2; skip 1
+  remove SQUARE     remove the snake, ladder, special square, item or script on SQUARE
+  size N            change the number of squares
+  name TEXT         set the board's name
+  reset blank|standard
+                    start over from an empty or the standard board
+  show              preview the board
+  save [PATH]       write the board file
+  quit              leave (unsaved changes are lost)`,
+        MsgEditorNew:        "new board %s",
+        MsgEditorPrompt:     "edit> ",
+        MsgEditorSquaresNaN: "squares must be numbers",
+        MsgEditorSquareNaN:  "square must be a number",
+        MsgEditorSizeNaN:    "size must be a number",
+        MsgEditorNotSaved:   "not saved: %v",
+        MsgEditorSaved:      "saved %s",
+        MsgEditorDiscard:    "discarding unsaved changes",
+        MsgEditorProblems:   "problems:",
+        MsgEditorWarning:    "warning: %s",
+        MsgDebugHelp:        `next [N]         step forward, rolling the dice past the end of the line
+back [N]         step back
+turn T           go to turn T
+where NAME [T]   where a player is, now or at turn T
+roll N           branch a new line from here, rolling N instead
+lines            list the lines
+line L           switch to line L
+board            draw the board as it stands
+quit`,
+        MsgDebugPrompt:      "debug> ",
+        MsgDebugStart:       "start",
+        MsgDebugStep:        "line %d, step %d of %d, turn %d: %s",
+        MsgDebugTokens:      "  tokens %v",
+        MsgDebugSkips:       "  skips %d",
+        MsgDebugItems:       "  items %v",
+        MsgDebugWon:         "%s has won",
+        MsgDebugDrawn:       "the game is drawn",
+        MsgDebugOver:        "the game is over",
+        MsgDebugNoRecord:    "no replayable game in the record",
+        MsgDebugNoTurn:      "turn %d isn't on this line (it has turns %d to %d)",
+        MsgDebugWhere:       "%s is on %d after turn %d",
+        MsgDebugNoPlayer:    "no player called %q",
+        MsgDebugLineMain:    "%s %d  the game, to turn %d",
+        MsgDebugLineBranch:  "%s %d  from line %d at turn %d (%s), to turn %d",
+        MsgDebugTurnUsage:   "usage: turn T",
+        MsgDebugWhereUsage:  "usage: where NAME [T]",
+        MsgDebugRollUsage:   "usage: roll N, N from 1 to 6",
+        MsgDebugLineUsage:   "usage: line L, L from 1 to %d",
+        MsgDebugUnknown:     "unknown command %q (try help)",
+        MsgDebugUsage:       "usage: debug [game-record]",
+        MsgHistoryUsage:     "usage: history search [-limit n] <words>",
+        MsgHistoryNone:      "no matching games",
+        MsgHistoryAbandoned: "(abandoned)",
+        MsgHistoryHit:       `#%d  %s  %s  winner: %s
+    %s`,
+
+        MsgStatsNone:       "no recorded games yet",
+        MsgStatsFinished:   "%d games finished, average %.1f rolls, %s",
+        MsgStatsAbandoned:  "%d abandoned:",
+        MsgStatsColumns:    "player                games   wins   win %%",
+        MsgStatsHeadToHead: "head to head",
+        MsgStatsMeeting:    "  %s %d - %d %s  (%d games)",
+
+        MsgNoSaves:        "no saved games",
+        MsgSaveUnreadable: "%s  (unreadable: %v)",
+        MsgSaveEntry:      "%s  turn %d  %s  saved %s",
+
+        MsgBoardSquares:  "The board has %d squares.",
+        MsgBoardSnakes:   "Snakes: %s.",
+        MsgBoardLadders:  "Ladders: %s.",
+        MsgBoardSpecials: "Special squares: %s.",
+        MsgBoardJump:     "%d to %d",
+        MsgBoardOn:       "%s on %d",
+        MsgBoardAt:       "on %d",
+        MsgBoardOff:      "off the board",
+        MsgBoardAnd:      " and ",
+        MsgBoardPlayer:   "%s is %s.",
+        MsgBoardClock:    "%s is %s, with %s on the clock.",
+
+        MsgOnOffer: "on offer: 1) %d  2) %d",
+
+        MsgNoProfiles:          "no player profiles yet",
+        MsgProfileColumns:      "player                games   wins  rating  color          token",
+        MsgProfileRecord:       "%s: %d games, %d wins",
+        MsgProfileRating:       ", rated %.0f",
+        MsgProfileLook:         "plays as %s in %s",
+        MsgProfileInitial:      "their initial",
+        MsgProfileSeatColor:    "their seat's colour",
+        MsgProfileStats:        "%d rolls, %d squares moved, %d snakes, %d ladders",
+        MsgProfileAwards:       "awards: %s",
+        MsgProfileAchievements: "achievements: %s",
+    },
+    "es": {
+        MsgTurnPrompt:   "Turno de %s. Pulsa Enter para tirar...",
+        MsgTimeUp:       "Se acabó el tiempo, tirando automáticamente.",
+        MsgCountdown:    "Tirada automática en %2ds ",
+        MsgRolling:      "Tirando... %d",
+        MsgRolled:       "Sacó: %d",
+        MsgMovesTo:      "%s avanza a la casilla %d",
+        MsgWins:         "¡%s gana la partida!",
+        MsgWarnLoadPref: "aviso: no se pudieron cargar las preferencias: %v",
+        MsgWarnSavePref: "aviso: no se pudieron guardar las preferencias: %v",
+        MsgWarnHook:     "aviso: %v",
+        MsgBookmarkUsage:  "uso: bookmark <nombre>",
+        MsgRestoreUsage:   "uso: restore <nombre>",
+        MsgBookmarkSaved:  "Guardado como %q.",
+        MsgBookmarkFailed: "Error de marcador: %v",
+        MsgNoBookmark:     "No hay marcador llamado %q (prueba 'bookmarks').",
+        MsgRestored:       "Restaurado %q, de vuelta al turno %d.",
+        MsgBookmarkEntry:  "  %-20s turno %d",
+        MsgUnknownCommand: "Comando desconocido %q. Pulsa Enter para tirar.",
+        MsgCancelled:      "Partida detenida: %v",
+        MsgWarnHistory:    "aviso: no se guardó el historial de la partida: %v",
+        MsgWarnRatings:    "aviso: no se actualizaron las clasificaciones: %v",
+        MsgAwardsHeader:     "Premios",
+        MsgAwardLuckiest:    "Jugador con más suerte: %s (%+.1f puntos sobre la tirada media)",
+        MsgAwardSnakeMagnet: "Imán de serpientes: %s (serpientes: %d)",
+        MsgAwardComeback:    "Remontada de la partida: %s (iba %d casillas por detrás)",
+        MsgWarnCard:         "aviso: no se escribió la tarjeta resumen: %v",
+        MsgTotalTurns:       "%d turnos",
+        MsgCoachFall:        "Entrenador: ¡caer de %d a %d duele! Desde la casilla %d, el %.0f%% de las tiradas caen en una serpiente.",
+        MsgCoachLongShot:    "Entrenador: %s ganó con solo un %.1f%% de probabilidad de ganar en el turno %d.",
+        MsgQuit:             "Partida abandonada.",
+        MsgSkipTurn:         "¡%s perderá su próximo turno!",
+        MsgExtraRoll:        "¡%s vuelve a tirar!",
+        MsgTeleported:       "¡%s se teletransporta de %d a %d!",
+        MsgSwapped:          "¡%s intercambia su posición con %s!",
+        MsgSkipped:          "%s pierde este turno.",
+        MsgItemFound:        "¡%s consigue %s! (escribe 'use %[2]s' antes de tirar)",
+        MsgShield:           "¡La inmunidad de %s lo salva de la serpiente en %d!",
+        MsgItems:            "Objetos de %s: %s",
+        MsgNoItems:          "%s no tiene objetos.",
+        MsgUseUsage:         "uso: use immunity|boost",
+        MsgItemUsed:         "%s usa %s.",
+        MsgItemFailed:       "No se puede usar: %v",
+        MsgTeamWins:         "¡El equipo %s gana la partida! (%s llegó a la meta)",
+        MsgCaptured:         "¡%s captura a %s, que vuelve a la casilla %d!",
+        MsgChooseToken:      "¿Qué ficha mueves? %s",
+        MsgTokenDefault:     "Se mueve la ficha %d.",
+        MsgTokenMovesTo:     "%s mueve la ficha %d a %d",
+        MsgMatchScore:       "Marcador tras %d partidas: %s (gana quien llegue a %d)",
+        MsgMatchWon:         "¡%s gana el encuentro!",
+        MsgNextGame:         "=== Partida %d ===",
+        MsgPaused:           "Partida en pausa y guardada. Ejecuta 'resume' para continuar.",
+        MsgPauseFailed:      "No se pudo guardar la partida: %v",
+        MsgResumed:          "Reanudando en el turno %d; le toca a %s.",
+        MsgWaitingRemote:    "Esperando a que %s se una en %s...",
+        MsgRemoteJoined:     "%s se ha unido.",
+        MsgPickDie:          "Sacó %d y %d. ¿Con cuál se queda? (1 o 2)",
+        MsgDieTaken:         "Se queda con el %d.",
+        MsgDieDefault:       "Se queda con la primera tirada, %d.",
+        MsgYourMove:         "Juega %s (%s; Enter juega la primera):",
+        MsgBadMove:          "No se puede jugar %q: %v",
+        MsgDraw:             "Empate.",
+        MsgTurnPromptKey:    "Turno de %s. Pulsa una tecla para tirar (b: tablero, q: salir)...",
+        MsgSetupCount:       "¿Cuántos jugadores? (de 2 a %d)",
+        MsgSetupBadCount:    "Escribe un número de 2 a %d.",
+        MsgSetupName:        "Nombre del jugador %d:",
+        MsgSetupBadName:     "Los nombres no pueden estar vacíos ni llevar , : ; o =.",
+        MsgSetupNameTaken:   "%s ya está jugando; elige otro nombre.",
+        MsgSetupBot:         "¿Juega el ordenador por %s? (Enter para no, o easy, medium o hard)",
+        MsgSetupBadBot:      "Responde no, sí, easy, medium o hard.",
+        MsgStats:            "%s: %d tiradas (%s), %d serpientes, %d escaleras, %d casillas recorridas",
+        MsgSummaryHeader:    "Resumen (%s)",
+        MsgSummaryPlayer:    "%s terminó en %s; sacó %s",
+        MsgSummarySetback:   "Mayor retroceso: %s, %d -> %d en el turno %d",
+        MsgSummaryCaptured:  "Mayor retroceso: %s, capturado por %s, %d -> %d en el turno %d",
+        MsgSummaryLadder:    "Escalera más afortunada: %s, %d -> %d en el turno %d",
+        MsgWarnSummary:      "aviso: no se escribió el resumen de la partida: %v",
+        MsgWarnTranscript:   "aviso: no se escribió la transcripción: %v",
+        MsgDaily:            "Desafío del día %s: todos los que lo jueguen tienen este tablero y estos dados",
+        MsgOffBoard:         "%s sigue fuera del tablero; entra con un seis",
+        MsgThreeSixes:       "¡Tres seises seguidos! %s pierde la tirada y queda en la casilla %d",
+        MsgTableOpen:        "La mesa %s está abierta; los demás se sientan con: join %s",
+        MsgTableWaiting:     "Esperando a que se sienten %d más (escribe leave para levantarte)...",
+        MsgChat:             "%s dice: %s",
+        MsgChatHint:         "Escribe say y un mensaje, cuando quieras, para hablar con la mesa.",
+        MsgClocks:           "Tiempo restante: %s",
+        MsgFlagFell:         "¡A %s se le ha acabado el tiempo y queda fuera de la partida!",
+        MsgOdds:             "Probabilidades de ganar: %s",
+        MsgCrashFound:       "Una partida se cortó en el turno %d (%s; le toca a %s). ¿Continuarla? (Enter para sí, n para una nueva)",
+        MsgWarnCrashSave:    "aviso: la partida no se está guardando contra fallos: %v",
+        MsgWarnCrashLoad:    "aviso: no se puede reanudar la partida que se cortó: %v",
+        MsgScript:           "¡La casilla de %s lo manda a la %d!",
+        MsgScriptOther:      "¡La casilla de %s manda a %s a la %d!",
+        MsgAchievement:      "%s consiguió un logro: %s",
+        MsgWarnProfiles:     "aviso: perfiles de jugador: %v",
+        MsgRollOff:          "Se tira para decidir el orden de juego:",
+        MsgRollOffRoll:      "%s saca %d",
+        MsgRollOffTie:       "%s empatan con %d y vuelven a tirar",
+        MsgPlayOrder:        "Orden de juego: %s",
+        MsgRollHistory:      "%s ha sacado %s",
+        MsgNoRolls:          "%s aún no ha tirado",
+
+        MsgEditorHelp:       `comandos:
+  snake DESDE HASTA añade una serpiente (sustituye lo que empiece en DESDE)
+  ladder DESDE HASTA
+                    añade una escalera
+  special CASILLA TIPO
+                    convierte CASILLA en una casilla skip_turn, extra_roll, teleport o swap
+  item CASILLA OBJETO
+                    pone un objeto immunity o boost en CASILLA
+  script CASILLA EFECTO
+                    da a CASILLA un efecto programado, como: back 2; skip 1
+  remove CASILLA    quita la serpiente, escalera, casilla especial, objeto o efecto de CASILLA
+  size N            cambia el número de casillas
+  name TEXTO        pone nombre al tablero
+  reset blank|standard
+                    empieza de nuevo con un tablero vacío o el estándar
+  show              muestra el tablero
+  save [RUTA]       escribe el archivo del tablero
+  quit              sale (se pierden los cambios sin guardar)`,
+        MsgEditorNew:        "tablero nuevo %s",
+        MsgEditorPrompt:     "editar> ",
+        MsgEditorSquaresNaN: "las casillas deben ser números",
+        MsgEditorSquareNaN:  "la casilla debe ser un número",
+        MsgEditorSizeNaN:    "el tamaño debe ser un número",
+        MsgEditorNotSaved:   "no se guardó: %v",
+        MsgEditorSaved:      "guardado en %s",
+        MsgEditorDiscard:    "se descartan los cambios sin guardar",
+        MsgEditorProblems:   "problemas:",
+        MsgEditorWarning:    "aviso: %s",
+        MsgDebugHelp:        `next [N]         avanza, tirando los dados más allá del final de la línea
+back [N]         retrocede
+turn T           va al turno T
+where NOMBRE [T] dónde está un jugador, ahora o en el turno T
+roll N           abre una línea nueva desde aquí, sacando N en su lugar
+lines            lista las líneas
+line L           cambia a la línea L
+board            dibuja el tablero tal como está
+quit`,
+        MsgDebugPrompt:      "depurar> ",
+        MsgDebugStart:       "inicio",
+        MsgDebugStep:        "línea %d, paso %d de %d, turno %d: %s",
+        MsgDebugTokens:      "  fichas %v",
+        MsgDebugSkips:       "  pierde %d",
+        MsgDebugItems:       "  objetos %v",
+        MsgDebugWon:         "%s ha ganado",
+        MsgDebugDrawn:       "la partida acaba en empate",
+        MsgDebugOver:        "la partida ha terminado",
+        MsgDebugNoRecord:    "no hay ninguna partida reproducible en el registro",
+        MsgDebugNoTurn:      "el turno %d no está en esta línea (tiene los turnos %d a %d)",
+        MsgDebugWhere:       "%s está en la %d tras el turno %d",
+        MsgDebugNoPlayer:    "no hay ningún jugador llamado %q",
+        MsgDebugLineMain:    "%s %d  la partida, hasta el turno %d",
+        MsgDebugLineBranch:  "%s %d  de la línea %d en el turno %d (%s), hasta el turno %d",
+        MsgDebugTurnUsage:   "uso: turn T",
+        MsgDebugWhereUsage:  "uso: where NOMBRE [T]",
+        MsgDebugRollUsage:   "uso: roll N, con N de 1 a 6",
+        MsgDebugLineUsage:   "uso: line L, con L de 1 a %d",
+        MsgDebugUnknown:     "comando desconocido %q (prueba help)",
+        MsgDebugUsage:       "uso: debug [registro-de-partida]",
+        MsgHistoryUsage:     "uso: history search [-limit n] <palabras>",
+        MsgHistoryNone:      "ninguna partida coincide",
+        MsgHistoryAbandoned: "(abandonada)",
+        MsgHistoryHit:       `#%d  %s  %s  ganador: %s
+    %s`,
+
+        MsgStatsNone:       "aún no hay partidas registradas",
+        MsgStatsFinished:   "%d partidas terminadas, %.1f tiradas de media, %s",
+        MsgStatsAbandoned:  "%d abandonadas:",
+        MsgStatsColumns:    "jugador               part.  vict. %% vict.",
+        MsgStatsHeadToHead: "cara a cara",
+        MsgStatsMeeting:    "  %s %d - %d %s  (%d partidas)",
+
+        MsgNoSaves:        "no hay partidas guardadas",
+        MsgSaveUnreadable: "%s  (ilegible: %v)",
+        MsgSaveEntry:      "%s  turno %d  %s  guardada %s",
+
+        MsgBoardSquares:  "El tablero tiene %d casillas.",
+        MsgBoardSnakes:   "Serpientes: %s.",
+        MsgBoardLadders:  "Escaleras: %s.",
+        MsgBoardSpecials: "Casillas especiales: %s.",
+        MsgBoardJump:     "%d a %d",
+        MsgBoardOn:       "%s en la %d",
+        MsgBoardAt:       "en la %d",
+        MsgBoardOff:      "fuera del tablero",
+        MsgBoardAnd:      " y ",
+        MsgBoardPlayer:   "%s está %s.",
+        MsgBoardClock:    "%s está %s, con %s en el reloj.",
+
+        MsgOnOffer: "a elegir: 1) %d  2) %d",
+
+        MsgNoProfiles:          "aún no hay perfiles de jugador",
+        MsgProfileColumns:      "jugador               part.  vict.  puntos  color          ficha",
+        MsgProfileRecord:       "%s: %d partidas, %d victorias",
+        MsgProfileRating:       ", puntuación %.0f",
+        MsgProfileLook:         "juega como %s en %s",
+        MsgProfileInitial:      "su inicial",
+        MsgProfileSeatColor:    "el color de su puesto",
+        MsgProfileStats:        "%d tiradas, %d casillas avanzadas, %d serpientes, %d escaleras",
+        MsgProfileAwards:       "premios: %s",
+        MsgProfileAchievements: "logros: %s",
+    },
+}
+
+// Catalog renders messages in one language
+type Catalog struct {
+    lang string
+}
+
+var loadLocales sync.Once
+
+// loadLocaleAssets merges locales/<lang>.json assets (message key to
+// format) over the built-in catalogs, adding any new languages. Bad files
+// are skipped: a broken translation shouldn't stop the game.
+func loadLocaleAssets() {
+    names, err := assetNames("locales")
+    if err != nil {
+        return
+    }
+    for _, n := range names {
+        lang, ok := strings.CutSuffix(path.Base(n), ".json")
+        if !ok {
+            continue
+        }
+        data, err := readAsset(n)
+        if err != nil {
+            continue
+        }
+        var msgs map[MsgKey]string
+        if json.Unmarshal(data, &msgs) != nil {
+            continue
+        }
+        if catalogs[lang] == nil {
+            catalogs[lang] = map[MsgKey]string{}
+        }
+        for k, v := range msgs {
+            catalogs[lang][k] = v
+        }
+    }
+}
+
+func NewCatalog(lang string) (Catalog, error) {
+    loadLocales.Do(loadLocaleAssets)
+    if _, ok := catalogs[lang]; !ok {
+        return Catalog{}, fmt.Errorf("unsupported language %q (have %s)", lang, strings.Join(Languages(), ", "))
+    }
+    return Catalog{lang}, nil
+}
+
+// Languages lists the available catalogs
+func Languages() []string {
+    loadLocales.Do(loadLocaleAssets)
+    langs := make([]string, 0, len(catalogs))
+    for l := range catalogs {
+        langs = append(langs, l)
+    }
+    sort.Strings(langs)
+    return langs
+}
+
+// T formats the message for key, falling back to English when the
+// catalog has no translation
+func (c Catalog) T(key MsgKey, args ...any) string {
+    format, ok := catalogs[c.lang][key]
+    if !ok {
+        format = catalogs["en"][key]
+    }
+    return fmt.Sprintf(format, args...)
+}
+
+// defaultLang picks a catalog from $LANG (e.g. es_ES.UTF-8), else English
+func defaultLang() string {
+    lang := os.Getenv("LANG")
+    if i := strings.IndexAny(lang, "_.@"); i >= 0 {
+        lang = lang[:i]
+    }
+    loadLocales.Do(loadLocaleAssets)
+    if _, ok := catalogs[lang]; ok {
+        return lang
+    }
+    return "en"
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+    "errors"
+    "flag"
+    "fmt"
+    "html"
+    "image"
+    "image/color"
+    "image/png"
+    "io"
+    "math"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+)
+
+// Board images are drawn in the same units as the printed page (cellUnits
+// per cell) with the same snake and ladder shapes; the PNG is rasterised
+// from those shapes directly rather than from the SVG.
+
+var (
+    imgLight  = color.RGBA{0xff, 0xff, 0xff, 0xff}
+    imgShade  = color.RGBA{0xf4, 0xf4, 0xf4, 0xff}
+    imgInk    = color.RGBA{0x33, 0x33, 0x33, 0xff}
+    imgSnake  = color.RGBA{0x2e, 0x7d, 0x32, 0xff}
+    imgLadder = color.RGBA{0x8d, 0x6e, 0x63, 0xff}
+)
+
+// playerColors are the counters' colours, by seat
+var playerColors = []color.RGBA{
+    {0xd3, 0x2f, 0x2f, 0xff}, // red
+    {0x19, 0x76, 0xd2, 0xff}, // blue
+    {0xfb, 0xc0, 0x2d, 0xff}, // yellow
+    {0x7b, 0x1f, 0xa2, 0xff}, // purple
+    {0xf5, 0x7c, 0x00, 0xff}, // orange
+    {0x00, 0x97, 0xa7, 0xff}, // teal
+}
+
+// specialTint is the background of a special square, if it has one
+func specialTint(sq Square) (color.RGBA, bool) {
+    switch sq.(type) {
+    case SkipTurn:
+        return color.RGBA{0xff, 0xcd, 0xd2, 0xff}, true
+    case ExtraRoll:
+        return color.RGBA{0xc8, 0xe6, 0xc9, 0xff}, true
+    case Teleport:
+        return color.RGBA{0xd1, 0xc4, 0xe9, 0xff}, true
+    case Swap:
+        return color.RGBA{0xff, 0xe0, 0xb2, 0xff}, true
+    case ItemSquare:
+        return color.RGBA{0xff, 0xf9, 0xc4, 0xff}, true
+    case ScriptSquare:
+        return color.RGBA{0xb3, 0xe5, 0xfc, 0xff}, true
+    }
+    return color.RGBA{}, false
+}
+
+func cssColor(c color.RGBA) string {
+    return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// cellFill is the background of the cell at col drawing sq, striped by
+// column as on the printed page
+func cellFill(b Board, sq, col int) color.RGBA {
+    if c, ok := specialTint(b.Square(sq)); ok {
+        return c
+    }
+    if col%2 == 0 {
+        return imgShade
+    }
+    return imgLight
+}
+
+// counterSpot is where the counter of the seat-th player sits on square
+// sq: counters share a cell in two rows of three
+func counterSpot(g Grid, sq, seat int) (x, y float64) {
+    r, c, _ := g.Cell(sq)
+    return (float64(c) + 0.25 + 0.25*float64(seat%3)) * cellUnits, (float64(r) + 0.55 + 0.25*float64(seat/3%2)) * cellUnits
+}
+
+// writeBoardSVG draws b, and any players' counters, as a standalone SVG
+// cellPx pixels to a square
+func writeBoardSVG(w io.Writer, title string, b Board, players []Player, cellPx int) error {
+    g := GridFor(b)
+    var sb strings.Builder
+    vw, vh := g.Width*cellUnits, g.Rows()*cellUnits
+    fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d" font-family="sans-serif">`+"\n",
+        vw, vh, g.Width*cellPx, g.Rows()*cellPx)
+    fmt.Fprintf(&sb, "<title>%s</title>\n", html.EscapeString(title))
+    for row := 0; row < g.Rows(); row++ {
+        for col := 0; col < g.Width; col++ {
+            sq, ok := g.Square(row, col)
+            if !ok {
+                continue
+            }
+            x, y := col*cellUnits, row*cellUnits
+            fmt.Fprintf(&sb, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s" stroke="%s" stroke-width="2"/>`,
+                x, y, cellUnits, cellUnits, cssColor(cellFill(b, sq, col)), cssColor(imgInk))
+            fmt.Fprintf(&sb, `<text x="%d" y="%d" font-size="24" fill="%s">%d</text>`, x+6, y+26, cssColor(imgInk), sq)
+            if label := printLabel(b.Square(sq)); label != "" {
+                fmt.Fprintf(&sb, `<text x="%d" y="%d" font-size="12" fill="#555555">%s</text>`, x+6, y+44, html.EscapeString(label))
+            }
+            sb.WriteString("\n")
+        }
+    }
+    center := func(sq int) (float64, float64) {
+        r, c, _ := g.Cell(sq)
+        return (float64(c) + 0.5) * cellUnits, (float64(r) + 0.5) * cellUnits
+    }
+    for _, l := range b.Ladders() {
+        x1, y1 := center(l.From.Index)
+        x2, y2 := center(l.To.Index)
+        fmt.Fprintf(&sb, `<path d="%s" fill="none" stroke="%s" stroke-width="6"/>`+"\n", ladderPath(x1, y1, x2, y2), cssColor(imgLadder))
+    }
+    for _, s := range b.Snakes() {
+        x1, y1 := center(s.From.Index)
+        x2, y2 := center(s.To.Index)
+        fmt.Fprintf(&sb, `<path d="%s" fill="none" stroke="%s" stroke-width="14" stroke-linecap="round" opacity="0.8"/>`, snakePath(x1, y1, x2, y2), cssColor(imgSnake))
+        fmt.Fprintf(&sb, `<circle cx="%.0f" cy="%.0f" r="18" fill="%s"/>`+"\n", x1, y1, cssColor(imgSnake))
+    }
+    for i, p := range players {
+        for _, sq := range p.tokenSquares() {
+            x, y := counterSpot(g, sq.Index, i)
+            fmt.Fprintf(&sb, `<circle cx="%.0f" cy="%.0f" r="11" fill="%s" stroke="%s" stroke-width="2"><title>%s</title></circle>`+"\n",
+                x, y, cssColor(playerColors[i%len(playerColors)]), cssColor(imgInk), html.EscapeString(p.Name))
+        }
+    }
+    sb.WriteString("</svg>\n")
+    _, err := io.WriteString(w, sb.String())
+    return err
+}
+
+// writeBoardPNG draws what writeBoardSVG does as a PNG, minus the text
+// labels on special squares (their tint still marks them)
+func writeBoardPNG(w io.Writer, b Board, players []Player, cellPx int) error {
+    g := GridFor(b)
+    c := &canvas{image.NewRGBA(image.Rect(0, 0, g.Width*cellPx, g.Rows()*cellPx)), float64(cellPx) / cellUnits}
+    for row := 0; row < g.Rows(); row++ {
+        for col := 0; col < g.Width; col++ {
+            sq, ok := g.Square(row, col)
+            if !ok {
+                continue
+            }
+            x, y := float64(col*cellUnits), float64(row*cellUnits)
+            c.rect(x, y, cellUnits, cellUnits, cellFill(b, sq, col))
+            c.outline(x, y, cellUnits, cellUnits, imgInk)
+            c.number(x+6, y+6, 24, sq, imgInk)
+        }
+    }
+    center := func(sq int) (float64, float64) {
+        r, col, _ := g.Cell(sq)
+        return (float64(col) + 0.5) * cellUnits, (float64(r) + 0.5) * cellUnits
+    }
+    for _, l := range b.Ladders() {
+        x1, y1 := center(l.From.Index)
+        x2, y2 := center(l.To.Index)
+        for _, seg := range ladderLines(x1, y1, x2, y2) {
+            c.line(seg[0], seg[1], seg[2], seg[3], 3, imgLadder)
+        }
+    }
+    for _, s := range b.Snakes() {
+        x1, y1 := center(s.From.Index)
+        x2, y2 := center(s.To.Index)
+        cv := snakeCurve(x1, y1, x2, y2)
+        const steps = 64
+        px, py := x1, y1
+        for i := 1; i <= steps; i++ {
+            t := float64(i) / steps
+            u := 1 - t
+            x := u*u*u*cv[0] + 3*u*u*t*cv[2] + 3*u*t*t*cv[4] + t*t*t*cv[6]
+            y := u*u*u*cv[1] + 3*u*u*t*cv[3] + 3*u*t*t*cv[5] + t*t*t*cv[7]
+            c.line(px, py, x, y, 7, imgSnake)
+            px, py = x, y
+        }
+        c.disk(x1, y1, 18, imgSnake)
+    }
+    for i, p := range players {
+        for _, sq := range p.tokenSquares() {
+            x, y := counterSpot(g, sq.Index, i)
+            c.disk(x, y, 12, imgInk)
+            c.disk(x, y, 10, playerColors[i%len(playerColors)])
+        }
+    }
+    return png.Encode(w, c.img)
+}
+
+// canvas paints onto img in board units, scale pixels to the unit
+type canvas struct {
+    img   *image.RGBA
+    scale float64
+}
+
+func (c *canvas) rect(x, y, w, h float64, col color.RGBA) {
+    x0, y0 := int(math.Round(x*c.scale)), int(math.Round(y*c.scale))
+    x1, y1 := int(math.Round((x+w)*c.scale)), int(math.Round((y+h)*c.scale))
+    for py := y0; py < y1; py++ {
+        for px := x0; px < x1; px++ {
+            c.img.SetRGBA(px, py, col)
+        }
+    }
+}
+
+// outline is a one-pixel border just inside x, y, w, h
+func (c *canvas) outline(x, y, w, h float64, col color.RGBA) {
+    px := 1 / c.scale
+    c.rect(x, y, w, px, col)
+    c.rect(x, y+h-px, w, px, col)
+    c.rect(x, y, px, h, col)
+    c.rect(x+w-px, y, px, h, col)
+}
+
+func (c *canvas) disk(cx, cy, r float64, col color.RGBA) {
+    cx, cy, r = cx*c.scale, cy*c.scale, math.Max(r*c.scale, 0.5)
+    for py := int(cy - r); py <= int(cy+r); py++ {
+        for px := int(cx - r); px <= int(cx+r); px++ {
+            if dx, dy := float64(px)+0.5-cx, float64(py)+0.5-cy; dx*dx+dy*dy <= r*r {
+                c.img.SetRGBA(px, py, col)
+            }
+        }
+    }
+}
+
+// line is a round-capped stroke of half-width r
+func (c *canvas) line(x1, y1, x2, y2, r float64, col color.RGBA) {
+    n := int(math.Hypot(x2-x1, y2-y1)*c.scale) + 1
+    for i := 0; i <= n; i++ {
+        t := float64(i) / float64(n)
+        c.disk(x1+(x2-x1)*t, y1+(y2-y1)*t, r, col)
+    }
+}
+
+// digitGlyphs is a 3x5 bitmap font, a row of three bits per element
+var digitGlyphs = [10][5]uint8{
+    {7, 5, 5, 5, 7}, {2, 6, 2, 2, 7}, {7, 1, 7, 4, 7}, {7, 1, 7, 1, 7}, {5, 5, 7, 1, 1},
+    {7, 4, 7, 1, 7}, {7, 4, 7, 5, 7}, {7, 1, 1, 1, 1}, {7, 5, 7, 5, 7}, {7, 5, 7, 1, 7},
+}
+
+// number writes n with its top left at x, y, size units tall
+func (c *canvas) number(x, y, size float64, n int, col color.RGBA) {
+    dot := size / 5
+    for _, d := range strconv.Itoa(n) {
+        for row, bits := range digitGlyphs[d-'0'] {
+            for bit := 0; bit < 3; bit++ {
+                if bits&(4>>bit) != 0 {
+                    c.rect(x+float64(bit)*dot, y+float64(row)*dot, dot, dot, col)
+                }
+            }
+        }
+        x += 4 * dot
+    }
+}
+
+// parsePlacements reads "Alice:37,Bob:12", the players to draw and their
+// squares on b
+func parsePlacements(s string, b Board) ([]Player, error) {
+    var players []Player
+    for _, part := range strings.Split(s, ",") {
+        name, at, ok := strings.Cut(part, ":")
+        if !ok {
+            return nil, fmt.Errorf("player %q needs a square, as in Alice:37", part)
+        }
+        sq, err := strconv.Atoi(strings.TrimSpace(at))
+        if err != nil || sq < 1 || sq > b.FinalSquare.Index {
+            return nil, fmt.Errorf("player %s: %q is not a square on this board", name, at)
+        }
+        players = append(players, Player{Name: strings.TrimSpace(name), Position: mustBP(sq)})
+    }
+    return players, nil
+}
+
+// runExportImage implements `export-image -o FILE.svg|FILE.png [board-file]`
+func runExportImage(args []string) error {
+    fs := flag.NewFlagSet("export-image", flag.ContinueOnError)
+    outPath := fs.String("o", "", "image to write; .svg or .png")
+    placed := fs.String("players", "", "counters to draw, as Alice:37,Bob:12")
+    cellPx := fs.Int("cell", 60, "pixels per square")
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+    usage := errors.New("usage: export-image -o board.svg|board.png [-players Alice:37,Bob:12] [-cell px] [board-file]")
+    if *outPath == "" || fs.NArg() > 1 {
+        return usage
+    }
+    if *cellPx < 10 || *cellPx > 1000 {
+        return fmt.Errorf("-cell %d must be between 10 and 1000", *cellPx)
+    }
+    title, b := "Snakes & Ladders", CreateStandardBoard()
+    if fs.NArg() == 1 {
+        c, err := LoadBoardConfig(fs.Arg(0))
+        if err != nil {
+            return err
+        }
+        if b, err = c.Build(); err != nil {
+            return fmt.Errorf("%s: %w", fs.Arg(0), err)
+        }
+        if c.Name != "" {
+            title = c.Name
+        }
+    }
+    var players []Player
+    if *placed != "" {
+        var err error
+        if players, err = parsePlacements(*placed, b); err != nil {
+            return err
+        }
+    }
+    var write func(io.Writer) error
+    switch strings.ToLower(filepath.Ext(*outPath)) {
+    case ".svg":
+        write = func(w io.Writer) error { return writeBoardSVG(w, title, b, players, *cellPx) }
+    case ".png":
+        write = func(w io.Writer) error { return writeBoardPNG(w, b, players, *cellPx) }
+    default:
+        return fmt.Errorf("%s: want a .svg or .png file", *outPath)
+    }
+    f, err := os.Create(*outPath)
+    if err != nil {
+        return err
+    }
+    if err := write(f); err != nil {
+        f.Close()
+        return err
+    }
+    return f.Close()
+}
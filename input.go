@@ -0,0 +1,179 @@
+package main
+
+import (
+    "bytes"
+    "context"
+    "io"
+    "os"
+    "strings"
+    "sync"
+    "time"
+    "unicode"
+)
+
+// lineInput hands on what is typed a line at a time, or in keys mode a
+// keypress at a time, from one goroutine that reads its source for as
+// long as there is any. Every prompt on the source waits on the same
+// lineInput, so the game loop can wait with a deadline, and a prompt that
+// gives up waiting leaves the next line for the next prompt rather than
+// a read of its own still pending to swallow it.
+type lineInput struct {
+    lines chan string
+
+    mu   sync.Mutex
+    keys bool              // hand on each read whole, from a raw terminal
+    say  func(text string) // takes what is typed after "say", if set
+}
+
+func newLineInput(r io.Reader) *lineInput {
+    return newChatInput(r, nil)
+}
+
+// newChatInput is newLineInput for a player in a network game: what they
+// type after "say", whenever they type it, goes to say rather than to
+// their prompts
+func newChatInput(r io.Reader, say func(text string)) *lineInput {
+    in := &lineInput{lines: make(chan string), say: say}
+    go in.read(r)
+    return in
+}
+
+// stdinInput is the lineInput for standard input, which everything
+// reading the terminal shares
+var stdinInput = sync.OnceValue(func() *lineInput {
+    return newLineInput(os.Stdin)
+})
+
+func (in *lineInput) read(r io.Reader) {
+    defer close(in.lines)
+    // in keys mode one read is one keypress, so an arrow key's escape
+    // sequence doesn't count as several
+    buf := make([]byte, 256)
+    var pending []byte
+    for {
+        n, err := r.Read(buf)
+        in.mu.Lock()
+        keys := in.keys
+        in.mu.Unlock()
+        if keys && n > 0 {
+            in.lines <- string(buf[:n])
+        } else {
+            pending = append(pending, buf[:n]...)
+            for {
+                i := bytes.IndexByte(pending, '\n')
+                if i < 0 {
+                    break
+                }
+                in.hand(string(pending[:i+1]))
+                pending = pending[i+1:]
+            }
+        }
+        if err != nil {
+            if len(pending) > 0 {
+                in.hand(string(pending))
+            }
+            return
+        }
+    }
+}
+
+// hand passes a line on to the prompt waiting for it, or to say
+func (in *lineInput) hand(line string) {
+    in.mu.Lock()
+    say := in.say
+    in.mu.Unlock()
+    if text, ok := chatText(line); ok && say != nil {
+        say(text)
+        return
+    }
+    in.lines <- line
+}
+
+// chat sends what is typed after "say" to say from now on
+func (in *lineInput) chat(say func(text string)) {
+    in.mu.Lock()
+    defer in.mu.Unlock()
+    in.say = say
+}
+
+// keysMode switches between handing on lines and single keypresses
+func (in *lineInput) keysMode(on bool) {
+    in.mu.Lock()
+    defer in.mu.Unlock()
+    in.keys = on
+}
+
+// ReadLine waits for the next trimmed line, returning io.EOF once the
+// input has ended, or ctx's error once it is done
+func (in *lineInput) ReadLine(ctx context.Context) (string, error) {
+    select {
+    case line, ok := <-in.lines:
+        if !ok {
+            return "", io.EOF
+        }
+        return strings.TrimSpace(line), nil
+    case <-ctx.Done():
+        return "", ctx.Err()
+    }
+}
+
+// chatText is what a line typed as "say TEXT" says
+func chatText(line string) (string, bool) {
+    cmd, text, _ := strings.Cut(strings.TrimSpace(line), " ")
+    text = cleanChat(text)
+    return text, cmd == "say" && text != ""
+}
+
+// cleanChat is text trimmed, without the control characters that could
+// redraw other players' terminals
+func cleanChat(text string) string {
+    return strings.TrimSpace(strings.Map(func(r rune) rune {
+        if unicode.IsControl(r) {
+            return -1
+        }
+        return r
+    }, text))
+}
+
+// chatLine is name saying text, as a line of its own: it clears any
+// countdown being drawn, which picks up again on the next line
+func chatLine(msgs Catalog, name, text string) string {
+    return "\r\033[K" + msgs.T(MsgChat, name, text) + "\n"
+}
+
+// WaitTurn blocks until the player enters a line, limit elapses or ctx is
+// done, rendering a countdown while it waits. It returns the trimmed
+// line, or false when the time ran out. A zero limit waits until a line
+// arrives; closed input returns an empty line straight away.
+func (in *lineInput) WaitTurn(ctx context.Context, clock Clock, limit time.Duration, r Renderer) (string, bool, error) {
+    if limit <= 0 {
+        select {
+        case line := <-in.lines:
+            return strings.TrimSpace(line), true, nil
+        case <-ctx.Done():
+            return "", false, ctx.Err()
+        }
+    }
+    deadline := clock.Now().Add(limit)
+    for {
+        left := deadline.Sub(clock.Now())
+        if left <= 0 {
+            r.RenderCountdown(-1)
+            return "", false, nil
+        }
+        r.RenderCountdown(int(left.Round(time.Second).Seconds()))
+        step := left % time.Second
+        if step == 0 {
+            step = time.Second
+        }
+        select {
+        case line := <-in.lines:
+            r.RenderCountdown(0)
+            return strings.TrimSpace(line), true, nil
+        case <-ctx.Done():
+            r.RenderCountdown(-1)
+            return "", false, ctx.Err()
+        case <-clock.After(step):
+        }
+    }
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+)
+
+// ItemKind is a power-up a player can collect and later use before a roll
+type ItemKind string
+
+const (
+    // ItemImmunity makes the holder ignore the next snake they land on
+    ItemImmunity ItemKind = "immunity"
+    // ItemBoost adds boostSquares to the holder's next roll
+    ItemBoost ItemKind = "boost"
+)
+
+// ItemKinds lists every item, for help text and validation
+var ItemKinds = []ItemKind{ItemImmunity, ItemBoost}
+
+const boostSquares = 2
+
+// ItemSquare hands whoever lands on it an Item
+type ItemSquare struct {
+    Pos  BoardPos
+    Item ItemKind
+}
+
+func (i ItemSquare) Dest() BoardPos { return i.Pos }
+
+var (
+    ErrNoItem    = errors.New("you don't have that item")
+    ErrItemInUse = errors.New("that item is already active")
+)
+
+func parseItem(s string) (ItemKind, error) {
+    for _, k := range ItemKinds {
+        if string(k) == s {
+            return k, nil
+        }
+    }
+    return "", fmt.Errorf("unknown item %q (want one of %v)", s, ItemKinds)
+}
+
+// useItem spends one of the current player's items, arming its effect for
+// their coming roll. gs is left unchanged.
+func useItem(gs GameState, item ItemKind) (GameState, error) {
+    ps := append([]Player(nil), gs.Players...)
+    cur := &ps[gs.CurrentPlayerIndex]
+    at := -1
+    for i, it := range cur.Items {
+        if it == item {
+            at = i
+            break
+        }
+    }
+    if at < 0 {
+        return gs, ErrNoItem
+    }
+    switch item {
+    case ItemImmunity:
+        if cur.Immune {
+            return gs, ErrItemInUse
+        }
+        cur.Immune = true
+    case ItemBoost:
+        cur.Boost += boostSquares
+    }
+    cur.Items = append(append([]ItemKind(nil), cur.Items[:at]...), cur.Items[at+1:]...)
+    gs.Players = ps
+    return gs, nil
+}
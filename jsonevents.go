@@ -0,0 +1,19 @@
+package main
+
+import (
+    "encoding/json"
+    "io"
+)
+
+// jsonEvents is an Observer writing each event as one JSON object per line
+type jsonEvents struct {
+    enc *json.Encoder
+}
+
+func newJSONEvents(w io.Writer) *jsonEvents {
+    return &jsonEvents{json.NewEncoder(w)}
+}
+
+func (j *jsonEvents) OnEvent(ev Event) {
+    j.enc.Encode(ev)
+}
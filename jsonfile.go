@@ -0,0 +1,58 @@
+package main
+
+import (
+    "encoding/json"
+    "errors"
+    "io/fs"
+    "os"
+    "path/filepath"
+)
+
+// appPath is a path under the per-user snakesladders config directory
+func appPath(elem ...string) (string, error) {
+    dir, err := os.UserConfigDir()
+    if err != nil {
+        return "", err
+    }
+    return filepath.Join(append([]string{dir, "snakesladders"}, elem...)...), nil
+}
+
+// readJSONFile decodes path into v. A missing file leaves v untouched and
+// is not an error, so callers can pre-fill v with defaults.
+func readJSONFile(path string, v any) error {
+    data, err := os.ReadFile(path)
+    if errors.Is(err, fs.ErrNotExist) {
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+    return json.Unmarshal(data, v)
+}
+
+// writeJSONFile replaces path with v as indented JSON, creating parent
+// directories. It writes a temp file and renames it over path so a crash
+// mid-write never leaves a truncated file behind.
+func writeJSONFile(path string, v any) error {
+    if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+        return err
+    }
+    data, err := json.MarshalIndent(v, "", "  ")
+    if err != nil {
+        return err
+    }
+    tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+    if err != nil {
+        return err
+    }
+    if _, err := tmp.Write(data); err != nil {
+        tmp.Close()
+        os.Remove(tmp.Name())
+        return err
+    }
+    if err := tmp.Close(); err != nil {
+        os.Remove(tmp.Name())
+        return err
+    }
+    return os.Rename(tmp.Name(), path)
+}
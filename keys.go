@@ -0,0 +1,49 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "os"
+    "time"
+)
+
+// keyInput switches in, which reads the terminal f, to single keypresses,
+// putting f into raw mode until restore is called. Each keypress then
+// arrives as one "line" holding the key; keyController makes sense of it.
+func keyInput(in *lineInput, f *os.File) (restore func(), err error) {
+    raw, err := makeRaw(int(f.Fd()))
+    if err != nil {
+        return nil, errors.New("single-key input needs a terminal: " + err.Error())
+    }
+    in.keysMode(true)
+    return func() {
+        in.keysMode(false)
+        raw()
+    }, nil
+}
+
+// keyController is a player at this terminal pressing single keys (-keys):
+// at the roll prompt q quits, b shows the board and any other key rolls;
+// when choosing a token or die, or a move in a game from play, a digit
+// picks it and any other key takes the default, q still quitting games
+// from play
+type keyController struct {
+    in *lineInput
+}
+
+func (k keyController) WaitTurn(ctx context.Context, p Prompt, clock Clock, limit time.Duration, r Renderer) (string, bool, error) {
+    key, answered, err := k.in.WaitTurn(ctx, clock, limit, r)
+    if !answered || err != nil {
+        return "", answered, err
+    }
+    choosing := p.Roll.Value != 0 || len(p.Choices) > 0 || p.Game != nil
+    switch {
+    case key == "q" && (p.Game != nil || !choosing):
+        return "quit", true, nil
+    case key == "b" && !choosing:
+        return "board", true, nil
+    case choosing && len(key) == 1 && key[0] >= '1' && key[0] <= '9':
+        return key, true, nil
+    }
+    return "", true, nil
+}
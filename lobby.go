@@ -0,0 +1,292 @@
+package main
+
+import (
+    "crypto/rand"
+    "errors"
+    "fmt"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+)
+
+var (
+    ErrNoTable     = errors.New("no table with that code")
+    ErrTableFull   = errors.New("that table is full")
+    ErrGameStarted = errors.New("that table's game has started")
+)
+
+// LobbySettings are the rules a lobby table plays by, as the flags of the
+// same names set them
+type LobbySettings struct {
+    Seats   int    `json:"seats"`
+    Preset  string `json:"preset,omitempty"`
+    Rules   string `json:"rules,omitempty"`
+    Capture string `json:"capture,omitempty"`
+    Tokens  int    `json:"tokens,omitempty"`
+}
+
+// rules checks the settings will make a game on b
+func (ls LobbySettings) rules(b Board) (Rules, error) {
+    if ls.Seats < 2 || ls.Seats > maxPlayers {
+        return Rules{}, fmt.Errorf("a table seats 2 to %d, not %d", maxPlayers, ls.Seats)
+    }
+    r := Rules{Tokens: max(ls.Tokens, 1)}
+    spec := RuleSpec{Preset: ls.Preset, Enable: strings.Split(ls.Rules, ","), Capture: ls.Capture}
+    if err := spec.Apply(&r); err != nil {
+        return Rules{}, err
+    }
+    return r, r.Check(b)
+}
+
+// lobbySeat is a seat at a lobby table; an empty name is a free seat
+type lobbySeat struct {
+    Name  string `json:"name"`
+    Ready bool   `json:"ready"`
+    token string
+}
+
+// LobbyTable is a table as the lobby lists it
+type LobbyTable struct {
+    Code     string        `json:"code"`
+    Settings LobbySettings `json:"settings"`
+    Seats    []lobbySeat   `json:"seats"`
+    Started  bool          `json:"started"`
+    active   time.Time     // when anyone last did anything at it, before it started
+}
+
+// copy is t sharing nothing with it, to hand out from under the lock
+func (t *LobbyTable) copy() LobbyTable {
+    c := *t
+    c.Seats = append([]lobbySeat(nil), t.Seats...)
+    return c
+}
+
+func (t *LobbyTable) full() bool {
+    for _, seat := range t.Seats {
+        if seat.Name == "" {
+            return false
+        }
+    }
+    return true
+}
+
+// lobby gathers players into tables before their games start. Anyone can
+// open a table with the settings they want; others join it by its code,
+// each getting a seat and its token, and say when they are ready. Once
+// every seat is taken and everyone is ready, start begins the game with
+// the players in seat order, their tokens carrying over. Tables that
+// never start are swept away once idle; the server that started a game
+// removes its table when it is done with it.
+type lobby struct {
+    board Board
+    clock Clock
+    start func(code string, names []string, rules Rules, tokens *seatTokens) error
+
+    mu     sync.Mutex
+    tables map[string]*LobbyTable
+}
+
+func newLobby(board Board, clock Clock, start func(code string, names []string, rules Rules, tokens *seatTokens) error) *lobby {
+    return &lobby{board: board, clock: clock, start: start, tables: map[string]*LobbyTable{}}
+}
+
+// lobbyCodeChars are what table codes are made of, leaving out letters
+// easily mistaken for others
+const lobbyCodeChars = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// Open sets up an empty table
+func (l *lobby) Open(ls LobbySettings) (LobbyTable, error) {
+    if _, err := ls.rules(l.board); err != nil {
+        return LobbyTable{}, err
+    }
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    t := &LobbyTable{Code: l.newCode(), Settings: ls, Seats: make([]lobbySeat, ls.Seats), active: l.clock.Now()}
+    l.tables[t.Code] = t
+    return t.copy(), nil
+}
+
+// Host opens a table for names and starts its game straight away, for
+// players who need no lobby: they claim their seats by joining the game
+// under their names
+func (l *lobby) Host(ls LobbySettings, names []string) (LobbyTable, error) {
+    ls.Seats = len(names)
+    rules, err := ls.rules(l.board)
+    if err != nil {
+        return LobbyTable{}, err
+    }
+    t := &LobbyTable{Settings: ls, Started: true}
+    seen := map[string]bool{}
+    for _, name := range names {
+        if name == "" || strings.ContainsAny(name, ",:;=") || seen[name] {
+            return LobbyTable{}, fmt.Errorf("bad or repeated player name %q", name)
+        }
+        seen[name] = true
+        t.Seats = append(t.Seats, lobbySeat{Name: name, Ready: true})
+    }
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    t.Code = l.newCode()
+    if err := l.start(t.Code, names, rules, &seatTokens{}); err != nil {
+        return LobbyTable{}, fmt.Errorf("starting the game: %w", err)
+    }
+    l.tables[t.Code] = t
+    return t.copy(), nil
+}
+
+// newCode is a code no table has; l.mu must be held
+func (l *lobby) newCode() string {
+    for {
+        var b [5]byte
+        if _, err := rand.Read(b[:]); err != nil {
+            panic(err) // crypto/rand never fails on supported platforms
+        }
+        for i := range b {
+            b[i] = lobbyCodeChars[int(b[i])%len(lobbyCodeChars)]
+        }
+        if l.tables[string(b[:])] == nil {
+            return string(b[:])
+        }
+    }
+}
+
+// remove forgets a started table whose game its server has collected
+func (l *lobby) remove(code string) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    delete(l.tables, code)
+}
+
+// sweep closes the tables, as of now, idle for idle without starting
+func (l *lobby) sweep(now time.Time, idle time.Duration) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    for code, t := range l.tables {
+        if !t.Started && now.Sub(t.active) >= idle {
+            delete(l.tables, code)
+        }
+    }
+}
+
+// List is the tables still waiting for players or for them to be ready
+func (l *lobby) List() []LobbyTable {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    var open []LobbyTable
+    for _, t := range l.tables {
+        if !t.Started {
+            open = append(open, t.copy())
+        }
+    }
+    sort.Slice(open, func(i, j int) bool { return open[i].Code < open[j].Code })
+    return open
+}
+
+// Table looks a table up by its code, which is read case-insensitively
+func (l *lobby) Table(code string) (LobbyTable, error) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    t, ok := l.tables[strings.ToUpper(code)]
+    if !ok {
+        return LobbyTable{}, ErrNoTable
+    }
+    return t.copy(), nil
+}
+
+// Join seats name at the first free seat at table code
+func (l *lobby) Join(code, name string) (PlayerID, string, error) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    t, ok := l.tables[strings.ToUpper(code)]
+    switch {
+    case !ok:
+        return 0, "", ErrNoTable
+    case t.Started:
+        return 0, "", ErrGameStarted
+    case name == "" || strings.ContainsAny(name, ",:;="):
+        return 0, "", errors.New("names can't be empty or use , : ; or =")
+    }
+    free := -1
+    for i, seat := range t.Seats {
+        if seat.Name == name {
+            return 0, "", fmt.Errorf("%s is already at this table", name)
+        }
+        if seat.Name == "" && free < 0 {
+            free = i
+        }
+    }
+    if free < 0 {
+        return 0, "", ErrTableFull
+    }
+    t.Seats[free] = lobbySeat{Name: name, token: newToken()}
+    t.active = l.clock.Now()
+    return PlayerID(free), t.Seats[free].token, nil
+}
+
+// seat checks token is for seat id at table code; l.mu must be held
+func (l *lobby) seat(code string, id PlayerID, token string) (*LobbyTable, error) {
+    t, ok := l.tables[strings.ToUpper(code)]
+    switch {
+    case !ok:
+        return nil, ErrNoTable
+    case t.Started:
+        return nil, ErrGameStarted
+    case int(id) < 0 || int(id) >= len(t.Seats) || t.Seats[id].Name == "" || !sameToken(t.Seats[id].token, token):
+        return nil, ErrBadToken
+    }
+    t.active = l.clock.Now()
+    return t, nil
+}
+
+// Ready says whether seat id at table code is ready to play, starting
+// the game if that makes everyone ready at a full table
+func (l *lobby) Ready(code string, id PlayerID, token string, ready bool) (LobbyTable, error) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    t, err := l.seat(code, id, token)
+    if err != nil {
+        return LobbyTable{}, err
+    }
+    t.Seats[id].Ready = ready
+    if !t.full() {
+        return t.copy(), nil
+    }
+    var names []string
+    tokens := &seatTokens{tokens: map[PlayerID]string{}}
+    for i, seat := range t.Seats {
+        if !seat.Ready {
+            return t.copy(), nil
+        }
+        names = append(names, seat.Name)
+        tokens.tokens[PlayerID(i)] = seat.token
+    }
+    rules, err := t.Settings.rules(l.board)
+    if err == nil {
+        err = l.start(t.Code, names, rules, tokens)
+    }
+    if err != nil {
+        return t.copy(), fmt.Errorf("starting the game: %w", err)
+    }
+    t.Started = true
+    return t.copy(), nil
+}
+
+// Leave gives up seat id at table code before the game starts, closing
+// the table once nobody is left at it
+func (l *lobby) Leave(code string, id PlayerID, token string) error {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    t, err := l.seat(code, id, token)
+    if err != nil {
+        return err
+    }
+    t.Seats[id] = lobbySeat{}
+    for _, seat := range t.Seats {
+        if seat.Name != "" {
+            return nil
+        }
+    }
+    delete(l.tables, t.Code)
+    return nil
+}
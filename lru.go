@@ -0,0 +1,68 @@
+package main
+
+import (
+    "container/list"
+    "sync"
+)
+
+// lruCache is a size-bounded map that evicts the least recently used
+// entry once full. It is safe for concurrent use.
+type lruCache[K comparable, V any] struct {
+    mu    sync.Mutex
+    max   int
+    order *list.List // front is most recently used; values are *lruEntry
+    items map[K]*list.Element
+}
+
+type lruEntry[K comparable, V any] struct {
+    key K
+    val V
+}
+
+func newLRUCache[K comparable, V any](max int) *lruCache[K, V] {
+    return &lruCache[K, V]{max: max, order: list.New(), items: map[K]*list.Element{}}
+}
+
+func (c *lruCache[K, V]) Get(k K) (V, bool) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if e, ok := c.items[k]; ok {
+        c.order.MoveToFront(e)
+        return e.Value.(*lruEntry[K, V]).val, true
+    }
+    var zero V
+    return zero, false
+}
+
+func (c *lruCache[K, V]) Add(k K, v V) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if e, ok := c.items[k]; ok {
+        e.Value.(*lruEntry[K, V]).val = v
+        c.order.MoveToFront(e)
+        return
+    }
+    c.items[k] = c.order.PushFront(&lruEntry[K, V]{k, v})
+    for c.order.Len() > c.max {
+        oldest := c.order.Back()
+        c.order.Remove(oldest)
+        delete(c.items, oldest.Value.(*lruEntry[K, V]).key)
+    }
+}
+
+// getOrCompute returns the cached value for k, computing and caching it
+// on a miss. Two goroutines missing at once may both compute it.
+func (c *lruCache[K, V]) getOrCompute(k K, compute func() V) V {
+    if v, ok := c.Get(k); ok {
+        return v
+    }
+    v := compute()
+    c.Add(k, v)
+    return v
+}
+
+func (c *lruCache[K, V]) Len() int {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    return c.order.Len()
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "io"
+    "strconv"
+    "strings"
+)
+
+// Ludo for two to four players, four tokens each. A token leaves its yard
+// for its start square on a six, goes once round the 52-square track and
+// up its own home column, reaching home only with an exact roll. Landing
+// on an opponent away from the safe squares (every start, and the star
+// eight squares past it) sends their tokens back to the yard. A six earns
+// another roll, and the first to bring all four home wins.
+//
+// A turn is two moves: "roll", then the number of the token to move, which
+// is made for the player when only one can.
+type Ludo struct {
+    names  []string
+    starts []int // each player's start square on the track
+    tokens [][ludoTokens]int
+    dice   DiceStream
+    cur    int
+    roll   int // the roll waiting for a token to be chosen, 0 if none
+    rolls  int
+    won    int // 1 + the winner's index
+}
+
+const (
+    ludoTokens = 4
+    ludoTrack  = 52
+    // a token's progress: ludoYard before it enters, then steps from its
+    // start square: 0 to 50 round the track, 51 to 55 up the home column
+    // and ludoHome at the end
+    ludoYard = -1
+    ludoHome = 56
+)
+
+func NewLudo(names []string, dice DiceStream) (*Ludo, error) {
+    if len(names) < 2 || len(names) > 4 {
+        return nil, fmt.Errorf("Ludo is for two to four players, not %d", len(names))
+    }
+    g := &Ludo{names: names, dice: dice, tokens: make([][ludoTokens]int, len(names))}
+    for i := range names {
+        // two players sit opposite each other
+        seat := i
+        if len(names) == 2 {
+            seat = 2 * i
+        }
+        g.starts = append(g.starts, seat*ludoTrack/4)
+        for k := range g.tokens[i] {
+            g.tokens[i][k] = ludoYard
+        }
+    }
+    return g, nil
+}
+
+func newLudoFromArgs(args, names []string, opts Options) (TurnGame, error) {
+    if len(args) > 0 {
+        return nil, errors.New("usage: play ludo")
+    }
+    return NewLudo(names, randomDice())
+}
+
+// square is where on the track p's token at progress is, or -1 off it
+func (g *Ludo) square(p, progress int) int {
+    if progress < 0 || progress > 50 {
+        return -1
+    }
+    return (g.starts[p] + progress) % ludoTrack
+}
+
+func (g *Ludo) safe(sq int) bool {
+    return sq%(ludoTrack/4) == 0 || sq%(ludoTrack/4) == 8
+}
+
+// dest is where token k of the current player ends up with roll, and
+// whether it can move at all
+func (g *Ludo) dest(k, roll int) (int, bool) {
+    switch at := g.tokens[g.cur][k]; {
+    case at == ludoYard:
+        return 0, roll == 6
+    case at+roll > ludoHome:
+        return at, false
+    default:
+        return at + roll, true
+    }
+}
+
+func (g *Ludo) movable(roll int) []int {
+    var ks []int
+    for k := range g.tokens[g.cur] {
+        if _, ok := g.dest(k, roll); ok {
+            ks = append(ks, k)
+        }
+    }
+    return ks
+}
+
+func (g *Ludo) Players() []string {
+    return append([]string(nil), g.names...)
+}
+
+func (g *Ludo) CurrentPlayer() PlayerID {
+    return PlayerID(g.cur)
+}
+
+func (g *Ludo) LegalMoves() []string {
+    switch {
+    case g.won != 0:
+        return nil
+    case g.roll == 0:
+        return []string{"roll"}
+    }
+    var moves []string
+    for _, k := range g.movable(g.roll) {
+        moves = append(moves, strconv.Itoa(k+1))
+    }
+    return moves
+}
+
+func (g *Ludo) Apply(move string) ([]Event, error) {
+    if g.won != 0 {
+        return nil, ErrGameOver
+    }
+    move = strings.TrimSpace(move)
+    if g.roll == 0 {
+        if move != "roll" {
+            return nil, errors.New(`roll first ("roll")`)
+        }
+        g.rolls++
+        g.roll = g.dice.Roll().Value
+        evs := []Event{{Kind: EventRoll, Turn: g.rolls, Player: g.names[g.cur], Roll: g.roll}}
+        switch ks := g.movable(g.roll); len(ks) {
+        case 0:
+            evs = append(evs, Event{Kind: EventSkipped, Turn: g.rolls, Player: g.names[g.cur], Reason: "no_move"})
+            g.endTurn()
+        case 1:
+            evs = append(evs, g.moveToken(ks[0])...)
+        }
+        return evs, nil
+    }
+    k, err := strconv.Atoi(move)
+    if err != nil || k < 1 || k > ludoTokens {
+        return nil, fmt.Errorf("no token %q (want 1 to %d)", move, ludoTokens)
+    }
+    if _, ok := g.dest(k-1, g.roll); !ok {
+        return nil, fmt.Errorf("token %d can't move %d", k, g.roll)
+    }
+    return g.moveToken(k - 1), nil
+}
+
+// moveToken moves the current player's token k with the pending roll,
+// capturing whoever it lands on, and ends the turn unless that was a six
+func (g *Ludo) moveToken(k int) []Event {
+    from := g.tokens[g.cur][k]
+    to, _ := g.dest(k, g.roll)
+    g.tokens[g.cur][k] = to
+    evs := []Event{{Kind: EventMove, Turn: g.rolls, Player: g.names[g.cur], Token: k + 1, From: from + 1, To: to + 1}}
+    if sq := g.square(g.cur, to); sq >= 0 && !g.safe(sq) {
+        for p := range g.tokens {
+            for j, at := range g.tokens[p] {
+                if p != g.cur && g.square(p, at) == sq {
+                    g.tokens[p][j] = ludoYard
+                    evs = append(evs, Event{Kind: EventCapture, Turn: g.rolls, Player: g.names[g.cur], Other: g.names[p], Token: j + 1, From: at + 1, To: 0})
+                }
+            }
+        }
+    }
+    if g.allHome(g.cur) {
+        g.won = g.cur + 1
+        return evs
+    }
+    if g.roll == 6 {
+        g.roll = 0
+        return append(evs, Event{Kind: EventExtraRoll, Turn: g.rolls, Player: g.names[g.cur]})
+    }
+    g.endTurn()
+    return evs
+}
+
+func (g *Ludo) endTurn() {
+    g.roll = 0
+    g.cur = (g.cur + 1) % len(g.names)
+}
+
+func (g *Ludo) allHome(p int) bool {
+    for _, at := range g.tokens[p] {
+        if at != ludoHome {
+            return false
+        }
+    }
+    return true
+}
+
+func (g *Ludo) Outcome() Outcome {
+    if g.won != 0 {
+        return Win{Winner: Player{Name: g.names[g.won-1]}}
+    }
+    return Ongoing{}
+}
+
+// Render lists where everyone's tokens are: track squares count from 1 at
+// the first player's start
+func (g *Ludo) Render(w io.Writer) {
+    for p, name := range g.names {
+        var at []string
+        for k, progress := range g.tokens[p] {
+            var where string
+            switch {
+            case progress == ludoYard:
+                where = "yard"
+            case progress == ludoHome:
+                where = "home"
+            case progress > 50:
+                where = fmt.Sprintf("home column %d", progress-50)
+            default:
+                where = fmt.Sprintf("square %d", g.square(p, progress)+1)
+            }
+            at = append(at, fmt.Sprintf("%d: %s", k+1, where))
+        }
+        fmt.Fprintf(w, "%-10s start %2d | %s\n", name, g.starts[p]+1, strings.Join(at, ", "))
+    }
+    if g.roll != 0 {
+        fmt.Fprintf(w, "%s rolled %d\n", g.names[g.cur], g.roll)
+    }
+}
+
+// BestMove prefers, in order, capturing, reaching home, leaving the yard
+// and landing on a safe square, and otherwise the token furthest behind,
+// avoiding squares an opponent could hit next roll
+func (g *Ludo) BestMove() string {
+    if g.roll == 0 {
+        return "roll"
+    }
+    best, bestScore := 0, -1<<31
+    for _, k := range g.movable(g.roll) {
+        from := g.tokens[g.cur][k]
+        to, _ := g.dest(k, g.roll)
+        score := -from
+        sq := g.square(g.cur, to)
+        switch {
+        case to == ludoHome:
+            score += 800
+        case from == ludoYard:
+            score += 600
+        case sq >= 0 && g.safe(sq):
+            score += 200
+        }
+        if sq >= 0 && !g.safe(sq) {
+            for p := range g.tokens {
+                if p == g.cur {
+                    continue
+                }
+                for _, at := range g.tokens[p] {
+                    theirs := g.square(p, at)
+                    if theirs == sq {
+                        score += 1000
+                    } else if theirs >= 0 && (sq-theirs+ludoTrack)%ludoTrack <= 6 {
+                        score -= 300
+                    }
+                }
+            }
+        }
+        if score > bestScore {
+            best, bestScore = k, score
+        }
+    }
+    return strconv.Itoa(best + 1)
+}
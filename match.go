@@ -0,0 +1,58 @@
+package main
+
+import (
+    "fmt"
+    "io"
+    "strings"
+)
+
+// Match is a series of games scored one point per win; the first entrant
+// (player, or team in team games) to Target points takes it
+type Match struct {
+    Target  int
+    Entries []string
+    Score   map[string]int
+    Games   int
+}
+
+func NewMatch(entries []string, target int) *Match {
+    return &Match{Target: target, Entries: entries, Score: map[string]int{}}
+}
+
+// Record scores a finished game and reports whether the match is decided
+func (m *Match) Record(res GameResult) bool {
+    m.Games++
+    if res.Outcome == OutcomeWin {
+        who := res.Winner
+        if res.Team != "" {
+            who = res.Team
+        }
+        m.Score[who]++
+    }
+    return m.Winner() != ""
+}
+
+// Winner is whoever has reached Target, or "" while the match goes on
+func (m *Match) Winner() string {
+    for _, e := range m.Entries {
+        if m.Score[e] >= m.Target {
+            return e
+        }
+    }
+    return ""
+}
+
+// StartingOrder rotates names so each game of the match is started by
+// the next seat along
+func (m *Match) StartingOrder(names []string) []string {
+    k := m.Games % len(names)
+    return append(append([]string(nil), names[k:]...), names[:k]...)
+}
+
+func printMatchScore(out io.Writer, msgs Catalog, m *Match) {
+    parts := make([]string, len(m.Entries))
+    for i, e := range m.Entries {
+        parts[i] = fmt.Sprintf("%s %d", e, m.Score[e])
+    }
+    fmt.Fprintln(out, msgs.T(MsgMatchScore, m.Games, strings.Join(parts, " - "), m.Target))
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "net"
+    "net/http"
+    "sort"
+    "sync"
+    "time"
+)
+
+// serverMetrics are what the servers report at /metrics, in the
+// Prometheus text format, for operators of a hosted instance
+var serverMetrics = newMetrics()
+
+// histogram counts observations into cumulative buckets, Prometheus-style
+type histogram struct {
+    bounds []float64 // upper bounds, ascending; +Inf is implied
+    counts []uint64  // per bound, not cumulative
+    sum    float64
+    count  uint64
+}
+
+func newHistogram(bounds ...float64) *histogram {
+    return &histogram{bounds: bounds, counts: make([]uint64, len(bounds))}
+}
+
+func (h *histogram) observe(v float64) {
+    for i, b := range h.bounds {
+        if v <= b {
+            h.counts[i]++
+            break
+        }
+    }
+    h.sum += v
+    h.count++
+}
+
+// write writes h as metric name, with labels (`key="value"`, or "")
+func (h *histogram) write(w io.Writer, name, labels string) {
+    sep := ""
+    if labels != "" {
+        sep = ","
+    }
+    var cum uint64
+    for i, b := range h.bounds {
+        cum += h.counts[i]
+        fmt.Fprintf(w, "%s_bucket{%s%sle=\"%g\"} %d\n", name, labels, sep, b, cum)
+    }
+    fmt.Fprintf(w, "%s_bucket{%s%sle=\"+Inf\"} %d\n", name, labels, sep, h.count)
+    if labels != "" {
+        labels = "{" + labels + "}"
+    }
+    fmt.Fprintf(w, "%s_sum%s %g\n%s_count%s %d\n", name, labels, h.sum, name, labels, h.count)
+}
+
+type metrics struct {
+    mu          sync.Mutex
+    started     uint64
+    finished    map[EventKind]uint64 // by the event that ended the game
+    rolls       uint64
+    durations   *histogram            // of finished games, in seconds
+    connections int64                 // open event streams
+    latency     map[string]*histogram // of requests, in seconds, by route
+}
+
+func newMetrics() *metrics {
+    return &metrics{
+        finished:  map[EventKind]uint64{},
+        durations: newHistogram(30, 60, 120, 300, 600, 1200, 1800, 3600),
+        latency:   map[string]*histogram{},
+    }
+}
+
+// observer counts one game's events; each game needs its own
+func (m *metrics) observer() Observer {
+    return &gameMetrics{m: m}
+}
+
+type gameMetrics struct {
+    m       *metrics
+    started time.Time
+}
+
+func (gm *gameMetrics) OnEvent(ev Event) {
+    m := gm.m
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    switch ev.Kind {
+    case EventStart:
+        gm.started = ev.Time
+        m.started++
+    case EventRoll:
+        m.rolls++
+    case EventWin, EventDraw, EventAbort:
+        m.finished[ev.Kind]++
+        m.durations.observe(ev.Time.Sub(gm.started).Seconds())
+    }
+}
+
+// connected counts an open event stream until the returned func is called
+func (m *metrics) connected() (closed func()) {
+    m.mu.Lock()
+    m.connections++
+    m.mu.Unlock()
+    var once sync.Once
+    return func() {
+        once.Do(func() {
+            m.mu.Lock()
+            m.connections--
+            m.mu.Unlock()
+        })
+    }
+}
+
+// timed is h recording how long its requests take under route
+func (m *metrics) timed(route string, h http.HandlerFunc) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        defer func(start time.Time) { m.took(route, time.Since(start)) }(time.Now())
+        h(w, r)
+    }
+}
+
+// took records how long a request to route took
+func (m *metrics) took(route string, d time.Duration) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    h := m.latency[route]
+    if h == nil {
+        h = newHistogram(.001, .005, .01, .05, .1, .5, 1, 5)
+        m.latency[route] = h
+    }
+    h.observe(d.Seconds())
+}
+
+func (m *metrics) write(w io.Writer) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    fmt.Fprintf(w, "# HELP snl_games_started_total Games started.\n# TYPE snl_games_started_total counter\nsnl_games_started_total %d\n", m.started)
+    fmt.Fprintf(w, "# HELP snl_games_finished_total Games finished, by how they ended.\n# TYPE snl_games_finished_total counter\n")
+    for _, kind := range []EventKind{EventWin, EventDraw, EventAbort} {
+        fmt.Fprintf(w, "snl_games_finished_total{outcome=%q} %d\n", kind, m.finished[kind])
+    }
+    fmt.Fprintf(w, "# HELP snl_rolls_total Dice rolls played.\n# TYPE snl_rolls_total counter\nsnl_rolls_total %d\n", m.rolls)
+    fmt.Fprintf(w, "# HELP snl_game_duration_seconds How long finished games lasted.\n# TYPE snl_game_duration_seconds histogram\n")
+    m.durations.write(w, "snl_game_duration_seconds", "")
+    fmt.Fprintf(w, "# HELP snl_active_connections Open event streams.\n# TYPE snl_active_connections gauge\nsnl_active_connections %d\n", m.connections)
+    fmt.Fprintf(w, "# HELP snl_request_duration_seconds How long requests took, by HTTP route or gRPC method.\n# TYPE snl_request_duration_seconds histogram\n")
+    routes := make([]string, 0, len(m.latency))
+    for route := range m.latency {
+        routes = append(routes, route)
+    }
+    sort.Strings(routes)
+    for _, route := range routes {
+        m.latency[route].write(w, "snl_request_duration_seconds", fmt.Sprintf("route=%q", route))
+    }
+}
+
+func (m *metrics) handle(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+    m.write(w)
+}
+
+// serveMetrics serves /metrics alone at addr until ctx is done, for
+// servers with no HTTP endpoint of their own
+func serveMetrics(ctx context.Context, addr string, m *metrics) error {
+    ln, err := net.Listen("tcp", addr)
+    if err != nil {
+        return err
+    }
+    mux := http.NewServeMux()
+    mux.HandleFunc("GET /metrics", m.handle)
+    srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+    go func() {
+        <-ctx.Done()
+        srv.Close()
+    }()
+    if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+        return err
+    }
+    return nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+    "errors"
+    "math"
+    "math/rand"
+)
+
+// Searchable is a TurnGame bots can look ahead in: one without chance,
+// whose positions can be copied to try moves on
+type Searchable interface {
+    TurnGame
+    // Clone is a copy of the game as it stands that shares nothing with it
+    Clone() Searchable
+}
+
+// Scorer is a Searchable that can say how good an unfinished position is
+// for a player, between -1 (lost) and 1 (won), for searches that stop
+// before the end of the game. Without it such positions count as even.
+type Scorer interface {
+    Score(p PlayerID) float64
+}
+
+// searchBudget bounds the positions a hard bot looks at for one move
+const searchBudget = 200000
+
+// winScore is what a won position is worth, more than any Score and more
+// still the sooner it comes
+const winScore = 1000
+
+var errBudget = errors.New("search budget spent")
+
+// search is minimax with alpha-beta pruning from me's point of view: me
+// maximizes, and every other player is taken to play against them
+type search struct {
+    me    PlayerID
+    nodes int
+    cut   bool // some line was cut off by the depth limit
+}
+
+// value is g's worth to s.me, looking depth moves ahead
+func (s *search) value(g Searchable, depth int, alpha, beta float64) (float64, error) {
+    if s.nodes++; s.nodes > searchBudget {
+        return 0, errBudget
+    }
+    if w, ok := g.Outcome().(Win); ok {
+        v := winScore + float64(depth)
+        if w.Winner.Name != g.Players()[s.me] {
+            v = -v
+        }
+        return v, nil
+    }
+    moves := g.LegalMoves()
+    if len(moves) == 0 {
+        return 0, nil
+    }
+    if depth == 0 {
+        s.cut = true
+        if sc, ok := g.(Scorer); ok {
+            return sc.Score(s.me), nil
+        }
+        return 0, nil
+    }
+    maximize := g.CurrentPlayer() == s.me
+    best := math.Inf(1)
+    if maximize {
+        best = math.Inf(-1)
+    }
+    for _, m := range moves {
+        next := g.Clone()
+        if _, err := next.Apply(m); err != nil {
+            continue
+        }
+        v, err := s.value(next, depth-1, alpha, beta)
+        if err != nil {
+            return 0, err
+        }
+        if maximize {
+            best, alpha = max(best, v), max(alpha, v)
+        } else {
+            best, beta = min(best, v), min(beta, v)
+        }
+        if alpha >= beta {
+            break
+        }
+    }
+    return best, nil
+}
+
+// searchMove picks the current player's move by searching up to maxDepth
+// moves ahead, deepening one move at a time until the game tree is
+// exhausted, a forced result turns up or searchBudget runs out. Equally
+// good moves are chosen between at random.
+func searchMove(g Searchable, maxDepth int) string {
+    moves := g.LegalMoves()
+    rand.Shuffle(len(moves), func(i, j int) { moves[i], moves[j] = moves[j], moves[i] })
+    choice := moves[0]
+    s := search{me: g.CurrentPlayer()}
+    for depth := 1; depth <= maxDepth; depth++ {
+        s.cut = false
+        best, alpha := "", math.Inf(-1)
+        for _, m := range moves {
+            next := g.Clone()
+            if _, err := next.Apply(m); err != nil {
+                continue
+            }
+            v, err := s.value(next, depth-1, alpha, math.Inf(1))
+            if err != nil {
+                return choice
+            }
+            if v > alpha || best == "" {
+                best, alpha = m, v
+            }
+        }
+        if best == "" {
+            return choice
+        }
+        choice = best
+        if !s.cut || math.Abs(alpha) >= winScore {
+            break
+        }
+    }
+    return choice
+}
+
+// Greedy is a TurnGame that can say which move looks best right now, for
+// bots in games of chance, where searching ahead would peek at the dice
+type Greedy interface {
+    BestMove() string
+}
+
+// pickMove answers a playTurnGame prompt. Easy bots play at random. In a
+// Searchable game, medium bots look two moves ahead (enough to take a win
+// or block one) and hard ones as deep as searchBudget allows; otherwise
+// both take a Greedy game's best move, or one at random.
+func (b botController) pickMove(g TurnGame) string {
+    if b.level == BotEasy {
+        return randomMove(g)
+    }
+    switch g := g.(type) {
+    case Searchable:
+        if b.level == BotMedium {
+            return searchMove(g, 2)
+        }
+        return searchMove(g, math.MaxInt)
+    case Greedy:
+        return g.BestMove()
+    }
+    return randomMove(g)
+}
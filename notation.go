@@ -0,0 +1,422 @@
+package main
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Move notation is a terse text form of a game log, for pasting into
+// chats and issues. Like chess PGN it is a few tags and then one line per
+// turn:
+//
+//    [Players "Alice, Bob"]
+//    [Date "2024-05-01T10:00:00Z"]
+//    [Setup {"board":...,"rules":...,"state":...}]
+//    1:Alice d4 1>5
+//    2:Bob d5 1>6
+//    3:Alice !shield d6 5>11
+//    4:Bob#2 d3[3,5] 6>9L31
+//    ...
+//    57:Bob d2 98>100
+//    win Bob
+//
+// A turn is its number, the player (quoted if need be) and their token in
+// games with several, the items they used first, the roll with the two on
+// offer under pick_die, and the move from>to followed by each jump taken:
+// S snake, L ladder, T teleport, W swap, E square script, X three sixes
+// forfeiting the roll, each with the square it ends on.
+// The game ends with "win NAME", "draw", "abort [REASON]" or "pause", and
+// the next game, if any, starts with its own tags. Lines starting with ;
+// are comments. A [Restore ...] tag, with a setup, loads a bookmark. An
+// [Order "roll_off"] or [Order "shuffle"] tag after Players says how
+// their order was decided.
+//
+// The Setup tag carries the board, rules and dice, so reading notation
+// plays the game again from it and checks every turn goes as written;
+// Players and Date are for people and for event times.
+
+// notatedTurn is one turn line of move notation
+type notatedTurn struct {
+    turn     int
+    player   string
+    token    int // 1-based, in games with several tokens each
+    items    []string
+    roll     int
+    choices  []int
+    from, to int
+    jumps    []notatedJump
+}
+
+type notatedJump struct {
+    kind EventKind
+    to   int
+}
+
+var jumpLetters = map[EventKind]byte{EventSnake: 'S', EventLadder: 'L', EventTeleport: 'T', EventSwap: 'W', EventThreeSixes: 'X', EventScript: 'E'}
+
+// add takes the part of a turn's events that its line records
+func (t *notatedTurn) add(ev Event) {
+    switch ev.Kind {
+    case EventRoll:
+        t.turn, t.player, t.roll, t.choices = ev.Turn, ev.Player, ev.Roll, ev.Choices
+    case EventMove:
+        t.token, t.from, t.to = ev.Token, ev.From, ev.To
+    case EventSnake, EventLadder, EventTeleport, EventSwap, EventThreeSixes:
+        t.jumps = append(t.jumps, notatedJump{ev.Kind, ev.To})
+    case EventScript:
+        if ev.Other == "" {
+            t.jumps = append(t.jumps, notatedJump{ev.Kind, ev.To})
+        }
+    }
+}
+
+func (t notatedTurn) String() string {
+    var b strings.Builder
+    fmt.Fprintf(&b, "%d:%s", t.turn, notationName(t.player))
+    if t.token > 0 {
+        fmt.Fprintf(&b, "#%d", t.token)
+    }
+    for _, item := range t.items {
+        b.WriteString(" !" + item)
+    }
+    fmt.Fprintf(&b, " d%d", t.roll)
+    if len(t.choices) > 0 {
+        choices := make([]string, len(t.choices))
+        for i, c := range t.choices {
+            choices[i] = strconv.Itoa(c)
+        }
+        fmt.Fprintf(&b, "[%s]", strings.Join(choices, ","))
+    }
+    fmt.Fprintf(&b, " %d>%d", t.from, t.to)
+    for _, j := range t.jumps {
+        fmt.Fprintf(&b, "%c%d", jumpLetters[j.kind], j.to)
+    }
+    return b.String()
+}
+
+// notationName is name as notation writes it: bare unless it would be
+// misread that way
+func notationName(name string) string {
+    if name == "" || strings.ContainsAny(name, " \t:#![]\";") || strconv.Quote(name) != `"`+name+`"` {
+        return strconv.Quote(name)
+    }
+    return name
+}
+
+// parseTurn reads a turn line
+func parseTurn(line string) (notatedTurn, error) {
+    var t notatedTurn
+    n, rest, ok := strings.Cut(line, ":")
+    turn, err := strconv.Atoi(n)
+    if !ok || err != nil || turn < 1 {
+        return t, fmt.Errorf("%q doesn't start with a turn number and a colon", line)
+    }
+    t.turn = turn
+    if strings.HasPrefix(rest, `"`) {
+        q, err := strconv.QuotedPrefix(rest)
+        if err != nil {
+            return t, fmt.Errorf("bad player name in %q", line)
+        }
+        t.player, _ = strconv.Unquote(q)
+        rest = rest[len(q):]
+    } else {
+        end := strings.IndexAny(rest, " \t#")
+        if end <= 0 {
+            return t, fmt.Errorf("no player in %q", line)
+        }
+        t.player, rest = rest[:end], rest[end:]
+    }
+    if after, ok := strings.CutPrefix(rest, "#"); ok {
+        if t.token, rest, ok = leadingInt(after); !ok || t.token < 1 {
+            return t, fmt.Errorf("bad token number in %q", line)
+        }
+    }
+    fields := strings.Fields(rest)
+    for len(fields) > 0 && strings.HasPrefix(fields[0], "!") {
+        t.items = append(t.items, fields[0][1:])
+        fields = fields[1:]
+    }
+    if len(fields) != 2 {
+        return t, fmt.Errorf("%q isn't a roll and a move", strings.Join(fields, " "))
+    }
+    roll, ok := strings.CutPrefix(fields[0], "d")
+    if ok {
+        t.roll, roll, ok = leadingInt(roll)
+    }
+    if !ok || t.roll < 1 || t.roll > 6 {
+        return t, fmt.Errorf("bad roll %q (want d1 to d6)", fields[0])
+    }
+    if roll != "" {
+        offer, ok := strings.CutPrefix(roll, "[")
+        if ok {
+            offer, ok = strings.CutSuffix(offer, "]")
+        }
+        if !ok {
+            return t, fmt.Errorf("bad roll %q", fields[0])
+        }
+        for _, c := range strings.Split(offer, ",") {
+            n, err := strconv.Atoi(c)
+            if err != nil {
+                return t, fmt.Errorf("bad rolls on offer in %q", fields[0])
+            }
+            t.choices = append(t.choices, n)
+        }
+    }
+    move := fields[1]
+    if t.from, move, ok = leadingInt(move); ok {
+        move, ok = strings.CutPrefix(move, ">")
+    }
+    if ok {
+        t.to, move, ok = leadingInt(move)
+    }
+    for ok && move != "" {
+        kind := EventKind("")
+        for k, letter := range jumpLetters {
+            if move[0] == letter {
+                kind = k
+            }
+        }
+        var to int
+        if to, move, ok = leadingInt(move[1:]); ok && kind != "" {
+            t.jumps = append(t.jumps, notatedJump{kind, to})
+        }
+        ok = ok && kind != ""
+    }
+    if !ok {
+        return t, fmt.Errorf("bad move %q (want from>to, then S, L, T, W, E or X and a square for each jump)", fields[1])
+    }
+    return t, nil
+}
+
+// leadingInt splits the decimal number off the front of s
+func leadingInt(s string) (n int, rest string, ok bool) {
+    end := 0
+    for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+        end++
+    }
+    n, err := strconv.Atoi(s[:end])
+    return n, s[end:], err == nil
+}
+
+// writeNotation writes the games in evs in move notation. Games logged
+// before their setup was (format version 3), or with players leaving or
+// joining, can't be written.
+func writeNotation(w io.Writer, evs []Event) error {
+    var cur *notatedTurn
+    var items []string
+    started := false
+    flush := func() {
+        if cur != nil {
+            fmt.Fprintln(w, cur)
+            cur = nil
+        }
+    }
+    for i, ev := range evs {
+        if !started && ev.Kind != EventStart {
+            continue
+        }
+        switch ev.Kind {
+        case EventStart, EventRestore:
+            flush()
+            if ev.Setup == nil {
+                return fmt.Errorf("event %d: game logged without its setup (before format version 3)", i+1)
+            }
+            setup, err := json.Marshal(ev.Setup)
+            if err != nil {
+                return err
+            }
+            if ev.Kind == EventRestore {
+                fmt.Fprintf(w, "[Restore %s]\n", setup)
+                continue
+            }
+            if started {
+                fmt.Fprintln(w)
+            }
+            started = true
+            fmt.Fprintf(w, "[Players %q]\n", strings.Join(ev.Players, ", "))
+            if ev.Reason != "" {
+                fmt.Fprintf(w, "[Order %q]\n", ev.Reason)
+            }
+            fmt.Fprintf(w, "[Date %q]\n[Setup %s]\n", ev.Time.UTC().Format(time.RFC3339), setup)
+        case EventUseItem:
+            flush()
+            items = append(items, ev.Item)
+        case EventRoll:
+            flush()
+            cur = &notatedTurn{items: items}
+            items = nil
+            cur.add(ev)
+        case EventMove, EventSnake, EventLadder, EventTeleport, EventSwap, EventThreeSixes, EventScript:
+            if cur != nil {
+                cur.add(ev)
+            }
+        case EventWin:
+            flush()
+            fmt.Fprintln(w, "win", notationName(ev.Player))
+        case EventAbort:
+            flush()
+            fmt.Fprintln(w, strings.TrimSpace("abort "+ev.Reason))
+        case EventDraw, EventPause:
+            flush()
+            fmt.Fprintln(w, ev.Kind)
+        case EventLeave, EventJoin:
+            return fmt.Errorf("event %d: move notation can't record players leaving or joining", i+1)
+        }
+    }
+    flush()
+    return nil
+}
+
+// readNotation plays the games written in move notation again from their
+// setups, checking each turn goes as written, and returns their events
+func readNotation(data []byte) ([]Event, error) {
+    var (
+        evs     []Event
+        gs      GameState
+        turn    int
+        playing bool
+        when    time.Time
+        order   string
+    )
+    line := func(text string) error {
+        if tag, ok := strings.CutPrefix(text, "["); ok {
+            name, value, ok := strings.Cut(tag, " ")
+            value, closed := strings.CutSuffix(value, "]")
+            if !ok || !closed {
+                return fmt.Errorf("bad tag %s", text)
+            }
+            switch name {
+            case "Order":
+                var err error
+                if order, err = strconv.Unquote(value); err != nil {
+                    return fmt.Errorf("bad order %s", value)
+                }
+            case "Date":
+                s, err := strconv.Unquote(value)
+                if err == nil {
+                    when, err = time.Parse(time.RFC3339, s)
+                }
+                if err != nil {
+                    return fmt.Errorf("bad date %s", value)
+                }
+            case "Setup", "Restore":
+                if playing == (name == "Setup") {
+                    if playing {
+                        return errors.New("a new game starts before the last one ended")
+                    }
+                    return errors.New("restore outside a game")
+                }
+                var setup GameSetup
+                if err := json.Unmarshal([]byte(value), &setup); err != nil {
+                    return fmt.Errorf("bad setup: %w", err)
+                }
+                var err error
+                if gs, err = setup.state(); err != nil {
+                    return fmt.Errorf("bad setup: %w", err)
+                }
+                turn, playing = setup.State.Turn, true
+                ev := startEvent(gs, turn, when)
+                if name == "Restore" {
+                    ev = Event{Kind: EventRestore, Time: when, Turn: turn, Dice: ev.Dice, Hash: ev.Hash}
+                } else {
+                    ev.Reason, order = order, ""
+                }
+                ev.Setup = &setup
+                evs = append(evs, ev)
+            }
+            return nil
+        }
+        if !playing {
+            return errors.New("moves before a [Setup] tag")
+        }
+        result, arg, _ := strings.Cut(text, " ")
+        outcome := checkOutcome(gs)
+        switch EventKind(result) {
+        case EventWin:
+            name, err := strconv.Unquote(arg)
+            if err != nil {
+                name = arg
+            }
+            if win, ok := outcome.(Win); !ok || win.Winner.Name != name {
+                return fmt.Errorf("%s hasn't won: the game is %s", name, outcome)
+            }
+            playing = false
+            evs = append(evs, winEvent(outcome.(Win), gs, turn, when))
+            return nil
+        case EventDraw:
+            if _, ok := outcome.(Draw); !ok {
+                return fmt.Errorf("the game isn't drawn: it is %s", outcome)
+            }
+            playing = false
+            evs = append(evs, drawEvent(gs, turn, when))
+            return nil
+        case EventAbort, EventPause:
+            if _, ok := outcome.(Ongoing); !ok {
+                return fmt.Errorf("the game can't %s: it is %s", result, outcome)
+            }
+            playing = false
+            evs = append(evs, Event{Kind: EventKind(result), Time: when, Turn: turn, Reason: arg, Hash: stateHash(gs)})
+            return nil
+        }
+        t, err := parseTurn(text)
+        if err != nil {
+            return err
+        }
+        if _, ok := outcome.(Ongoing); !ok {
+            return fmt.Errorf("turn %d played after the game ended (%s)", t.turn, outcome)
+        }
+        if cur := gs.Players[gs.CurrentPlayerIndex].Name; cur != t.player {
+            return fmt.Errorf("turn %d: %s to play, not %s", t.turn, cur, t.player)
+        }
+        for _, item := range t.items {
+            if gs, err = useItem(gs, ItemKind(item)); err != nil {
+                return fmt.Errorf("turn %d: %w", t.turn, err)
+            }
+            evs = append(evs, Event{Kind: EventUseItem, Time: when, Turn: turn, Player: t.player, Item: item, Hash: stateHash(gs)})
+        }
+        if t.token > 0 {
+            if gs, err = withToken(gs, t.token-1); err != nil {
+                return fmt.Errorf("turn %d: %w", t.turn, err)
+            }
+        }
+        pick := 1
+        for k, c := range t.choices {
+            if c == t.roll {
+                pick = k + 1
+                break
+            }
+        }
+        dr, choices := drawRoll(&gs.Dice, gs.Rules, pick)
+        if dr.Value != t.roll {
+            return fmt.Errorf("turn %d: the dice give %d, not %d", t.turn, dr.Value, t.roll)
+        }
+        turn++
+        played := turnEvents(gs, dr, turn, when)
+        played[0].Choices = dieValues(choices)
+        gs = applyMove(gs, dr)
+        got := notatedTurn{items: t.items}
+        for _, ev := range played {
+            got.add(ev)
+        }
+        if got.String() != t.String() {
+            return fmt.Errorf("the game goes %s, not %s", got, t)
+        }
+        evs = append(evs, played...)
+        return nil
+    }
+    for n, text := range strings.Split(string(data), "\n") {
+        text = strings.TrimSpace(text)
+        if text == "" || strings.HasPrefix(text, ";") {
+            continue
+        }
+        if err := line(text); err != nil {
+            return nil, fmt.Errorf("line %d: %w", n+1, err)
+        }
+    }
+    return evs, nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+    "fmt"
+    "strings"
+)
+
+// oddsPlayouts is how many games the odds play out where the solver's
+// model doesn't fit
+const oddsPlayouts = 500
+
+// winOdds gives each player's chance of winning from gs, with the current
+// player about to roll: worked out exactly by winProbabilities for a
+// plain race, and estimated by playing the game out from gs otherwise.
+// Playouts are seeded from the turn, so the same position gives the same
+// odds; a game that ends with nobody winning counts for nobody.
+func winOdds(gs GameState) []float64 {
+    if oddsExact(gs) {
+        return winProbabilities(gs)
+    }
+    odds := make([]float64, len(gs.Players))
+    r := NewSeededRoller(int64(gs.Turns) + 1)
+    for i := 0; i < oddsPlayouts; i++ {
+        win := playout(gs, r, 2000)
+        if win.Winner.Name == "" {
+            continue
+        }
+        for k, p := range gs.Players {
+            if won(win, p) {
+                odds[k] += 1.0 / oddsPlayouts
+            }
+        }
+    }
+    return odds
+}
+
+// oddsExact reports whether winProbabilities' model fits gs: a race of
+// lone tokens under the classic movement rules, with nothing one player
+// does changing another's chances
+func oddsExact(gs GameState) bool {
+    r := gs.Rules
+    r.EnterOnSix, r.Clock, r.TeamWin = false, 0, ""
+    if r != (Rules{}) {
+        return false
+    }
+    for _, p := range gs.Players {
+        if p.Team != "" || p.Left || p.SkipTurns > 0 || p.Boost > 0 || p.Immune || len(p.Items) > 0 {
+            return false
+        }
+    }
+    for i := 1; i < gs.Board.FinalSquare.Index; i++ {
+        switch gs.Board.Square(i).(type) {
+        case Normal, Snake, Ladder, Teleport:
+        default:
+            return false
+        }
+    }
+    return true
+}
+
+// oddsLine is each player's chance of winning from gs, as "Alice 41%,
+// Bob 59%", leaving out players who have left
+func oddsLine(gs GameState) string {
+    var parts []string
+    for i, pr := range winOdds(gs) {
+        if !gs.Players[i].Left {
+            parts = append(parts, fmt.Sprintf("%s %.0f%%", gs.Players[i].Name, 100*pr))
+        }
+    }
+    return strings.Join(parts, ", ")
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+    "fmt"
+    "io"
+    "math/rand"
+    "sort"
+    "strings"
+)
+
+// How the order of play is decided when it isn't as the players were
+// named; a game's start event records which in its Reason
+const (
+    OrderRollOff = "roll_off" // everyone rolls, highest first, ties rolling again
+    OrderShuffle = "shuffle"  // at random
+)
+
+// orderHow describes each way of ordering players for logs
+var orderHow = map[string]string{OrderRollOff: "by roll-off", OrderShuffle: "shuffled"}
+
+// rollOff orders names by a roll each, highest first. Players who tie
+// roll again among themselves until they are split; every roll goes to
+// rolled, and every tie to tied before it is rolled off.
+func rollOff(names []string, roll func() int, rolled func(name string, v int), tied func(names []string, v int)) []string {
+    if len(names) < 2 {
+        return names
+    }
+    rolls := map[string]int{}
+    for _, n := range names {
+        rolls[n] = roll()
+        rolled(n, rolls[n])
+    }
+    byRoll := append([]string(nil), names...)
+    sort.SliceStable(byRoll, func(i, j int) bool { return rolls[byRoll[i]] > rolls[byRoll[j]] })
+    var order []string
+    for i := 0; i < len(byRoll); {
+        j := i + 1
+        for j < len(byRoll) && rolls[byRoll[j]] == rolls[byRoll[i]] {
+            j++
+        }
+        group := byRoll[i:j]
+        if len(group) > 1 {
+            tied(group, rolls[group[0]])
+            group = rollOff(group, roll, rolled, tied)
+        }
+        order = append(order, group...)
+        i = j
+    }
+    return order
+}
+
+// decideOrder is the order names play in, decided as how says, telling
+// out how it came about
+func decideOrder(names []string, how string, rng *rand.Rand, out io.Writer, msgs Catalog) []string {
+    var order []string
+    switch how {
+    case OrderRollOff:
+        fmt.Fprintln(out, msgs.T(MsgRollOff))
+        order = rollOff(names, func() int { return 1 + rng.Intn(6) },
+            func(name string, v int) { fmt.Fprintln(out, "  "+msgs.T(MsgRollOffRoll, name, v)) },
+            func(names []string, v int) { fmt.Fprintln(out, "  "+msgs.T(MsgRollOffTie, strings.Join(names, ", "), v)) })
+    case OrderShuffle:
+        order = append([]string(nil), names...)
+        rng.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+    default:
+        return names
+    }
+    fmt.Fprintln(out, msgs.T(MsgPlayOrder, strings.Join(order, ", ")))
+    return order
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "os/signal"
+    "strings"
+    "syscall"
+    "time"
+)
+
+// errPaused is the cancellation cause when a player pauses the game; play
+// then saves it for `resume` instead of abandoning it
+var errPaused = errors.New("game paused")
+
+// shutdownReason is the reason a pause event gives when a server puts
+// its games away to shut down
+const shutdownReason = "shutdown"
+
+// signalStop is the cancellation cause when a signal stops the program.
+// It counts as a pause, so games are saved, and keeps the signal for the
+// exit status.
+type signalStop struct{ sig os.Signal }
+
+func (s signalStop) Error() string { return "stopped by " + s.sig.String() }
+
+func (s signalStop) Is(target error) bool { return target == errPaused }
+
+// exitOnSignal exits with the status a shell gives a program a signal
+// killed, 128 plus its number, if a signal was why ctx was cancelled. It
+// is deferred first in runCLI (and playHere, for Ctrl-Z), so it runs
+// last, once everything else has been saved, flushed and closed.
+func exitOnSignal(ctx context.Context) {
+    var stop signalStop
+    if !errors.As(context.Cause(ctx), &stop) {
+        return
+    }
+    code := 1
+    if n, ok := stop.sig.(syscall.Signal); ok {
+        code = 128 + int(n)
+    }
+    os.Exit(code)
+}
+
+// Autosave is a paused game: a snapshot plus everything needed to carry
+// on from it without the original flags
+type Autosave struct {
+    Snapshot
+    Board     BoardConfig
+    Rules     Rules
+    GameID    string
+    StartedAt time.Time
+}
+
+// legacyAutosavePath is where the paused game was kept before saves
+// went through a GameStore; it is still read, and removed once resumed
+func legacyAutosavePath() (string, error) {
+    return appPath("saves", "autosave.json")
+}
+
+// loadAutosave reads the game saved under key and rebuilds its board,
+// checking the snapshot still fits it
+func loadAutosave(store GameStore, key string) (Autosave, Board, error) {
+    a, err := store.Load(key)
+    if errors.Is(err, ErrNoSave) && key == autosaveKey {
+        var path string
+        if path, err = legacyAutosavePath(); err == nil {
+            err = readJSONFile(path, &a)
+        }
+    }
+    if err != nil && !errors.Is(err, ErrNoSave) {
+        return a, Board{}, err
+    }
+    if len(a.Players) == 0 {
+        if key == autosaveKey {
+            return a, Board{}, errors.New("no paused game to resume")
+        }
+        return a, Board{}, fmt.Errorf("no saved game %q to resume", key)
+    }
+    b, err := a.Board.Build()
+    if err != nil {
+        return a, Board{}, fmt.Errorf("saved game's board: %w", err)
+    }
+    if _, err := a.Restore(b); err != nil {
+        return a, Board{}, err
+    }
+    return a, b, nil
+}
+
+// offerCrashResume asks at the terminal whether to carry on the game a
+// crash cut off, if play left one under crashKey, forgetting it if not.
+// The save has the dice as they were, so a seeded game rolls on as it
+// would have.
+func offerCrashResume(ctx context.Context, store GameStore, in *lineInput, out io.Writer, msgs Catalog) (Autosave, Board, bool) {
+    if _, err := store.Load(crashKey); err != nil {
+        if !errors.Is(err, ErrNoSave) {
+            fmt.Fprintln(out, msgs.T(MsgWarnCrashLoad, err))
+        }
+        return Autosave{}, Board{}, false
+    }
+    a, b, err := loadAutosave(store, crashKey)
+    if err != nil {
+        fmt.Fprintln(out, msgs.T(MsgWarnCrashLoad, err))
+        return a, b, false
+    }
+    var names []string
+    for _, p := range a.Players {
+        names = append(names, p.Name)
+    }
+    fmt.Fprint(out, msgs.T(MsgCrashFound, a.Turn, strings.Join(names, ", "), a.Players[a.CurrentPlayerIndex].Name)+" ")
+    line, err := readSetupLine(ctx, in)
+    if err != nil {
+        return a, b, false // keep it for next time
+    }
+    switch strings.ToLower(line) {
+    case "", "y", "yes", "s", "si", "sí":
+        return a, b, true
+    }
+    if err := store.Delete(crashKey); err != nil {
+        fmt.Fprintln(out, msgs.T(MsgWarnCrashLoad, err))
+    }
+    return a, b, false
+}
+
+// removeAutosave forgets the saved game once it has been played out
+func removeAutosave(store GameStore, key string) error {
+    if key == autosaveKey {
+        path, err := legacyAutosavePath()
+        if err != nil {
+            return err
+        }
+        if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+            return err
+        }
+    }
+    return store.Delete(key)
+}
+
+// pauseOnSignal cancels ctx with a signalStop on the first of sigs. Later
+// signals get their default behaviour again, so a second Ctrl-C still
+// kills a game that is slow to save.
+func pauseOnSignal(ctx context.Context, cancel context.CancelCauseFunc, sigs ...os.Signal) {
+    if len(sigs) == 0 {
+        return
+    }
+    ch := make(chan os.Signal, 1)
+    signal.Notify(ch, sigs...)
+    defer signal.Stop(ch)
+    select {
+    case sig := <-ch:
+        cancel(signalStop{sig})
+    case <-ctx.Done():
+    }
+}
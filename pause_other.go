@@ -0,0 +1,8 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// suspendSignals is empty where there is no job control
+var suspendSignals []os.Signal
@@ -0,0 +1,12 @@
+//go:build unix
+
+package main
+
+import (
+    "os"
+    "syscall"
+)
+
+// suspendSignals pause an interactive game: Ctrl-Z saves it rather than
+// leaving a stopped job holding the terminal
+var suspendSignals = []os.Signal{syscall.SIGTSTP}
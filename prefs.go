@@ -0,0 +1,38 @@
+package main
+
+// ProfilePrefs are the settings remembered for a profile between runs
+type ProfilePrefs struct {
+    Speed      Speed  `json:"speed,omitempty"`
+    ResultHook string `json:"result_hook,omitempty"`
+}
+
+// loadPrefs returns every stored profile; a missing file is an empty set
+func loadPrefs() (map[string]ProfilePrefs, error) {
+    prefs := map[string]ProfilePrefs{}
+    path, err := appPath("profiles.json")
+    if err != nil {
+        return prefs, err
+    }
+    if err := readJSONFile(path, &prefs); err != nil {
+        return map[string]ProfilePrefs{}, err
+    }
+    return prefs, nil
+}
+
+// updatePrefs applies fn to one profile's stored settings under the file
+// lock, so changes from another running instance aren't overwritten
+func updatePrefs(profile string, fn func(*ProfilePrefs)) (ProfilePrefs, error) {
+    path, err := appPath("profiles.json")
+    if err != nil {
+        return ProfilePrefs{}, err
+    }
+    prefs := map[string]ProfilePrefs{}
+    var pp ProfilePrefs
+    err = updateJSONFile(path, &prefs, func() error {
+        pp = prefs[profile]
+        fn(&pp)
+        prefs[profile] = pp
+        return nil
+    })
+    return pp, err
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+    "errors"
+    "flag"
+    "fmt"
+    "html/template"
+    "io"
+    "math"
+    "os"
+    "strings"
+)
+
+// Paper is a printable page size
+type Paper struct {
+    Name          string
+    Width, Height string // CSS lengths
+}
+
+var papers = map[string]Paper{
+    "a4":     {"A4", "210mm", "297mm"},
+    "letter": {"letter", "8.5in", "11in"},
+}
+
+// printCell is one square of the printed grid
+type printCell struct {
+    Square int
+    Label  string
+    Blank  bool
+}
+
+// printJump is a snake or ladder drawn over the grid, in SVG user units
+// where each cell is cellUnits wide
+type printJump struct {
+    Path   string // snake body, or ladder rails and rungs
+    HeadX  float64
+    HeadY  float64
+    Ladder bool
+}
+
+const cellUnits = 100
+
+var printTmpl = template.Must(template.New("print").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+@page { size: {{.Paper.Name}} portrait; margin: 12mm; }
+body { font-family: sans-serif; margin: 0; }
+h1 { font-size: 16pt; text-align: center; margin: 0 0 4mm; }
+.board { position: relative; width: 100%; aspect-ratio: {{.Cols}} / {{.Rows}}; }
+table { position: absolute; inset: 0; width: 100%; height: 100%; border-collapse: collapse; table-layout: fixed; }
+td { border: 0.4mm solid #333; vertical-align: top; padding: 1mm; font-size: 9pt; }
+td.blank { border: none; }
+td:nth-child(odd) { background: #f4f4f4; }
+td .label { display: block; font-size: 6pt; color: #555; }
+svg { position: absolute; inset: 0; width: 100%; height: 100%; }
+.snake { fill: none; stroke: #2e7d32; stroke-width: 14; stroke-linecap: round; opacity: 0.8; }
+.ladder { fill: none; stroke: #8d6e63; stroke-width: 6; }
+.head { fill: #2e7d32; }
+.legend { font-size: 8pt; margin-top: 4mm; }
+@media screen { body { max-width: {{.Paper.Width}}; margin: 1em auto; } }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<div class="board">
+<table>
+{{range .Cells}}<tr>{{range .}}{{if .Blank}}<td class="blank"></td>{{else}}<td>{{.Square}}{{if .Label}}<span class="label">{{.Label}}</span>{{end}}</td>{{end}}{{end}}</tr>
+{{end}}</table>
+<svg viewBox="0 0 {{.ViewW}} {{.ViewH}}" preserveAspectRatio="none">
+{{range .Jumps}}{{if .Ladder}}<path class="ladder" d="{{.Path}}"/>{{else}}<path class="snake" d="{{.Path}}"/><circle class="head" cx="{{.HeadX}}" cy="{{.HeadY}}" r="18"/>{{end}}
+{{end}}</svg>
+</div>
+<p class="legend">Start on 1 and race to {{.Final}}. Landing on a snake's head slides you down to its tail; landing at the foot of a ladder climbs you to its top.</p>
+</body>
+</html>
+`))
+
+// writePrintHTML renders b as a print-ready HTML page for paper
+func writePrintHTML(w io.Writer, title string, b Board, paper Paper) error {
+    g := GridFor(b)
+    rows := make([][]printCell, g.Rows())
+    for r := range rows {
+        for c := 0; c < g.Width; c++ {
+            sq, ok := g.Square(r, c)
+            rows[r] = append(rows[r], printCell{Square: sq, Label: printLabel(b.Square(sq)), Blank: !ok})
+        }
+    }
+    center := func(sq int) (float64, float64) {
+        r, c, _ := g.Cell(sq)
+        return (float64(c) + 0.5) * cellUnits, (float64(r) + 0.5) * cellUnits
+    }
+    var jumps []printJump
+    for _, s := range b.Snakes() {
+        x1, y1 := center(s.From.Index)
+        x2, y2 := center(s.To.Index)
+        jumps = append(jumps, printJump{Path: snakePath(x1, y1, x2, y2), HeadX: x1, HeadY: y1})
+    }
+    for _, l := range b.Ladders() {
+        x1, y1 := center(l.From.Index)
+        x2, y2 := center(l.To.Index)
+        jumps = append(jumps, printJump{Path: ladderPath(x1, y1, x2, y2), Ladder: true})
+    }
+    return printTmpl.Execute(w, map[string]any{
+        "Title": title,
+        "Paper": paper,
+        "Rows":  g.Rows(),
+        "Cols":  g.Width,
+        "Cells": rows,
+        "ViewW": g.Width * cellUnits,
+        "ViewH": g.Rows() * cellUnits,
+        "Jumps": jumps,
+        "Final": b.FinalSquare.Index,
+    })
+}
+
+func printLabel(sq Square) string {
+    switch sq := sq.(type) {
+    case SkipTurn:
+        return "miss a turn"
+    case ExtraRoll:
+        return "roll again"
+    case Teleport:
+        return "teleport"
+    case Swap:
+        return "swap with leader"
+    case ItemSquare:
+        return "item: " + string(sq.Item)
+    case ScriptSquare:
+        return sq.Script.Source
+    }
+    return ""
+}
+
+// snakePath is a wavy cubic curve from head to tail
+func snakePath(x1, y1, x2, y2 float64) string {
+    c := snakeCurve(x1, y1, x2, y2)
+    return fmt.Sprintf("M %.0f %.0f C %.0f %.0f, %.0f %.0f, %.0f %.0f",
+        c[0], c[1], c[2], c[3], c[4], c[5], c[6], c[7])
+}
+
+// snakeCurve is the control points of snakePath's curve, x and y in turn
+func snakeCurve(x1, y1, x2, y2 float64) [8]float64 {
+    dx, dy := x2-x1, y2-y1
+    length := math.Hypot(dx, dy)
+    nx, ny := -dy/length*40, dx/length*40 // sideways wiggle
+    return [8]float64{x1, y1, x1 + dx/3 + nx, y1 + dy/3 + ny, x1 + 2*dx/3 - nx, y1 + 2*dy/3 - ny, x2, y2}
+}
+
+// ladderPath is two rails with rungs about every half cell
+func ladderPath(x1, y1, x2, y2 float64) string {
+    var d []string
+    for _, l := range ladderLines(x1, y1, x2, y2) {
+        d = append(d, fmt.Sprintf("M %.0f %.0f L %.0f %.0f", l[0], l[1], l[2], l[3]))
+    }
+    return strings.Join(d, " ")
+}
+
+// ladderLines is ladderPath's straight lines as x1, y1, x2, y2: the two
+// rails, then the rungs
+func ladderLines(x1, y1, x2, y2 float64) [][4]float64 {
+    dx, dy := x2-x1, y2-y1
+    length := math.Hypot(dx, dy)
+    ox, oy := -dy/length*15, dx/length*15 // half the ladder's width
+    lines := [][4]float64{{x1 + ox, y1 + oy, x2 + ox, y2 + oy}, {x1 - ox, y1 - oy, x2 - ox, y2 - oy}}
+    rungs := int(length / (cellUnits / 2))
+    for i := 1; i < rungs; i++ {
+        t := float64(i) / float64(rungs)
+        x, y := x1+dx*t, y1+dy*t
+        lines = append(lines, [4]float64{x + ox, y + oy, x - ox, y - oy})
+    }
+    return lines
+}
+
+// runExport writes a printable page or a Graphviz graph for a board file
+// (the standard board if none is given)
+func runExport(args []string, out io.Writer) error {
+    fs := flag.NewFlagSet("export", flag.ContinueOnError)
+    paperName := fs.String("paper", "a4", "page size: a4 or letter")
+    format := fs.String("format", "html", "html for a printable page, dot for a Graphviz graph")
+    outPath := fs.String("o", "", "write the export here instead of stdout")
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+    paper, ok := papers[*paperName]
+    if !ok {
+        return fmt.Errorf("unknown paper %q (want a4 or letter)", *paperName)
+    }
+    var write func(io.Writer, string, Board) error
+    switch *format {
+    case "html":
+        write = func(w io.Writer, title string, b Board) error { return writePrintHTML(w, title, b, paper) }
+    case "dot":
+        write = func(w io.Writer, _ string, b Board) error { return ExportDOT(b, w) }
+    default:
+        return fmt.Errorf("unknown format %q (want html or dot)", *format)
+    }
+    title, b := "Snakes & Ladders", CreateStandardBoard()
+    switch fs.NArg() {
+    case 0:
+    case 1:
+        c, err := LoadBoardConfig(fs.Arg(0))
+        if err != nil {
+            return err
+        }
+        if b, err = c.Build(); err != nil {
+            return fmt.Errorf("%s: %w", fs.Arg(0), err)
+        }
+        if c.Name != "" {
+            title = c.Name
+        }
+    default:
+        return errors.New("usage: export [-format html|dot] [-paper a4|letter] [-o file] [board-file]")
+    }
+    if *outPath == "" {
+        return write(out, title, b)
+    }
+    f, err := os.Create(*outPath)
+    if err != nil {
+        return err
+    }
+    if err := write(f, title, b); err != nil {
+        f.Close()
+        return err
+    }
+    return f.Close()
+}
@@ -0,0 +1,371 @@
+package main
+
+import (
+    "cmp"
+    "errors"
+    "fmt"
+    "io"
+    "path/filepath"
+    "slices"
+    "sort"
+    "strings"
+    "sync"
+    "unicode"
+)
+
+// PlayerProfile is what is kept about a player from game to game, by
+// name: how they like to look and how they have done
+type PlayerProfile struct {
+    Name         string            `json:"name"`
+    Color        string            `json:"color,omitempty"` // one of profileColors; "" for their seat's
+    Token        string            `json:"token,omitempty"` // the character marking them on the board; "" for their initial
+    Games        int               `json:"games"`           // finished games, won, lost or drawn
+    Wins         int               `json:"wins"`
+    Stats        PlayerStats       `json:"stats"`                  // totals over those games
+    Rating       float64           `json:"rating,omitempty"`       // from the ladder after their last rated game
+    Awards       map[AwardKind]int `json:"awards,omitempty"`       // how often they won each award
+    Achievements []string          `json:"achievements,omitempty"` // earned, oldest first
+}
+
+// ProfileStore keeps player profiles by name
+type ProfileStore interface {
+    Load(name string) (PlayerProfile, error) // a fresh profile if there is none
+    // Update applies fn to name's profile and keeps it, unless fn fails
+    Update(name string, fn func(*PlayerProfile) error) (PlayerProfile, error)
+    List() ([]PlayerProfile, error) // by name
+}
+
+// openProfiles is where players' profiles are kept alongside the games a
+// -store spec names: in memory for the memory store, in players.json in a
+// file store's directory, and otherwise in the config directory
+func openProfiles(spec string) (ProfileStore, error) {
+    name, arg, _ := strings.Cut(spec, ":")
+    switch {
+    case name == "memory":
+        return newMemoryProfiles(), nil
+    case name == "file" && arg != "":
+        return fileProfiles{filepath.Join(arg, "players.json")}, nil
+    }
+    path, err := appPath("players.json")
+    if err != nil {
+        return nil, err
+    }
+    return fileProfiles{path}, nil
+}
+
+// profileColors name ansiPlayerColors, in the same order
+var profileColors = []string{"cyan", "magenta", "yellow", "blue", "bright-cyan", "bright-magenta", "bright-yellow", "bright-blue"}
+
+// checkToken says whether mark can stand for a player on the board: one
+// printable character a column wide, and not one the board uses itself
+func checkToken(mark string) error {
+    r := []rune(mark)
+    if len(r) != 1 || !unicode.IsPrint(r[0]) || unicode.IsSpace(r[0]) || r[0] >= 0x1100 || r[0] == '*' || r[0] == '?' {
+        return fmt.Errorf("token %q must be a single narrow character other than * and ?", mark)
+    }
+    return nil
+}
+
+// achievement is a milestone a profile reaches once
+type achievement struct {
+    name    string
+    reached func(p PlayerProfile) bool
+}
+
+var achievements = []achievement{
+    {"first_win", func(p PlayerProfile) bool { return p.Wins >= 1 }},
+    {"ten_wins", func(p PlayerProfile) bool { return p.Wins >= 10 }},
+    {"regular", func(p PlayerProfile) bool { return p.Games >= 25 }},
+    {"veteran", func(p PlayerProfile) bool { return p.Games >= 100 }},
+    {"snake_charmer", func(p PlayerProfile) bool { return p.Stats.Snakes >= 50 }},
+    {"mountaineer", func(p PlayerProfile) bool { return p.Stats.Ladders >= 50 }},
+    {"marathon", func(p PlayerProfile) bool { return p.Stats.Traveled >= 5000 }},
+    {"lucky_streak", func(p PlayerProfile) bool { return p.Awards[AwardLuckiest] >= 5 }},
+    {"comeback_kid", func(p PlayerProfile) bool { return p.Awards[AwardComeback] >= 3 }},
+}
+
+// recordGame adds a finished game to p, returning the achievements it
+// earned
+func (p *PlayerProfile) recordGame(res GameResult, stats PlayerStats, awards []Award) []string {
+    p.Games++
+    for _, pr := range res.Players {
+        if pr.Name == p.Name && (res.Winner == p.Name || res.Team != "" && pr.Team == res.Team) {
+            p.Wins++
+        }
+    }
+    p.Stats.Rolls += stats.Rolls
+    for i, n := range stats.Faces {
+        p.Stats.Faces[i] += n
+    }
+    p.Stats.Snakes += stats.Snakes
+    p.Stats.Ladders += stats.Ladders
+    p.Stats.Traveled += stats.Traveled
+    for _, a := range awards {
+        if a.Player != p.Name {
+            continue
+        }
+        if p.Awards == nil {
+            p.Awards = map[AwardKind]int{}
+        }
+        p.Awards[a.Kind]++
+    }
+    var earned []string
+    for _, a := range achievements {
+        if !p.has(a.name) && a.reached(*p) {
+            p.Achievements = append(p.Achievements, a.name)
+            earned = append(earned, a.name)
+        }
+    }
+    return earned
+}
+
+func (p PlayerProfile) has(achievement string) bool {
+    for _, a := range p.Achievements {
+        if a == achievement {
+            return true
+        }
+    }
+    return false
+}
+
+// gameStats tallies each player's PlayerStats from the last game in evs
+func gameStats(evs []Event) map[string]PlayerStats {
+    stats := map[string]PlayerStats{}
+    for _, ev := range evs {
+        st := stats[ev.Player]
+        switch ev.Kind {
+        case EventStart:
+            stats = map[string]PlayerStats{}
+            continue
+        case EventRoll:
+            st.Rolls++
+            if ev.Roll >= 1 && ev.Roll <= 6 {
+                st.Faces[ev.Roll-1]++
+            }
+        case EventMove:
+            st.Traveled += max(ev.To-ev.From, ev.From-ev.To)
+        case EventSnake:
+            st.Snakes++
+        case EventLadder:
+            st.Ladders++
+        default:
+            continue
+        }
+        stats[ev.Player] = st
+    }
+    return stats
+}
+
+// updateProfiles adds a finished game, whose events are evs, to the
+// profile of everyone who played it, copying each rating from the ladder.
+// It reports the achievements earned to out.
+func updateProfiles(store ProfileStore, res GameResult, evs []Event, out io.Writer, msgs Catalog) error {
+    ladder, err := loadRatings()
+    if err != nil {
+        return err
+    }
+    stats, awards := gameStats(evs), computeAwards(evs)
+    var errs []error
+    for _, pr := range res.Players {
+        var earned []string
+        _, err := store.Update(pr.Name, func(p *PlayerProfile) error {
+            earned = p.recordGame(res, stats[pr.Name], awards)
+            if rt, ok := ladder[pr.Name]; ok {
+                p.Rating = rt.Rating
+            }
+            return nil
+        })
+        if err != nil {
+            errs = append(errs, fmt.Errorf("%s: %w", pr.Name, err))
+            continue
+        }
+        for _, a := range earned {
+            fmt.Fprintln(out, msgs.T(MsgAchievement, pr.Name, a))
+        }
+    }
+    return errors.Join(errs...)
+}
+
+// loadProfiles is the profiles of names that have one
+func loadProfiles(store ProfileStore, names []string) (map[string]PlayerProfile, error) {
+    profiles := map[string]PlayerProfile{}
+    for _, n := range names {
+        p, err := store.Load(n)
+        if err != nil {
+            return profiles, err
+        }
+        profiles[n] = p
+    }
+    return profiles, nil
+}
+
+// runPlayer lists the profiles in store, shows one, or with
+// "NAME color C" and "NAME token T" sets how a player looks
+func runPlayer(args []string, store ProfileStore, msgs Catalog, out io.Writer) error {
+    const usage = "usage: player [NAME [color COLOR|none] [token MARK|none]]"
+    if len(args) == 0 {
+        all, err := store.List()
+        if err != nil {
+            return err
+        }
+        if len(all) == 0 {
+            fmt.Fprintln(out, msgs.T(MsgNoProfiles))
+            return nil
+        }
+        fmt.Fprintln(out, msgs.T(MsgProfileColumns))
+        for _, p := range all {
+            fmt.Fprintf(out, "%-20s %6d %6d %7.0f  %-14s %s\n", p.Name, p.Games, p.Wins, p.Rating, cmp.Or(p.Color, "-"), cmp.Or(p.Token, "-"))
+        }
+        return nil
+    }
+    name, sets := args[0], args[1:]
+    if len(sets)%2 != 0 {
+        return errors.New(usage)
+    }
+    for i := 0; i < len(sets); i += 2 {
+        switch v := sets[i+1]; sets[i] {
+        case "color":
+            if v != "none" && !slices.Contains(profileColors, v) {
+                return fmt.Errorf("unknown color %q (want one of %s, or none)", v, strings.Join(profileColors, ", "))
+            }
+        case "token":
+            if v != "none" {
+                if err := checkToken(v); err != nil {
+                    return err
+                }
+            }
+        default:
+            return errors.New(usage)
+        }
+    }
+    p, err := store.Load(name)
+    if len(sets) > 0 {
+        p, err = store.Update(name, func(p *PlayerProfile) error {
+            for i := 0; i < len(sets); i += 2 {
+                v := sets[i+1]
+                if v == "none" {
+                    v = ""
+                }
+                if sets[i] == "color" {
+                    p.Color = v
+                } else {
+                    p.Token = v
+                }
+            }
+            return nil
+        })
+    }
+    if err != nil {
+        return err
+    }
+    printProfile(out, msgs, p)
+    return nil
+}
+
+func printProfile(out io.Writer, msgs Catalog, p PlayerProfile) {
+    fmt.Fprint(out, msgs.T(MsgProfileRecord, p.Name, p.Games, p.Wins))
+    if p.Rating != 0 {
+        fmt.Fprint(out, msgs.T(MsgProfileRating, p.Rating))
+    }
+    fmt.Fprintln(out)
+    if p.Color != "" || p.Token != "" {
+        fmt.Fprintln(out, msgs.T(MsgProfileLook, cmp.Or(p.Token, msgs.T(MsgProfileInitial)), cmp.Or(p.Color, msgs.T(MsgProfileSeatColor))))
+    }
+    if s := p.Stats; s.Rolls > 0 {
+        fmt.Fprintln(out, msgs.T(MsgProfileStats, s.Rolls, s.Traveled, s.Snakes, s.Ladders))
+    }
+    if len(p.Awards) > 0 {
+        var awards []string
+        for k, n := range p.Awards {
+            awards = append(awards, fmt.Sprintf("%s ×%d", k, n))
+        }
+        sort.Strings(awards)
+        fmt.Fprintln(out, msgs.T(MsgProfileAwards, strings.Join(awards, ", ")))
+    }
+    if len(p.Achievements) > 0 {
+        fmt.Fprintln(out, msgs.T(MsgProfileAchievements, strings.Join(p.Achievements, ", ")))
+    }
+}
+
+// fileProfiles keeps profiles in one JSON file, changed under its lock
+type fileProfiles struct {
+    path string
+}
+
+func (f fileProfiles) Load(name string) (PlayerProfile, error) {
+    all := map[string]PlayerProfile{}
+    if err := readJSONFile(f.path, &all); err != nil {
+        return PlayerProfile{}, err
+    }
+    return withName(all[name], name), nil
+}
+
+func (f fileProfiles) Update(name string, fn func(*PlayerProfile) error) (PlayerProfile, error) {
+    all := map[string]PlayerProfile{}
+    var p PlayerProfile
+    err := updateJSONFile(f.path, &all, func() error {
+        p = withName(all[name], name)
+        if err := fn(&p); err != nil {
+            return err
+        }
+        all[name] = p
+        return nil
+    })
+    return p, err
+}
+
+func (f fileProfiles) List() ([]PlayerProfile, error) {
+    all := map[string]PlayerProfile{}
+    if err := readJSONFile(f.path, &all); err != nil {
+        return nil, err
+    }
+    return sortedProfiles(all), nil
+}
+
+// memoryProfiles keeps profiles for as long as the process runs
+type memoryProfiles struct {
+    mu       sync.Mutex
+    profiles map[string]PlayerProfile
+}
+
+func newMemoryProfiles() *memoryProfiles {
+    return &memoryProfiles{profiles: map[string]PlayerProfile{}}
+}
+
+func (m *memoryProfiles) Load(name string) (PlayerProfile, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    return withName(m.profiles[name], name), nil
+}
+
+func (m *memoryProfiles) Update(name string, fn func(*PlayerProfile) error) (PlayerProfile, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    p := withName(m.profiles[name], name)
+    if err := fn(&p); err != nil {
+        return PlayerProfile{}, err
+    }
+    m.profiles[name] = p
+    return p, nil
+}
+
+func (m *memoryProfiles) List() ([]PlayerProfile, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    return sortedProfiles(m.profiles), nil
+}
+
+// withName is p, a fresh profile when it is the zero value, as name's
+func withName(p PlayerProfile, name string) PlayerProfile {
+    p.Name = name
+    return p
+}
+
+func sortedProfiles(m map[string]PlayerProfile) []PlayerProfile {
+    all := make([]PlayerProfile, 0, len(m))
+    for _, p := range m {
+        all = append(all, p)
+    }
+    sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+    return all
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "io"
+    "math/rand"
+    "reflect"
+    "strconv"
+)
+
+// Property testing: RandomBoard, RandomGameState and RandomRolls draw
+// valid inputs from a seeded source, and CheckMoves plays rolls through
+// ApplyMove asserting CheckInvariants after each, so a rule change can be
+// fuzzed over thousands of boards and positions (see `fuzz`).
+
+// randomScripts are square scripts RandomBoard picks from
+var randomScripts = []string{"forward roll", "back 3", "if roll == 6 then skip 1 else again", "goto square - 4 * (rank - 1)", "give boost", "swap last"}
+
+// RandomBoard is a valid board of 10 to 100 squares with snakes, ladders
+// and now and then an effect square, item or script
+func RandomBoard(rng *rand.Rand) Board {
+    for {
+        size := 10 + rng.Intn(91)
+        bb := NewBoardBuilder(size)
+        used := map[int]bool{1: true, size: true}
+        free := func(lo, hi int) (int, bool) {
+            for try := 0; try < 20; try++ {
+                if sq := lo + rng.Intn(hi-lo+1); !used[sq] {
+                    used[sq] = true
+                    return sq, true
+                }
+            }
+            return 0, false
+        }
+        for i := rng.Intn(size/8 + 1); i > 0; i-- {
+            if from, ok := free(3, size-1); ok {
+                bb.AddSnake(from, 1+rng.Intn(from-1))
+            }
+        }
+        for i := rng.Intn(size/8 + 1); i > 0; i-- {
+            if from, ok := free(2, size-2); ok {
+                bb.AddLadder(from, from+1+rng.Intn(size-from))
+            }
+        }
+        for i := rng.Intn(size/20 + 1); i > 0; i-- {
+            sq, ok := free(2, size-1)
+            if !ok {
+                continue
+            }
+            switch k := rng.Intn(len(SpecialKinds) + 2); {
+            case k < len(SpecialKinds):
+                bb.AddSpecial(sq, SpecialKinds[k])
+            case k == len(SpecialKinds):
+                bb.AddItem(sq, ItemKinds[rng.Intn(len(ItemKinds))])
+            default:
+                bb.AddScript(sq, randomScripts[rng.Intn(len(randomScripts))])
+            }
+        }
+        if b, err := bb.Build(); err == nil {
+            return b
+        }
+    }
+}
+
+// RandomRules is a set of optional rules that can be played together on b
+func RandomRules(rng *rand.Rand, b Board) Rules {
+    var r Rules
+    for _, i := range rng.Perm(len(RuleNames)) {
+        if rng.Intn(3) > 0 {
+            continue
+        }
+        try := r
+        if try.Enable(RuleNames[i]) == nil && try.Check(b) == nil {
+            r = try
+        }
+    }
+    r.Capture = []Capture{CaptureOff, CaptureToStart, CaptureToCheckpoint}[rng.Intn(3)]
+    if rng.Intn(4) == 0 {
+        r.Tokens = 2
+    }
+    return r
+}
+
+// RandomGameState is a game of one to four players on b, under
+// RandomRules, some random way into play: every state it returns is
+// one the game can reach
+func RandomGameState(rng *rand.Rand, b Board) GameState {
+    names := []string{"Ann", "Ben", "Cat", "Dev"}[:1+rng.Intn(4)]
+    gs := newGameState(b, names)
+    gs.setRules(RandomRules(rng, b))
+    gs.RandState, gs.Dice = rng.Uint64(), NewDiceStream(rng.Uint64())
+    placeAtStart(&gs)
+    setupTokens(&gs, gs.Rules.Tokens)
+    for n := rng.Intn(40); n > 0; n-- {
+        if _, ongoing := checkOutcome(gs).(Ongoing); !ongoing {
+            break
+        }
+        dr, _ := drawRoll(&gs.Dice, gs.Rules, 1+rng.Intn(2))
+        gs = applyMove(gs, dr)
+    }
+    return gs
+}
+
+// RandomRolls is n rolls of a fair die
+func RandomRolls(rng *rand.Rand, n int) []DieRoll {
+    rolls := make([]DieRoll, n)
+    for i := range rolls {
+        rolls[i] = DieRoll{1 + rng.Intn(6)}
+    }
+    return rolls
+}
+
+// CheckInvariants reports the first rule of the game gs breaks: every
+// token on the board (or waiting off it under enter_on_six), a valid
+// player to move and token to move, no turns owed, and at most one
+// winner, who is home
+func CheckInvariants(gs GameState) error {
+    if err := checkState(gs); err != nil {
+        return err
+    }
+    final := gs.Board.FinalSquare
+    teams, home := false, 0
+    for _, p := range gs.Players {
+        if p.SkipTurns < 0 {
+            return fmt.Errorf("%s has %d turns to skip", p.Name, p.SkipTurns)
+        }
+        if len(p.Tokens) > 0 && p.Position != p.Tokens[p.Active] {
+            return fmt.Errorf("%s is on %d but their token %d is on %d", p.Name, p.Position.Index, p.Active+1, p.Tokens[p.Active].Index)
+        }
+        teams = teams || p.Team != ""
+        if p.home(final) {
+            home++
+        }
+    }
+    if !teams && home > 1 {
+        return fmt.Errorf("%d players have won", home)
+    }
+    if w, ok := checkOutcome(gs).(Win); ok && !w.Winner.home(final) {
+        return fmt.Errorf("%s won without getting home", w.Winner.Name)
+    }
+    return nil
+}
+
+// CheckMoves plays rolls from gs until they run out or the game ends,
+// checking after each that ApplyMove left the state it was given alone
+// and reached one satisfying CheckInvariants
+func CheckMoves(gs GameState, rolls []DieRoll) error {
+    if err := CheckInvariants(gs); err != nil {
+        return fmt.Errorf("before any roll: %w", err)
+    }
+    for i, dr := range rolls {
+        if _, ongoing := checkOutcome(gs).(Ongoing); !ongoing {
+            return nil
+        }
+        before := append([]Player(nil), gs.Players...)
+        next, err := ApplyMove(gs, dr)
+        if err != nil {
+            return fmt.Errorf("roll %d (%d): %w", i+1, dr.Value, err)
+        }
+        if !reflect.DeepEqual(before, gs.Players) {
+            return fmt.Errorf("roll %d (%d): ApplyMove modified its input", i+1, dr.Value)
+        }
+        if err := CheckInvariants(next); err != nil {
+            return fmt.Errorf("roll %d (%d): %w", i+1, dr.Value, err)
+        }
+        gs = next
+    }
+    return nil
+}
+
+// runFuzz implements `fuzz GAMES`: CheckMoves over games seeded seed..,
+// each a RandomGameState on a RandomBoard, naming the seed of the first
+// that breaks an invariant
+func runFuzz(args []string, seed int64, out io.Writer) error {
+    games := 0
+    if len(args) == 1 {
+        games, _ = strconv.Atoi(args[0])
+    }
+    if games < 1 {
+        return errors.New("usage: fuzz GAMES")
+    }
+    for g := int64(0); g < int64(games); g++ {
+        rng := rand.New(rand.NewSource(seed + g))
+        gs := RandomGameState(rng, RandomBoard(rng))
+        if err := CheckMoves(gs, RandomRolls(rng, 1000)); err != nil {
+            return fmt.Errorf("seed %d: %w", seed+g, err)
+        }
+    }
+    fmt.Fprintf(out, "fuzz: %d games from seed %d, invariants held\n", games, seed)
+    return nil
+}
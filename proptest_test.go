@@ -0,0 +1,73 @@
+package main
+
+import (
+    "math/rand"
+    "testing"
+)
+
+func TestRandomGamesKeepInvariants(t *testing.T) {
+    games := 500
+    if testing.Short() {
+        games = 50
+    }
+    for seed := int64(0); seed < int64(games); seed++ {
+        rng := rand.New(rand.NewSource(seed))
+        b := RandomBoard(rng)
+        gs := RandomGameState(rng, b)
+        if err := CheckInvariants(gs); err != nil {
+            t.Fatalf("seed %d: RandomGameState: %v", seed, err)
+        }
+        if err := CheckMoves(gs, RandomRolls(rng, 1000)); err != nil {
+            t.Fatalf("seed %d: %v", seed, err)
+        }
+    }
+}
+
+func TestCheckInvariantsCatchesBrokenStates(t *testing.T) {
+    start := func() GameState {
+        gs := newGameState(CreateStandardBoard(), []string{"Alice", "Bob"})
+        placeAtStart(&gs)
+        return gs
+    }
+    tests := []struct {
+        name  string
+        spoil func(gs *GameState)
+    }{
+        {"off the board", func(gs *GameState) { gs.Players[1].Position = BoardPos{101} }},
+        {"no such player to move", func(gs *GameState) { gs.CurrentPlayerIndex = 2 }},
+        {"two winners", func(gs *GameState) {
+            gs.Players[0].Position = gs.Board.FinalSquare
+            gs.Players[1].Position = gs.Board.FinalSquare
+        }},
+        {"turns owed", func(gs *GameState) { gs.Players[0].SkipTurns = -1 }},
+    }
+    if err := CheckInvariants(start()); err != nil {
+        t.Fatalf("a new game: %v", err)
+    }
+    for _, tt := range tests {
+        gs := start()
+        tt.spoil(&gs)
+        if err := CheckInvariants(gs); err == nil {
+            t.Errorf("%s: CheckInvariants found nothing wrong", tt.name)
+        }
+    }
+}
+
+// FuzzApply plays the fuzzed rolls, taken mod 6, from a random game
+// seeded with seed, checking every state on the way
+func FuzzApply(f *testing.F) {
+    f.Add(int64(1), []byte{0, 1, 2, 3, 4, 5})
+    f.Add(int64(42), []byte{5, 5, 5, 5, 5, 5, 5, 5})
+    f.Add(int64(7), []byte{2})
+    f.Fuzz(func(t *testing.T, seed int64, rolls []byte) {
+        rng := rand.New(rand.NewSource(seed))
+        gs := RandomGameState(rng, RandomBoard(rng))
+        dice := make([]DieRoll, len(rolls))
+        for i, r := range rolls {
+            dice[i] = DieRoll{1 + int(r)%6}
+        }
+        if err := CheckMoves(gs, dice); err != nil {
+            t.Fatal(err)
+        }
+    })
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+    "errors"
+    "math"
+    "net"
+    "net/http"
+    "strconv"
+    "sync"
+    "time"
+)
+
+var ErrRateLimited = errors.New("too many requests; slow down")
+
+// maxRequestBody caps what the web server reads of any request body
+const maxRequestBody = 16 << 10
+
+// rateLimiter is a token bucket per client: each may make burst requests
+// in a row, then one every per. A nil rateLimiter allows everything.
+type rateLimiter struct {
+    clock Clock
+    per   time.Duration
+    burst int
+
+    mu      sync.Mutex
+    clients map[string]*bucket
+}
+
+type bucket struct {
+    tokens float64
+    last   time.Time
+}
+
+// newRateLimiter allows n requests per period, up to n of them at once;
+// with n 0 it is nil, allowing everything
+func newRateLimiter(clock Clock, n int, period time.Duration) *rateLimiter {
+    if n <= 0 {
+        return nil
+    }
+    return &rateLimiter{clock: clock, per: period / time.Duration(n), burst: n, clients: map[string]*bucket{}}
+}
+
+// allow takes one of client's tokens, or says how long until it has one
+func (l *rateLimiter) allow(client string) (wait time.Duration, ok bool) {
+    if l == nil {
+        return 0, true
+    }
+    now := l.clock.Now()
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    b := l.clients[client]
+    if b == nil {
+        if len(l.clients) >= 4096 {
+            l.forget(now)
+        }
+        b = &bucket{tokens: float64(l.burst), last: now}
+        l.clients[client] = b
+    }
+    b.tokens = math.Min(float64(l.burst), b.tokens+float64(now.Sub(b.last))/float64(l.per))
+    b.last = now
+    if b.tokens < 1 {
+        return time.Duration((1 - b.tokens) * float64(l.per)), false
+    }
+    b.tokens--
+    return 0, true
+}
+
+// forget drops the clients whose buckets have filled up again, so they
+// cost nothing to keep track of; l.mu must be held
+func (l *rateLimiter) forget(now time.Time) {
+    full := l.per * time.Duration(l.burst)
+    for client, b := range l.clients {
+        if now.Sub(b.last) >= full {
+            delete(l.clients, client)
+        }
+    }
+}
+
+// limited is h refusing clients over l's rate with 429 Too Many Requests
+func limited(l *rateLimiter, h http.HandlerFunc) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        if wait, ok := l.allow(clientOf(r)); !ok {
+            w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+            writeError(w, ErrRateLimited)
+            return
+        }
+        h(w, r)
+    }
+}
+
+// clientOf is who sent r, as far as rate limits go: its address without
+// the port. Forwarding headers are ignored, since clients can forge them.
+func clientOf(r *http.Request) string {
+    host, _, err := net.SplitHostPort(r.RemoteAddr)
+    if err != nil {
+        return r.RemoteAddr
+    }
+    return host
+}
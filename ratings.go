@@ -0,0 +1,115 @@
+package main
+
+import (
+    "fmt"
+    "io"
+    "math"
+    "sort"
+)
+
+const (
+    initialRating = 1500
+    eloK          = 32
+)
+
+// Rating is a player's standing on the local ladder
+type Rating struct {
+    Rating float64 `json:"rating"`
+    Games  int     `json:"games"`
+    Wins   int     `json:"wins"`
+}
+
+// Ratings maps player name to rating
+type Ratings map[string]Rating
+
+func (r Ratings) get(name string) Rating {
+    if rt, ok := r[name]; ok {
+        return rt
+    }
+    return Rating{Rating: initialRating}
+}
+
+// expectedScore is Elo's probability that a rated ra beats rb
+func expectedScore(ra, rb float64) float64 {
+    return 1 / (1 + math.Pow(10, (rb-ra)/400))
+}
+
+// RecordGame updates the ladder for a finished game. A multiplayer game
+// counts as the winner beating each other player head to head; losers
+// don't score against each other. Every pairing uses the pre-game ratings.
+func (r Ratings) RecordGame(winner string, players []string) {
+    before := make(map[string]float64, len(players))
+    for _, p := range players {
+        before[p] = r.get(p).Rating
+    }
+    delta := make(map[string]float64, len(players))
+    for _, p := range players {
+        if p == winner {
+            continue
+        }
+        gain := eloK * (1 - expectedScore(before[winner], before[p]))
+        delta[winner] += gain
+        delta[p] -= gain
+    }
+    for _, p := range players {
+        rt := r.get(p)
+        rt.Rating += delta[p]
+        rt.Games++
+        if p == winner {
+            rt.Wins++
+        }
+        r[p] = rt
+    }
+}
+
+// updateRatings applies a finished game to the stored ladder
+func updateRatings(res GameResult) error {
+    path, err := appPath("ratings.json")
+    if err != nil {
+        return err
+    }
+    names := make([]string, len(res.Players))
+    for i, p := range res.Players {
+        names[i] = p.Name
+    }
+    r := Ratings{}
+    return updateJSONFile(path, &r, func() error {
+        r.RecordGame(res.Winner, names)
+        return nil
+    })
+}
+
+func loadRatings() (Ratings, error) {
+    r := Ratings{}
+    path, err := appPath("ratings.json")
+    if err != nil {
+        return r, err
+    }
+    if err := readJSONFile(path, &r); err != nil {
+        return Ratings{}, err
+    }
+    return r, nil
+}
+
+// printLadder lists players best first
+func printLadder(out io.Writer, r Ratings) {
+    names := make([]string, 0, len(r))
+    for n := range r {
+        names = append(names, n)
+    }
+    sort.Slice(names, func(i, j int) bool {
+        if r[names[i]].Rating != r[names[j]].Rating {
+            return r[names[i]].Rating > r[names[j]].Rating
+        }
+        return names[i] < names[j]
+    })
+    if len(names) == 0 {
+        fmt.Fprintln(out, "no rated games yet")
+        return
+    }
+    fmt.Fprintf(out, "%-4s %-20s %7s %6s %6s\n", "#", "player", "rating", "games", "wins")
+    for i, n := range names {
+        rt := r[n]
+        fmt.Fprintf(out, "%-4d %-20s %7.0f %6d %6d\n", i+1, n, rt.Rating, rt.Games, rt.Wins)
+    }
+}
@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package main
+
+import "errors"
+
+// makeRaw has no terminal control to use here, so -keys reports an error
+func makeRaw(fd int) (func(), error) {
+    return nil, errors.New("not supported on this system")
+}
@@ -0,0 +1,26 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package main
+
+import (
+    "syscall"
+    "unsafe"
+)
+
+// makeRaw turns off line buffering and echo on the terminal fd, leaving
+// signals alone so Ctrl-C and Ctrl-Z still pause the game
+func makeRaw(fd int) (func(), error) {
+    var old syscall.Termios
+    if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), ioctlGetTermios, uintptr(unsafe.Pointer(&old))); errno != 0 {
+        return nil, errno
+    }
+    raw := old
+    raw.Lflag &^= syscall.ICANON | syscall.ECHO
+    raw.Cc[syscall.VMIN], raw.Cc[syscall.VTIME] = 1, 0
+    if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), ioctlSetTermios, uintptr(unsafe.Pointer(&raw))); errno != 0 {
+        return nil, errno
+    }
+    return func() {
+        syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), ioctlSetTermios, uintptr(unsafe.Pointer(&old)))
+    }, nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+)
+
+// seatPresence keeps network players' seats through dropped connections.
+// A seat counts as connected while any of its player's event streams is
+// open; once the last one closes they have grace to attach another
+// before drop takes them out of the game. With no grace, seats are kept
+// however long their players are away.
+type seatPresence struct {
+    clock Clock
+    grace time.Duration
+    drop  func(PlayerID)
+
+    mu      sync.Mutex
+    streams map[PlayerID]int
+    away    map[PlayerID]chan struct{} // closed when the player is back
+}
+
+func newSeatPresence(clock Clock, grace time.Duration, drop func(PlayerID)) *seatPresence {
+    return &seatPresence{clock: clock, grace: grace, drop: drop, streams: map[PlayerID]int{}, away: map[PlayerID]chan struct{}{}}
+}
+
+// attach counts a stream for id, calling off a pending drop; detach must
+// be called once it closes
+func (p *seatPresence) attach(id PlayerID) (detach func()) {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    if back, ok := p.away[id]; ok {
+        close(back)
+        delete(p.away, id)
+    }
+    p.streams[id]++
+    var once sync.Once
+    return func() { once.Do(func() { p.detach(id) }) }
+}
+
+func (p *seatPresence) detach(id PlayerID) {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    if p.streams[id]--; p.streams[id] > 0 || p.grace <= 0 {
+        return
+    }
+    back := make(chan struct{})
+    p.away[id] = back
+    go func() {
+        select {
+        case <-p.clock.After(p.grace):
+        case <-back:
+            return
+        }
+        p.mu.Lock()
+        gone := p.away[id] == back
+        if gone {
+            delete(p.away, id)
+        }
+        p.mu.Unlock()
+        if gone {
+            p.drop(id)
+        }
+    }()
+}
+
+// streamPos is how far through a game's events a client got, as the
+// event IDs handed to it read: "GAME-ID/N" after its Nth event. Resuming
+// from a position in another game starts from the beginning.
+func streamPos(gameID, lastID string) int {
+    id, n, ok := strings.Cut(lastID, "/")
+    if !ok || id != gameID {
+        return 0
+    }
+    seen, err := strconv.Atoi(n)
+    if err != nil || seen < 0 {
+        return 0
+    }
+    return seen
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+    "fmt"
+    "io"
+    "sort"
+    "strings"
+)
+
+// Theme is a look for the board: the mark each kind of square carries
+// and whether the cells are boxed in. Every mark in a theme takes up the
+// same number of terminal columns, Plain included.
+type Theme struct {
+    Name string
+    // the marks, Plain being an ordinary square's
+    Snake, Ladder, SkipTurn, ExtraRoll string
+    Teleport, Swap, Item, Script       string
+    Plain                              string
+
+    Width  int // columns each mark takes up
+    Boxed  bool
+    Linear bool // tell the board in sentences instead of drawing it
+
+    tokens map[string]rune // players marked by their profile's token rather than their initial
+    msgs   Catalog         // the language of any words it draws
+}
+
+var (
+    // classicTheme marks v a snake's head, ^ a ladder's foot, z skip turn,
+    // + extra roll, @ teleport, ~ swap, $ an item and ! a square script,
+    // in ASCII boxes
+    classicTheme = Theme{Name: "classic", Snake: "v", Ladder: "^", SkipTurn: "z", ExtraRoll: "+", Teleport: "@", Swap: "~", Item: "$", Script: "!", Plain: " ", Width: 1, Boxed: true}
+    emojiTheme   = Theme{Name: "emoji", Snake: "🐍", Ladder: "🪜", SkipTurn: "💤", ExtraRoll: "🎲", Teleport: "🌀", Swap: "🔄", Item: "🎁", Script: "📜", Plain: "  ", Width: 2, Boxed: true}
+    // minimalTheme is classic's marks without the boxes
+    minimalTheme = Theme{Name: "minimal", Snake: "v", Ladder: "^", SkipTurn: "z", ExtraRoll: "+", Teleport: "@", Swap: "~", Item: "$", Script: "!", Plain: " ", Width: 1}
+    // linearTheme draws nothing, for screen readers
+    linearTheme = Theme{Name: "linear", Linear: true}
+)
+
+var themes = map[string]Theme{"classic": classicTheme, "emoji": emojiTheme, "minimal": minimalTheme, "linear": linearTheme}
+
+// themeNames lists themes for usage messages
+func themeNames() []string {
+    var names []string
+    for n := range themes {
+        names = append(names, n)
+    }
+    sort.Strings(names)
+    return names
+}
+
+func ParseTheme(name string) (Theme, error) {
+    if name == "" {
+        return classicTheme, nil
+    }
+    t, ok := themes[name]
+    if !ok {
+        return Theme{}, fmt.Errorf("unknown theme %q (want one of %s)", name, strings.Join(themeNames(), ", "))
+    }
+    return t, nil
+}
+
+// mark is what t shows on sq
+func (t Theme) mark(sq Square) string {
+    switch sq.(type) {
+    case Snake:
+        return t.Snake
+    case Ladder:
+        return t.Ladder
+    case SkipTurn:
+        return t.SkipTurn
+    case ExtraRoll:
+        return t.ExtraRoll
+    case Teleport:
+        return t.Teleport
+    case Swap:
+        return t.Swap
+    case ItemSquare:
+        return t.Item
+    case ScriptSquare:
+        return t.Script
+    }
+    return t.Plain
+}
+
+// withTokens is t marking each player in tokens with their own character
+func (t Theme) withTokens(tokens map[string]rune) Theme {
+    t.tokens = tokens
+    return t
+}
+
+// withMessages is t putting any words it draws in msgs' language
+func (t Theme) withMessages(msgs Catalog) Theme {
+    t.msgs = msgs
+    return t
+}
+
+// RenderBoard draws b with each cell as its number, its mark and the
+// initial or token of any player on it (* for several). A zero Theme
+// draws classic.
+func (t Theme) RenderBoard(out io.Writer, b Board, players []Player) {
+    if t.Name == "" {
+        t = classicTheme.withTokens(t.tokens).withMessages(t.msgs)
+    }
+    if t.Linear {
+        describeBoard(out, t.msgs, b, players)
+        return
+    }
+    g := GridFor(b)
+    occupants := map[int][]Player{}
+    for _, p := range players {
+        for _, sq := range p.tokenSquares() {
+            occupants[sq.Index] = append(occupants[sq.Index], p)
+        }
+    }
+    wall, sep := "", ""
+    if t.Boxed {
+        wall, sep = "|", strings.Repeat("+"+strings.Repeat("-", 4+t.Width), g.Width)+"+"
+    }
+    for row := 0; row < g.Rows(); row++ {
+        if t.Boxed {
+            fmt.Fprintln(out, sep)
+        }
+        var line strings.Builder
+        for col := 0; col < g.Width; col++ {
+            if !t.Boxed && col > 0 {
+                line.WriteByte(' ')
+            }
+            sq, ok := g.Square(row, col)
+            if !ok {
+                fmt.Fprintf(&line, "%s%*s", wall, 4+t.Width, "")
+                continue
+            }
+            who := ' '
+            switch ps := occupants[sq]; len(ps) {
+            case 0:
+            case 1:
+                who = []rune(ps[0].Name + "?")[0]
+                if tok, ok := t.tokens[ps[0].Name]; ok {
+                    who = tok
+                }
+            default:
+                who = '*'
+            }
+            fmt.Fprintf(&line, "%s%3d%s%c", wall, sq, t.mark(b.Square(sq)), who)
+        }
+        if t.Boxed {
+            fmt.Fprintln(out, line.String()+wall)
+        } else {
+            fmt.Fprintln(out, strings.TrimRight(line.String(), " "))
+        }
+    }
+    if t.Boxed {
+        fmt.Fprintln(out, sep)
+    }
+    if line := clockLine(players); line != "" {
+        fmt.Fprintln(out, "Time left: "+line)
+    }
+}
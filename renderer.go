@@ -0,0 +1,338 @@
+package main
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "io"
+    "strings"
+)
+
+// Renderer shows an interactive game as play runs it. Play tells it what
+// is happening and writes nothing itself, so the same game can come out
+// as plain or coloured text (textRenderer), JSON lines for another
+// program to draw (jsonRenderer) or a full-screen board (tuiRenderer).
+type Renderer interface {
+    // RenderTurnPrompt asks gs's current player to roll; keys says a
+    // single keypress will do
+    RenderTurnPrompt(gs GameState, keys bool)
+    // RenderCountdown shows the seconds left to answer a prompt, then
+    // clears them with 0 once answered or ends the line with -1 if not
+    RenderCountdown(secs int)
+    // RenderRolling shows the die tumbling on face, clearing it with 0
+    RenderRolling(face int)
+    // RenderSuspense holds the table before a special square is revealed
+    RenderSuspense(waiting bool)
+    RenderRoll(player string, roll int)
+    // RenderEffect shows what one of a turn's events did beyond the move:
+    // special squares, items and captures
+    RenderEffect(ev Event)
+    // RenderMove shows where gs's player idx ended up after a turn's evs
+    RenderMove(gs GameState, idx int, evs []Event)
+    RenderBoard(gs GameState)
+    // RenderWin shows how the game ended in gs, a Win or a Draw
+    RenderWin(gs GameState, o Outcome)
+    RenderTurnEnd()
+    // RenderNotice shows anything else play has to say, the message key
+    // with its arguments
+    RenderNotice(key MsgKey, args ...any)
+}
+
+// uiNames are the -ui choices
+var uiNames = []string{"text", "json", "tui"}
+
+// textRenderer writes the game as lines of text, each player in their
+// colour if style has them. When spoken, spokenEvents tells the events
+// instead, so it keeps to prompts and notices.
+type textRenderer struct {
+    out    io.Writer
+    msgs   Catalog
+    theme  Theme
+    style  Style
+    spoken bool
+}
+
+// newPlainRenderer writes plain text to out
+func newPlainRenderer(out io.Writer, msgs Catalog, theme Theme) *textRenderer {
+    return &textRenderer{out: out, msgs: msgs, theme: theme.withMessages(msgs)}
+}
+
+// newColorRenderer writes text to out coloured with style
+func newColorRenderer(out io.Writer, msgs Catalog, theme Theme, style Style) *textRenderer {
+    return &textRenderer{out: out, msgs: msgs, theme: theme.withMessages(msgs), style: style}
+}
+
+func (r *textRenderer) RenderTurnPrompt(gs GameState, keys bool) {
+    if line := clockLine(gs.Players); line != "" {
+        fmt.Fprintln(r.out, r.msgs.T(MsgClocks, line))
+    }
+    prompt := MsgTurnPrompt
+    if keys {
+        prompt = MsgTurnPromptKey
+    }
+    fmt.Fprintln(r.out, r.msgs.T(prompt, r.style.Player(gs.Players[gs.CurrentPlayerIndex].Name)))
+}
+
+func (r *textRenderer) RenderCountdown(secs int) {
+    switch {
+    case secs > 0:
+        fmt.Fprint(r.out, "\r"+r.msgs.T(MsgCountdown, secs))
+    case secs == 0:
+        fmt.Fprint(r.out, "\r\033[K")
+    default:
+        fmt.Fprintln(r.out)
+    }
+}
+
+func (r *textRenderer) RenderRolling(face int) {
+    if face == 0 {
+        fmt.Fprint(r.out, "\r\033[K")
+        return
+    }
+    fmt.Fprint(r.out, "\r"+r.msgs.T(MsgRolling, face))
+}
+
+func (r *textRenderer) RenderSuspense(waiting bool) {
+    if waiting {
+        fmt.Fprint(r.out, "...")
+    } else {
+        fmt.Fprintln(r.out)
+    }
+}
+
+func (r *textRenderer) RenderRoll(player string, roll int) {
+    if !r.spoken {
+        fmt.Fprintln(r.out, r.msgs.T(MsgRolled, roll))
+    }
+}
+
+func (r *textRenderer) RenderEffect(ev Event) {
+    if !r.spoken {
+        narrateEffect(r.out, r.msgs, r.style, ev)
+    }
+}
+
+func (r *textRenderer) RenderMove(gs GameState, idx int, evs []Event) {
+    if r.spoken {
+        return
+    }
+    moved := gs.Players[idx]
+    var position string
+    if moved.Position == OffBoard {
+        position = r.msgs.T(MsgOffBoard, r.style.Player(moved.Name))
+    } else if len(moved.Tokens) > 0 {
+        position = r.msgs.T(MsgTokenMovesTo, r.style.Player(moved.Name), moved.Active+1, moved.Position.Index)
+    } else {
+        position = r.msgs.T(MsgMovesTo, r.style.Player(moved.Name), moved.Position.Index)
+    }
+    // the position line is where snakes and ladders show
+    for _, ev := range evs {
+        if ev.Kind == EventSnake {
+            position = r.style.Snake(position)
+            break
+        }
+        if ev.Kind == EventLadder {
+            position = r.style.Ladder(position)
+            break
+        }
+    }
+    fmt.Fprintln(r.out, position)
+}
+
+func (r *textRenderer) RenderBoard(gs GameState) {
+    r.theme.RenderBoard(r.out, gs.Board, gs.Players)
+}
+
+func (r *textRenderer) RenderWin(gs GameState, o Outcome) {
+    if r.spoken {
+        return
+    }
+    switch o := o.(type) {
+    case Win:
+        fmt.Fprintln(r.out, r.style.Banner(winLine(r.msgs, o.Winner.Name, o.Team)))
+    case Draw:
+        fmt.Fprintln(r.out, r.style.Banner(r.msgs.T(MsgDraw)))
+    }
+}
+
+func (r *textRenderer) RenderTurnEnd() {
+    if !r.spoken {
+        fmt.Fprintln(r.out, "--------------------------------")
+    }
+}
+
+// RenderNotice shows any argument naming a player in their colour
+func (r *textRenderer) RenderNotice(key MsgKey, args ...any) {
+    args = append([]any(nil), args...)
+    for i, a := range args {
+        if name, ok := a.(string); ok {
+            args[i] = r.style.Player(name)
+        }
+    }
+    fmt.Fprintln(r.out, r.msgs.T(key, args...))
+}
+
+// jsonRenderer writes one JSON object a line for each thing play shows,
+// with the text the plain renderer would have written, for a front end
+// in another program. The tumbling die and countdowns are left out.
+type jsonRenderer struct {
+    enc  *json.Encoder
+    text *textRenderer // for each line's text
+    buf  bytes.Buffer
+}
+
+// renderLine is a line jsonRenderer writes: Render says what it shows
+type renderLine struct {
+    Render    string         `json:"render"` // prompt, roll, effect, move, board, win, turn_end or notice
+    Player    string         `json:"player,omitempty"`
+    Roll      int            `json:"roll,omitempty"`
+    Square    int            `json:"square,omitempty"`
+    Event     *Event         `json:"event,omitempty"`
+    Positions map[string]int `json:"positions,omitempty"`
+    Key       MsgKey         `json:"key,omitempty"`
+    Text      string         `json:"text,omitempty"`
+}
+
+func newJSONRenderer(out io.Writer, msgs Catalog) *jsonRenderer {
+    j := &jsonRenderer{enc: json.NewEncoder(out)}
+    j.text = newPlainRenderer(&j.buf, msgs, minimalTheme)
+    return j
+}
+
+// write sends line with the text draw gives, unless it gives none
+func (j *jsonRenderer) write(line renderLine, draw func()) {
+    j.buf.Reset()
+    if draw != nil {
+        if draw(); j.buf.Len() == 0 {
+            return
+        }
+    }
+    line.Text = strings.TrimRight(j.buf.String(), "\n")
+    j.enc.Encode(line)
+}
+
+func (j *jsonRenderer) RenderTurnPrompt(gs GameState, keys bool) {
+    j.write(renderLine{Render: "prompt", Player: gs.Players[gs.CurrentPlayerIndex].Name}, func() { j.text.RenderTurnPrompt(gs, keys) })
+}
+
+func (j *jsonRenderer) RenderCountdown(int) {}
+func (j *jsonRenderer) RenderRolling(int)   {}
+func (j *jsonRenderer) RenderSuspense(bool) {}
+
+func (j *jsonRenderer) RenderRoll(p string, roll int) {
+    j.write(renderLine{Render: "roll", Player: p, Roll: roll}, func() { j.text.RenderRoll(p, roll) })
+}
+
+func (j *jsonRenderer) RenderEffect(ev Event) {
+    j.write(renderLine{Render: "effect", Player: ev.Player, Event: &ev}, func() { j.text.RenderEffect(ev) })
+}
+
+func (j *jsonRenderer) RenderMove(gs GameState, idx int, evs []Event) {
+    p := gs.Players[idx]
+    j.write(renderLine{Render: "move", Player: p.Name, Square: p.Position.Index}, func() { j.text.RenderMove(gs, idx, evs) })
+}
+
+func (j *jsonRenderer) RenderBoard(gs GameState) {
+    at := map[string]int{}
+    for _, p := range gs.Players {
+        at[p.Name] = p.Position.Index
+    }
+    j.write(renderLine{Render: "board", Positions: at}, func() { j.text.RenderBoard(gs) })
+}
+
+func (j *jsonRenderer) RenderWin(gs GameState, o Outcome) {
+    line := renderLine{Render: "win"}
+    if w, ok := o.(Win); ok {
+        line.Player = w.Winner.Name
+    }
+    j.write(line, func() { j.text.RenderWin(gs, o) })
+}
+
+func (j *jsonRenderer) RenderTurnEnd() {
+    j.write(renderLine{Render: "turn_end"}, nil)
+}
+
+func (j *jsonRenderer) RenderNotice(key MsgKey, args ...any) {
+    j.write(renderLine{Render: "notice", Key: key}, func() { j.text.RenderNotice(key, args...) })
+}
+
+// tuiLogLines is how much of the narration tuiRenderer keeps on screen
+const tuiLogLines = 8
+
+// tuiRenderer keeps the board on screen, redrawing it with the latest
+// narration under it at every prompt and at the end
+type tuiRenderer struct {
+    out  io.Writer
+    text *textRenderer // narrates into buf
+    buf  bytes.Buffer
+    log  []string
+}
+
+func newTUIRenderer(out io.Writer, msgs Catalog, theme Theme, style Style) *tuiRenderer {
+    t := &tuiRenderer{out: out}
+    t.text = newColorRenderer(&t.buf, msgs, theme, style)
+    return t
+}
+
+// keep runs draw, adding what it writes to the narration
+func (t *tuiRenderer) keep(draw func()) {
+    t.buf.Reset()
+    draw()
+    for _, line := range strings.Split(strings.TrimRight(t.buf.String(), "\n"), "\n") {
+        t.log = append(t.log, line)
+    }
+    if len(t.log) > tuiLogLines {
+        t.log = t.log[len(t.log)-tuiLogLines:]
+    }
+}
+
+// redraw clears the screen for the board and the narration, then draw's
+// lines under them
+func (t *tuiRenderer) redraw(gs GameState, draw func()) {
+    fmt.Fprint(t.out, "\x1b[H\x1b[2J")
+    t.text.theme.RenderBoard(t.out, gs.Board, gs.Players)
+    fmt.Fprintln(t.out)
+    for _, line := range t.log {
+        fmt.Fprintln(t.out, line)
+    }
+    t.text.out = t.out
+    defer func() { t.text.out = &t.buf }()
+    draw()
+}
+
+func (t *tuiRenderer) RenderTurnPrompt(gs GameState, keys bool) {
+    t.redraw(gs, func() { t.text.RenderTurnPrompt(gs, keys) })
+}
+
+// the countdown, the tumbling die and suspense draw in place on the
+// prompt's line
+func (t *tuiRenderer) RenderCountdown(secs int) { t.direct(func() { t.text.RenderCountdown(secs) }) }
+func (t *tuiRenderer) RenderRolling(face int)   { t.direct(func() { t.text.RenderRolling(face) }) }
+func (t *tuiRenderer) RenderSuspense(w bool)    { t.direct(func() { t.text.RenderSuspense(w) }) }
+
+func (t *tuiRenderer) direct(draw func()) {
+    t.text.out = t.out
+    defer func() { t.text.out = &t.buf }()
+    draw()
+}
+
+func (t *tuiRenderer) RenderRoll(p string, roll int) { t.keep(func() { t.text.RenderRoll(p, roll) }) }
+func (t *tuiRenderer) RenderEffect(ev Event)         { t.keep(func() { t.text.RenderEffect(ev) }) }
+func (t *tuiRenderer) RenderMove(gs GameState, idx int, evs []Event) {
+    t.keep(func() { t.text.RenderMove(gs, idx, evs) })
+}
+
+// RenderBoard redraws the screen: the board is always on it
+func (t *tuiRenderer) RenderBoard(gs GameState) { t.redraw(gs, func() {}) }
+
+func (t *tuiRenderer) RenderWin(gs GameState, o Outcome) {
+    t.redraw(gs, func() { t.text.RenderWin(gs, o) })
+}
+
+// RenderTurnEnd draws nothing: the next prompt redraws the screen
+func (t *tuiRenderer) RenderTurnEnd() {}
+
+func (t *tuiRenderer) RenderNotice(key MsgKey, args ...any) {
+    t.direct(func() { t.text.RenderNotice(key, args...) })
+    t.keep(func() { t.text.RenderNotice(key, args...) })
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "io"
+)
+
+// GameSetup is the position a game starts or restarts from, recorded on
+// start and restore events so the log can be replayed without the flags
+// or bookmarks it was played with
+type GameSetup struct {
+    Board BoardConfig `json:"board"`
+    Rules Rules       `json:"rules"`
+    State Snapshot    `json:"state"`
+}
+
+func setupOf(gs GameState, turn int) *GameSetup {
+    return &GameSetup{Board: ConfigFromBoard("", gs.Board), Rules: gs.Rules, State: takeSnapshot(gs, turn)}
+}
+
+// state rebuilds the game state the setup describes
+func (s GameSetup) state() (GameState, error) {
+    b, err := s.Board.Build()
+    if err != nil {
+        return GameState{}, err
+    }
+    gs, err := s.State.Restore(b)
+    gs.setRules(s.Rules)
+    return gs, err
+}
+
+// stateHash is a checksum of everything that decides how gs plays on:
+// the board, rules, every player's tokens, items and pending effects,
+// whose turn it is, and where the dice and square effects have got to.
+// Equal states always hash the same, whichever build produced them.
+func stateHash(gs GameState) string {
+    h := sha256.New()
+    fmt.Fprintf(h, "board %s\n", boardFingerprint(gs.Board))
+    r := gs.Rules
+    fmt.Fprintf(h, "rules team_win=%s capture=%s tokens=%d exact_win=%t bounce=%t six_again=%t chain_jumps=%t pick_die=%t\n",
+        r.TeamWin, r.Capture, r.Tokens, r.ExactWin, r.Bounce, r.SixAgain, r.ChainJumps, r.PickDie)
+    for _, p := range gs.Players {
+        fmt.Fprintf(h, "player %q team=%q at=%d tokens=%v active=%d skip=%d items=%v immune=%t boost=%d",
+            p.Name, p.Team, p.Position.Index, p.tokenSquares(), p.Active, p.SkipTurns, p.Items, p.Immune, p.Boost)
+        // only when set, so hashes from before players could leave still match
+        if p.Left {
+            fmt.Fprint(h, " left")
+        }
+        fmt.Fprintln(h)
+    }
+    fmt.Fprintf(h, "current %d rand %d dice %s %d %d\n", gs.CurrentPlayerIndex, gs.RandState, gs.Dice.Algorithm, gs.Dice.Seed, gs.Dice.Draws)
+    // only with a turn limit, so hashes from before there was one still match
+    if r.MaxTurns > 0 {
+        fmt.Fprintf(h, "limit %d %s turns %d\n", r.MaxTurns, r.TurnLimit, gs.Turns)
+    }
+    if r.EnterOnSix {
+        fmt.Fprintln(h, "enter_on_six")
+    }
+    if r.ThreeSixes != ThreeSixesOff {
+        fmt.Fprintf(h, "three_sixes %s", r.ThreeSixes)
+        for _, p := range gs.Players {
+            fmt.Fprintf(h, " %d%v", p.Sixes, p.TurnStart)
+        }
+        fmt.Fprintln(h)
+    }
+    return hex.EncodeToString(h.Sum(nil))
+}
+
+// replayer follows games through their events as they arrive, replaying
+// each from its setup and checking the state it reaches against the hash
+// recorded on the event. Only a failed check is reported; after one the
+// replayer waits for the next setup.
+type replayer struct {
+    gs      GameState
+    playing bool
+    roll    *Event // the roll the next move event will say the token of
+}
+
+// apply takes the next event
+func (r *replayer) apply(ev Event) error {
+    err := r.step(ev)
+    if err != nil {
+        r.playing, r.roll = false, nil
+    }
+    return err
+}
+
+func (r *replayer) step(ev Event) error {
+    switch ev.Kind {
+    case EventStart, EventRestore:
+        r.roll = nil
+        if ev.Setup == nil {
+            if ev.Kind == EventStart || r.playing {
+                return errors.New("game logged without its setup (before format version 3), so it can't be replayed")
+            }
+            return nil
+        }
+        gs, err := ev.Setup.state()
+        if err != nil {
+            return fmt.Errorf("bad setup: %w", err)
+        }
+        r.gs, r.playing = gs, true
+        return r.check(ev.Turn, ev.Hash)
+    case EventUseItem:
+        if !r.playing {
+            return nil
+        }
+        if cur := r.gs.Players[r.gs.CurrentPlayerIndex].Name; cur != ev.Player {
+            return fmt.Errorf("%s used an item, but the replay has %s to play", ev.Player, cur)
+        }
+        gs, err := useItem(r.gs, ItemKind(ev.Item))
+        if err != nil {
+            return fmt.Errorf("replaying %s's %s: %w", ev.Player, ev.Item, err)
+        }
+        r.gs = gs
+        return r.check(ev.Turn, ev.Hash)
+    case EventRoll:
+        if !r.playing {
+            return nil
+        }
+        if cur := r.gs.Players[r.gs.CurrentPlayerIndex].Name; cur != ev.Player {
+            return fmt.Errorf("turn %d: %s rolled, but the replay has %s to play", ev.Turn, ev.Player, cur)
+        }
+        r.roll = &ev
+    case EventMove:
+        if !r.playing || r.roll == nil {
+            return nil
+        }
+        roll := *r.roll
+        r.roll = nil
+        if ev.Token > 0 {
+            gs, err := withToken(r.gs, ev.Token-1)
+            if err != nil {
+                return fmt.Errorf("turn %d: %w", roll.Turn, err)
+            }
+            r.gs = gs
+        }
+        pick := 0
+        for k, c := range roll.Choices {
+            if c == roll.Roll {
+                pick = k + 1
+                break
+            }
+        }
+        dr, _ := drawRoll(&r.gs.Dice, r.gs.Rules, max(pick, 1))
+        if dr.Value != roll.Roll {
+            return fmt.Errorf("turn %d: the dice give %d, but the log has %d", roll.Turn, dr.Value, roll.Roll)
+        }
+        gs, err := ApplyMove(r.gs, dr)
+        if err != nil {
+            return fmt.Errorf("turn %d: %w", roll.Turn, err)
+        }
+        r.gs = gs
+        return r.check(roll.Turn, roll.Hash)
+    case EventLeave, EventJoin:
+        if !r.playing {
+            return nil
+        }
+        seat := ev.Player
+        if ev.Kind == EventJoin {
+            seat = ev.Other
+        }
+        idx := seatOf(r.gs, seat)
+        if idx < 0 || r.gs.Players[idx].Left == (ev.Kind == EventLeave) {
+            return fmt.Errorf("turn %d: %s, but the replay has no such seat", ev.Turn, describeEvent(ev))
+        }
+        switch {
+        case ev.Reason == timeUpReason:
+            r.gs = flagFall(r.gs, idx)
+        case ev.Kind == EventLeave:
+            r.gs = leaveSeat(r.gs, idx)
+        default:
+            r.gs = takeSeat(r.gs, idx, ev.Player)
+        }
+        return r.check(ev.Turn, ev.Hash)
+    case EventWin, EventDraw, EventAbort, EventPause:
+        if !r.playing {
+            return nil
+        }
+        r.playing = false
+        return r.check(ev.Turn, ev.Hash)
+    }
+    return nil
+}
+
+// check compares the replayed state with a recorded hash, if there is one
+func (r *replayer) check(turn int, hash string) error {
+    if hash == "" {
+        return nil
+    }
+    if got := stateHash(r.gs); got != hash {
+        return fmt.Errorf("turn %d: replay reached state %.12s, but the log recorded %.12s", turn, got, hash)
+    }
+    return nil
+}
+
+// verifyReplay replays every game in an event log from its setup, taking
+// the logged rolls, token and die choices and item uses, and checks each
+// reaches the logged state after every turn. It reports how many games it
+// checked to the end.
+func verifyReplay(evs []Event) (games int, err error) {
+    var r replayer
+    var errs []error
+    for i, ev := range evs {
+        ending := r.playing && (ev.Kind == EventWin || ev.Kind == EventDraw || ev.Kind == EventAbort || ev.Kind == EventPause)
+        if err := r.apply(ev); err != nil {
+            errs = append(errs, fmt.Errorf("event %d: %w", i+1, err))
+            continue
+        }
+        if ending {
+            games++
+        }
+    }
+    return games, errors.Join(errs...)
+}
+
+// runVerify implements `verify EVENTS-FILE`
+func runVerify(args []string, out io.Writer) error {
+    if len(args) != 1 {
+        return errors.New("usage: verify <game-record-file>")
+    }
+    evs, err := openGameRecord(args[0])
+    if err != nil {
+        return err
+    }
+    games, err := verifyReplay(evs)
+    if err != nil {
+        return err
+    }
+    if games == 0 {
+        return errors.New("no finished games to verify")
+    }
+    fmt.Fprintf(out, "%d games replayed to their recorded final states\n", games)
+    return nil
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+    crand "crypto/rand"
+    "encoding/binary"
+    "errors"
+    "fmt"
+    "math/rand"
+    "strconv"
+    "strings"
+)
+
+// Roller is a source of die rolls. The engine never reaches for a global
+// RNG when given one, so seeded rollers make whole games reproducible.
+type Roller interface {
+    Roll() DieRoll
+}
+
+// randRoller rolls from its own math/rand stream
+type randRoller struct {
+    rng *rand.Rand
+}
+
+func NewSeededRoller(seed int64) Roller {
+    return &randRoller{rand.New(rand.NewSource(seed))}
+}
+
+func (r *randRoller) Roll() DieRoll {
+    dr, _ := NewDieRoll(r.rng.Intn(6) + 1)
+    return dr
+}
+
+// cryptoRoller rolls from crypto/rand, so no one can work out the rolls
+// to come from the ones so far
+type cryptoRoller struct{}
+
+func NewCryptoRoller() Roller {
+    return cryptoRoller{}
+}
+
+func (cryptoRoller) Roll() DieRoll {
+    var b [1]byte
+    for {
+        if _, err := crand.Read(b[:]); err != nil {
+            panic(err) // crypto/rand never fails on supported platforms
+        }
+        // 252 is the largest multiple of 6 a byte holds; above it would
+        // favour the low faces
+        if b[0] < 252 {
+            return DieRoll{int(b[0]%6) + 1}
+        }
+    }
+}
+
+// weightedRoller rolls a biased die: face n comes up in proportion to
+// weights[n-1]
+type weightedRoller struct {
+    rng     *rand.Rand
+    weights [6]float64
+    total   float64
+}
+
+// NewWeightedRoller is a seeded die whose faces come up in proportion to
+// weights; no weight may be negative and at least one must be positive
+func NewWeightedRoller(seed int64, weights [6]float64) (Roller, error) {
+    total := 0.0
+    for n, w := range weights {
+        if w < 0 {
+            return nil, fmt.Errorf("face %d has negative weight %g", n+1, w)
+        }
+        total += w
+    }
+    if total <= 0 {
+        return nil, errors.New("no face has any weight")
+    }
+    return &weightedRoller{rand.New(rand.NewSource(seed)), weights, total}, nil
+}
+
+func (r *weightedRoller) Roll() DieRoll {
+    x := r.rng.Float64() * r.total
+    face := 6
+    for n, w := range r.weights {
+        if x < w {
+            face = n + 1
+            break
+        }
+        x -= w
+    }
+    return DieRoll{face}
+}
+
+// scriptedRoller plays back a fixed sequence of rolls, starting over once
+// it runs out, so a game can be steered onto particular squares
+type scriptedRoller struct {
+    rolls []DieRoll
+    next  int
+}
+
+func NewScriptedRoller(values ...int) (Roller, error) {
+    if len(values) == 0 {
+        return nil, errors.New("a scripted die needs at least one roll")
+    }
+    rolls := make([]DieRoll, len(values))
+    for i, v := range values {
+        dr, err := NewDieRoll(v)
+        if err != nil {
+            return nil, err
+        }
+        rolls[i] = dr
+    }
+    return &scriptedRoller{rolls: rolls}, nil
+}
+
+func (r *scriptedRoller) Roll() DieRoll {
+    dr := r.rolls[r.next]
+    r.next = (r.next + 1) % len(r.rolls)
+    return dr
+}
+
+// ParseRoller builds the Roller spec describes, seeded with seed: "" or
+// "fair" for a fair die, "crypto" for an unseeded one from crypto/rand,
+// "weights:W1,...,W6" for a biased one, or "script:V1,V2,..." for a fixed
+// sequence of rolls
+func ParseRoller(spec string, seed int64) (Roller, error) {
+    kind, list, _ := strings.Cut(spec, ":")
+    var nums []float64
+    if list != "" {
+        for _, f := range strings.Split(list, ",") {
+            n, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+            if err != nil {
+                return nil, fmt.Errorf("dice %q: %q is not a number", spec, f)
+            }
+            nums = append(nums, n)
+        }
+    }
+    switch kind {
+    case "", "fair":
+        if len(nums) == 0 {
+            return NewSeededRoller(seed), nil
+        }
+    case "crypto":
+        if len(nums) == 0 {
+            return NewCryptoRoller(), nil
+        }
+    case "weights":
+        if len(nums) != 6 {
+            return nil, fmt.Errorf("dice %q: want a weight for each of the six faces", spec)
+        }
+        r, err := NewWeightedRoller(seed, [6]float64(nums))
+        if err != nil {
+            return nil, fmt.Errorf("dice %q: %w", spec, err)
+        }
+        return r, nil
+    case "script":
+        values := make([]int, len(nums))
+        for i, n := range nums {
+            values[i] = int(n)
+            if float64(values[i]) != n {
+                return nil, fmt.Errorf("dice %q: %g is not a roll", spec, n)
+            }
+        }
+        r, err := NewScriptedRoller(values...)
+        if err != nil {
+            return nil, fmt.Errorf("dice %q: %w", spec, err)
+        }
+        return r, nil
+    }
+    return nil, fmt.Errorf("unknown dice %q (want fair, crypto, weights:W1,...,W6 or script:V1,V2,...)", spec)
+}
+
+// DiceAlgorithm names the generator behind DiceStream. Saves record it so
+// a build with a different generator refuses them rather than quietly
+// rolling different dice.
+const DiceAlgorithm = "splitmix64"
+
+// DiceStream is a game's dice: splitmix64 seeded with Seed, Draws rolls
+// in. Roll n is a pure function of Seed and n, so a save holding the
+// stream fixes every roll still to come, and anyone with the seed can
+// recompute every roll already made (see auditDice).
+type DiceStream struct {
+    Algorithm string `json:"algorithm"`
+    Seed      uint64 `json:"seed"`
+    Draws     uint64 `json:"draws"`
+}
+
+func NewDiceStream(seed uint64) DiceStream {
+    return DiceStream{Algorithm: DiceAlgorithm, Seed: seed}
+}
+
+// randomDice is a stream with an unpredictable seed, for real games
+func randomDice() DiceStream {
+    var b [8]byte
+    if _, err := crand.Read(b[:]); err != nil {
+        panic(err) // crypto/rand never fails on supported platforms
+    }
+    return NewDiceStream(binary.LittleEndian.Uint64(b[:]))
+}
+
+// Roll draws the next roll
+func (d *DiceStream) Roll() DieRoll {
+    d.Draws++
+    return d.At(d.Draws)
+}
+
+// Peek is the next k rolls, without drawing them
+func (d DiceStream) Peek(k int) []DieRoll {
+    rolls := make([]DieRoll, k)
+    for i := range rolls {
+        rolls[i] = d.At(d.Draws + uint64(i) + 1)
+    }
+    return rolls
+}
+
+// At is the n-th roll (counting from 1) of the stream
+func (d DiceStream) At(n uint64) DieRoll {
+    s := d.Seed + (n-1)*0x9e3779b97f4a7c15
+    dr, _ := NewDieRoll(int(nextRand(&s)%6) + 1)
+    return dr
+}
+
+// drawRoll takes the turn's roll from d: the next one, or with the
+// pick_die rule the pick-th (from 1) of the next two, which are both used
+// up. choices is the pair offered, if any.
+func drawRoll(d *DiceStream, r Rules, pick int) (roll DieRoll, choices []DieRoll) {
+    if !r.PickDie {
+        return d.Roll(), nil
+    }
+    choices = d.Peek(2)
+    d.Draws += 2
+    return choices[pick-1], choices
+}
+
+func dieValues(rolls []DieRoll) []int {
+    var vs []int
+    for _, r := range rolls {
+        vs = append(vs, r.Value)
+    }
+    return vs
+}
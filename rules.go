@@ -0,0 +1,492 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "sort"
+    "strings"
+)
+
+// Rule is one optional movement rule. The move logic consults whichever
+// hooks (overshootRule, jumpRule, afterMoveRule, extraTurnRule) a rule
+// implements at fixed points of every move, so rules combine without
+// applyMove knowing which are on. Combinations that make no sense are
+// declared by the rules themselves and rejected by Rules.Check.
+type Rule interface {
+    Name() string
+    // Conflicts maps the names of rules this one can't be played with to
+    // the reason
+    Conflicts() map[string]string
+}
+
+// overshootRule decides where a roll that passes the final square lands
+type overshootRule interface {
+    Overshoot(b Board, from BoardPos, steps int) BoardPos
+}
+
+// jumpRule may carry a player on from the end of the jump they just took:
+// it returns the next jump to take, if any
+type jumpRule interface {
+    Follow(b Board, jump Square) (Square, bool)
+}
+
+// afterMoveRule acts once the player at idx has finished moving and any
+// square effect has been applied, before the turn passes on
+type afterMoveRule interface {
+    AfterMove(gs *GameState, idx int)
+}
+
+// extraTurnRule gives the mover another turn after rolling dr
+type extraTurnRule interface {
+    ExtraTurn(dr DieRoll) bool
+}
+
+// entryRule keeps players off the board until a roll brings them on
+type entryRule interface {
+    Enter(dr DieRoll) BoardPos // where dr brings a waiting player, or OffBoard
+}
+
+// rollRule sees each roll before it is played, and may forfeit it: the
+// mover's tokens then go to back and the turn passes. It may keep count
+// of rolls on the mover.
+type rollRule interface {
+    BeforeRoll(p *Player, dr DieRoll) (back []BoardPos, forfeit bool)
+}
+
+// boardRule reports boards the rule can't be played on
+type boardRule interface {
+    CheckBoard(b Board) error
+}
+
+// RuleNames are the rules -rules accepts
+var RuleNames = []string{"exact_win", "bounce", "six_again", "chain_jumps", "pick_die", "enter_on_six", "three_sixes", "three_sixes_back"}
+
+// Enable turns on the comma-separated rules in names
+func (r *Rules) Enable(names string) error {
+    for _, n := range strings.Split(names, ",") {
+        switch strings.TrimSpace(n) {
+        case "":
+        case "exact_win":
+            r.ExactWin = true
+        case "bounce":
+            r.Bounce = true
+        case "six_again":
+            r.SixAgain = true
+        case "chain_jumps":
+            r.ChainJumps = true
+        case "pick_die":
+            r.PickDie = true
+        case "enter_on_six":
+            r.EnterOnSix = true
+        case "three_sixes", "three_sixes_back":
+            mode := ThreeSixesCancel
+            if strings.TrimSpace(n) == "three_sixes_back" {
+                mode = ThreeSixesBack
+            }
+            if r.ThreeSixes != ThreeSixesOff && r.ThreeSixes != mode {
+                return errors.New("rules three_sixes and three_sixes_back can't be combined: both decide what a third six does")
+            }
+            r.ThreeSixes = mode
+        default:
+            return fmt.Errorf("unknown rule %q (want one of %s)", n, strings.Join(RuleNames, ", "))
+        }
+    }
+    return nil
+}
+
+// RuleSpec is a set of rules written out by name, as board files and
+// RulePresets hold them: a preset, and rules on top of it
+type RuleSpec struct {
+    Preset    string   `json:"preset,omitempty"`     // one of RulePresets
+    Enable    []string `json:"enable,omitempty"`     // from RuleNames
+    Capture   string   `json:"capture,omitempty"`    // as -capture takes it
+    MaxTurns  int      `json:"max_turns,omitempty"`  // as -max-turns
+    TurnLimit string   `json:"turn_limit,omitempty"` // as -turn-limit
+}
+
+// RulePresets are the named sets of rules -preset and board files can
+// start from. Presets only turn rules on, so any rules added to one play
+// alongside it.
+var RulePresets = map[string]RuleSpec{
+    "classic":   {},
+    "fast":      {Enable: []string{"six_again", "chain_jumps"}, MaxTurns: 150, TurnLimit: string(TurnLimitClosest)},
+    "cutthroat": {Enable: []string{"exact_win", "three_sixes_back"}, Capture: string(CaptureToStart)},
+}
+
+// rulePresetNames lists RulePresets for usage messages
+func rulePresetNames() []string {
+    var names []string
+    for n := range RulePresets {
+        names = append(names, n)
+    }
+    sort.Strings(names)
+    return names
+}
+
+// Apply turns on the rules s names in r, its preset's first. It reports
+// every name it doesn't know; whether the rules go together is for Check.
+func (s RuleSpec) Apply(r *Rules) error {
+    var errs []error
+    if s.Preset != "" {
+        p, ok := RulePresets[s.Preset]
+        if !ok {
+            errs = append(errs, fmt.Errorf("unknown rules preset %q (want one of %s)", s.Preset, strings.Join(rulePresetNames(), ", ")))
+        } else if err := p.Apply(r); err != nil {
+            errs = append(errs, err)
+        }
+    }
+    if err := r.Enable(strings.Join(s.Enable, ",")); err != nil {
+        errs = append(errs, err)
+    }
+    if s.Capture != "" {
+        c, err := ParseCapture(s.Capture)
+        if err != nil {
+            errs = append(errs, err)
+        }
+        r.Capture = c
+    }
+    if s.MaxTurns < 0 {
+        errs = append(errs, fmt.Errorf("max_turns %d is negative", s.MaxTurns))
+    } else if s.MaxTurns > 0 {
+        r.MaxTurns = s.MaxTurns
+    }
+    if s.TurnLimit != "" {
+        tl, err := ParseTurnLimit(s.TurnLimit)
+        if err != nil {
+            errs = append(errs, err)
+        }
+        r.TurnLimit = tl
+    }
+    return errors.Join(errs...)
+}
+
+// units are the Rules that r turns on
+func (r Rules) units() []Rule {
+    var us []Rule
+    if r.ExactWin {
+        us = append(us, exactWin{})
+    }
+    if r.Bounce {
+        us = append(us, bounceBack{})
+    }
+    if r.SixAgain {
+        us = append(us, sixAgain{})
+    }
+    if r.ChainJumps {
+        us = append(us, chainJumps{})
+    }
+    if r.Capture != CaptureOff {
+        us = append(us, captureRule{r.Capture})
+    }
+    if r.PickDie {
+        us = append(us, pickDie{})
+    }
+    if r.EnterOnSix {
+        us = append(us, enterOnSix{})
+    }
+    if r.ThreeSixes != ThreeSixesOff {
+        us = append(us, threeSixes{r.ThreeSixes})
+    }
+    return us
+}
+
+// ruleSet is the Rules a game turns on, as units lists them. The move
+// logic asks it several times a move, so a GameState keeps its own, built
+// once by setRules.
+type ruleSet []Rule
+
+// setRules plays gs under r from now on
+func (gs *GameState) setRules(r Rules) {
+    gs.Rules, gs.units = r, r.units()
+}
+
+// ruleUnits is the ruleSet of gs.Rules: the one setRules built, or, for a
+// state whose Rules were filled in some other way, a fresh one
+func (gs *GameState) ruleUnits() ruleSet {
+    if gs.units == nil {
+        return gs.Rules.units()
+    }
+    return gs.units
+}
+
+// Check reports every pair of rules in r that conflict and every rule
+// that can't be played on b
+func (r Rules) Check(b Board) error {
+    us := r.units()
+    var errs []error
+    for i, u := range us {
+        for _, v := range us[i+1:] {
+            why, ok := u.Conflicts()[v.Name()]
+            if !ok {
+                why, ok = v.Conflicts()[u.Name()]
+            }
+            if ok {
+                errs = append(errs, fmt.Errorf("rules %s and %s can't be combined: %s", u.Name(), v.Name(), why))
+            }
+        }
+        if br, ok := u.(boardRule); ok {
+            if err := br.CheckBoard(b); err != nil {
+                errs = append(errs, fmt.Errorf("rule %s: %w", u.Name(), err))
+            }
+        }
+    }
+    return errors.Join(errs...)
+}
+
+// advance moves steps squares on from from, letting a rule decide what an
+// overshoot does (by default it stops on the final square)
+func (r ruleSet) advance(b Board, from BoardPos, steps int) BoardPos {
+    if from.Index+steps > b.FinalSquare.Index {
+        for _, u := range r {
+            if o, ok := u.(overshootRule); ok {
+                return o.Overshoot(b, from, steps)
+            }
+        }
+    }
+    return advance(b, from, steps)
+}
+
+// start is where players begin: square 1, or off the board under an
+// entryRule
+func (r ruleSet) start() BoardPos {
+    for _, u := range r {
+        if _, ok := u.(entryRule); ok {
+            return OffBoard
+        }
+    }
+    return mustBP(1)
+}
+
+// enter is where a player waiting off the board goes on rolling dr
+func (r ruleSet) enter(dr DieRoll) BoardPos {
+    for _, u := range r {
+        if e, ok := u.(entryRule); ok {
+            return e.Enter(dr)
+        }
+    }
+    return mustBP(1)
+}
+
+// beforeRoll runs every rollRule on p's roll of dr, reporting whether
+// one forfeits it and where p's tokens go if so
+func (r ruleSet) beforeRoll(p *Player, dr DieRoll) ([]BoardPos, bool) {
+    for _, u := range r {
+        if h, ok := u.(rollRule); ok {
+            if back, forfeit := h.BeforeRoll(p, dr); forfeit {
+                return back, true
+            }
+        }
+    }
+    return nil, false
+}
+
+// placeAtStart puts every player in gs where its rules begin them
+func placeAtStart(gs *GameState) {
+    for i := range gs.Players {
+        gs.Players[i].Position = gs.ruleUnits().start()
+    }
+}
+
+// jumpChain is the jumps a player landing on sq takes: sq itself, then
+// any a jumpRule carries them on to. A square that isn't a snake or
+// ladder is returned alone.
+func (r ruleSet) jumpChain(b Board, sq Square) []Square {
+    var chain []Square
+    r.eachJump(b, sq, func(j Square) { chain = append(chain, j) })
+    return chain
+}
+
+// eachJump calls fn with each square of sq's jumpChain in turn, without
+// building the chain, for the move logic to run without allocating
+func (r ruleSet) eachJump(b Board, sq Square, fn func(Square)) {
+    fn(sq)
+    if !isJump(sq) {
+        return
+    }
+    at := sq
+    for _, u := range r {
+        j, ok := u.(jumpRule)
+        if !ok {
+            continue
+        }
+        // Check rejects boards with loops; the bound is a backstop
+        for n := 0; n < b.FinalSquare.Index; n++ {
+            next, ok := j.Follow(b, at)
+            if !ok {
+                break
+            }
+            fn(next)
+            at = next
+        }
+    }
+}
+
+// afterMove runs every afterMoveRule and reports whether one of the
+// rules gives the mover another turn for rolling dr
+func (r ruleSet) afterMove(gs *GameState, idx int, dr DieRoll) (again bool) {
+    for _, u := range r {
+        if h, ok := u.(afterMoveRule); ok {
+            h.AfterMove(gs, idx)
+        }
+        if h, ok := u.(extraTurnRule); ok && h.ExtraTurn(dr) {
+            again = true
+        }
+    }
+    return again
+}
+
+func isJump(sq Square) bool {
+    switch sq.(type) {
+    case Snake, Ladder:
+        return true
+    }
+    return false
+}
+
+// exactWin: a roll that would pass the final square is forfeited
+type exactWin struct{}
+
+func (exactWin) Name() string { return "exact_win" }
+
+func (exactWin) Conflicts() map[string]string {
+    return map[string]string{"bounce": "both decide what a roll past the final square does"}
+}
+
+func (exactWin) Overshoot(b Board, from BoardPos, steps int) BoardPos {
+    return from
+}
+
+// bounceBack: a roll that would pass the final square counts the excess
+// back down from it
+type bounceBack struct{}
+
+func (bounceBack) Name() string { return "bounce" }
+
+func (bounceBack) Conflicts() map[string]string {
+    return map[string]string{"exact_win": "both decide what a roll past the final square does"}
+}
+
+func (bounceBack) Overshoot(b Board, from BoardPos, steps int) BoardPos {
+    final := b.FinalSquare.Index
+    return mustBP(max(final-(from.Index+steps-final), 1))
+}
+
+// sixAgain: rolling a six earns another turn
+type sixAgain struct{}
+
+func (sixAgain) Name() string                 { return "six_again" }
+func (sixAgain) Conflicts() map[string]string { return nil }
+func (sixAgain) ExtraTurn(dr DieRoll) bool    { return dr.Value == 6 }
+
+// chainJumps: a snake or ladder ending at the start of another takes that
+// one too
+type chainJumps struct{}
+
+func (chainJumps) Name() string                 { return "chain_jumps" }
+func (chainJumps) Conflicts() map[string]string { return nil }
+
+func (chainJumps) Follow(b Board, jump Square) (Square, bool) {
+    next := b.Square(jump.Dest().Index)
+    return next, isJump(next)
+}
+
+// CheckBoard rejects boards where chained jumps go round in a loop
+func (chainJumps) CheckBoard(b Board) error {
+    for sq := 1; sq <= b.FinalSquare.Index; sq++ {
+        seen := map[int]bool{}
+        at := b.Square(sq)
+        for isJump(at) && !seen[at.Dest().Index] {
+            seen[at.Dest().Index] = true
+            at = b.Square(at.Dest().Index)
+        }
+        if isJump(at) {
+            var path []int
+            for i := range seen {
+                path = append(path, i)
+            }
+            sort.Ints(path)
+            return fmt.Errorf("jumps from %d loop forever through squares %v", sq, path)
+        }
+    }
+    return nil
+}
+
+// pickDie: each turn the player sees two rolls and moves by the one they
+// choose. Play handles the choice; the move itself is unchanged.
+type pickDie struct{}
+
+func (pickDie) Name() string                 { return "pick_die" }
+func (pickDie) Conflicts() map[string]string { return nil }
+
+// captureRule sends opponents sharing the mover's square back (see capture)
+type captureRule struct {
+    mode Capture
+}
+
+func (captureRule) Name() string                 { return "capture" }
+func (captureRule) Conflicts() map[string]string { return nil }
+
+func (c captureRule) AfterMove(gs *GameState, idx int) {
+    capture(gs, c.mode, idx)
+}
+
+// enterOnSix: players start off the board and come on at square 1 only
+// by rolling a six
+type enterOnSix struct{}
+
+func (enterOnSix) Name() string                 { return "enter_on_six" }
+func (enterOnSix) Conflicts() map[string]string { return nil }
+
+func (enterOnSix) Enter(dr DieRoll) BoardPos {
+    if dr.Value == 6 {
+        return mustBP(1)
+    }
+    return OffBoard
+}
+
+// ThreeSixes is what rolling a third six in a row does under the
+// three_sixes rules
+type ThreeSixes string
+
+const (
+    ThreeSixesOff ThreeSixes = ""
+    // ThreeSixesCancel forfeits the roll: the player stays put
+    ThreeSixesCancel ThreeSixes = "cancel"
+    // ThreeSixesBack forfeits it and sends the player back to where they
+    // were before the first of the sixes
+    ThreeSixesBack ThreeSixes = "back"
+)
+
+// threeSixes: a third six in a row is forfeit, as mode says
+type threeSixes struct {
+    mode ThreeSixes
+}
+
+func (t threeSixes) Name() string {
+    if t.mode == ThreeSixesBack {
+        return "three_sixes_back"
+    }
+    return "three_sixes"
+}
+
+func (threeSixes) Conflicts() map[string]string { return nil }
+
+func (t threeSixes) BeforeRoll(p *Player, dr DieRoll) ([]BoardPos, bool) {
+    if dr.Value != 6 {
+        p.Sixes, p.TurnStart = 0, nil
+        return nil, false
+    }
+    if p.Sixes == 0 {
+        p.TurnStart = append([]BoardPos(nil), p.tokenSquares()...)
+    }
+    p.Sixes++
+    if p.Sixes < 3 {
+        return nil, false
+    }
+    back := append([]BoardPos(nil), p.tokenSquares()...)
+    if t.mode == ThreeSixesBack {
+        back = p.TurnStart
+    }
+    p.Sixes, p.TurnStart = 0, nil
+    return back, true
+}
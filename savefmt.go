@@ -0,0 +1,157 @@
+package main
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "strings"
+)
+
+// FormatVersion is the version of the game files this build writes:
+// snapshots (autosave and bookmarks), simulation checkpoints and event
+// logs, whose start event carries it. Files from before versioning count
+// as version 1.
+//
+// When one of those schemas changes, bump FormatVersion and register a
+// migration from the previous version, so older files keep loading.
+//
+//   - 2: files record their version; saves from before the dice stream
+//     was recorded get fresh dice
+//   - 3: event logs record each game's setup and final state hash, for
+//     verify
+const FormatVersion = 3
+
+// A migration upgrades a decoded file of one version to the next, in place
+type migration func(doc map[string]any) error
+
+// File kinds, each with its own migrations
+const (
+    kindSave       = "save" // a Snapshot, alone or inside an Autosave
+    kindCheckpoint = "simulation checkpoint"
+    kindEvents     = "event log"
+)
+
+// migrations[kind][v] upgrades a kind file from version v to v+1. A
+// version with nothing to change for a kind needs no entry.
+var migrations = map[string]map[int]migration{
+    kindSave: {
+        1: func(doc map[string]any) error {
+            // saved before dice were recorded: carry on with new ones
+            dice, _ := lookupFold(doc, "Dice")
+            if m, ok := dice.(map[string]any); ok {
+                if alg, _ := m["algorithm"].(string); alg != "" {
+                    return nil
+                }
+            }
+            d := randomDice()
+            setFold(doc, "Dice", map[string]any{"algorithm": d.Algorithm, "seed": json.Number(fmt.Sprint(d.Seed)), "draws": 0})
+            return nil
+        },
+    },
+}
+
+// checkVersion reports a version this build can't read
+func checkVersion(kind string, v int) error {
+    switch {
+    case v > FormatVersion:
+        return fmt.Errorf("%s is format version %d, from a newer release; this build reads versions 1 to %d", kind, v, FormatVersion)
+    case v < 1:
+        return fmt.Errorf("%s has unknown format version %d", kind, v)
+    }
+    return nil
+}
+
+// decodeVersioned decodes data, a kind file of any supported version,
+// into v, running the migrations to bring it up to FormatVersion first
+func decodeVersioned(data []byte, kind string, v any) error {
+    dec := json.NewDecoder(bytes.NewReader(data))
+    dec.UseNumber() // keep seeds exact through the round trip
+    var doc map[string]any
+    if err := dec.Decode(&doc); err != nil {
+        return err
+    }
+    if doc == nil {
+        return json.Unmarshal(data, v)
+    }
+    version := 1
+    if raw, ok := lookupFold(doc, "version"); ok {
+        n, ok := raw.(json.Number)
+        i, err := n.Int64()
+        if !ok || err != nil {
+            return fmt.Errorf("%s has a malformed version %v", kind, raw)
+        }
+        version = int(i)
+    }
+    if err := checkVersion(kind, version); err != nil {
+        return err
+    }
+    if version < FormatVersion {
+        for ; version < FormatVersion; version++ {
+            if m := migrations[kind][version]; m != nil {
+                if err := m(doc); err != nil {
+                    return fmt.Errorf("upgrading %s from version %d: %w", kind, version, err)
+                }
+            }
+        }
+        setFold(doc, "version", FormatVersion)
+        var err error
+        if data, err = json.Marshal(doc); err != nil {
+            return err
+        }
+    }
+    return json.Unmarshal(data, v)
+}
+
+// lookupFold finds key in doc the way encoding/json matches field names,
+// ignoring case
+func lookupFold(doc map[string]any, key string) (any, bool) {
+    for k, v := range doc {
+        if strings.EqualFold(k, key) {
+            return v, true
+        }
+    }
+    return nil, false
+}
+
+// setFold replaces the value of key in doc, whatever its case, or adds it
+func setFold(doc map[string]any, key string, v any) {
+    for k := range doc {
+        if strings.EqualFold(k, key) {
+            doc[k] = v
+            return
+        }
+    }
+    doc[key] = v
+}
+
+func (a *Autosave) UnmarshalJSON(data []byte) error {
+    type plain Autosave
+    return decodeVersioned(data, kindSave, (*plain)(a))
+}
+
+func (cp *SimCheckpoint) UnmarshalJSON(data []byte) error {
+    type plain SimCheckpoint
+    return decodeVersioned(data, kindCheckpoint, (*plain)(cp))
+}
+
+// bookmarkFile is the bookmarks file: each bookmark is versioned on its
+// own, since they are added one at a time by different releases
+type bookmarkFile map[string]Snapshot
+
+func (f *bookmarkFile) UnmarshalJSON(data []byte) error {
+    var raw map[string]json.RawMessage
+    if err := json.Unmarshal(data, &raw); err != nil {
+        return err
+    }
+    if *f == nil {
+        *f = bookmarkFile{}
+    }
+    for name, r := range raw {
+        var s Snapshot
+        if err := decodeVersioned(r, kindSave, &s); err != nil {
+            return fmt.Errorf("bookmark %q: %w", name, err)
+        }
+        (*f)[name] = s
+    }
+    return nil
+}
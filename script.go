@@ -0,0 +1,502 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "slices"
+    "strconv"
+    "strings"
+    "unicode"
+)
+
+// A square script is an effect a board file writes for a square, so a
+// variant needs no Go for a square of its own:
+//
+//    "scripts": [
+//      {"square": 30, "effect": "back 2"},
+//      {"square": 55, "effect": "if roll == 6 then forward roll else skip 1"},
+//      {"square": 70, "effect": "swap leader; again"}
+//    ]
+//
+// A script is statements separated by semicolons, run in order on
+// whoever lands on the square. Each is an action, or "if COND then
+// ACTION [else ACTION]". The actions are:
+//
+//    forward N, back N   move N squares, stopping at square 1 and the finish
+//    goto N              move to square N
+//    skip N              miss the next N turns
+//    again               roll again
+//    swap leader         trade places with the leading opponent
+//    swap last           trade places with the opponent furthest behind
+//    give ITEM           collect an item (one of ItemKinds)
+//
+// Moves a script makes take no snakes or ladders. N and COND are
+// integer expressions over numbers and roll (the die), square (the
+// script's), position (the mover's now), final (the finish square),
+// players (still playing) and rank (the mover's place, 1 leading), with
+// + - * / %, comparisons, and, or, not, brackets and random(A, B), which
+// draws from the game's effects as teleports do so replays agree. A
+// comparison is 1 or 0, and dividing by zero gives 0.
+//
+// The sandbox is the language itself: a script can only read and move
+// the players, it has no loops, and it is held to maxScriptLen bytes and
+// maxScriptDepth nesting, so every one runs in a few steps.
+
+const (
+    maxScriptLen   = 256
+    maxScriptDepth = 16
+)
+
+// scriptVars are the names a script's expressions can read
+var scriptVars = []string{"roll", "square", "position", "final", "players", "rank"}
+
+// ScriptSquare runs Script on whoever lands on it
+type ScriptSquare struct {
+    Pos    BoardPos
+    Script *SquareScript
+}
+
+func (s ScriptSquare) Dest() BoardPos { return s.Pos }
+
+// SquareScript is a square script compiled, with its Source
+type SquareScript struct {
+    Source string
+    stmts  []scriptStmt
+}
+
+// scriptStmt runs then if cond (nil for none) holds, or otherwise orElse
+type scriptStmt struct {
+    cond         scriptExpr
+    then, orElse scriptAction
+}
+
+type scriptExpr func(*scriptEnv) int
+
+type scriptAction func(*scriptEnv)
+
+// scriptEnv is what a running script sees and has done
+type scriptEnv struct {
+    gs    *GameState
+    idx   int // the mover
+    at    int // the script's square
+    roll  int
+    again bool
+}
+
+func (e *scriptEnv) mover() *Player { return &e.gs.Players[e.idx] }
+
+// CompileScript checks a square script and readies it to run
+func CompileScript(src string) (*SquareScript, error) {
+    if len(src) > maxScriptLen {
+        return nil, fmt.Errorf("script is %d bytes, over the %d allowed", len(src), maxScriptLen)
+    }
+    toks, err := lexScript(src)
+    if err != nil {
+        return nil, err
+    }
+    p := &scriptParser{toks: toks}
+    s := &SquareScript{Source: src}
+    for !p.done() {
+        if p.accept(";") {
+            continue
+        }
+        st, err := p.stmt()
+        if err != nil {
+            return nil, err
+        }
+        s.stmts = append(s.stmts, st)
+        if !p.done() && !p.accept(";") {
+            return nil, p.unexpected("; between statements")
+        }
+    }
+    if len(s.stmts) == 0 {
+        return nil, errors.New("script does nothing")
+    }
+    return s, nil
+}
+
+// lexScript splits src into numbers, names and operators
+func lexScript(src string) ([]string, error) {
+    var toks []string
+    for i := 0; i < len(src); {
+        c := rune(src[i])
+        switch {
+        case unicode.IsSpace(c):
+            i++
+        case c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c == '_':
+            j := i
+            for j < len(src) && (src[j] >= '0' && src[j] <= '9' || src[j] >= 'a' && src[j] <= 'z' || src[j] == '_') {
+                j++
+            }
+            toks = append(toks, src[i:j])
+            i = j
+        case strings.ContainsRune("=!<>", c) && i+1 < len(src) && src[i+1] == '=':
+            toks = append(toks, src[i:i+2])
+            i += 2
+        case strings.ContainsRune("<>+-*/%(),;", c):
+            toks = append(toks, src[i:i+1])
+            i++
+        default:
+            return nil, fmt.Errorf("unexpected %q at byte %d", src[i], i+1)
+        }
+    }
+    return toks, nil
+}
+
+// scriptParser compiles tokens by recursive descent
+type scriptParser struct {
+    toks  []string
+    pos   int
+    depth int
+    reads int // variables and random draws parsed; none since a point means a constant
+}
+
+func (p *scriptParser) done() bool { return p.pos >= len(p.toks) }
+
+func (p *scriptParser) peek() string {
+    if p.done() {
+        return ""
+    }
+    return p.toks[p.pos]
+}
+
+func (p *scriptParser) accept(tok string) bool {
+    if p.peek() == tok && !p.done() {
+        p.pos++
+        return true
+    }
+    return false
+}
+
+func (p *scriptParser) unexpected(want string) error {
+    if p.done() {
+        return fmt.Errorf("script ends where it wants %s", want)
+    }
+    return fmt.Errorf("want %s, not %q", want, p.peek())
+}
+
+func (p *scriptParser) stmt() (scriptStmt, error) {
+    if !p.accept("if") {
+        a, err := p.action()
+        return scriptStmt{then: a}, err
+    }
+    var st scriptStmt
+    var err error
+    if st.cond, err = p.expr(); err != nil {
+        return st, err
+    }
+    if !p.accept("then") {
+        return st, p.unexpected("then")
+    }
+    if st.then, err = p.action(); err != nil {
+        return st, err
+    }
+    if p.accept("else") {
+        st.orElse, err = p.action()
+    }
+    return st, err
+}
+
+func (p *scriptParser) action() (scriptAction, error) {
+    verb := p.peek()
+    p.pos++
+    switch verb {
+    case "forward", "back", "goto":
+        n, err := p.expr()
+        if err != nil {
+            return nil, err
+        }
+        return func(e *scriptEnv) {
+            to := n(e)
+            switch verb {
+            case "forward":
+                to += e.mover().Position.Index
+            case "back":
+                to = e.mover().Position.Index - to
+            }
+            e.mover().Position = mustBP(min(max(to, 1), e.gs.Board.FinalSquare.Index))
+        }, nil
+    case "skip":
+        n, err := p.expr()
+        if err != nil {
+            return nil, err
+        }
+        return func(e *scriptEnv) { e.mover().SkipTurns += max(n(e), 0) }, nil
+    case "again":
+        return func(e *scriptEnv) { e.again = true }, nil
+    case "swap":
+        who := p.peek()
+        if who != "leader" && who != "last" {
+            return nil, p.unexpected("leader or last after swap")
+        }
+        p.pos++
+        return func(e *scriptEnv) {
+            j := leadingOpponent(e.gs.Board, e.gs.Players, e.idx)
+            if who == "last" {
+                j = trailingOpponent(e.gs.Board, e.gs.Players, e.idx)
+            }
+            if j >= 0 {
+                ps := e.gs.Players
+                ps[e.idx].Position, ps[j].Position = ps[j].Position, ps[e.idx].Position
+            }
+        }, nil
+    case "give":
+        item, err := parseItem(p.peek())
+        if err != nil {
+            return nil, err
+        }
+        p.pos++
+        return func(e *scriptEnv) {
+            items := e.mover().Items
+            e.mover().Items = append(items[:len(items):len(items)], item)
+        }, nil
+    }
+    p.pos--
+    return nil, p.unexpected("forward, back, goto, skip, again, swap or give")
+}
+
+// expr parses an expression: or binds loosest, then and, not,
+// comparisons, + and -, and * / % tightest
+func (p *scriptParser) expr() (scriptExpr, error) {
+    if p.depth++; p.depth > maxScriptDepth {
+        return nil, fmt.Errorf("script nests more than %d deep", maxScriptDepth)
+    }
+    defer func() { p.depth-- }()
+    return p.binary(0)
+}
+
+// scriptLevels are the binary operators, loosest first
+var scriptLevels = [][]string{
+    {"or"},
+    {"and"},
+    {"==", "!=", "<", "<=", ">", ">="},
+    {"+", "-"},
+    {"*", "/", "%"},
+}
+
+func (p *scriptParser) binary(level int) (scriptExpr, error) {
+    if level == len(scriptLevels) {
+        return p.unary()
+    }
+    l, err := p.binary(level + 1)
+    if err != nil {
+        return nil, err
+    }
+    for {
+        op := p.peek()
+        if p.done() || !slices.Contains(scriptLevels[level], op) {
+            return l, nil
+        }
+        p.pos++
+        r, err := p.binary(level + 1)
+        if err != nil {
+            return nil, err
+        }
+        l = scriptOp(op, l, r)
+    }
+}
+
+func (p *scriptParser) unary() (scriptExpr, error) {
+    switch {
+    case p.accept("-"):
+        x, err := p.subexpr(p.unary)
+        if err != nil {
+            return nil, err
+        }
+        return func(e *scriptEnv) int { return -x(e) }, nil
+    case p.accept("not"):
+        x, err := p.subexpr(p.unary)
+        if err != nil {
+            return nil, err
+        }
+        return func(e *scriptEnv) int { return truth(x(e) == 0) }, nil
+    case p.accept("("):
+        x, err := p.expr()
+        if err != nil {
+            return nil, err
+        }
+        if !p.accept(")") {
+            return nil, p.unexpected(")")
+        }
+        return x, nil
+    case p.accept("random"):
+        if !p.accept("(") {
+            return nil, p.unexpected("( after random")
+        }
+        reads := p.reads
+        lo, err := p.expr()
+        if err != nil {
+            return nil, err
+        }
+        if !p.accept(",") {
+            return nil, p.unexpected(", in random")
+        }
+        hi, err := p.expr()
+        if err != nil {
+            return nil, err
+        }
+        if !p.accept(")") {
+            return nil, p.unexpected(")")
+        }
+        if p.reads == reads {
+            if a, b := lo(nil), hi(nil); b > a && randomSpan(a, b) == 0 {
+                return nil, fmt.Errorf("random(%d, %d) spans more numbers than it can draw from", a, b)
+            }
+        }
+        p.reads++
+        return func(e *scriptEnv) int {
+            a, b := lo(e), hi(e)
+            if b <= a {
+                return a
+            }
+            n := nextRand(&e.gs.RandState)
+            if span := randomSpan(a, b); span != 0 {
+                n %= span
+            }
+            return a + int(n)
+        }, nil
+    }
+    tok := p.peek()
+    if n, err := strconv.Atoi(tok); err == nil && !p.done() {
+        p.pos++
+        return func(*scriptEnv) int { return n }, nil
+    }
+    if slices.Contains(scriptVars, tok) {
+        p.pos++
+        p.reads++
+        return scriptVar(tok), nil
+    }
+    return nil, p.unexpected("a number or one of " + strings.Join(scriptVars, ", "))
+}
+
+// randomSpan is how many numbers a..b holds, for b > a, or 0 if that
+// overflows a uint64, as every int does where ints are 64 bits
+func randomSpan(a, b int) uint64 {
+    return uint64(b) - uint64(a) + 1
+}
+
+// subexpr parses with f one level deeper, so nested negations count
+// against maxScriptDepth too
+func (p *scriptParser) subexpr(f func() (scriptExpr, error)) (scriptExpr, error) {
+    if p.depth++; p.depth > maxScriptDepth {
+        return nil, fmt.Errorf("script nests more than %d deep", maxScriptDepth)
+    }
+    defer func() { p.depth-- }()
+    return f()
+}
+
+func scriptOp(op string, l, r scriptExpr) scriptExpr {
+    return func(e *scriptEnv) int {
+        a := l(e)
+        switch op {
+        case "or":
+            if a != 0 {
+                return 1
+            }
+            return truth(r(e) != 0)
+        case "and":
+            if a == 0 {
+                return 0
+            }
+            return truth(r(e) != 0)
+        }
+        b := r(e)
+        switch op {
+        case "==":
+            return truth(a == b)
+        case "!=":
+            return truth(a != b)
+        case "<":
+            return truth(a < b)
+        case "<=":
+            return truth(a <= b)
+        case ">":
+            return truth(a > b)
+        case ">=":
+            return truth(a >= b)
+        case "+":
+            return a + b
+        case "-":
+            return a - b
+        case "*":
+            return a * b
+        case "/":
+            if b == 0 {
+                return 0
+            }
+            return a / b
+        }
+        if b == 0 {
+            return 0
+        }
+        return a % b
+    }
+}
+
+func scriptVar(name string) scriptExpr {
+    return func(e *scriptEnv) int {
+        switch name {
+        case "roll":
+            return e.roll
+        case "square":
+            return e.at
+        case "position":
+            return e.mover().Position.Index
+        case "final":
+            return e.gs.Board.FinalSquare.Index
+        }
+        n, ahead := 0, 0
+        for j, p := range e.gs.Players {
+            if p.Left || p.home(e.gs.Board.FinalSquare) {
+                continue
+            }
+            n++
+            if j != e.idx && p.Position.Index > e.mover().Position.Index {
+                ahead++
+            }
+        }
+        if name == "players" {
+            return n
+        }
+        return ahead + 1
+    }
+}
+
+func truth(b bool) int {
+    if b {
+        return 1
+    }
+    return 0
+}
+
+// run plays s on the player at idx, who rolled dr and is on s's square,
+// reporting whether it gives them another roll. It changes gs.Players in
+// place.
+func (s *SquareScript) run(gs *GameState, idx int, dr DieRoll) (again bool) {
+    e := &scriptEnv{gs: gs, idx: idx, at: gs.Players[idx].Position.Index, roll: dr.Value}
+    for _, st := range s.stmts {
+        switch {
+        case st.cond == nil || st.cond(e) != 0:
+            st.then(e)
+        case st.orElse != nil:
+            st.orElse(e)
+        }
+    }
+    return e.again
+}
+
+// trailingOpponent is leadingOpponent's opposite: the furthest-behind
+// player, other than idx or their teammates, still playing (earliest seat
+// on ties), or -1 if there is none
+func trailingOpponent(b Board, ps []Player, idx int) int {
+    worst := -1
+    for j, p := range ps {
+        if j == idx || p.home(b.FinalSquare) || (p.Team != "" && p.Team == ps[idx].Team) {
+            continue
+        }
+        if worst < 0 || p.Position.Index < ps[worst].Position.Index {
+            worst = j
+        }
+    }
+    return worst
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+    "strings"
+    "testing"
+)
+
+// random over every int spans one more number than a uint64 holds, which
+// once wrapped to a zero modulus and panicked
+func TestScriptRandomWholeRange(t *testing.T) {
+    _, err := CompileScript("goto random(-9223372036854775807-1, 9223372036854775807)")
+    if err == nil || !strings.Contains(err.Error(), "spans more numbers") {
+        t.Errorf("CompileScript took random over every int: %v", err)
+    }
+    // bounds read from the game can't be checked until the script runs
+    s, err := CompileScript("goto random(position - position - 9223372036854775807 - 1, 9223372036854775807)")
+    if err != nil {
+        t.Fatal(err)
+    }
+    gs := newGameState(CreateStandardBoard(), []string{"Alice", "Bob"})
+    placeAtStart(&gs)
+    for range 100 {
+        s.run(&gs, 0, DieRoll{3})
+        if at := gs.Players[0].Position.Index; at < 1 || at > gs.Board.FinalSquare.Index {
+            t.Fatalf("the script moved Alice off the board, to %d", at)
+        }
+    }
+}
@@ -0,0 +1,27 @@
+#!/bin/sh
+# Builds single-binary releases (assets embedded) for every supported
+# platform, and a WebAssembly build for web pages (with the wasm_exec.js
+# that loads it), into dist/, with a SHA256SUMS file. Run from the repository root:
+#
+#   scripts/release.sh v1.2.0
+set -eu
+version=${1:?usage: scripts/release.sh VERSION}
+if [ ! -f go.mod ]; then
+    echo "release.sh: no go.mod here; run it from the repository root" >&2
+    exit 1
+fi
+go generate ./...
+go run . assets verify
+rm -rf dist && mkdir dist
+for target in linux/amd64 linux/arm64 darwin/amd64 darwin/arm64 windows/amd64 windows/arm64; do
+    os=${target%/*}
+    arch=${target#*/}
+    out=dist/snakesladders-$version-$os-$arch
+    [ "$os" = windows ] && out=$out.exe
+    echo "building $out"
+    CGO_ENABLED=0 GOOS=$os GOARCH=$arch go build -trimpath -ldflags "-s -w" -o "$out" .
+done
+echo "building dist/snakesladders-$version.wasm"
+GOOS=js GOARCH=wasm go build -trimpath -ldflags "-s -w" -o "dist/snakesladders-$version.wasm" .
+cp "$(go env GOROOT)/lib/wasm/wasm_exec.js" dist/
+cd dist && sha256sum snakesladders-* > SHA256SUMS
@@ -0,0 +1,99 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "strconv"
+    "strings"
+)
+
+// maxPlayers is the most players setup seats; beyond it the wait between
+// turns gets long and the board's initials hard to tell apart
+const maxPlayers = 8
+
+// errSetupInput is setup's input running out before every question was
+// answered
+var errSetupInput = errors.New("input ended before setup finished")
+
+// runSetup asks at the terminal how many are playing, their names and
+// which of them the computer plays, asking again after a bad answer.
+// It returns the players in turn order and the bots with their levels.
+func runSetup(ctx context.Context, in *lineInput, out io.Writer, msgs Catalog) ([]string, []string, map[string]BotLevel, error) {
+    ask := func(prompt string) (string, error) {
+        fmt.Fprint(out, prompt+" ")
+        return readSetupLine(ctx, in)
+    }
+    var count int
+    for {
+        line, err := ask(msgs.T(MsgSetupCount, maxPlayers))
+        if err != nil {
+            return nil, nil, nil, err
+        }
+        if n, err := strconv.Atoi(line); err == nil && n >= 2 && n <= maxPlayers {
+            count = n
+            break
+        }
+        fmt.Fprintln(out, msgs.T(MsgSetupBadCount, maxPlayers))
+    }
+    var names []string
+    seen := map[string]bool{}
+    for len(names) < count {
+        name, err := ask(msgs.T(MsgSetupName, len(names)+1))
+        if err != nil {
+            return nil, nil, nil, err
+        }
+        switch {
+        case name == "" || strings.ContainsAny(name, ",:;="):
+            fmt.Fprintln(out, msgs.T(MsgSetupBadName))
+        case seen[name]:
+            fmt.Fprintln(out, msgs.T(MsgSetupNameTaken, name))
+        default:
+            seen[name] = true
+            names = append(names, name)
+        }
+    }
+    var bots []string
+    levels := map[string]BotLevel{}
+    for _, name := range names {
+        for {
+            line, err := ask(msgs.T(MsgSetupBot, name))
+            if err != nil {
+                return nil, nil, nil, err
+            }
+            line = strings.ToLower(line)
+            if line == "" || line == "n" || line == "no" {
+                break
+            }
+            switch line {
+            case "y", "yes", "s", "si", "sí":
+                line = ""
+            }
+            if level, err := ParseBotLevel(line); err == nil {
+                bots = append(bots, name)
+                levels[name] = level
+                break
+            }
+            fmt.Fprintln(out, msgs.T(MsgSetupBadBot))
+        }
+    }
+    return names, bots, levels, nil
+}
+
+// readSetupLine reads one trimmed line from in, giving up when ctx is
+// done
+func readSetupLine(ctx context.Context, in *lineInput) (string, error) {
+    line, err := in.ReadLine(ctx)
+    if err == io.EOF {
+        err = errSetupInput
+    }
+    return line, err
+}
+
+// isTerminal reports whether f is a terminal rather than a file or pipe
+func isTerminal(f *os.File) bool {
+    fi, err := f.Stat()
+    return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+    "bufio"
+    "context"
+    "encoding/json"
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "os"
+    "reflect"
+    "runtime"
+    "sync"
+)
+
+// SimConfig drives a batch simulation run
+type SimConfig struct {
+    Games     int
+    Seed      int64 // game i uses Seed+i, so any game can be replayed alone
+    Names     []string
+    MaxTurns  int
+    MaxMemory uint64 // heap bytes before per-game records are sampled; 0 = no limit
+    Dice      string // a ParseRoller spec; "" rolls fair
+
+    Checkpoint      string `json:"-"` // file progress is saved to; "" = none
+    CheckpointEvery int    `json:"-"` // games between checkpoints
+    Workers         int    `json:"-"` // goroutines playing games; 0 = one per CPU
+}
+
+// SimRecord is the line written for one simulated game
+type SimRecord struct {
+    Game   int    `json:"game"`
+    Seed   int64  `json:"seed"`
+    Winner string `json:"winner,omitempty"`
+    Turns  int    `json:"turns"`
+}
+
+// SimSummary aggregates a run. Its size depends only on the player count,
+// never on the number of games.
+type SimSummary struct {
+    Games       int
+    Wins        []int
+    Unfinished  int
+    TotalTurns  int
+    Written     int // records actually written
+    SampleEvery int // 1 unless the memory guard kicked in
+}
+
+func (s SimSummary) MeanTurns() float64 {
+    if s.Games == 0 {
+        return 0
+    }
+    return float64(s.TotalTurns) / float64(s.Games)
+}
+
+const memCheckEvery = 1024
+
+// simBatch is how many games the workers play ahead of the records and
+// summary, which take the results in game order
+const simBatch = 8192
+
+// SimCheckpoint is how far a run had got: everything needed to carry on
+// from game Next and end with the same summary and records as a run that
+// was never interrupted
+type SimCheckpoint struct {
+    Version int // FormatVersion when written
+    Config  SimConfig
+    Board   BoardConfig
+    Next    int
+    Summary SimSummary
+    Written int64 // bytes of records output for games before Next
+}
+
+// loadSimCheckpoint reads the checkpoint at path, checking it belongs to
+// the same run (games, seed, players, limits and board)
+func loadSimCheckpoint(path string, b Board, cfg SimConfig) (*SimCheckpoint, error) {
+    var cp SimCheckpoint
+    if err := readJSONFile(path, &cp); err != nil {
+        return nil, err
+    }
+    if cp.Summary.Wins == nil {
+        return nil, fmt.Errorf("%s: no checkpoint to resume from", path)
+    }
+    cp.Config.Checkpoint, cp.Config.CheckpointEvery, cp.Config.Workers = cfg.Checkpoint, cfg.CheckpointEvery, cfg.Workers
+    if !reflect.DeepEqual(cp.Config, cfg) || !reflect.DeepEqual(cp.Board, ConfigFromBoard("", b)) {
+        return nil, errors.New(path + ": checkpoint is for a different run (games, seed, players, dice or board changed)")
+    }
+    return &cp, nil
+}
+
+type countingWriter struct {
+    w io.Writer
+    n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+    n, err := c.w.Write(p)
+    c.n += int64(n)
+    return n, err
+}
+
+// runSimulation plays cfg.Games games on b, streaming one JSON record per
+// game to w as it goes. If the heap grows past cfg.MaxMemory the run keeps
+// going but writes only every SampleEvery-th record, doubling the interval
+// each time the limit is hit again.
+// Cancelling ctx stops the run between games; the summary covers the games
+// finished so far.
+//
+// With cfg.Checkpoint set, progress is saved every cfg.CheckpointEvery
+// games and when the run stops. Passing that checkpoint back as resume
+// (with w positioned at its Written offset) continues where it left off.
+// Every game is seeded independently, so the result is identical to an
+// uninterrupted run (unless the memory guard starts sampling records, which
+// depends on the heap at the time).
+//
+// Games are shared out among cfg.Workers goroutines a batch at a time.
+// Each game still rolls its own stream, seeded by its number, so the
+// results don't depend on how many workers there are.
+func runSimulation(ctx context.Context, b Board, cfg SimConfig, w io.Writer, resume *SimCheckpoint) (SimSummary, error) {
+    sum := SimSummary{Wins: make([]int, len(cfg.Names)), SampleEvery: 1}
+    if _, err := ParseRoller(cfg.Dice, cfg.Seed); err != nil {
+        return sum, err
+    }
+    cw := &countingWriter{w: w}
+    start := 0
+    if resume != nil {
+        sum, cw.n, start = resume.Summary, resume.Written, resume.Next
+    }
+    bw := bufio.NewWriter(cw)
+    enc := json.NewEncoder(bw)
+    var ms runtime.MemStats
+    board := ConfigFromBoard("", b)
+    checkpoint := func(next int) error {
+        if err := bw.Flush(); err != nil {
+            return err
+        }
+        if cfg.Checkpoint == "" {
+            return nil
+        }
+        return writeJSONFile(cfg.Checkpoint, SimCheckpoint{Version: FormatVersion, Config: cfg, Board: board, Next: next, Summary: sum, Written: cw.n})
+    }
+
+    results := make([]SimResult, simBatch)
+    for i := start; i < cfg.Games; i++ {
+        if (i-start)%simBatch == 0 {
+            playBatch(b, cfg, i, results[:min(simBatch, cfg.Games-i)])
+        }
+        if i%256 == 0 && ctx.Err() != nil {
+            return sum, errors.Join(ctx.Err(), checkpoint(i))
+        }
+        if cfg.CheckpointEvery > 0 && i > start && i%cfg.CheckpointEvery == 0 {
+            if err := checkpoint(i); err != nil {
+                return sum, err
+            }
+        }
+        seed := cfg.Seed + int64(i)
+        res := results[(i-start)%simBatch]
+        sum.Games++
+        sum.TotalTurns += res.Turns
+        rec := SimRecord{Game: i, Seed: seed, Turns: res.Turns}
+        if res.Winner < 0 {
+            sum.Unfinished++
+        } else {
+            sum.Wins[res.Winner]++
+            rec.Winner = cfg.Names[res.Winner]
+        }
+        if i%sum.SampleEvery == 0 {
+            if err := enc.Encode(rec); err != nil {
+                return sum, err
+            }
+            sum.Written++
+        }
+        if cfg.MaxMemory > 0 && i%memCheckEvery == memCheckEvery-1 {
+            if err := bw.Flush(); err != nil {
+                return sum, err
+            }
+            runtime.ReadMemStats(&ms)
+            if ms.HeapAlloc > cfg.MaxMemory {
+                // only garbage? then it's not our fault yet
+                runtime.GC()
+                runtime.ReadMemStats(&ms)
+            }
+            if ms.HeapAlloc > cfg.MaxMemory {
+                sum.SampleEvery *= 2
+            }
+        }
+    }
+    return sum, checkpoint(cfg.Games)
+}
+
+// playBatch plays games lo, lo+1, ... into out, sharing them among
+// cfg.Workers goroutines
+func playBatch(b Board, cfg SimConfig, lo int, out []SimResult) {
+    workers := cfg.Workers
+    if workers <= 0 {
+        workers = runtime.NumCPU()
+    }
+    workers = min(workers, len(out))
+    var wg sync.WaitGroup
+    for w := 0; w < workers; w++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for k := w; k < len(out); k += workers {
+                seed := cfg.Seed + int64(lo+k)
+                r, _ := ParseRoller(cfg.Dice, seed) // runSimulation checked it
+                out[k] = simulateGame(b, cfg.Names, r, uint64(seed), cfg.MaxTurns)
+            }
+        }()
+    }
+    wg.Wait()
+}
+
+func printSimSummary(out io.Writer, names []string, s SimSummary) {
+    fmt.Fprintf(out, "%d games, mean %.1f turns", s.Games, s.MeanTurns())
+    if s.Unfinished > 0 {
+        fmt.Fprintf(out, ", %d unfinished", s.Unfinished)
+    }
+    fmt.Fprintln(out)
+    for i, n := range names {
+        fmt.Fprintf(out, "  %-10s %6.2f%% wins\n", n, 100*float64(s.Wins[i])/float64(s.Games))
+    }
+    if s.SampleEvery > 1 {
+        fmt.Fprintf(out, "memory limit reached: wrote %d of %d records (sampling 1 in %d)\n", s.Written, s.Games, s.SampleEvery)
+    }
+}
+
+func cmdSimulate(fs *flag.FlagSet) func(context.Context) error {
+    boardPath := addBoardFlag(fs)
+    seed := addSeedFlag(fs, "the first game's RNG seed")
+    workers := fs.Int("workers", 0, "goroutines to play the games on (0: one per CPU)")
+    dice := fs.String("dice", "", "dice: fair (the default), crypto, weights:W1,...,W6 (biased) or script:V1,V2,... (a fixed sequence, repeated)")
+    resultsPath := fs.String("results", "", "stream one JSON record per simulated game to this file")
+    maxMemory := fs.Uint64("max-memory", 0, "heap limit in MiB; beyond it per-game records are sampled")
+    checkpointPath := fs.String("checkpoint", "", "save progress to this file periodically and on interrupt")
+    resume := fs.Bool("resume", false, "continue the run saved in the -checkpoint file")
+    timeout := fs.Duration("game-timeout", 0, "abandon the simulation after this long")
+    return func(ctx context.Context) error {
+        games, err := gamesArg(fs)
+        if err != nil {
+            return err
+        }
+        b, err := boardFlag(*boardPath)
+        if err != nil {
+            return err
+        }
+        if *timeout > 0 {
+            var cancel context.CancelFunc
+            ctx, cancel = context.WithTimeout(ctx, *timeout)
+            defer cancel()
+        }
+        names := []string{"Alice", "Bob"}
+        cfg := SimConfig{Games: games, Seed: *seed, Names: names, MaxTurns: 10000, MaxMemory: *maxMemory << 20, Dice: *dice,
+            Checkpoint: *checkpointPath, CheckpointEvery: 10000, Workers: *workers}
+        if _, err := ParseRoller(cfg.Dice, cfg.Seed); err != nil {
+            return usageError{err}
+        }
+        var cp *SimCheckpoint
+        if *resume {
+            if *checkpointPath == "" {
+                return usageError{errors.New("-resume needs -checkpoint")}
+            }
+            if cp, err = loadSimCheckpoint(*checkpointPath, b, cfg); err != nil {
+                return err
+            }
+            fmt.Printf("resuming at game %d of %d\n", cp.Next, cfg.Games)
+        }
+        w := io.Discard
+        if *resultsPath != "" {
+            f, err := os.OpenFile(*resultsPath, os.O_RDWR|os.O_CREATE, 0o644)
+            if err == nil {
+                // drop records written after the checkpoint; they'll be redone
+                var keep int64
+                if cp != nil {
+                    keep = cp.Written
+                }
+                if err = f.Truncate(keep); err == nil {
+                    _, err = f.Seek(keep, io.SeekStart)
+                }
+            }
+            if err != nil {
+                return err
+            }
+            defer f.Close()
+            w = f
+        }
+        sum, err := runSimulation(ctx, b, cfg, w, cp)
+        printSimSummary(os.Stdout, names, sum)
+        return err
+    }
+}
@@ -0,0 +1,47 @@
+package main
+
+// stepInPlace is applyMove without the copy: it mutates gs directly. Bulk
+// simulation uses it to avoid allocating a player slice per turn, so it
+// must stay in lockstep with applyMove (see runDiffTest), but for player
+// stats, which simulations have no use for.
+func stepInPlace(gs *GameState, dr DieRoll) {
+    idx := gs.CurrentPlayerIndex
+    cur := &gs.Players[idx]
+    if back, forfeit := gs.ruleUnits().beforeRoll(cur, dr); forfeit {
+        forfeitRoll(gs, idx, back)
+        return
+    }
+    square := settle(*gs, *cur, dr)
+    cur.Position = square.Dest()
+    resolveEffect(gs, idx, square, dr)
+}
+
+// SimResult is the outcome of one simulated game
+type SimResult struct {
+    Winner int // index into the player list, -1 if the turn cap was hit
+    Turns  int
+}
+
+// simulateGame plays names to completion on b with no I/O, stopping after
+// maxTurns rolls if nobody has won. effectSeed seeds random square effects.
+func simulateGame(b Board, names []string, r Roller, effectSeed uint64, maxTurns int) SimResult {
+    gs := newGameState(b, names)
+    gs.RandState = effectSeed
+    for turn := 1; turn <= maxTurns; turn++ {
+        idx := gs.CurrentPlayerIndex
+        stepInPlace(&gs, r.Roll())
+        if gs.Players[idx].Position == b.FinalSquare {
+            return SimResult{Winner: idx, Turns: turn}
+        }
+    }
+    return SimResult{Winner: -1, Turns: maxTurns}
+}
+
+func newGameState(b Board, names []string) GameState {
+    start := mustBP(1)
+    players := make([]Player, len(names))
+    for i, n := range names {
+        players[i] = Player{Name: n, Position: start}
+    }
+    return GameState{Board: b, Players: players}
+}
@@ -1,11 +1,16 @@
 package main
 
 import (
-    "bufio"
+    "context"
     "errors"
+    "flag"
     "fmt"
+    "io"
     "math/rand"
+    "net"
     "os"
+    "slices"
+    "strings"
     "time"
 )
 
@@ -14,6 +19,10 @@ type BoardPos struct {
     Index int
 }
 
+// OffBoard is where a player waits before coming on under the
+// enter_on_six rule; it is no square of any board
+var OffBoard = BoardPos{}
+
 func NewBoardPos(i int) (BoardPos, error) {
     if i < 1 || i > 100 {
         return BoardPos{}, fmt.Errorf("BoardPos out of bounds: %d", i)
@@ -53,34 +62,82 @@ func (s Snake) Dest() BoardPos       { return s.To }
 type Ladder struct{ From, To BoardPos }
 func (l Ladder) Dest() BoardPos      { return l.To }
 
-// Board holds the map and final square
+// SkipTurn costs whoever lands on it their next turn
+type SkipTurn struct{ Pos BoardPos }
+func (s SkipTurn) Dest() BoardPos    { return s.Pos }
+
+// ExtraRoll lets whoever lands on it roll again straight away
+type ExtraRoll struct{ Pos BoardPos }
+func (e ExtraRoll) Dest() BoardPos   { return e.Pos }
+
+// Teleport sends whoever lands on it to a random square short of the
+// finish; Dest is only where the teleport starts
+type Teleport struct{ Pos BoardPos }
+func (t Teleport) Dest() BoardPos    { return t.Pos }
+
+// Swap trades places with the leading opponent
+type Swap struct{ Pos BoardPos }
+func (s Swap) Dest() BoardPos        { return s.Pos }
+
+// Board holds the squares and the final square. Build one with a
+// BoardBuilder; the zero Board has no squares.
 type Board struct {
-    Squares    map[int]Square
+    squares     []Square // squares[i] is square i; squares[0] is unused
     FinalSquare BoardPos
 }
 
+// Square is square i, or nil if b has none
+func (b Board) Square(i int) Square {
+    if i < 1 || i >= len(b.squares) {
+        return nil
+    }
+    return b.squares[i]
+}
+
+// IsZero reports whether b is the zero Board
+func (b Board) IsZero() bool {
+    return b.squares == nil
+}
+
 func CreateStandardBoard() Board {
-    squares := make(map[int]Square, 100)
-    for i := 1; i <= 100; i++ {
-        pos, _ := NewBoardPos(i)
-        squares[i] = Normal{pos}
+    b, err := NewBoardBuilder(100).
+        // Snakes
+        AddSnake(16, 6).
+        AddSnake(47, 26).
+        // ... (other snakes) ...
+        AddSnake(98, 78).
+        // Ladders
+        AddLadder(1, 38).
+        AddLadder(4, 14).
+        // ... (other ladders) ...
+        AddLadder(80, 100).
+        Build()
+    if err != nil {
+        panic(err) // the layout above is fixed; only editing it wrongly gets here
     }
-    add := func(from, to int, sq Square) {
-        squares[from] = sq
+    return b
+}
+
+// Snakes lists the board's snakes in square order
+func (b Board) Snakes() []Snake {
+    var out []Snake
+    for i := 1; i <= b.FinalSquare.Index; i++ {
+        if s, ok := b.Square(i).(Snake); ok {
+            out = append(out, s)
+        }
     }
-    // Snakes
-    add(16, 6, Snake{mustBP(16), mustBP(6)})
-    add(47, 26, Snake{mustBP(47), mustBP(26)})
-    // ... (other snakes) ...
-    add(98, 78, Snake{mustBP(98), mustBP(78)})
-    // Ladders
-    add(1, 38, Ladder{mustBP(1), mustBP(38)})
-    add(4, 14, Ladder{mustBP(4), mustBP(14)})
-    // ... (other ladders) ...
-    add(80, 100, Ladder{mustBP(80), mustBP(100)})
+    return out
+}
 
-    final, _ := NewBoardPos(100)
-    return Board{Squares: squares, FinalSquare: final}
+// Ladders lists the board's ladders in square order
+func (b Board) Ladders() []Ladder {
+    var out []Ladder
+    for i := 1; i <= b.FinalSquare.Index; i++ {
+        if l, ok := b.Square(i).(Ladder); ok {
+            out = append(out, l)
+        }
+    }
+    return out
 }
 
 func mustBP(i int) BoardPos {
@@ -93,8 +150,39 @@ func mustBP(i int) BoardPos {
 
 // Player
 type Player struct {
-    Name     string
-    Position BoardPos
+    Name      string
+    Position  BoardPos
+    SkipTurns int           // turns still to be missed
+    Items     []ItemKind    // collected and not yet used; never modified in place
+    Immune    bool          // an immunity item is armed against the next snake
+    Boost     int           // squares added to the next roll
+    Team      string        // "" outside team games
+    Tokens    []BoardPos    // every token's square when playing with several; see tokens.go
+    Active    int           // which of Tokens Position is
+    Left      bool          // has left a network game, or run out of time: their tokens stay put and their turns are passed over
+    Sixes     int           // sixes rolled in a row, counted under the three_sixes rules
+    TurnStart []BoardPos    // tokenSquares before the first of those sixes
+    Clock     time.Duration // time left under Rules.Clock; see clocks.go
+    Stats     PlayerStats
+}
+
+// PlayerStats are a player's figures for the game so far
+type PlayerStats struct {
+    Rolls    int
+    Faces    [6]int // Faces[n-1] is how many times they rolled n
+    Snakes   int    // snakes slid down
+    Ladders  int    // ladders climbed
+    Traveled int    // squares moved by their rolls, not counting snakes and ladders
+    // History is every roll, in order. It is only ever appended to through
+    // a fresh array, so copies of a state can share it.
+    History []int `json:",omitempty"`
+}
+
+// count adds a roll of dr to st
+func (st *PlayerStats) count(dr DieRoll) {
+    st.Rolls++
+    st.Faces[dr.Value-1]++
+    st.History = append(st.History[:len(st.History):len(st.History)], dr.Value)
 }
 
 // GameState
@@ -102,74 +190,1079 @@ type GameState struct {
     Board              Board
     Players            []Player
     CurrentPlayerIndex int
+    RandState          uint64 // drives random square effects; part of the state so moves stay reproducible
+    Dice               DiceStream
+    Rules              Rules
+    Turns              int // rolls played, counted against Rules.MaxTurns
+
+    units ruleSet // Rules' units, built by setRules
+}
+
+// Rules are the optional rules a game is played with; the zero value is
+// the classic game
+type Rules struct {
+    TeamWin TeamWin // how a team game is won; ignored without teams
+    Capture Capture // what happens to a player someone lands on
+    Tokens  int     // tokens per player; a player wins once all are home
+
+    // movement rules; see rules.go
+    ExactWin   bool // rolls past the final square are forfeited
+    Bounce     bool // rolls past the final square bounce back off it
+    SixAgain   bool // a six earns another turn
+    ChainJumps bool // a jump ending on another jump takes that one too
+    EnterOnSix bool // players start off the board and need a six to come on
+
+    PickDie bool // each turn offers two rolls and the player takes one
+
+    ThreeSixes ThreeSixes // what a third six in a row does
+
+    MaxTurns  int       // rolls after which, with nobody home, the game ends (0: never)
+    TurnLimit TurnLimit // how it ends then
+
+    Clock time.Duration // each player's time for the whole game (0: no clocks); see clocks.go
 }
 
-// Outcome sum type
-type Outcome interface{}
+// Outcome sum type: Ongoing, Win, Draw, Abandoned or Paused. It is
+// sealed, so a type switch over those covers every outcome.
+type Outcome interface {
+    isOutcome()
+    String() string // for display
+}
 
 type Ongoing struct{ State GameState }
-type Win struct{ Winner Player }
+// Win names the player who finished and, in team games, their team (with
+// TeamWinAll, Winner is the team's first seat)
+type Win struct {
+    Winner Player
+    Team   string
+}
 
-func applyMove(gs GameState, dr DieRoll) GameState {
+// Draw is a game over with nobody winning: a full grid in tic-tac-toe or
+// Connect Four, or in Snakes & Ladders a game that hit Rules.MaxTurns
+// under TurnLimitDraw, or under TurnLimitClosest with the leaders tied
+// (see limitOutcome)
+type Draw struct{}
+
+// Abandoned is a game stopped before anyone won
+type Abandoned struct {
+    State  GameState
+    Reason AbortReason
+}
+
+// Paused is a game saved part-way through, to be carried on with `resume`
+type Paused struct{ State GameState }
+
+func (Ongoing) isOutcome()   {}
+func (Win) isOutcome()       {}
+func (Draw) isOutcome()      {}
+func (Abandoned) isOutcome() {}
+func (Paused) isOutcome()    {}
+
+func (Ongoing) String() string { return "in progress" }
+func (Draw) String() string    { return "draw" }
+func (Paused) String() string  { return "paused" }
+
+func (w Win) String() string {
+    if w.Team != "" {
+        return "won by team " + w.Team
+    }
+    return "won by " + w.Winner.Name
+}
+
+func (a Abandoned) String() string {
+    if a.Reason == "" {
+        return "abandoned"
+    }
+    return "abandoned (" + string(a.Reason) + ")"
+}
+
+// AbortReason says, machine-readably, why a game was abandoned
+type AbortReason string
+
+const (
+    AbortPlayerQuit     AbortReason = "player_quit"
+    AbortNetworkFailure AbortReason = "network_failure"
+    AbortAdmin          AbortReason = "admin_action"
+    AbortTimeout        AbortReason = "timeout"
+    AbortInterrupted    AbortReason = "interrupted"
+    AbortPlayersLeft    AbortReason = "players_left"
+)
+
+// abortReasonFor maps a context error to the reason it represents
+func abortReasonFor(err error) AbortReason {
+    if errors.Is(err, context.DeadlineExceeded) {
+        return AbortTimeout
+    }
+    return AbortInterrupted
+}
+
+// ErrBadState is wrapped by the errors ApplyMove returns for game states
+// no game could have reached, e.g. ones decoded from a damaged file
+var ErrBadState = errors.New("invalid game state")
+
+// ApplyMove plays the current player's roll of dr in gs, as applyMove
+// does, but first checks gs and dr make sense, so that a bad state is an
+// error rather than a crash
+func ApplyMove(gs GameState, dr DieRoll) (GameState, error) {
+    if err := checkMove(gs, dr); err != nil {
+        return gs, err
+    }
+    return applyMove(gs, dr), nil
+}
+
+// checkMove reports why applyMove can't play dr in gs, if it can't
+func checkMove(gs GameState, dr DieRoll) error {
+    if dr.Value < 1 || dr.Value > 6 {
+        return fmt.Errorf("%w: a die can't roll %d", ErrBadState, dr.Value)
+    }
+    return checkState(gs)
+}
+
+// checkState reports the first thing wrong with gs: no players, a turn
+// index out of range, a board with missing squares or jumps off it, or a
+// player off the board
+func checkState(gs GameState) error {
     b := gs.Board
-    ps := append([]Player(nil), gs.Players...) // copy
+    final := b.FinalSquare.Index
+    switch {
+    case len(gs.Players) == 0:
+        return fmt.Errorf("%w: no players", ErrBadState)
+    case gs.CurrentPlayerIndex < 0 || gs.CurrentPlayerIndex >= len(gs.Players):
+        return fmt.Errorf("%w: turn index %d with %d players", ErrBadState, gs.CurrentPlayerIndex, len(gs.Players))
+    case final < 2 || final > 100:
+        return fmt.Errorf("%w: board ends on square %d", ErrBadState, final)
+    }
+    on := func(pos BoardPos) bool { return pos.Index >= 1 && pos.Index <= final }
+    waiting := gs.ruleUnits().start() == OffBoard
+    for i := 1; i <= final; i++ {
+        sq := b.Square(i)
+        if sq == nil {
+            return fmt.Errorf("%w: board has no square %d", ErrBadState, i)
+        }
+        from := i
+        switch sq := sq.(type) {
+        case Snake:
+            from = sq.From.Index
+        case Ladder:
+            from = sq.From.Index
+        }
+        if from != i || !on(sq.Dest()) {
+            return fmt.Errorf("%w: square %d leads to square %d", ErrBadState, i, sq.Dest().Index)
+        }
+    }
+    for _, p := range gs.Players {
+        if p.Boost < 0 {
+            return fmt.Errorf("%w: %s has a boost of %d", ErrBadState, p.Name, p.Boost)
+        }
+        if len(p.Tokens) > 0 && (p.Active < 0 || p.Active >= len(p.Tokens)) {
+            return fmt.Errorf("%w: %s moves token %d of %d", ErrBadState, p.Name, p.Active+1, len(p.Tokens))
+        }
+        for _, sq := range append([]BoardPos{p.Position}, p.Tokens...) {
+            if !on(sq) && !(waiting && sq == OffBoard) {
+                return fmt.Errorf("%w: %s is on square %d, off the board", ErrBadState, p.Name, sq.Index)
+            }
+        }
+    }
+    return nil
+}
+
+// applyMove plays the current player's roll of dr in gs, which must pass
+// checkMove
+func applyMove(gs GameState, dr DieRoll) GameState {
+    gs.Players = append([]Player(nil), gs.Players...) // copy
+    gs.Apply(dr)
+    return gs
+}
+
+// Apply is applyMove in place: it plays the current player's roll of dr
+// by changing gs, and so gs.Players, directly. That saves copying the
+// players every turn, but any copy of gs sharing its Players sees the
+// change, so use it on states nothing else holds. gs must pass checkMove.
+func (gs *GameState) Apply(dr DieRoll) {
     idx := gs.CurrentPlayerIndex
-    cur := ps[idx]
+    if back, forfeit := gs.ruleUnits().beforeRoll(&gs.Players[idx], dr); forfeit {
+        gs.Players[idx].Stats.count(dr)
+        forfeitRoll(gs, idx, back)
+        return
+    }
+    square := settle(*gs, gs.Players[idx], dr)
+    countRoll(*gs, &gs.Players[idx], dr)
+    gs.Players[idx].Position = square.Dest()
+    resolveEffect(gs, idx, square, dr)
+}
 
-    raw := cur.Position.Index + dr.Value
-    var newPos BoardPos
-    if raw > b.FinalSquare.Index {
-        newPos = b.FinalSquare
-    } else {
-        newPos = mustBP(raw)
+// countRoll adds p's roll of dr in gs to their stats, before they move
+func countRoll(gs GameState, p *Player, dr DieRoll) {
+    st := &p.Stats
+    st.count(dr)
+    land := playerLanding(gs, *p, dr)
+    st.Traveled += max(land.Index-p.Position.Index, p.Position.Index-land.Index)
+    sq := gs.Board.Square(land.Index)
+    if _, snake := sq.(Snake); snake && p.Immune {
+        return
+    }
+    gs.ruleUnits().eachJump(gs.Board, sq, func(j Square) {
+        switch j.(type) {
+        case Snake:
+            st.Snakes++
+        case Ladder:
+            st.Ladders++
+        }
+    })
+}
+
+// settle is the square p's roll of dr ends on: where it lands, or the end
+// of the jumps taken from there. An immune player stops at a snake.
+func settle(gs GameState, p Player, dr DieRoll) Square {
+    land := playerLanding(gs, p, dr)
+    if land == OffBoard {
+        return Normal{OffBoard}
     }
+    sq := gs.Board.Square(land.Index)
+    if _, snake := sq.(Snake); snake && p.Immune {
+        return sq
+    }
+    gs.ruleUnits().eachJump(gs.Board, sq, func(j Square) { sq = j })
+    return sq
+}
+
+// resolveEffect finishes the move of the player at idx, who rolled dr,
+// has just landed on sq and taken any snake or ladder there: it applies
+// special square effects and the rules' after-move effects (such as
+// capture), then picks who plays next: the same player after an extra
+// roll, otherwise as passTurn does. It changes gs.Players in place.
+func resolveEffect(gs *GameState, idx int, sq Square, dr DieRoll) {
+    b, ps := gs.Board, gs.Players
+    gs.Turns++
+    ps[idx].Boost = 0
+    again := false
+    switch sq := sq.(type) {
+    case Snake:
+        if ps[idx].Immune {
+            ps[idx].Position, ps[idx].Immune = sq.From, false
+        }
+    case ItemSquare:
+        ps[idx].Items = append(ps[idx].Items[:len(ps[idx].Items):len(ps[idx].Items)], sq.Item)
+    case SkipTurn:
+        ps[idx].SkipTurns++
+    case ExtraRoll:
+        again = true
+    case Teleport:
+        ps[idx].Position = mustBP(1 + int(nextRand(&gs.RandState)%uint64(b.FinalSquare.Index-1)))
+    case Swap:
+        if j := leadingOpponent(b, ps, idx); j >= 0 {
+            ps[idx].Position, ps[j].Position = ps[j].Position, ps[idx].Position
+        }
+    case ScriptSquare:
+        again = sq.Script.run(gs, idx, dr) && !ps[idx].home(b.FinalSquare)
+    }
+    if gs.ruleUnits().afterMove(gs, idx, dr) && !ps[idx].home(b.FinalSquare) {
+        again = true
+    }
+    for j := range ps {
+        ps[j].syncActive()
+    }
+    if again {
+        gs.CurrentPlayerIndex = idx
+        return
+    }
+    passTurn(gs, idx)
+}
 
-    square := b.Squares[newPos.Index]
-    dest := square.Dest()
-    ps[idx].Position = dest
+// forfeitRoll ends the turn of the player at idx without moving, bar
+// putting their tokens on back. It changes gs.Players in place.
+func forfeitRoll(gs *GameState, idx int, back []BoardPos) {
+    gs.Turns++
+    gs.Players[idx].Boost = 0
+    gs.Players[idx].setTokens(back)
+    passTurn(gs, idx)
+}
 
+// passTurn gives the turn to the next seat after idx that isn't missing a
+// turn (missed turns are used up as they are passed over), already home or
+// left. It changes gs.Players in place.
+func passTurn(gs *GameState, idx int) {
+    ps := gs.Players
     next := (idx + 1) % len(ps)
-    return GameState{b, ps, next}
+    for i := 0; i < 2*len(ps); i++ {
+        if ps[next].home(gs.Board.FinalSquare) || ps[next].Left {
+            next = (next + 1) % len(ps)
+            continue
+        }
+        if ps[next].SkipTurns == 0 {
+            break
+        }
+        ps[next].SkipTurns--
+        next = (next + 1) % len(ps)
+    }
+    gs.CurrentPlayerIndex = next
+}
+
+// leadingOpponent is the furthest-ahead player, other than idx or their
+// teammates, who is still playing (earliest seat on ties), or -1 if there
+// is none
+func leadingOpponent(b Board, ps []Player, idx int) int {
+    best := -1
+    for j, p := range ps {
+        if j == idx || p.home(b.FinalSquare) || (p.Team != "" && p.Team == ps[idx].Team) {
+            continue
+        }
+        if best < 0 || p.Position.Index > ps[best].Position.Index {
+            best = j
+        }
+    }
+    return best
+}
+
+// nextRand is splitmix64: a tiny generator whose whole state is one
+// integer, so it can live inside an immutable GameState
+func nextRand(s *uint64) uint64 {
+    *s += 0x9e3779b97f4a7c15
+    z := *s
+    z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+    z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+    return z ^ (z >> 31)
+}
+
+// landing is the square a roll reaches before any snake or ladder applies
+func landing(b Board, from BoardPos, dr DieRoll) BoardPos {
+    return advance(b, from, dr.Value)
+}
+
+// playerLanding is where p's roll of dr lands in gs, counting any boost
+func playerLanding(gs GameState, p Player, dr DieRoll) BoardPos {
+    if p.Position == OffBoard {
+        return gs.ruleUnits().enter(dr)
+    }
+    return gs.ruleUnits().advance(gs.Board, p.Position, dr.Value+p.Boost)
+}
+
+func advance(b Board, from BoardPos, steps int) BoardPos {
+    raw := from.Index + steps
+    if raw > b.FinalSquare.Index {
+        return b.FinalSquare
+    }
+    return mustBP(raw)
 }
 
 func checkOutcome(gs GameState) Outcome {
     for _, p := range gs.Players {
-        if p.Position == gs.Board.FinalSquare {
-            return Win{p}
+        if !p.home(gs.Board.FinalSquare) {
+            continue
+        }
+        if p.Team == "" || gs.Rules.TeamWin != TeamWinAll {
+            return Win{Winner: p, Team: p.Team}
+        }
+        if teamHome(gs, p.Team) {
+            return Win{Winner: p, Team: p.Team}
         }
     }
+    if o, ok := clockOutcome(gs); ok {
+        return o
+    }
+    if gs.Rules.MaxTurns > 0 && gs.Turns >= gs.Rules.MaxTurns {
+        return limitOutcome(gs)
+    }
     return Ongoing{gs}
 }
 
-func play(names []string) {
-    rand.Seed(time.Now().UnixNano())
-    board := CreateStandardBoard()
-    players := make([]Player, len(names))
-    start, _ := NewBoardPos(1)
-    for i, n := range names {
-        players[i] = Player{Name: n, Position: start}
+// Options tweaks how the interactive loop runs
+type Options struct {
+    TurnTimeout time.Duration // auto-roll after this long; 0 waits for Enter
+    Speed       Speed
+    Pacing      *Pacing // overrides Speed's pacing when set
+    Msgs        Catalog
+    Observers   []Observer
+    Out         io.Writer // narration; defaults to stdout
+    Deps        Deps
+    Board       Board // zero value means the standard board
+    Teams       []Team
+    Rules       Rules
+    Input       *lineInput // where local players type; defaults to stdin
+    Controllers map[string]PlayerController // players not at this terminal, by name
+    Resume      *Autosave    // carry on this paused game instead of starting afresh
+    Store       GameStore    // where pausing saves the game; defaults to the config directory
+    Seed        uint64       // seeds the dice and square effects; 0 picks one at random
+    Theme       Theme        // how the board is drawn; zero is classic
+    Color       bool         // colour the narration (see Style)
+    Accessible  bool         // narrate in plain sentences, for screen readers (see spokenEvents)
+    Odds        bool         // after every turn, show each player's chance of winning (see winOdds)
+    UI          string       // how to show the game: one of uiNames, "" for text
+    CrashSaves  bool         // after every turn, save the game under crashKey (see offerCrashResume)
+    Profiles    ProfileStore // players' preferred colours and tokens; nil plays without
+    Order       string       // how names' order was decided, OrderRollOff or OrderShuffle, for the start event; "" as given
+}
+
+// play runs an interactive game until someone wins, a player quits or
+// pauses, or ctx is done. Quitting and cancellation give an abandoned
+// result, cancellation also returning ctx's error; pausing (the pause
+// command, or cancellation with errPaused) saves the game for `resume`.
+// With opts.CrashSaves it is also saved under crashKey after every turn,
+// for the caller to forget once play returns: one left behind was cut off.
+func play(ctx context.Context, names []string, opts Options) (GameResult, error) {
+    board := opts.Board
+    if board.IsZero() {
+        board = CreateStandardBoard()
+    }
+    state := newGameState(board, names)
+    state.RandState = uint64(time.Now().UnixNano())
+    state.Dice = randomDice()
+    if opts.Seed != 0 {
+        state.RandState, state.Dice = opts.Seed, NewDiceStream(opts.Seed)
+    }
+    state.setRules(opts.Rules)
+    placeAtStart(&state)
+    assignTeams(&state, opts.Teams)
+    setupTokens(&state, opts.Rules.Tokens)
+    startClocks(&state)
+    input := opts.Input
+    if input == nil {
+        input = stdinInput()
+    }
+    pacing := opts.Speed.Pacing()
+    if opts.Pacing != nil {
+        pacing = *opts.Pacing
+    }
+    // accessible narration comes from the spokenEvents observer; play
+    // keeps to prompts and answers
+    if opts.Accessible {
+        pacing.RollAnimation, pacing.Suspense = 0, 0
+    }
+    msgs := opts.Msgs
+    out := opts.Out
+    if out == nil {
+        out = os.Stdout
+    }
+    if opts.Deps.Rand == nil && opts.Seed != 0 {
+        opts.Deps.Rand = rand.New(rand.NewSource(int64(opts.Seed)))
+    }
+    deps := opts.Deps.withDefaults()
+    clock := deps.Clock
+    gameID := deps.IDs.NewID()
+    started := clock.Now()
+    turns := 0
+    if r := opts.Resume; r != nil {
+        restored, err := r.Restore(board)
+        if err != nil {
+            return GameResult{}, err
+        }
+        state, turns, gameID, started = restored, r.Turn, r.GameID, r.StartedAt
+        state.setRules(r.Rules)
+    }
+    var seated []string
+    for _, p := range state.Players {
+        seated = append(seated, p.Name)
+    }
+    profiles := map[string]PlayerProfile{}
+    if opts.Profiles != nil {
+        var err error
+        if profiles, err = loadProfiles(opts.Profiles, seated); err != nil {
+            fmt.Fprintln(os.Stderr, msgs.T(MsgWarnProfiles, err))
+        }
+    }
+    theme, tokens := opts.Theme.withMessages(msgs), map[string]rune{}
+    for n, p := range profiles {
+        if p.Token != "" && checkToken(p.Token) == nil {
+            tokens[n] = []rune(p.Token)[0]
+        }
+    }
+    if len(tokens) > 0 {
+        theme = theme.withTokens(tokens)
+    }
+    var style Style
+    if opts.Color {
+        style = newStyleFor(seated, profiles)
+    }
+    var render Renderer
+    switch opts.UI {
+    case "json":
+        render = newJSONRenderer(out, msgs)
+    case "tui":
+        render = newTUIRenderer(out, msgs, theme, style)
+    default:
+        render = &textRenderer{out: out, msgs: msgs, theme: theme, style: style, spoken: opts.Accessible}
+    }
+    if opts.Resume != nil {
+        render.RenderNotice(MsgResumed, turns, state.Players[state.CurrentPlayerIndex].Name)
+    }
+    start := startEvent(state, turns, clock.Now())
+    if opts.Resume == nil {
+        start.Reason = opts.Order
+    }
+    notify(opts.Observers, start)
+    abandon := func(reason AbortReason) GameResult {
+        ended := clock.Now()
+        notify(opts.Observers, Event{Kind: EventAbort, Time: ended, Turn: turns, Reason: string(reason), Hash: stateHash(state)})
+        return newGameResult(gameID, state, Abandoned{state, reason}, turns, started, ended)
+    }
+    store, storeErr := opts.Store, error(nil)
+    if store == nil {
+        store, storeErr = openStore("")
+    }
+    // save keeps the game as it stands under key, for resume
+    save := func(key string) error {
+        if storeErr != nil {
+            return storeErr
+        }
+        return store.Save(key, Autosave{
+            Snapshot:  takeSnapshot(state, turns),
+            Board:     ConfigFromBoard("", board),
+            Rules:     state.Rules,
+            GameID:    gameID,
+            StartedAt: started,
+        })
+    }
+    crashSaves := opts.CrashSaves
+    pause := func() (GameResult, error) {
+        if err := save(autosaveKey); err != nil {
+            render.RenderNotice(MsgPauseFailed, err)
+            return abandon(AbortInterrupted), err
+        }
+        ended := clock.Now()
+        notify(opts.Observers, Event{Kind: EventPause, Time: ended, Turn: turns, Hash: stateHash(state)})
+        render.RenderNotice(MsgPaused)
+        return newGameResult(gameID, state, Paused{state}, turns, started, ended), nil
+    }
+    // halt ends the game after err, pausing rather than abandoning it if
+    // that's why ctx was cancelled
+    halt := func(err error) (GameResult, error) {
+        if errors.Is(context.Cause(ctx), errPaused) {
+            return pause()
+        }
+        return abandon(abortReasonFor(err)), err
     }
-    state := GameState{board, players, 0}
-    reader := bufio.NewReader(os.Stdin)
 
     for {
-        if res := checkOutcome(state); win, ok := res.(Win); ok {
-            fmt.Printf("%s wins the game!\n", win.Winner.Name)
-            return
+        switch o := checkOutcome(state).(type) {
+        case Win:
+            ended := clock.Now()
+            notify(opts.Observers, winEvent(o, state, turns, ended))
+            render.RenderWin(state, o)
+            return newGameResult(gameID, state, o, turns, started, ended), nil
+        case Draw:
+            ended := clock.Now()
+            notify(opts.Observers, drawEvent(state, turns, ended))
+            render.RenderWin(state, o)
+            return newGameResult(gameID, state, o, turns, started, ended), nil
+        case Abandoned:
+            return abandon(o.Reason), nil
+        }
+        if err := ctx.Err(); err != nil {
+            return halt(err)
         }
         cur := state.Players[state.CurrentPlayerIndex]
-        fmt.Printf("%s's turn. Press Enter to roll...\n", cur.Name)
-        reader.ReadString('\n')
-        roll := RollDie()
-        fmt.Printf("Rolled: %d\n", roll.Value)
-        state = applyMove(state, roll)
-        prev := (state.CurrentPlayerIndex + len(state.Players) - 1) % len(state.Players)
-        moved := state.Players[prev]
-        fmt.Printf("%s moves to %d\n", moved.Name, moved.Position.Index)
-        fmt.Println("--------------------------------")
+        var ctl PlayerController = localController{input}
+        if c, ok := opts.Controllers[cur.Name]; ok {
+            ctl = c
+        }
+        if b, ok := ctl.(botController); ok && b.rng == nil {
+            b.rng = deps.Rand
+            ctl = b
+        }
+        _, keys := ctl.(keyController)
+        render.RenderTurnPrompt(state, keys)
+        // the player's clock runs from the prompt to their roll, and caps
+        // the wait: it running out first forfeits rather than auto-rolls
+        waitFrom, limit := clock.Now(), opts.TurnTimeout
+        if state.Rules.Clock > 0 && (limit <= 0 || cur.Clock < limit) {
+            limit = cur.Clock
+        }
+        line, answered, err := ctl.WaitTurn(ctx, Prompt{State: state}, clock, limit, render)
+        if err != nil {
+            return halt(err)
+        }
+        if state.Rules.Clock > 0 {
+            idx := state.CurrentPlayerIndex
+            state.Players = append([]Player(nil), state.Players...)
+            state.Players[idx].Clock -= clock.Now().Sub(waitFrom)
+            if state.Players[idx].Clock <= 0 {
+                state = flagFall(state, idx)
+                notify(opts.Observers, timeUpEvent(state, idx, turns, clock.Now()))
+                render.RenderNotice(MsgFlagFell, cur.Name)
+                continue
+            }
+        }
+        if !answered {
+            render.RenderNotice(MsgTimeUp)
+        } else if line != "" {
+            emit := func(ev Event) {
+                ev.Time, ev.Turn = clock.Now(), turns
+                notify(opts.Observers, ev)
+            }
+            switch handleCommand(render, line, &state, &turns, emit) {
+            case cmdQuit:
+                render.RenderNotice(MsgQuit)
+                return abandon(AbortPlayerQuit), nil
+            case cmdPause:
+                return pause()
+            }
+            continue
+        }
+        if err := pacing.animateRoll(ctx, render); err != nil {
+            return halt(err)
+        }
+        // the roll is drawn into a copy, so a game halted before it is
+        // logged ends in the state its log leads to
+        next := state
+        var roll DieRoll
+        var choices []DieRoll
+        if next.Rules.PickDie {
+            notify(opts.Observers, Event{Kind: EventOffer, Time: clock.Now(), Turn: turns, Player: cur.Name, Choices: dieValues(next.Dice.Peek(2))})
+            if next, roll, choices, err = chooseDie(ctx, ctl, clock, opts.TurnTimeout, render, next); err != nil {
+                return halt(err)
+            }
+        } else {
+            roll = next.Dice.Roll()
+            render.RenderRoll(cur.Name, roll.Value)
+        }
+        if len(cur.Tokens) > 0 {
+            if next, err = chooseToken(ctx, ctl, clock, opts.TurnTimeout, render, next, roll); err != nil {
+                return halt(err)
+            }
+            cur = next.Players[next.CurrentPlayerIndex]
+        }
+        if _, normal := board.Square(playerLanding(next, cur, roll).Index).(Normal); !normal {
+            if err := pacing.suspense(ctx, render); err != nil {
+                return halt(err)
+            }
+        }
+        turns++
+        idx := next.CurrentPlayerIndex
+        evs := turnEvents(next, roll, turns, clock.Now())
+        evs[0].Choices = dieValues(choices)
+        for i, ev := range evs {
+            if i > 0 {
+                // cut short, not halted: a turn's events go out together
+                sleepCtx(ctx, pacing.BetweenEvents)
+            }
+            notify(opts.Observers, ev)
+            render.RenderEffect(ev)
+        }
+        state = applyMove(next, roll)
+        render.RenderMove(state, idx, evs)
+        if _, ongoing := checkOutcome(state).(Ongoing); ongoing && opts.Odds {
+            render.RenderNotice(MsgOdds, oddsLine(state))
+        }
+        if crashSaves {
+            if err := save(crashKey); err != nil {
+                render.RenderNotice(MsgWarnCrashSave, err)
+                crashSaves = false
+            }
+        }
+        render.RenderTurnEnd()
+        if err := sleepCtx(ctx, pacing.AfterTurn); err != nil {
+            return halt(err)
+        }
+    }
+}
+
+func winLine(msgs Catalog, winner, team string) string {
+    if team != "" {
+        return msgs.T(MsgTeamWins, team, winner)
+    }
+    return msgs.T(MsgWins, winner)
+}
+
+// chooseDie offers the current player the next two rolls and draws both,
+// returning the one they take. Anything but 1 or 2 (including no answer in
+// time) takes the first.
+func chooseDie(ctx context.Context, input PlayerController, clock Clock, limit time.Duration, r Renderer, gs GameState) (GameState, DieRoll, []DieRoll, error) {
+    offer := gs.Dice.Peek(2)
+    r.RenderNotice(MsgPickDie, offer[0].Value, offer[1].Value)
+    line, answered, err := input.WaitTurn(ctx, Prompt{State: gs, Choices: offer}, clock, limit, r)
+    if err != nil {
+        return gs, DieRoll{}, nil, err
+    }
+    msg, pick := MsgDieDefault, 1
+    if answered && (line == "1" || line == "2") {
+        msg, pick = MsgDieTaken, int(line[0]-'0')
+    }
+    roll, choices := drawRoll(&gs.Dice, gs.Rules, pick)
+    r.RenderNotice(msg, roll.Value)
+    return gs, roll, choices, nil
+}
+
+// chooseToken asks the current player which token to move with roll.
+// Anything but a valid choice (including no answer in time) moves the
+// first token that isn't home.
+func chooseToken(ctx context.Context, input PlayerController, clock Clock, limit time.Duration, r Renderer, gs GameState, roll DieRoll) (GameState, error) {
+    ks := movableTokens(gs)
+    if len(ks) > 1 {
+        cur := gs.Players[gs.CurrentPlayerIndex]
+        var opts []string
+        for _, k := range ks {
+            opts = append(opts, fmt.Sprintf("%d (%d -> %d)", k+1, cur.Tokens[k].Index, gs.ruleUnits().advance(gs.Board, cur.Tokens[k], roll.Value+cur.Boost).Index))
+        }
+        r.RenderNotice(MsgChooseToken, strings.Join(opts, ", "))
+        line, answered, err := input.WaitTurn(ctx, Prompt{State: gs, Roll: roll}, clock, limit, r)
+        if err != nil {
+            return gs, err
+        }
+        var k int
+        if _, scanErr := fmt.Sscan(line, &k); answered && scanErr == nil {
+            if chosen, err := withToken(gs, k-1); err == nil {
+                return chosen, nil
+            }
+        }
+        r.RenderNotice(MsgTokenDefault, ks[0]+1)
+    }
+    return withToken(gs, ks[0])
+}
+
+// narrateEffect tells the table about special squares and items; plain
+// moves, snakes and ladders speak for themselves in the position line
+func narrateEffect(out io.Writer, msgs Catalog, style Style, ev Event) {
+    ev.Player, ev.Other = style.Player(ev.Player), style.Player(ev.Other)
+    switch ev.Kind {
+    case EventSkipTurn:
+        fmt.Fprintln(out, msgs.T(MsgSkipTurn, ev.Player))
+    case EventExtraRoll:
+        fmt.Fprintln(out, msgs.T(MsgExtraRoll, ev.Player))
+    case EventTeleport:
+        fmt.Fprintln(out, msgs.T(MsgTeleported, ev.Player, ev.From, ev.To))
+    case EventSwap:
+        fmt.Fprintln(out, msgs.T(MsgSwapped, ev.Player, ev.Other))
+    case EventScript:
+        if ev.Other != "" {
+            fmt.Fprintln(out, msgs.T(MsgScriptOther, ev.Other, ev.Player, ev.To))
+        } else if ev.From != ev.To {
+            fmt.Fprintln(out, msgs.T(MsgScript, ev.Player, ev.To))
+        }
+    case EventSkipped:
+        fmt.Fprintln(out, msgs.T(MsgSkipped, ev.Player))
+    case EventItem:
+        fmt.Fprintln(out, msgs.T(MsgItemFound, ev.Player, ev.Item))
+    case EventShield:
+        fmt.Fprintln(out, msgs.T(MsgShield, ev.Player, ev.From))
+    case EventCapture:
+        fmt.Fprintln(out, msgs.T(MsgCaptured, ev.Player, ev.Other, ev.To))
+    case EventThreeSixes:
+        fmt.Fprintln(out, msgs.T(MsgThreeSixes, ev.Player, ev.To))
     }
 }
 
 func main() {
-    play([]string{"Alice", "Bob"})
+    if exportJS() {
+        return
+    }
+    os.Exit(runCLI(os.Args[1:]))
+}
+
+// playCommand defines the game played at this terminal as the command
+// name: play, resume or daily, or "" for no command at all
+func playCommand(name string) func(fs *flag.FlagSet) func(context.Context) error {
+    return func(fs *flag.FlagSet) func(context.Context) error {
+        f := addPlayFlags(fs)
+        return func(ctx context.Context) error {
+            return playHere(ctx, name, fs, f)
+        }
+    }
+}
+
+// playHere plays the games f describes at this terminal: one, or a
+// match's worth, or until one is paused
+func playHere(ctx context.Context, name string, fs *flag.FlagSet, f *playFlags) error {
+    switch name {
+    case "":
+        if fs.NArg() > 0 {
+            return usageError{fmt.Errorf("%q isn't a flag; a command goes before its flags", fs.Arg(0))}
+        }
+    case "resume", "daily":
+        if err := wantArgs(fs, 0, 1); err != nil {
+            return err
+        }
+    }
+    opts, err := f.options()
+    if err != nil {
+        return err
+    }
+    msgs := opts.Msgs
+    // Ctrl-Z pauses the game too, once it is under way, and then exits as
+    // the signal would have, like Ctrl-C
+    ctx, suspend := context.WithCancelCause(ctx)
+    defer exitOnSignal(ctx)
+    defer suspend(nil)
+    if *f.gameTimeout > 0 {
+        var cancel context.CancelFunc
+        ctx, cancel = context.WithTimeout(ctx, *f.gameTimeout)
+        defer cancel()
+    }
+
+    resumeKey := autosaveKey
+    switch name {
+    case "daily":
+        day, err := dailyDay(fs.Arg(0), time.Now())
+        if err == nil {
+            opts.Board, opts.Seed, err = dailyChallenge(day)
+        }
+        fs.Visit(func(fl *flag.Flag) {
+            if slices.Contains(dailyFixedFlags, fl.Name) {
+                err = fmt.Errorf("-%s would change the daily challenge, which everyone plays the same way", fl.Name)
+            }
+        })
+        if err != nil {
+            return usageError{err}
+        }
+        fmt.Println(msgs.T(MsgDaily, day.Format(time.DateOnly)))
+    case "resume":
+        if fs.Arg(0) != "" {
+            resumeKey = fs.Arg(0)
+        }
+        save, b, err := loadAutosave(opts.Store, resumeKey)
+        if err != nil {
+            return err
+        }
+        opts.Board, opts.Resume = b, &save
+    case "":
+        if !*f.auto && isTerminal(os.Stdin) {
+            if save, b, ok := offerCrashResume(ctx, opts.Store, stdinInput(), os.Stdout, msgs); ok {
+                opts.Board, opts.Resume, resumeKey = b, &save, crashKey
+            }
+        }
+    }
+    s, err := f.session(ctx, fs, &opts)
+    if err != nil {
+        return err
+    }
+    defer s.Close()
+    names := s.names
+    if *f.teams != "" {
+        teams, err := ParseTeams(*f.teams)
+        if err == nil {
+            opts.Rules.TeamWin, err = ParseTeamWin(*f.teamWin)
+        }
+        if err != nil {
+            return usageError{err}
+        }
+        opts.Teams, names = teams, seating(teams)
+    }
+    switch {
+    case *f.rollOff && *f.shuffle:
+        err = errors.New("-roll-off and -shuffle-order each decide the order of play; give one")
+    case (*f.rollOff || *f.shuffle) && *f.teams != "":
+        err = errors.New("teams take turns alternately, so their order can't be rolled off or shuffled")
+    case *f.rollOff:
+        opts.Order = OrderRollOff
+    case *f.shuffle:
+        opts.Order = OrderShuffle
+    }
+    if err != nil {
+        return usageError{err}
+    }
+    if opts.Resume != nil {
+        names = nil
+        for _, p := range opts.Resume.Players {
+            names = append(names, p.Name)
+        }
+    }
+    var bots, remotes []string
+    var levels map[string]BotLevel
+    seated := *f.setup
+    fs.Visit(func(fl *flag.Flag) {
+        switch fl.Name {
+        case "players", "teams", "bots":
+            if *f.setup {
+                err = fmt.Errorf("-setup asks who is playing, so leave out -%s", fl.Name)
+            }
+            seated = true
+        }
+    })
+    if err == nil && *f.setup && opts.Resume != nil {
+        err = errors.New("-setup can't change who is playing a resumed game")
+    }
+    if err != nil {
+        return usageError{err}
+    }
+    if *f.setup || !seated && opts.Resume == nil && !*f.auto && isTerminal(os.Stdin) {
+        names, bots, levels, err = runSetup(ctx, stdinInput(), os.Stdout, msgs)
+        if err != nil {
+            return fmt.Errorf("setup: %w", err)
+        }
+    }
+    if *f.bots != "" {
+        bots, levels, err = parseBots(*f.bots)
+    }
+    if err == nil && *f.remote != "" {
+        remotes, err = parseNameList(*f.remote)
+    }
+    if err == nil {
+        _, err = seatKinds(names, bots, remotes)
+    }
+    if err == nil && *f.auto && *f.keys {
+        err = errors.New("-auto and -keys don't mix: -auto never reads the keyboard")
+    }
+    if err == nil && *f.auto && len(remotes) > 0 {
+        err = errors.New("-auto plays every seat here, so there are none for -remote players")
+    }
+    if err != nil {
+        return usageError{err}
+    }
+    opts.Controllers = map[string]PlayerController{}
+    for _, n := range bots {
+        opts.Controllers[n] = botController{level: levels[n]}
+    }
+    if *f.auto {
+        for _, n := range names {
+            if _, bot := opts.Controllers[n]; !bot {
+                opts.Controllers[n] = autoController{}
+            }
+        }
+    }
+    if len(remotes) > 0 {
+        ln, err := net.Listen("tcp", *f.remoteAddr)
+        if err != nil {
+            return err
+        }
+        local := opts.Out
+        if local == nil {
+            local = os.Stdout
+        }
+        bcast := &broadcastWriter{local: local}
+        defer bcast.Close()
+        ctls, err := acceptRemotes(ctx, ln, remotes, bcast, msgs)
+        ln.Close()
+        if err != nil {
+            return fmt.Errorf("remote: %w", err)
+        }
+        for n, c := range ctls {
+            opts.Controllers[n] = c
+        }
+        opts.Out = bcast
+    }
+    entries := names
+    if opts.Teams != nil {
+        entries = nil
+        for _, t := range opts.Teams {
+            entries = append(entries, t.Name)
+        }
+    }
+    match := NewMatch(entries, max(*f.match, 1))
+    switch {
+    case *f.auto:
+        opts.Input = newLineInput(strings.NewReader(""))
+    case *f.keys:
+        in := stdinInput()
+        restore, err := keyInput(in, os.Stdin)
+        if err != nil {
+            return usageError{err}
+        }
+        defer restore() // on panic too
+        opts.Input = in
+        for _, n := range names {
+            if _, ok := opts.Controllers[n]; !ok {
+                opts.Controllers[n] = keyController{in}
+            }
+        }
+    case len(remotes) > 0:
+        // the host chats as whoever plays at this terminal
+        var here []string
+        for _, n := range names {
+            if _, ok := opts.Controllers[n]; !ok {
+                here = append(here, n)
+            }
+        }
+        who, out := strings.Join(here, ", "), opts.Out
+        if who == "" {
+            who = "host"
+        }
+        opts.Input = stdinInput()
+        opts.Input.chat(func(text string) {
+            fmt.Fprint(out, chatLine(msgs, who, text))
+        })
+    default:
+        opts.Input = stdinInput()
+    }
+    if name == "play" {
+        return runPlay(ctx, fs.Args(), names, opts)
+    }
+    narration := opts.Out
+    if narration == nil {
+        narration = os.Stdout
+    }
+    go pauseOnSignal(ctx, suspend, suspendSignals...)
+    opts.CrashSaves = true
+    // one source for the whole series, so a seeded one decides every
+    // game's order and bot choices the same way each run
+    if opts.Seed != 0 {
+        opts.Deps.Rand = rand.New(rand.NewSource(int64(opts.Seed)))
+    }
+    opts.Deps = opts.Deps.withDefaults()
+    for {
+        order := match.StartingOrder(names)
+        if opts.Resume == nil {
+            order = decideOrder(order, opts.Order, opts.Deps.Rand, narration, msgs)
+        }
+        res, playErr := play(ctx, order, opts)
+        if err := opts.Store.Delete(crashKey); err != nil {
+            fmt.Fprintln(os.Stderr, msgs.T(MsgWarnCrashSave, err))
+        }
+        if playErr != nil {
+            fmt.Fprintln(os.Stderr, msgs.T(MsgCancelled, playErr))
+        }
+        if res.Outcome == OutcomePaused {
+            return nil
+        }
+        if opts.Resume != nil {
+            opts.Resume = nil
+            if err := removeAutosave(opts.Store, resumeKey); err != nil {
+                fmt.Fprintln(os.Stderr, "resume:", err)
+            }
+        }
+        if res.Outcome == OutcomeWin {
+            summary := summarize(s.history.Events())
+            summary.write(narration, msgs)
+            if *f.summary != "" {
+                if err := writeSummary(*f.summary, summary, msgs); err != nil {
+                    fmt.Fprintln(os.Stderr, msgs.T(MsgWarnSummary, err))
+                }
+            }
+            awards := computeAwards(s.history.Events())
+            printAwards(narration, awards, msgs)
+            if *f.card != "" {
+                if err := writeSummaryCard(*f.card, res, awards, msgs); err != nil {
+                    fmt.Fprintln(os.Stderr, msgs.T(MsgWarnCard, err))
+                }
+            }
+            // the ladder rates individuals; team results would skew it
+            if res.Team == "" {
+                if err := updateRatings(res); err != nil {
+                    fmt.Fprintln(os.Stderr, msgs.T(MsgWarnRatings, err))
+                }
+            }
+        }
+        if res.Outcome == OutcomeWin || res.Outcome == OutcomeDraw {
+            if err := updateProfiles(opts.Profiles, res, s.history.Events(), narration, msgs); err != nil {
+                fmt.Fprintln(os.Stderr, msgs.T(MsgWarnProfiles, err))
+            }
+        }
+        if t := s.logs.transcript; t != nil && t.Err() != nil {
+            fmt.Fprintln(os.Stderr, msgs.T(MsgWarnTranscript, t.Err()))
+        }
+        if t := s.logs.transcripts; t != nil && t.Err() != nil {
+            fmt.Fprintln(os.Stderr, msgs.T(MsgWarnHistory, t.Err()))
+        }
+        if s.logs.db != nil {
+            if err := recordResult(s.logs.db, res); err != nil {
+                fmt.Fprintln(os.Stderr, msgs.T(MsgWarnHistory, err))
+            }
+        }
+        if s.prefs.ResultHook != "" {
+            if err := runResultHook(s.prefs.ResultHook, res); err != nil {
+                fmt.Fprintln(os.Stderr, msgs.T(MsgWarnHook, err))
+            }
+        }
+        if playErr != nil {
+            return exitCode(1)
+        }
+        if *f.match == 0 || res.Outcome != OutcomeWin && res.Outcome != OutcomeDraw {
+            return nil
+        }
+        decided := match.Record(res)
+        printMatchScore(narration, msgs, match)
+        if decided {
+            fmt.Fprintln(narration, msgs.T(MsgMatchWon, match.Winner()))
+            return nil
+        }
+        fmt.Fprintln(narration, msgs.T(MsgNextGame, match.Games+1))
+    }
 }
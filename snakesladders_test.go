@@ -0,0 +1,102 @@
+package main
+
+import (
+    "bytes"
+    "context"
+    "strings"
+    "testing"
+    "time"
+)
+
+// Alice and Bob take turns on the standard board, from square 1, with
+// rolls from a scripted die; no six, so nobody rolls again
+func TestScriptedRollsLand(t *testing.T) {
+    tests := []struct {
+        name       string
+        rolls      []int
+        alice, bob int
+    }{
+        {"plain square", []int{1}, 2, 1},
+        {"ladder foot", []int{3}, 14, 1}, // 4 climbs to 14
+        {"ladder foot, second player", []int{1, 3}, 2, 14},
+        {"snake head", []int{3, 1, 2}, 6, 2}, // 4 climbs to 14, then 16 slides to 6
+        {"snake head, second player", []int{1, 3, 1, 2}, 3, 6},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            r, err := NewScriptedRoller(tt.rolls...)
+            if err != nil {
+                t.Fatal(err)
+            }
+            gs := newGameState(CreateStandardBoard(), []string{"Alice", "Bob"})
+            placeAtStart(&gs)
+            for range tt.rolls {
+                if gs, err = ApplyMove(gs, r.Roll()); err != nil {
+                    t.Fatal(err)
+                }
+            }
+            if got := gs.Players[0].Position.Index; got != tt.alice {
+                t.Errorf("Alice is on %d, want %d", got, tt.alice)
+            }
+            if got := gs.Players[1].Position.Index; got != tt.bob {
+                t.Errorf("Bob is on %d, want %d", got, tt.bob)
+            }
+        })
+    }
+}
+
+func TestScriptedRollerRepeats(t *testing.T) {
+    r, err := NewScriptedRoller(2, 5)
+    if err != nil {
+        t.Fatal(err)
+    }
+    for i, want := range []int{2, 5, 2, 5} {
+        if got := r.Roll().Value; got != want {
+            t.Errorf("roll %d is %d, want %d", i+1, got, want)
+        }
+    }
+    for _, bad := range [][]int{nil, {0}, {7}, {3, 9}} {
+        if _, err := NewScriptedRoller(bad...); err == nil {
+            t.Errorf("NewScriptedRoller(%v) took rolls no die makes", bad)
+        }
+    }
+}
+
+// A seeded game with injected clock and IDs plays out the same every run:
+// the same winner after the same number of turns, told the same way
+func TestSeededGameEndToEnd(t *testing.T) {
+    start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+    run := func() (GameResult, string) {
+        var out bytes.Buffer
+        opts := Options{
+            Out:    &out,
+            Pacing: &Pacing{},
+            Seed:   7,
+            Rules:  Rules{Tokens: 2},
+            Store:  newMemoryStore(),
+            Input:  newLineInput(strings.NewReader("")),
+            Deps:   Deps{Clock: NewFakeClock(start), IDs: &SequentialIDs{Prefix: "game"}},
+            Controllers: map[string]PlayerController{
+                "Alice": autoController{},
+                "Bob":   autoController{},
+                "Carol": botController{level: BotEasy}, // chooses tokens with Deps.Rand
+            },
+        }
+        res, err := play(context.Background(), []string{"Alice", "Bob", "Carol"}, opts)
+        if err != nil {
+            t.Fatal(err)
+        }
+        return res, out.String()
+    }
+    res, told := run()
+    if res.Outcome != OutcomeWin || res.Winner != "Bob" || res.Turns != 218 {
+        t.Errorf("%s by %q after %d turns, want a win by Bob after 218", res.Outcome, res.Winner, res.Turns)
+    }
+    if res.GameID != "game1" || !res.StartedAt.Equal(start) {
+        t.Errorf("game %q started at %v, want game1 at %v", res.GameID, res.StartedAt, start)
+    }
+    again, toldAgain := run()
+    if again.Winner != res.Winner || again.Turns != res.Turns || toldAgain != told {
+        t.Errorf("a second run went differently: %s after %d turns", again.Winner, again.Turns)
+    }
+}
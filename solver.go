@@ -0,0 +1,134 @@
+package main
+
+import "fmt"
+
+// solverHorizon bounds how many turns ahead the solver looks. On the
+// standard board the chance of a game lasting this long is negligible.
+const solverHorizon = 1000
+
+// Solver results are cached by board fingerprint, since the coach asks
+// for the same tables turn after turn. A distribution is solverHorizon+1
+// floats (8KB), so distCache tops out around 4MB.
+var (
+    distCache = newLRUCache[distKey, []float64](512)
+    winCache  = newLRUCache[winKey, []float64](4096)
+)
+
+type distKey struct {
+    board string
+    from  int
+}
+
+type winKey struct {
+    board     string
+    rules     Rules
+    positions string
+    current   int
+}
+
+// finishDistribution returns p where p[t] is the chance a lone token on
+// from first reaches the final square on its t-th roll (p[0] is 1 if it is
+// already there). It is exact up to solverHorizon for snakes, ladders and
+// teleports; skip-turn, extra-roll and swap squares depend on the other
+// players, and items on choices, so those are treated as normal squares.
+// It assumes the classic movement rules (see Rules).
+// The result is shared with the cache and must not be modified.
+func finishDistribution(b Board, from BoardPos) []float64 {
+    return distCache.getOrCompute(distKey{boardFingerprint(b), from.Index}, func() []float64 {
+        return solveFinish(b, from)
+    })
+}
+
+func solveFinish(b Board, from BoardPos) []float64 {
+    final := b.FinalSquare.Index
+    p := make([]float64, solverHorizon+1)
+    if from.Index == final {
+        p[0] = 1
+        return p
+    }
+    m := transitionMatrix(b)
+    cur := make([]float64, final+1)
+    next := make([]float64, final+1)
+    cur[from.Index] = 1
+    for t := 1; t <= solverHorizon; t++ {
+        clear(next)
+        // off the board (enter_on_six), only a six brings a player on
+        next[0] = cur[0] * 5 / 6
+        next[b.Square(1).Dest().Index] += cur[0] / 6
+        for sq := 1; sq < final; sq++ {
+            if cur[sq] == 0 {
+                continue
+            }
+            for to, pr := range m[sq] {
+                next[to] += cur[sq] * pr
+            }
+        }
+        p[t] = next[final]
+        next[final] = 0
+        cur, next = next, cur
+    }
+    return p
+}
+
+// winProbabilities gives each player's chance of winning from gs, with
+// gs.CurrentPlayerIndex about to roll. Tokens don't interact, so each
+// player's finishing time is independent and player i wins on its k-th
+// roll if everyone rolling before it this round needs more than k rolls
+// and everyone after it needs at least k.
+func winProbabilities(gs GameState) []float64 {
+    key := winKey{board: boardFingerprint(gs.Board), rules: gs.Rules, current: gs.CurrentPlayerIndex}
+    for _, p := range gs.Players {
+        key.positions += fmt.Sprintf("%d,", p.Position.Index)
+    }
+    probs := winCache.getOrCompute(key, func() []float64 { return solveWin(gs) })
+    return append([]float64(nil), probs...)
+}
+
+func solveWin(gs GameState) []float64 {
+    n := len(gs.Players)
+    dist := make([][]float64, n)
+    surv := make([][]float64, n) // surv[i][k] = P(player i needs more than k rolls)
+    for i, p := range gs.Players {
+        dist[i] = finishDistribution(gs.Board, p.Position)
+        surv[i] = make([]float64, solverHorizon+1)
+        left := 1.0
+        for k := 0; k <= solverHorizon; k++ {
+            left -= dist[i][k]
+            surv[i][k] = max(left, 0)
+        }
+    }
+    probs := make([]float64, n)
+    for i := range gs.Players {
+        // seats ahead of i in this round's order, starting at the roller
+        order := (i - gs.CurrentPlayerIndex + n) % n
+        for k := 1; k <= solverHorizon; k++ {
+            pk := dist[i][k]
+            if pk == 0 {
+                continue
+            }
+            for j := range gs.Players {
+                if j == i {
+                    continue
+                }
+                if (j-gs.CurrentPlayerIndex+n)%n < order {
+                    pk *= surv[j][k]
+                } else {
+                    pk *= surv[j][k-1]
+                }
+            }
+            probs[i] += pk
+        }
+    }
+    return probs
+}
+
+// snakeChance is the chance a single roll from from ends on a snake
+func snakeChance(b Board, from BoardPos) float64 {
+    hits := 0
+    for face := 1; face <= 6; face++ {
+        if _, ok := b.Square(landing(b, from, DieRoll{face}).Index).(Snake); ok {
+            hits++
+        }
+    }
+    return float64(hits) / 6
+}
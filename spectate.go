@@ -0,0 +1,275 @@
+package main
+
+import (
+    "bufio"
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "net"
+    "sync"
+    "time"
+)
+
+// spectatorBuffer is how many events a spectator may fall behind by before
+// it is dropped rather than holding up the game
+const spectatorBuffer = 256
+
+// spectatorHub is an Observer fanning events out to read-only subscribers.
+// It keeps the game's history so late joiners catch up first. With a
+// delay set, spectators (late joiners included) only see each event that
+// long after it happened, so nobody watching can coach the players live.
+type spectatorHub struct {
+    mu      sync.Mutex
+    history []Event
+    subs    map[chan Event]struct{}
+    closed  bool
+    streams sync.WaitGroup
+
+    clock   Clock
+    delay   time.Duration
+    pending []delayedEvent
+    poke    chan struct{}
+}
+
+type delayedEvent struct {
+    due time.Time
+    ev  Event
+}
+
+func newSpectatorHub() *spectatorHub {
+    return &spectatorHub{subs: map[chan Event]struct{}{}}
+}
+
+// SetDelay holds every event back by d before spectators see it. Call it
+// before the first event.
+func (h *spectatorHub) SetDelay(clock Clock, d time.Duration) {
+    if d <= 0 {
+        return
+    }
+    h.clock, h.delay, h.poke = clock, d, make(chan struct{}, 1)
+    go h.release()
+}
+
+func (h *spectatorHub) OnEvent(ev Event) {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    if h.closed {
+        return
+    }
+    if h.delay > 0 {
+        h.pending = append(h.pending, delayedEvent{h.clock.Now().Add(h.delay), ev})
+        select {
+        case h.poke <- struct{}{}:
+        default:
+        }
+        return
+    }
+    h.publish(ev)
+}
+
+// release publishes delayed events as they come due, until Close
+func (h *spectatorHub) release() {
+    for {
+        h.mu.Lock()
+        if h.closed {
+            h.mu.Unlock()
+            return
+        }
+        var wait <-chan time.Time
+        if len(h.pending) > 0 {
+            if d := h.pending[0].due.Sub(h.clock.Now()); d > 0 {
+                wait = h.clock.After(d)
+            } else {
+                h.publish(h.pending[0].ev)
+                h.pending = h.pending[1:]
+                h.mu.Unlock()
+                continue
+            }
+        }
+        h.mu.Unlock()
+        select {
+        case <-wait:
+        case <-h.poke:
+        }
+    }
+}
+
+// publish hands ev to the history and subscribers; h.mu must be held
+func (h *spectatorHub) publish(ev Event) {
+    h.history = append(h.history, ev)
+    for ch := range h.subs {
+        select {
+        case ch <- ev:
+        default:
+            delete(h.subs, ch)
+            close(ch)
+        }
+    }
+}
+
+// Subscribe returns the events so far and a channel of the ones to come.
+// The channel is closed by cancel, or by the hub if the reader falls too
+// far behind.
+func (h *spectatorHub) Subscribe() ([]Event, <-chan Event, func()) {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    ch := make(chan Event, spectatorBuffer)
+    if h.closed {
+        close(ch)
+    } else {
+        h.subs[ch] = struct{}{}
+    }
+    past := append([]Event(nil), h.history...)
+    cancel := func() {
+        h.mu.Lock()
+        defer h.mu.Unlock()
+        if _, ok := h.subs[ch]; ok {
+            delete(h.subs, ch)
+            close(ch)
+        }
+    }
+    return past, ch, cancel
+}
+
+// Close ends every subscription once the queued events are delivered and
+// waits up to grace for the spectator connections to finish sending them.
+// Events still held back by a delay are released at once: the game is
+// over, so there is nothing left to coach.
+func (h *spectatorHub) Close(grace time.Duration) {
+    h.mu.Lock()
+    for _, d := range h.pending {
+        h.publish(d.ev)
+    }
+    h.pending = nil
+    h.closed = true
+    if h.poke != nil {
+        select {
+        case h.poke <- struct{}{}:
+        default:
+        }
+    }
+    for ch := range h.subs {
+        delete(h.subs, ch)
+        close(ch)
+    }
+    h.mu.Unlock()
+
+    done := make(chan struct{})
+    go func() {
+        h.streams.Wait()
+        close(done)
+    }()
+    select {
+    case <-done:
+    case <-time.After(grace):
+    }
+}
+
+// serveSpectators streams the hub to every connection on ln as JSON lines
+// until ctx is done. Connections are write-only from our side: anything a
+// spectator sends is ignored, so there is no way to roll from here.
+func serveSpectators(ctx context.Context, ln net.Listener, h *spectatorHub) {
+    go func() {
+        <-ctx.Done()
+        ln.Close()
+    }()
+    for {
+        conn, err := ln.Accept()
+        if err != nil {
+            return
+        }
+        h.streams.Add(1)
+        go func() {
+            defer h.streams.Done()
+            streamToSpectator(ctx, conn, h)
+        }()
+    }
+}
+
+func streamToSpectator(ctx context.Context, conn net.Conn, h *spectatorHub) {
+    defer conn.Close()
+    past, ch, cancel := h.Subscribe()
+    defer cancel()
+    enc := json.NewEncoder(conn)
+    for _, ev := range past {
+        if enc.Encode(ev) != nil {
+            return
+        }
+    }
+    for {
+        select {
+        case ev, ok := <-ch:
+            if !ok || enc.Encode(ev) != nil {
+                return
+            }
+        case <-ctx.Done():
+            return
+        }
+    }
+}
+
+// maxResyncs is how many times watch reconnects after its copy of the
+// game stops matching the hashes the server sends, before giving up
+const maxResyncs = 3
+
+// errDiverged is a watch stream that stopped matching the server's state
+type errDiverged struct{ err error }
+
+func (e errDiverged) Error() string { return "out of step with the game: " + e.err.Error() }
+func (e errDiverged) Unwrap() error { return e.err }
+
+// runWatch attaches to a spectator stream and narrates it on out. It
+// replays the game alongside, and if its state stops matching the hash on
+// an event it reconnects to catch up again from the server's history.
+func runWatch(ctx context.Context, addr string, out io.Writer) error {
+    shown := 0
+    for resyncs := 0; ; resyncs++ {
+        err := watchStream(ctx, addr, out, &shown)
+        var diverged errDiverged
+        if !errors.As(err, &diverged) {
+            return err
+        }
+        if resyncs == maxResyncs {
+            return fmt.Errorf("gave up after %d resyncs: %w", maxResyncs, err)
+        }
+        fmt.Fprintf(out, "%v; resyncing\n", err)
+    }
+}
+
+// watchStream follows one connection to addr, narrating events past the
+// first *shown, which earlier connections already showed
+func watchStream(ctx context.Context, addr string, out io.Writer, shown *int) error {
+    var d net.Dialer
+    conn, err := d.DialContext(ctx, "tcp", addr)
+    if err != nil {
+        return err
+    }
+    defer conn.Close()
+    stop := context.AfterFunc(ctx, func() { conn.Close() })
+    defer stop()
+    var r replayer
+    sc := bufio.NewScanner(conn)
+    sc.Buffer(nil, 1<<20) // start events carry the whole setup
+    for n := 0; sc.Scan(); n++ {
+        var ev Event
+        if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+            return fmt.Errorf("bad event from %s: %w", addr, err)
+        }
+        if err := r.apply(ev); err != nil {
+            return errDiverged{err}
+        }
+        if n >= *shown {
+            fmt.Fprintf(out, "turn %d: %s\n", ev.Turn, describeEvent(ev))
+            *shown++
+        }
+        if ev.Kind == EventWin || ev.Kind == EventDraw {
+            return nil
+        }
+    }
+    if ctx.Err() != nil {
+        return ctx.Err()
+    }
+    return sc.Err()
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "math/rand"
+    "time"
+)
+
+// Speed is a pacing preset: one knob for every delay, animation and bit of
+// suspense in the interactive loop
+type Speed string
+
+const (
+    SpeedInstant   Speed = "instant"
+    SpeedFast      Speed = "fast"
+    SpeedNormal    Speed = "normal"
+    SpeedCinematic Speed = "cinematic"
+)
+
+// Pacing is what a Speed expands to
+type Pacing struct {
+    RollAnimation time.Duration // die tumbles this long before settling
+    Suspense      time.Duration // pause before revealing a snake or ladder
+    AfterTurn     time.Duration // pause before the next player's prompt
+    BetweenEvents time.Duration // pause between the things one roll sets off
+}
+
+var pacings = map[Speed]Pacing{
+    SpeedInstant:   {},
+    SpeedFast:      {RollAnimation: 150 * time.Millisecond, AfterTurn: 100 * time.Millisecond},
+    SpeedNormal:    {RollAnimation: 600 * time.Millisecond, Suspense: 400 * time.Millisecond, AfterTurn: 300 * time.Millisecond},
+    SpeedCinematic: {RollAnimation: 1500 * time.Millisecond, Suspense: 1200 * time.Millisecond, AfterTurn: 800 * time.Millisecond, BetweenEvents: 400 * time.Millisecond},
+}
+
+func ParseSpeed(s string) (Speed, error) {
+    sp := Speed(s)
+    if _, ok := pacings[sp]; !ok {
+        return "", fmt.Errorf("unknown speed %q (want instant, fast, normal or cinematic)", s)
+    }
+    return sp, nil
+}
+
+func (s Speed) Pacing() Pacing {
+    if p, ok := pacings[s]; ok {
+        return p
+    }
+    return pacings[SpeedNormal]
+}
+
+// override adjusts p for one run: fast drops every delay, then a non-nil
+// delay replaces the pauses and a non-nil animate the roll animation (0
+// turns it off)
+func (p Pacing) override(fast bool, delay, animate *time.Duration) Pacing {
+    if fast {
+        p = Pacing{}
+    }
+    if delay != nil {
+        p.Suspense, p.AfterTurn, p.BetweenEvents = *delay, *delay, *delay
+    }
+    if animate != nil {
+        p.RollAnimation = *animate
+    }
+    return p
+}
+
+// animateRoll flickers random faces for the configured duration
+func (p Pacing) animateRoll(ctx context.Context, r Renderer) error {
+    if p.RollAnimation <= 0 {
+        return nil
+    }
+    const frame = 75 * time.Millisecond
+    defer r.RenderRolling(0)
+    for t := time.Duration(0); t < p.RollAnimation; t += frame {
+        r.RenderRolling(rand.Intn(6) + 1)
+        if err := sleepCtx(ctx, frame); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+func (p Pacing) suspense(ctx context.Context, r Renderer) error {
+    if p.Suspense <= 0 {
+        return nil
+    }
+    r.RenderSuspense(true)
+    defer r.RenderSuspense(false)
+    return sleepCtx(ctx, p.Suspense)
+}
+
+// sleepCtx is time.Sleep that gives up early when ctx is done
+func sleepCtx(ctx context.Context, d time.Duration) error {
+    if d <= 0 {
+        return ctx.Err()
+    }
+    t := time.NewTimer(d)
+    defer t.Stop()
+    select {
+    case <-t.C:
+        return nil
+    case <-ctx.Done():
+        return ctx.Err()
+    }
+}
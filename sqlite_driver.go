@@ -0,0 +1,10 @@
+//go:build sqlite
+
+package main
+
+// Pure-Go SQLite (FTS5 included), so the tagged build stays cgo-free
+import _ "modernc.org/sqlite"
+
+func init() {
+    historyDriver = "sqlite"
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "slices"
+    "strconv"
+    "strings"
+    "sync"
+)
+
+// sshServe runs the SSH server on addr until ctx is done, handing each
+// session to run with the user's login name, the command they asked for
+// (`ssh -p 2222 host join ABCDE` gives join and ABCDE) and their
+// terminal. hostKey is the server's private key file, made on first use.
+// It is nil unless the server is compiled in (go build -tags ssh).
+var sshServe func(ctx context.Context, addr, hostKey string, run sshSession) error
+
+// sshSession plays a game at an SSH user's terminal
+type sshSession func(ctx context.Context, user string, args []string, term io.ReadWriter) error
+
+var errNoSSH = errors.New("built without SSH support; rebuild with -tags ssh")
+
+// sshLobby is what serve-ssh runs for each session. With no command the
+// user plays the game the server's flags set up, at their own terminal;
+// given player names they play those hot-seat. Otherwise they meet at the
+// lobby's tables, under their login names:
+//
+//    tables             lists the tables waiting for players
+//    open SEATS [RULES] opens a table and sits down at it
+//    join CODE          sits down at a table
+//
+// Once a table is full its game is played across all of its players'
+// terminals, each taking their own turns; anyone at a table can say
+// something to it at any time. Pausing can't be resumed from another
+// session, so every game pauses into a store of its own.
+type sshLobby struct {
+    ctx   context.Context
+    names []string
+    opts  Options
+    lobby *lobby
+
+    mu     sync.Mutex
+    tables map[string]*sshTable // by code
+}
+
+// sshSeat is a player sitting at a lobby table
+type sshSeat struct {
+    term    io.Writer
+    in      *lineInput
+    started chan struct{} // closed as the table's game begins
+    done    chan struct{} // closed once it is over
+}
+
+func newSSHLobby(ctx context.Context, names []string, opts Options) *sshLobby {
+    board := opts.Board
+    if board.IsZero() {
+        board = CreateStandardBoard()
+    }
+    l := &sshLobby{ctx: ctx, names: names, opts: opts, tables: map[string]*sshTable{}}
+    l.lobby = newLobby(board, opts.Deps.withDefaults().Clock, l.start)
+    return l
+}
+
+func (l *sshLobby) Run(ctx context.Context, user string, args []string, term io.ReadWriter) error {
+    var err error
+    cmd := ""
+    if len(args) > 0 {
+        cmd = args[0]
+    }
+    switch {
+    case cmd == "tables" && len(args) == 1:
+        for _, t := range l.lobby.List() {
+            taken := 0
+            for _, seat := range t.Seats {
+                if seat.Name != "" {
+                    taken++
+                }
+            }
+            fmt.Fprintln(term, strings.TrimSpace(fmt.Sprintf("%s  %d/%d seats  %s", t.Code, taken, len(t.Seats), strings.Trim(t.Settings.Preset+","+t.Settings.Rules, ","))))
+        }
+        return nil
+    case cmd == "open" && (len(args) == 2 || len(args) == 3):
+        ls := LobbySettings{Rules: strings.Join(args[2:], "")}
+        if _, ok := RulePresets[ls.Rules]; ok {
+            ls.Preset, ls.Rules = ls.Rules, ""
+        }
+        if ls.Seats, err = strconv.Atoi(args[1]); err != nil {
+            err = fmt.Errorf("bad number of seats %q", args[1])
+            break
+        }
+        var t LobbyTable
+        if t, err = l.lobby.Open(ls); err == nil {
+            fmt.Fprintln(term, l.opts.Msgs.T(MsgTableOpen, t.Code, t.Code))
+            err = l.sit(ctx, t.Code, user, term)
+        }
+    case cmd == "join" && len(args) == 2:
+        err = l.sit(ctx, args[1], user, term)
+    case cmd == "tables" || cmd == "open" || cmd == "join":
+        err = errors.New("usage: tables | open SEATS [PRESET|RULES] | join CODE | [PLAYER...]")
+    default:
+        o, players := l.opts, l.names
+        if len(args) > 0 {
+            if players, err = parseNameList(strings.Join(args, ",")); err != nil {
+                break
+            }
+        }
+        o.Input, o.Out, o.Store = newLineInput(term), term, newMemoryStore()
+        o.Observers = append(slices.Clone(o.Observers), serverTelemetry.observers()...)
+        _, err = play(ctx, players, o)
+        return err
+    }
+    if err != nil {
+        fmt.Fprintln(term, err)
+    }
+    return err
+}
+
+// sit seats user at table code and waits for its game to start and end,
+// or for them to get up first
+func (l *sshLobby) sit(ctx context.Context, code, user string, term io.ReadWriter) error {
+    msgs := l.opts.Msgs
+    code = strings.ToUpper(code)
+    id, token, err := l.lobby.Join(code, user)
+    if err != nil {
+        return err
+    }
+    t, err := l.lobby.Table(code)
+    if err != nil {
+        return err
+    }
+    free := 0
+    for _, seat := range t.Seats {
+        if seat.Name == "" {
+            free++
+        }
+    }
+    l.mu.Lock()
+    table := l.tables[code]
+    if table == nil {
+        table = &sshTable{seats: make([]*sshSeat, len(t.Seats))}
+        l.tables[code] = table
+    }
+    l.mu.Unlock()
+    seat := &sshSeat{term: term, started: make(chan struct{}), done: make(chan struct{})}
+    seat.in = newChatInput(term, func(text string) {
+        fmt.Fprint(table, chatLine(msgs, user, text))
+    })
+    table.mu.Lock()
+    table.seats[id] = seat
+    table.mu.Unlock()
+    fmt.Fprintln(term, msgs.T(MsgChatHint))
+    fmt.Fprintln(table, msgs.T(MsgRemoteJoined, user))
+    if free > 0 {
+        fmt.Fprintln(table, msgs.T(MsgTableWaiting, free))
+    }
+    // the lobby starts the game as the last seat is taken
+    if _, err := l.lobby.Ready(code, id, token, true); err != nil {
+        l.getUp(code, id, token)
+        return err
+    }
+    for {
+        select {
+        case <-seat.started:
+            <-seat.done
+            return nil
+        case line, ok := <-seat.in.lines:
+            if ok && strings.TrimSpace(line) != "leave" {
+                continue
+            }
+        case <-ctx.Done():
+        }
+        select {
+        case <-seat.started: // too late to get up
+            <-seat.done
+            return nil
+        default:
+        }
+        l.getUp(code, id, token)
+        return ctx.Err()
+    }
+}
+
+// getUp gives up seat id at table code before its game starts
+func (l *sshLobby) getUp(code string, id PlayerID, token string) {
+    l.lobby.Leave(code, id, token)
+    _, err := l.lobby.Table(code)
+    l.mu.Lock()
+    table := l.tables[code]
+    if errors.Is(err, ErrNoTable) {
+        delete(l.tables, code)
+    }
+    l.mu.Unlock()
+    if table != nil {
+        table.mu.Lock()
+        table.seats[id] = nil
+        table.mu.Unlock()
+    }
+}
+
+// start plays table code's game once everyone has sat down, off on its
+// own: the lobby calls it with its lock held
+func (l *sshLobby) start(code string, names []string, rules Rules, tokens *seatTokens) error {
+    l.mu.Lock()
+    table := l.tables[code]
+    l.mu.Unlock()
+    table.mu.Lock()
+    seats := append([]*sshSeat(nil), table.seats...)
+    table.mu.Unlock()
+    o := l.opts
+    o.Rules, o.Out, o.Store = rules, table, newMemoryStore()
+    o.Observers = append(slices.Clone(o.Observers), serverTelemetry.observers()...)
+    o.Input = newLineInput(strings.NewReader(""))
+    o.Controllers = map[string]PlayerController{}
+    for i, name := range names {
+        o.Controllers[name] = remoteController{seats[i].in}
+        close(seats[i].started)
+    }
+    go func() {
+        play(l.ctx, names, o)
+        l.mu.Lock()
+        delete(l.tables, code)
+        l.mu.Unlock()
+        l.lobby.remove(code)
+        for _, seat := range seats {
+            close(seat.done)
+        }
+    }()
+    return nil
+}
+
+// sshTable is who is sitting at a lobby table, and writes what they all
+// see: their talk while they wait, then the game and their talk over it
+type sshTable struct {
+    mu    sync.Mutex
+    seats []*sshSeat // in seat order; nil where nobody sits
+}
+
+func (t *sshTable) Write(p []byte) (int, error) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    for _, seat := range t.seats {
+        if seat != nil {
+            seat.term.Write(p) // a player who has gone misses out
+        }
+    }
+    return len(p), nil
+}
@@ -0,0 +1,202 @@
+//go:build ssh
+
+package main
+
+import (
+    "context"
+    "crypto/ed25519"
+    "crypto/rand"
+    "encoding/binary"
+    "encoding/pem"
+    "errors"
+    "fmt"
+    "io"
+    "io/fs"
+    "net"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+    "time"
+
+    "golang.org/x/crypto/ssh"
+    "golang.org/x/term"
+)
+
+func init() {
+    sshServe = serveSSH
+}
+
+// serveSSH lets anyone in, under whatever name they log in with: as at
+// the web lobby, a name is only how the other players know you
+func serveSSH(ctx context.Context, addr, hostKey string, run sshSession) error {
+    signer, err := loadHostKey(hostKey)
+    if err != nil {
+        return err
+    }
+    config := &ssh.ServerConfig{NoClientAuth: true}
+    config.AddHostKey(signer)
+    ln, err := net.Listen("tcp", addr)
+    if err != nil {
+        return err
+    }
+    stop := context.AfterFunc(ctx, func() { ln.Close() })
+    defer stop()
+    var sessions sync.WaitGroup
+    for {
+        conn, err := ln.Accept()
+        if err != nil {
+            if ctx.Err() != nil {
+                drainSSHSessions(&sessions, sshDrainTimeout)
+                return nil
+            }
+            return err
+        }
+        go serveSSHConn(ctx, conn, config, run, &sessions)
+    }
+}
+
+// sshDrainTimeout is how long a shutting-down server gives its sessions
+// to put their games away and say so
+const sshDrainTimeout = 5 * time.Second
+
+// drainSSHSessions waits for sessions to end, for at most timeout
+func drainSSHSessions(sessions *sync.WaitGroup, timeout time.Duration) {
+    done := make(chan struct{})
+    go func() {
+        sessions.Wait()
+        close(done)
+    }()
+    select {
+    case <-done:
+    case <-time.After(timeout):
+    }
+}
+
+// loadHostKey reads the server's key from path, making it first if there
+// is none yet; an empty path is the one under the config directory
+func loadHostKey(path string) (ssh.Signer, error) {
+    if path == "" {
+        var err error
+        if path, err = appPath("ssh_host_ed25519_key"); err != nil {
+            return nil, err
+        }
+    }
+    data, err := os.ReadFile(path)
+    if errors.Is(err, fs.ErrNotExist) {
+        _, key, err := ed25519.GenerateKey(rand.Reader)
+        if err != nil {
+            return nil, err
+        }
+        block, err := ssh.MarshalPrivateKey(key, "snakesladders host key")
+        if err != nil {
+            return nil, err
+        }
+        data = pem.EncodeToMemory(block)
+        if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+            return nil, err
+        }
+        if err := os.WriteFile(path, data, 0o600); err != nil {
+            return nil, err
+        }
+    } else if err != nil {
+        return nil, err
+    }
+    signer, err := ssh.ParsePrivateKey(data)
+    if err != nil {
+        return nil, fmt.Errorf("%s: %w", path, err)
+    }
+    return signer, nil
+}
+
+func serveSSHConn(ctx context.Context, conn net.Conn, config *ssh.ServerConfig, run sshSession, sessions *sync.WaitGroup) {
+    sconn, chans, reqs, err := ssh.NewServerConn(conn, config)
+    if err != nil {
+        conn.Close()
+        return
+    }
+    defer sconn.Close()
+    go ssh.DiscardRequests(reqs)
+    for nc := range chans {
+        if nc.ChannelType() != "session" {
+            nc.Reject(ssh.UnknownChannelType, "only sessions are served")
+            continue
+        }
+        ch, chReqs, err := nc.Accept()
+        if err != nil {
+            continue
+        }
+        sessions.Add(1)
+        go func() {
+            defer sessions.Done()
+            serveSSHSession(ctx, sconn.User(), ch, chReqs, run)
+        }()
+    }
+}
+
+// serveSSHSession waits for the client to ask for a shell or a command,
+// then plays the session's game, on a line-editing terminal if the
+// client asked for one
+func serveSSHSession(ctx context.Context, user string, ch ssh.Channel, reqs <-chan *ssh.Request, run sshSession) {
+    defer ch.Close()
+    pty := false
+    var args []string
+wait:
+    for req := range reqs {
+        switch req.Type {
+        case "pty-req", "window-change", "env":
+            pty = pty || req.Type == "pty-req"
+            req.Reply(req.Type != "env", nil)
+            continue
+        case "exec":
+            if len(req.Payload) < 4 {
+                req.Reply(false, nil)
+                continue
+            }
+            args = strings.Fields(string(req.Payload[4:]))
+        case "shell":
+        default:
+            req.Reply(false, nil)
+            continue
+        }
+        req.Reply(true, nil)
+        break wait
+    }
+    go ssh.DiscardRequests(reqs)
+    var rw io.ReadWriter = ch
+    if pty {
+        rw = newSSHTerminal(ch)
+    }
+    code := uint32(0)
+    if err := run(ctx, user, args, rw); err != nil {
+        code = 1
+    }
+    ch.SendRequest("exit-status", false, binary.BigEndian.AppendUint32(nil, code))
+}
+
+// sshTerminal is a terminal the client has put in raw mode: it echoes and
+// edits lines as they are typed, and ends written lines with \r\n
+type sshTerminal struct {
+    *term.Terminal
+    lines *io.PipeReader
+}
+
+func newSSHTerminal(ch ssh.Channel) *sshTerminal {
+    t := term.NewTerminal(ch, "")
+    pr, pw := io.Pipe()
+    go func() {
+        for {
+            line, err := t.ReadLine()
+            if err != nil {
+                pw.CloseWithError(err)
+                return
+            }
+            io.WriteString(pw, line+"\n")
+        }
+    }()
+    return &sshTerminal{Terminal: t, lines: pr}
+}
+
+func (t *sshTerminal) Read(p []byte) (int, error) {
+    return t.lines.Read(p)
+}
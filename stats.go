@@ -0,0 +1,149 @@
+package main
+
+import (
+    "database/sql"
+    "fmt"
+    "io"
+    "time"
+)
+
+const resultsSchema = `
+CREATE TABLE IF NOT EXISTS results (
+    id          INTEGER PRIMARY KEY,
+    game_id     TEXT NOT NULL,
+    played_at   TEXT NOT NULL,
+    winner      TEXT,
+    rolls       INTEGER NOT NULL,
+    duration_ms INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS result_players (
+    result_id    INTEGER NOT NULL REFERENCES results(id),
+    seat         INTEGER NOT NULL,
+    player       TEXT NOT NULL,
+    final_square INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS result_players_player ON result_players(player);
+`
+
+// recordResult stores a finished game for the stats command
+func recordResult(db *sql.DB, res GameResult) error {
+    tx, err := db.Begin()
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+    r, err := tx.Exec(`INSERT INTO results (game_id, played_at, winner, abort_reason, rolls, duration_ms) VALUES (?, ?, ?, ?, ?, ?)`,
+        res.GameID, res.StartedAt.UTC().Format(time.RFC3339),
+        sql.NullString{String: res.Winner, Valid: res.Winner != ""},
+        sql.NullString{String: string(res.AbortReason), Valid: res.AbortReason != ""},
+        res.Turns, res.EndedAt.Sub(res.StartedAt).Milliseconds())
+    if err != nil {
+        return err
+    }
+    id, err := r.LastInsertId()
+    if err != nil {
+        return err
+    }
+    for i, p := range res.Players {
+        if _, err := tx.Exec(`INSERT INTO result_players (result_id, seat, player, final_square) VALUES (?, ?, ?, ?)`,
+            id, i, p.Name, p.Position); err != nil {
+            return err
+        }
+    }
+    return tx.Commit()
+}
+
+// runStats prints win counts, average game length, head-to-head records
+// and how many games were abandoned and why. Averages cover finished games.
+func runStats(dbPath string, msgs Catalog, out io.Writer) error {
+    db, err := openHistory(dbPath)
+    if err != nil {
+        return err
+    }
+    defer db.Close()
+
+    var games, finished int
+    var avgRolls, avgMs sql.NullFloat64
+    err = db.QueryRow(`
+        SELECT COUNT(*), COUNT(winner),
+               AVG(CASE WHEN winner IS NOT NULL THEN rolls END),
+               AVG(CASE WHEN winner IS NOT NULL THEN duration_ms END)
+        FROM results`).Scan(&games, &finished, &avgRolls, &avgMs)
+    if err != nil {
+        return err
+    }
+    if games == 0 {
+        fmt.Fprintln(out, msgs.T(MsgStatsNone))
+        return nil
+    }
+    fmt.Fprintln(out, msgs.T(MsgStatsFinished, finished, avgRolls.Float64,
+        (time.Duration(avgMs.Float64)*time.Millisecond).Round(time.Second)))
+    if games > finished {
+        rows, err := db.Query(`
+            SELECT COALESCE(abort_reason, 'unknown'), COUNT(*) FROM results
+            WHERE winner IS NULL GROUP BY 1 ORDER BY 2 DESC, 1`)
+        if err != nil {
+            return err
+        }
+        fmt.Fprint(out, msgs.T(MsgStatsAbandoned, games-finished))
+        for rows.Next() {
+            var reason string
+            var n int
+            if err := rows.Scan(&reason, &n); err != nil {
+                rows.Close()
+                return err
+            }
+            fmt.Fprintf(out, " %s %d", reason, n)
+        }
+        rows.Close()
+        if err := rows.Err(); err != nil {
+            return err
+        }
+        fmt.Fprintln(out)
+    }
+    fmt.Fprintln(out)
+
+    rows, err := db.Query(`
+        SELECT p.player, COUNT(*), SUM(r.winner = p.player)
+        FROM result_players p JOIN results r ON r.id = p.result_id
+        GROUP BY p.player ORDER BY 3 DESC, p.player`)
+    if err != nil {
+        return err
+    }
+    fmt.Fprintln(out, msgs.T(MsgStatsColumns))
+    for rows.Next() {
+        var name string
+        var played, wins int
+        if err := rows.Scan(&name, &played, &wins); err != nil {
+            rows.Close()
+            return err
+        }
+        fmt.Fprintf(out, "%-20s %6d %6d %6.1f%%\n", name, played, wins, 100*float64(wins)/float64(played))
+    }
+    rows.Close()
+    if err := rows.Err(); err != nil {
+        return err
+    }
+
+    rows, err = db.Query(`
+        SELECT a.player, b.player, COUNT(*),
+               SUM(r.winner = a.player), SUM(r.winner = b.player)
+        FROM result_players a
+        JOIN result_players b ON b.result_id = a.result_id AND a.player < b.player
+        JOIN results r ON r.id = a.result_id
+        GROUP BY a.player, b.player ORDER BY 3 DESC, a.player, b.player`)
+    if err != nil {
+        return err
+    }
+    defer rows.Close()
+    fmt.Fprintln(out, "\n"+msgs.T(MsgStatsHeadToHead))
+    for rows.Next() {
+        var a, b string
+        var met, aWins, bWins int
+        if err := rows.Scan(&a, &b, &met, &aWins, &bWins); err != nil {
+            return err
+        }
+        fmt.Fprintln(out, msgs.T(MsgStatsMeeting, a, aWins, bWins, b, met))
+    }
+    return rows.Err()
+}
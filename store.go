@@ -0,0 +1,330 @@
+package main
+
+import (
+    "database/sql"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "io/fs"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+)
+
+var ErrNoSave = errors.New("no saved game under that key")
+
+// GameStore keeps saved games by key: the paused game under
+// autosaveKey, the game being played under crashKey, and games servers
+// collected unfinished under their IDs.
+// Keys are made of letters, digits, '.', '_' and '-'.
+type GameStore interface {
+    Save(key string, a Autosave) error
+    Load(key string) (Autosave, error) // ErrNoSave if there is none
+    List() ([]string, error)           // sorted
+    Delete(key string) error           // no error if there was none
+}
+
+// autosaveKey is the key a paused game is saved under
+const autosaveKey = "autosave"
+
+// crashKey is the key play keeps a game in progress under, turn by turn,
+// so one cut off by a crash can be carried on
+const crashKey = "crash"
+
+// storeBackends open a GameStore from what follows the backend's name in
+// a -store spec ("file:DIR"). Other backends, e.g. Redis, plug in by
+// adding themselves here from an init function.
+var storeBackends = map[string]func(arg string) (GameStore, error){
+    "memory": func(string) (GameStore, error) { return newMemoryStore(), nil },
+    "file": func(dir string) (GameStore, error) {
+        if dir == "" {
+            return nil, errors.New("file store needs a directory")
+        }
+        return fileStore{dir}, nil
+    },
+    "sqlite": openSQLStore,
+}
+
+// openStore opens the store a -store spec names, BACKEND or BACKEND:ARG;
+// an empty spec is the files under the config directory
+func openStore(spec string) (GameStore, error) {
+    if spec == "" {
+        dir, err := appPath("saves", "games")
+        if err != nil {
+            return nil, err
+        }
+        return fileStore{dir}, nil
+    }
+    name, arg, _ := strings.Cut(spec, ":")
+    open, ok := storeBackends[name]
+    if !ok {
+        return nil, fmt.Errorf("unknown store %q (have %s)", name, strings.Join(storeNames(), ", "))
+    }
+    return open(arg)
+}
+
+func storeNames() []string {
+    var names []string
+    for name := range storeBackends {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names
+}
+
+func checkStoreKey(key string) error {
+    if key == "" || key[0] == '.' || strings.IndexFunc(key, func(r rune) bool {
+        return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '_' || r == '-')
+    }) >= 0 {
+        return fmt.Errorf("bad save key %q", key)
+    }
+    return nil
+}
+
+// saveGame is where g has got to, for a GameStore
+func saveGame(g *Game, started time.Time) Autosave {
+    gs := g.State()
+    return Autosave{
+        Snapshot:  takeSnapshot(gs, g.Turns()),
+        Board:     ConfigFromBoard("", gs.Board),
+        Rules:     gs.Rules,
+        GameID:    g.ID(),
+        StartedAt: started,
+    }
+}
+
+// runSaves lists the games in store, each of which `resume KEY` carries
+// on, or with "delete KEY" forgets one
+func runSaves(args []string, store GameStore, msgs Catalog, out io.Writer) error {
+    if len(args) == 2 && args[0] == "delete" {
+        return store.Delete(args[1])
+    }
+    if len(args) != 0 {
+        return errors.New("usage: saves [delete <key>]")
+    }
+    keys, err := store.List()
+    if err != nil {
+        return err
+    }
+    if len(keys) == 0 {
+        fmt.Fprintln(out, msgs.T(MsgNoSaves))
+    }
+    for _, key := range keys {
+        a, err := store.Load(key)
+        if err != nil {
+            fmt.Fprintln(out, msgs.T(MsgSaveUnreadable, key, err))
+            continue
+        }
+        var names []string
+        for _, p := range a.Players {
+            names = append(names, p.Name)
+        }
+        fmt.Fprintln(out, msgs.T(MsgSaveEntry, key, a.Turn, strings.Join(names, ", "), a.SavedAt.Format(time.DateTime)))
+    }
+    return nil
+}
+
+// memoryStore keeps saves for as long as the process runs
+type memoryStore struct {
+    mu    sync.Mutex
+    saves map[string][]byte // as JSON, so callers share nothing
+}
+
+func newMemoryStore() *memoryStore {
+    return &memoryStore{saves: map[string][]byte{}}
+}
+
+func (m *memoryStore) Save(key string, a Autosave) error {
+    if err := checkStoreKey(key); err != nil {
+        return err
+    }
+    data, err := json.Marshal(a)
+    if err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.saves[key] = data
+    return nil
+}
+
+func (m *memoryStore) Load(key string) (Autosave, error) {
+    m.mu.Lock()
+    data, ok := m.saves[key]
+    m.mu.Unlock()
+    var a Autosave
+    if !ok {
+        return a, ErrNoSave
+    }
+    return a, json.Unmarshal(data, &a)
+}
+
+func (m *memoryStore) List() ([]string, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    keys := make([]string, 0, len(m.saves))
+    for key := range m.saves {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+    return keys, nil
+}
+
+func (m *memoryStore) Delete(key string) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    delete(m.saves, key)
+    return nil
+}
+
+// fileStore keeps each save as KEY.json in its directory
+type fileStore struct {
+    dir string
+}
+
+func (f fileStore) path(key string) (string, error) {
+    if err := checkStoreKey(key); err != nil {
+        return "", err
+    }
+    return filepath.Join(f.dir, key+".json"), nil
+}
+
+func (f fileStore) Save(key string, a Autosave) error {
+    path, err := f.path(key)
+    if err != nil {
+        return err
+    }
+    return writeJSONFile(path, a)
+}
+
+func (f fileStore) Load(key string) (Autosave, error) {
+    var a Autosave
+    path, err := f.path(key)
+    if err != nil {
+        return a, err
+    }
+    data, err := os.ReadFile(path)
+    if errors.Is(err, fs.ErrNotExist) {
+        return a, ErrNoSave
+    }
+    if err != nil {
+        return a, err
+    }
+    if err := json.Unmarshal(data, &a); err != nil {
+        return a, fmt.Errorf("%s: %w", path, err)
+    }
+    return a, nil
+}
+
+func (f fileStore) List() ([]string, error) {
+    entries, err := os.ReadDir(f.dir)
+    if errors.Is(err, fs.ErrNotExist) {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    var keys []string
+    for _, e := range entries {
+        if key, ok := strings.CutSuffix(e.Name(), ".json"); ok && e.Type().IsRegular() && checkStoreKey(key) == nil {
+            keys = append(keys, key)
+        }
+    }
+    return keys, nil // ReadDir sorts by name
+}
+
+func (f fileStore) Delete(key string) error {
+    path, err := f.path(key)
+    if err != nil {
+        return err
+    }
+    if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+        return err
+    }
+    return nil
+}
+
+// sqlStore keeps saves in a table of the database it is opened on
+type sqlStore struct {
+    db *sql.DB
+}
+
+const storeSchema = `
+CREATE TABLE IF NOT EXISTS saves (
+    key  TEXT PRIMARY KEY,
+    body TEXT NOT NULL
+);
+`
+
+// openSQLStore opens the SQLite database at path as a GameStore
+func openSQLStore(path string) (GameStore, error) {
+    if path == "" {
+        return nil, errors.New("sqlite store needs a database path")
+    }
+    if historyDriver == "" {
+        return nil, errNoHistory
+    }
+    if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+        return nil, err
+    }
+    db, err := sql.Open(historyDriver, path)
+    if err != nil {
+        return nil, err
+    }
+    if _, err := db.Exec(`PRAGMA busy_timeout = 5000;` + storeSchema); err != nil {
+        db.Close()
+        return nil, fmt.Errorf("store schema: %w", err)
+    }
+    return sqlStore{db}, nil
+}
+
+func (s sqlStore) Save(key string, a Autosave) error {
+    if err := checkStoreKey(key); err != nil {
+        return err
+    }
+    data, err := json.Marshal(a)
+    if err != nil {
+        return err
+    }
+    _, err = s.db.Exec(`INSERT INTO saves (key, body) VALUES (?, ?) ON CONFLICT (key) DO UPDATE SET body = excluded.body`, key, string(data))
+    return err
+}
+
+func (s sqlStore) Load(key string) (Autosave, error) {
+    var a Autosave
+    var body string
+    err := s.db.QueryRow(`SELECT body FROM saves WHERE key = ?`, key).Scan(&body)
+    if errors.Is(err, sql.ErrNoRows) {
+        return a, ErrNoSave
+    }
+    if err != nil {
+        return a, err
+    }
+    return a, json.Unmarshal([]byte(body), &a)
+}
+
+func (s sqlStore) List() ([]string, error) {
+    rows, err := s.db.Query(`SELECT key FROM saves ORDER BY key`)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    var keys []string
+    for rows.Next() {
+        var key string
+        if err := rows.Scan(&key); err != nil {
+            return nil, err
+        }
+        keys = append(keys, key)
+    }
+    return keys, rows.Err()
+}
+
+func (s sqlStore) Delete(key string) error {
+    _, err := s.db.Exec(`DELETE FROM saves WHERE key = ?`, key)
+    return err
+}
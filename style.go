@@ -0,0 +1,88 @@
+package main
+
+import (
+    "os"
+    "slices"
+    "strings"
+)
+
+// Style colours what the game prints for a terminal: each player in their
+// own colour, snakes red, ladders green and the winner highlighted. The
+// zero Style prints plain text, for output that isn't a terminal.
+type Style struct {
+    seats map[string]int // colour index of each player, by name
+}
+
+const ansiReset = "\x1b[0m"
+
+// ansiPlayerColors are the SGR codes players are shown in, by seat; red
+// and green are kept for snakes and ladders
+var ansiPlayerColors = []string{"36", "35", "33", "34", "96", "95", "93", "94"}
+
+// newStyle is a Style colouring names, in seat order; nil names is plain
+func newStyle(names []string) Style {
+    if names == nil {
+        return Style{}
+    }
+    s := Style{seats: map[string]int{}}
+    for i, n := range names {
+        s.seats[n] = i % len(ansiPlayerColors)
+    }
+    return s
+}
+
+// newStyleFor is newStyle with each player shown in the colour their
+// profile asks for, one of profileColors, unless someone seated before
+// them asked for it first; the rest take the colours left, in seat order
+func newStyleFor(names []string, profiles map[string]PlayerProfile) Style {
+    s := Style{seats: map[string]int{}}
+    taken := map[int]bool{}
+    for _, n := range names {
+        if c := slices.Index(profileColors, profiles[n].Color); c >= 0 && !taken[c] {
+            s.seats[n], taken[c] = c, true
+        }
+    }
+    next := 0
+    for _, n := range names {
+        if _, ok := s.seats[n]; ok {
+            continue
+        }
+        for next < len(ansiPlayerColors) && taken[next] {
+            next++
+        }
+        s.seats[n], taken[next%len(ansiPlayerColors)] = next%len(ansiPlayerColors), true
+        next++
+    }
+    return s
+}
+
+// colorWanted says whether to colour output going to f: only when it is a
+// terminal, and neither -no-color nor $NO_COLOR says otherwise
+func colorWanted(f *os.File, noColor bool) bool {
+    return !noColor && os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb" && isTerminal(f)
+}
+
+// paint wraps text in SGR code, picking the code up again after anything
+// painted inside it
+func (s Style) paint(code, text string) string {
+    if s.seats == nil {
+        return text
+    }
+    on := "\x1b[" + code + "m"
+    return on + strings.ReplaceAll(text, ansiReset, ansiReset+on) + ansiReset
+}
+
+// Player is name in its player's colour
+func (s Style) Player(name string) string {
+    seat, ok := s.seats[name]
+    if !ok {
+        return name
+    }
+    return s.paint(ansiPlayerColors[seat], name)
+}
+
+func (s Style) Snake(text string) string  { return s.paint("31", text) }
+func (s Style) Ladder(text string) string { return s.paint("32", text) }
+
+// Banner makes text stand out, for the end of a game
+func (s Style) Banner(text string) string { return s.paint("1;7", text) }
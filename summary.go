@@ -0,0 +1,128 @@
+package main
+
+import (
+    "fmt"
+    "io"
+    "os"
+    "strconv"
+    "strings"
+)
+
+// Summary is the post-game report, worked out from a game's events alone
+type Summary struct {
+    Turns   int
+    Players []string
+    Squares map[string][]int  // where each player's tokens finished
+    Rolls   map[string][6]int // Rolls[p][n-1] is how often p rolled n
+    Setback *SummaryJump      // the furthest anyone was sent back, if anyone was
+    Ladder  *SummaryJump      // the longest ladder climbed, if any was
+}
+
+// SummaryJump is one move in a Summary: Player going From To on Turn,
+// and Other's token when it was a capture that sent Other back
+type SummaryJump struct {
+    Player, Other string
+    From, To      int
+    Turn          int
+}
+
+// summarize reports on the last game in evs
+func summarize(evs []Event) Summary {
+    s := Summary{Squares: map[string][]int{}, Rolls: map[string][6]int{}}
+    moved := map[string]int{} // each player's token last moved, for the jumps after it
+    place := func(player string, token, sq int) {
+        if token >= len(s.Squares[player]) {
+            grown := make([]int, token+1)
+            copy(grown, s.Squares[player])
+            s.Squares[player] = grown
+        }
+        s.Squares[player][token] = sq
+    }
+    setback := func(j SummaryJump) {
+        if j.From > j.To && (s.Setback == nil || j.From-j.To > s.Setback.From-s.Setback.To) {
+            s.Setback = &j
+        }
+    }
+    for _, ev := range evs {
+        token := max(ev.Token-1, 0)
+        switch ev.Kind {
+        case EventStart, EventRestore:
+            if ev.Kind == EventStart {
+                s = Summary{Players: ev.Players, Squares: map[string][]int{}, Rolls: map[string][6]int{}}
+            }
+            if ev.Setup == nil {
+                for _, p := range s.Players {
+                    place(p, 0, 1)
+                }
+                continue
+            }
+            for _, p := range ev.Setup.State.Players {
+                s.Squares[p.Name] = nil
+                for k, sq := range p.tokenSquares() {
+                    place(p.Name, k, sq.Index)
+                }
+            }
+        case EventRoll:
+            s.Turns = ev.Turn
+            if ev.Roll >= 1 && ev.Roll <= 6 {
+                r := s.Rolls[ev.Player]
+                r[ev.Roll-1]++
+                s.Rolls[ev.Player] = r
+            }
+        case EventMove:
+            moved[ev.Player] = token
+            place(ev.Player, token, ev.To)
+        case EventSnake, EventTeleport, EventThreeSixes:
+            place(ev.Player, moved[ev.Player], ev.To)
+            setback(SummaryJump{Player: ev.Player, From: ev.From, To: ev.To, Turn: ev.Turn})
+        case EventLadder:
+            place(ev.Player, moved[ev.Player], ev.To)
+            if s.Ladder == nil || ev.To-ev.From > s.Ladder.To-s.Ladder.From {
+                s.Ladder = &SummaryJump{Player: ev.Player, From: ev.From, To: ev.To, Turn: ev.Turn}
+            }
+        case EventSwap:
+            place(ev.Player, moved[ev.Player], ev.To)
+            place(ev.Other, 0, ev.From)
+            setback(SummaryJump{Player: ev.Other, From: ev.To, To: ev.From, Turn: ev.Turn})
+        case EventCapture:
+            place(ev.Other, token, ev.To)
+            setback(SummaryJump{Player: ev.Other, Other: ev.Player, From: ev.From, To: ev.To, Turn: ev.Turn})
+        }
+    }
+    return s
+}
+
+func (s Summary) write(out io.Writer, msgs Catalog) {
+    fmt.Fprintln(out, msgs.T(MsgSummaryHeader, msgs.T(MsgTotalTurns, s.Turns)))
+    for _, p := range s.Players {
+        squares := make([]string, len(s.Squares[p]))
+        for i, sq := range s.Squares[p] {
+            squares[i] = strconv.Itoa(sq)
+        }
+        rolls := make([]string, 6)
+        for i, n := range s.Rolls[p] {
+            rolls[i] = fmt.Sprintf("%d:%d", i+1, n)
+        }
+        fmt.Fprintln(out, "  "+msgs.T(MsgSummaryPlayer, p, strings.Join(squares, ", "), strings.Join(rolls, " ")))
+    }
+    if j := s.Setback; j != nil {
+        if j.Other != "" {
+            fmt.Fprintln(out, "  "+msgs.T(MsgSummaryCaptured, j.Player, j.Other, j.From, j.To, j.Turn))
+        } else {
+            fmt.Fprintln(out, "  "+msgs.T(MsgSummarySetback, j.Player, j.From, j.To, j.Turn))
+        }
+    }
+    if j := s.Ladder; j != nil {
+        fmt.Fprintln(out, "  "+msgs.T(MsgSummaryLadder, j.Player, j.From, j.To, j.Turn))
+    }
+}
+
+// writeSummary writes the summary to path as plain text
+func writeSummary(path string, s Summary, msgs Catalog) error {
+    f, err := os.Create(path)
+    if err != nil {
+        return err
+    }
+    s.write(f, msgs)
+    return f.Close()
+}
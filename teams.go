@@ -0,0 +1,104 @@
+package main
+
+import (
+    "fmt"
+    "strings"
+)
+
+// TeamWin says what a team needs to win
+type TeamWin string
+
+const (
+    // TeamWinAny: the first team with any member home wins (the default)
+    TeamWinAny TeamWin = "any"
+    // TeamWinAll: the first team with every member home wins; finished
+    // players sit out until then
+    TeamWinAll TeamWin = "all"
+)
+
+func ParseTeamWin(s string) (TeamWin, error) {
+    switch TeamWin(s) {
+    case "", TeamWinAny:
+        return TeamWinAny, nil
+    case TeamWinAll:
+        return TeamWinAll, nil
+    }
+    return "", fmt.Errorf("unknown team win rule %q (want any or all)", s)
+}
+
+// Team is a named group of players
+type Team struct {
+    Name    string
+    Members []string
+}
+
+// ParseTeams reads "red=Alice,Carol;blue=Bob,Dave"
+func ParseTeams(s string) ([]Team, error) {
+    var teams []Team
+    seen := map[string]bool{}
+    for _, part := range strings.Split(s, ";") {
+        name, list, ok := strings.Cut(part, "=")
+        name = strings.TrimSpace(name)
+        if !ok || name == "" {
+            return nil, fmt.Errorf("team %q: want NAME=PLAYER,PLAYER", part)
+        }
+        t := Team{Name: name}
+        for _, m := range strings.Split(list, ",") {
+            m = strings.TrimSpace(m)
+            if m == "" {
+                return nil, fmt.Errorf("team %s: empty player name", name)
+            }
+            if seen[m] {
+                return nil, fmt.Errorf("player %s is listed twice", m)
+            }
+            seen[m] = true
+            t.Members = append(t.Members, m)
+        }
+        teams = append(teams, t)
+    }
+    if len(teams) < 2 {
+        return nil, fmt.Errorf("need at least two teams")
+    }
+    return teams, nil
+}
+
+// seating orders the players so turns alternate between teams: first
+// members of every team, then second members, and so on
+func seating(teams []Team) []string {
+    var names []string
+    for i := 0; ; i++ {
+        added := false
+        for _, t := range teams {
+            if i < len(t.Members) {
+                names = append(names, t.Members[i])
+                added = true
+            }
+        }
+        if !added {
+            return names
+        }
+    }
+}
+
+// assignTeams tags each player in gs with their team
+func assignTeams(gs *GameState, teams []Team) {
+    team := map[string]string{}
+    for _, t := range teams {
+        for _, m := range t.Members {
+            team[m] = t.Name
+        }
+    }
+    for i := range gs.Players {
+        gs.Players[i].Team = team[gs.Players[i].Name]
+    }
+}
+
+// teamHome reports whether every member of team has finished
+func teamHome(gs GameState, team string) bool {
+    for _, p := range gs.Players {
+        if p.Team == team && !p.home(gs.Board.FinalSquare) {
+            return false
+        }
+    }
+    return true
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+    "bytes"
+    "cmp"
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "os"
+    "sync"
+    "time"
+)
+
+// Telemetry is anonymous usage counts a hosted server's operator can opt
+// in to sending with -telemetry: how many games were played, how long they
+// lasted and under which rules preset. It never sees names, addresses or
+// game IDs, only the events every game's observers get, so leaving it off
+// (the default) changes nothing else.
+
+// serverTelemetry is nil unless the operator opted in
+var serverTelemetry *telemetry
+
+// telemetryEvery is how often the counts are sent; they go once more as
+// the server shuts down
+const telemetryEvery = time.Hour
+
+// TelemetryReport is what is sent: totals since the server started
+type TelemetryReport struct {
+    Since           time.Time      `json:"since"`
+    Games           int            `json:"games"`            // started
+    Finished        int            `json:"finished"`         // won, drawn or abandoned
+    AverageDuration float64        `json:"average_duration"` // of finished games, in seconds
+    Presets         map[string]int `json:"presets"`          // games by rules preset, "custom" for any other rules
+}
+
+type telemetry struct {
+    mu       sync.Mutex
+    since    time.Time
+    games    int
+    finished int
+    duration time.Duration // of the finished games, in all
+    presets  map[string]int
+}
+
+func newTelemetry(now time.Time) *telemetry {
+    return &telemetry{since: now, presets: map[string]int{}}
+}
+
+// observers count one game, or nothing when telemetry is off; each game
+// needs its own
+func (t *telemetry) observers() []Observer {
+    if t == nil {
+        return nil
+    }
+    return []Observer{&gameTelemetry{t: t}}
+}
+
+type gameTelemetry struct {
+    t       *telemetry
+    started time.Time
+}
+
+func (gt *gameTelemetry) OnEvent(ev Event) {
+    t := gt.t
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    switch ev.Kind {
+    case EventStart:
+        gt.started = ev.Time
+        t.games++
+        preset := "custom"
+        if ev.Setup != nil {
+            preset = presetOf(ev.Setup.Rules)
+        }
+        t.presets[preset]++
+    case EventWin, EventDraw, EventAbort:
+        // a game restored part-way through was never seen starting, so
+        // its length is unknown: leave it out rather than time it from
+        // the zero time
+        if gt.started.IsZero() {
+            return
+        }
+        t.finished++
+        t.duration += ev.Time.Sub(gt.started)
+    }
+}
+
+// presetOf names the RulePresets entry r is, defaults aside (one token,
+// draw at the turn limit), or is "custom"
+func presetOf(r Rules) string {
+    plain := func(r Rules) Rules {
+        r.Tokens = max(r.Tokens, 1)
+        r.TurnLimit = cmp.Or(r.TurnLimit, TurnLimitDraw)
+        return r
+    }
+    for _, name := range rulePresetNames() {
+        var p Rules
+        if (RuleSpec{Preset: name}).Apply(&p) == nil && plain(p) == plain(r) {
+            return name
+        }
+    }
+    return "custom"
+}
+
+func (t *telemetry) report() TelemetryReport {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    rep := TelemetryReport{Since: t.since, Games: t.games, Finished: t.finished, Presets: map[string]int{}}
+    if t.finished > 0 {
+        rep.AverageDuration = (t.duration / time.Duration(t.finished)).Seconds()
+    }
+    for name, n := range t.presets {
+        rep.Presets[name] = n
+    }
+    return rep
+}
+
+func (t *telemetry) handle(w http.ResponseWriter, r *http.Request) {
+    writeJSON(w, http.StatusOK, t.report())
+}
+
+// checkTelemetryURL reports whether endpoint is somewhere reports can go
+func checkTelemetryURL(endpoint string) error {
+    u, err := url.Parse(endpoint)
+    if err != nil {
+        return fmt.Errorf("-telemetry: %w", err)
+    }
+    if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+        return errors.New("-telemetry wants an http or https URL")
+    }
+    return nil
+}
+
+// send posts the report to endpoint as JSON
+func (t *telemetry) send(ctx context.Context, endpoint string) error {
+    data, err := json.Marshal(t.report())
+    if err != nil {
+        return err
+    }
+    ctx, cancel := context.WithTimeout(ctx, hookTimeout)
+    defer cancel()
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Content-Type", "application/json")
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil {
+        return err
+    }
+    resp.Body.Close()
+    if resp.StatusCode/100 != 2 {
+        return fmt.Errorf("%s answered %s", endpoint, resp.Status)
+    }
+    return nil
+}
+
+// ship sends the report to endpoint at each tick of every and once more when
+// ctx is done, telling errs of any that fail; the server carries on either way
+func (t *telemetry) ship(ctx context.Context, endpoint string, every time.Duration, errs io.Writer) {
+    tick := time.NewTicker(every)
+    defer tick.Stop()
+    for {
+        select {
+        case <-tick.C:
+            if err := t.send(ctx, endpoint); err != nil {
+                fmt.Fprintln(errs, "telemetry:", err)
+            }
+        case <-ctx.Done():
+            if err := t.send(context.Background(), endpoint); err != nil {
+                fmt.Fprintln(errs, "telemetry:", err)
+            }
+            return
+        }
+    }
+}
+
+// withTelemetry runs serve, a server, shipping the counts to endpoint
+// meanwhile if the operator gave one. The last report goes out once
+// serve returns, however it went, before withTelemetry does.
+func withTelemetry(ctx context.Context, endpoint string, serve func() error) error {
+    if serverTelemetry == nil {
+        return serve()
+    }
+    ctx, stop := context.WithCancel(ctx)
+    shipped := make(chan struct{})
+    go func() {
+        serverTelemetry.ship(ctx, endpoint, telemetryEvery, os.Stderr)
+        close(shipped)
+    }()
+    err := serve()
+    stop()
+    <-shipped
+    return err
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "strconv"
+    "strings"
+)
+
+// TicTacToe is tic-tac-toe for two on a Size x Size grid, won by K marks
+// in a line across, down or diagonally: 3 and 3 for the classic game,
+// larger for variants such as 5x5 with 4 in a row. The first player is X.
+// Moves are square numbers, counted from 1 along each row from the top
+// left.
+type TicTacToe struct {
+    names []string
+    size  int
+    k     int
+    cells []int // 0 empty, else 1 + the player's index
+    cur   int
+    marks int
+    won   int // 1 + the winner's index, once someone has K in a line
+}
+
+// TicTacToe board sizes; the largest still fits a terminal
+const (
+    minTicTacToeSize = 3
+    maxTicTacToeSize = 15
+)
+
+// ticTacToeMarks are the players' marks, in seat order
+var ticTacToeMarks = [2]string{"X", "O"}
+
+func NewTicTacToe(names []string, size, k int) (*TicTacToe, error) {
+    if len(names) != 2 {
+        return nil, fmt.Errorf("tic-tac-toe is for two players, not %d", len(names))
+    }
+    if size < minTicTacToeSize || size > maxTicTacToeSize {
+        return nil, fmt.Errorf("board size %d out of range (%d to %d)", size, minTicTacToeSize, maxTicTacToeSize)
+    }
+    if k < 3 || k > size {
+        return nil, fmt.Errorf("can't need %d in a row on a %dx%d board (want 3 to %d)", k, size, size, size)
+    }
+    return &TicTacToe{names: names, size: size, k: k, cells: make([]int, size*size)}, nil
+}
+
+// newTicTacToeFromArgs reads `play tictactoe [-size N] [-k K]`
+func newTicTacToeFromArgs(args, names []string, opts Options) (TurnGame, error) {
+    fs := flag.NewFlagSet("tictactoe", flag.ContinueOnError)
+    size := fs.Int("size", 3, "squares along each side of the board")
+    k := fs.Int("k", 0, "marks in a line needed to win (default: the board size, at most 5)")
+    if err := fs.Parse(args); err != nil {
+        return nil, err
+    }
+    if fs.NArg() > 0 {
+        return nil, errors.New("usage: play tictactoe [-size N] [-k K]")
+    }
+    if *k == 0 {
+        *k = min(*size, 5)
+    }
+    return NewTicTacToe(names, *size, *k)
+}
+
+func (t *TicTacToe) Players() []string {
+    return append([]string(nil), t.names...)
+}
+
+func (t *TicTacToe) CurrentPlayer() PlayerID {
+    return PlayerID(t.cur)
+}
+
+func (t *TicTacToe) LegalMoves() []string {
+    if t.won != 0 {
+        return nil
+    }
+    var moves []string
+    for i, c := range t.cells {
+        if c == 0 {
+            moves = append(moves, strconv.Itoa(i+1))
+        }
+    }
+    return moves
+}
+
+func (t *TicTacToe) Apply(move string) ([]Event, error) {
+    if _, ongoing := t.Outcome().(Ongoing); !ongoing {
+        return nil, ErrGameOver
+    }
+    sq, err := strconv.Atoi(strings.TrimSpace(move))
+    if err != nil || sq < 1 || sq > len(t.cells) {
+        return nil, fmt.Errorf("no square %q (want 1 to %d)", move, len(t.cells))
+    }
+    if t.cells[sq-1] != 0 {
+        return nil, fmt.Errorf("square %d is taken", sq)
+    }
+    t.marks++
+    ev := Event{Kind: EventMark, Turn: t.marks, Player: t.names[t.cur], To: sq}
+    t.cells[sq-1] = t.cur + 1
+    at := func(r, c int) int { return t.cells[r*t.size+c] }
+    if inARow(t.size, t.size, (sq-1)/t.size, (sq-1)%t.size, t.k, at) {
+        t.won = t.cur + 1
+    }
+    t.cur = 1 - t.cur
+    return []Event{ev}, nil
+}
+
+func (t *TicTacToe) Outcome() Outcome {
+    switch {
+    case t.won != 0:
+        return Win{Winner: Player{Name: t.names[t.won-1]}}
+    case t.marks == len(t.cells):
+        return Draw{}
+    }
+    return Ongoing{}
+}
+
+// Render draws the grid, numbering the empty squares
+func (t *TicTacToe) Render(w io.Writer) {
+    width := len(strconv.Itoa(len(t.cells)))
+    sep := strings.Repeat("+"+strings.Repeat("-", width+2), t.size) + "+"
+    fmt.Fprintf(w, "%s is %s, %s is %s; %d in a row wins\n", t.names[0], ticTacToeMarks[0], t.names[1], ticTacToeMarks[1], t.k)
+    for r := 0; r < t.size; r++ {
+        fmt.Fprintln(w, sep)
+        for c := 0; c < t.size; c++ {
+            cell := strconv.Itoa(r*t.size + c + 1)
+            if m := t.cells[r*t.size+c]; m != 0 {
+                cell = ticTacToeMarks[m-1]
+            }
+            fmt.Fprintf(w, "| %*s ", width, cell)
+        }
+        fmt.Fprintln(w, "|")
+    }
+    fmt.Fprintln(w, sep)
+}
+
+func (t *TicTacToe) Clone() Searchable {
+    c := *t
+    c.cells = append([]int(nil), t.cells...)
+    return &c
+}
+
+func (t *TicTacToe) Score(p PlayerID) float64 {
+    return lineScore(t.size, t.size, t.k, p, func(r, c int) int { return t.cells[r*t.size+c] })
+}
+
+// inARow reports whether the mark at row r, column c of a rows x cols
+// grid is one of k or more the same in a line across, down or
+// diagonally. at reads a square's mark; 0 is empty.
+func inARow(rows, cols, r, c, k int, at func(r, c int) int) bool {
+    mark := at(r, c)
+    if mark == 0 {
+        return false
+    }
+    for _, d := range [][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}} {
+        n := 1
+        for _, sign := range []int{1, -1} {
+            for i := 1; ; i++ {
+                rr, cc := r+sign*i*d[0], c+sign*i*d[1]
+                if rr < 0 || rr >= rows || cc < 0 || cc >= cols || at(rr, cc) != mark {
+                    break
+                }
+                n++
+            }
+        }
+        if n >= k {
+            return true
+        }
+    }
+    return false
+}
+
+// lineScore is a Scorer's verdict for p on a two-player grid game won by
+// k in a line: every line of k squares still open to just one player
+// counts for them by the square of how many of their marks it holds
+func lineScore(rows, cols, k int, p PlayerID, at func(r, c int) int) float64 {
+    var score, most float64
+    forEachLine(rows, cols, k, func(cells [][2]int) {
+        var count [3]int
+        for _, rc := range cells {
+            count[at(rc[0], rc[1])]++
+        }
+        mine, theirs := count[int(p)+1], count[2-int(p)]
+        switch {
+        case theirs == 0:
+            score += float64(mine * mine)
+        case mine == 0:
+            score -= float64(theirs * theirs)
+        }
+        most += float64(k * k)
+    })
+    return score / most
+}
+
+// forEachLine calls f with the squares, as row and column, of every line
+// of k in a rows x cols grid
+func forEachLine(rows, cols, k int, f func(cells [][2]int)) {
+    cells := make([][2]int, k)
+    for r := 0; r < rows; r++ {
+        for c := 0; c < cols; c++ {
+            for _, d := range [][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}} {
+                er, ec := r+(k-1)*d[0], c+(k-1)*d[1]
+                if er < 0 || er >= rows || ec < 0 || ec >= cols {
+                    continue
+                }
+                for i := range cells {
+                    cells[i] = [2]int{r + i*d[0], c + i*d[1]}
+                }
+                f(cells)
+            }
+        }
+    }
+}
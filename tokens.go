@@ -0,0 +1,119 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+)
+
+// ErrTokenHome is returned when choosing a token that has already finished
+var ErrTokenHome = errors.New("that token is already home")
+
+// In games with several tokens per player, Player.Tokens holds every
+// token's square and Position mirrors the active one, Tokens[Active], so
+// the single-token move logic applies unchanged to whichever token is
+// being moved. Tokens is never modified in place: states share it.
+
+// setupTokens gives every player in gs n tokens on square 1
+func setupTokens(gs *GameState, n int) {
+    if n < 2 {
+        return
+    }
+    for i := range gs.Players {
+        p := &gs.Players[i]
+        p.Tokens = make([]BoardPos, n)
+        for k := range p.Tokens {
+            p.Tokens[k] = p.Position
+        }
+        p.Active = 0
+    }
+}
+
+// tokenSquares is where p's tokens are; a single-token player has one
+func (p Player) tokenSquares() []BoardPos {
+    if len(p.Tokens) == 0 {
+        return []BoardPos{p.Position}
+    }
+    return p.Tokens
+}
+
+// tokenNumber is p's active token counting from 1, or 0 for a
+// single-token player, as events number it
+func tokenNumber(p Player) int {
+    if len(p.Tokens) == 0 {
+        return 0
+    }
+    return p.Active + 1
+}
+
+// setTokens puts all p's tokens on sqs, as tokenSquares lists them
+func (p *Player) setTokens(sqs []BoardPos) {
+    if len(p.Tokens) == 0 {
+        p.Position = sqs[0]
+        return
+    }
+    p.Tokens = sqs
+    p.Position = sqs[p.Active]
+}
+
+// home reports whether all p's tokens have reached final
+func (p Player) home(final BoardPos) bool {
+    for _, sq := range p.tokenSquares() {
+        if sq != final {
+            return false
+        }
+    }
+    return true
+}
+
+// setToken moves p's token k to pos
+func (p *Player) setToken(k int, pos BoardPos) {
+    if len(p.Tokens) == 0 {
+        p.Position = pos
+        return
+    }
+    p.Tokens = append([]BoardPos(nil), p.Tokens...)
+    p.Tokens[k] = pos
+    if k == p.Active {
+        p.Position = pos
+    }
+}
+
+// syncActive writes Position back to the active token after the move
+// logic has changed it
+func (p *Player) syncActive() {
+    if len(p.Tokens) > 0 && p.Tokens[p.Active] != p.Position {
+        p.setToken(p.Active, p.Position)
+    }
+}
+
+// movableTokens lists the current player's tokens that aren't home
+func movableTokens(gs GameState) []int {
+    p := gs.Players[gs.CurrentPlayerIndex]
+    var ks []int
+    for k, sq := range p.tokenSquares() {
+        if sq != gs.Board.FinalSquare {
+            ks = append(ks, k)
+        }
+    }
+    return ks
+}
+
+// withToken makes token k the one the current player moves next; gs is
+// left unchanged
+func withToken(gs GameState, k int) (GameState, error) {
+    p := gs.Players[gs.CurrentPlayerIndex]
+    sqs := p.tokenSquares()
+    if k < 0 || k >= len(sqs) {
+        return gs, fmt.Errorf("no token %d", k+1)
+    }
+    if sqs[k] == gs.Board.FinalSquare {
+        return gs, ErrTokenHome
+    }
+    if len(p.Tokens) == 0 {
+        return gs, nil
+    }
+    gs.Players = append([]Player(nil), gs.Players...)
+    cur := &gs.Players[gs.CurrentPlayerIndex]
+    cur.Active, cur.Position = k, cur.Tokens[k]
+    return gs, nil
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "time"
+)
+
+// transcriptFormat marks a JSON document as a Transcript
+const transcriptFormat = "snakesladders-transcript"
+
+// Transcript is the canonical record of a run's games, written by
+// -transcript and read by verify, audit and the transcript command, which
+// derives every other export from it. As JSON:
+//
+//    {
+//      "format":  "snakesladders-transcript",
+//      "version": 3,                        // FormatVersion of the events
+//      "games": [{
+//        "players": ["Alice", "Bob"],       // in seat order
+//        "started": "2024-05-01T10:00:00Z",
+//        "ended":   "2024-05-01T10:09:30Z", // absent while still playing
+//        "outcome": "win",                  // win, draw, abort or pause; absent while still playing
+//        "winner":  "Bob",                  // and "team", when there is one
+//        "turns":   57,
+//        "board":   {...},                  // a board file, as the edit command writes
+//        "rules":   {...},                  // the Rules played by
+//        "events":  [{...}, ...]            // every Event, in order, from the start to the ending
+//      }, ...]
+//    }
+//
+// The events alone are enough to replay and check a game; the other
+// fields repeat what they say, for readers that don't want to replay.
+type Transcript struct {
+    Format  string           `json:"format"`
+    Version int              `json:"version"`
+    Games   []TranscriptGame `json:"games"`
+}
+
+// TranscriptGame is one game in a Transcript
+type TranscriptGame struct {
+    Players []string     `json:"players"`
+    Order   string       `json:"order,omitempty"` // how Players' order was decided, OrderRollOff or OrderShuffle; absent as named
+    Started time.Time    `json:"started"`
+    Ended   *time.Time   `json:"ended,omitempty"`
+    Outcome EventKind    `json:"outcome,omitempty"`
+    Winner  string       `json:"winner,omitempty"`
+    Team    string       `json:"team,omitempty"`
+    Turns   int          `json:"turns"`
+    Board   *BoardConfig `json:"board,omitempty"` // absent for games logged before version 3
+    Rules   *Rules       `json:"rules,omitempty"`
+    Events  []Event      `json:"events"`
+}
+
+// transcriptOf splits evs into its games, each beginning at a start event;
+// anything logged before the first start is dropped
+func transcriptOf(evs []Event) Transcript {
+    t := Transcript{Format: transcriptFormat, Version: FormatVersion, Games: []TranscriptGame{}}
+    var g *TranscriptGame
+    for _, ev := range evs {
+        if ev.Kind == EventStart {
+            t.Games = append(t.Games, TranscriptGame{Players: ev.Players, Order: ev.Reason, Started: ev.Time})
+            g = &t.Games[len(t.Games)-1]
+            if ev.Setup != nil {
+                board, rules := ev.Setup.Board, ev.Setup.Rules
+                g.Board, g.Rules = &board, &rules
+            }
+        }
+        if g == nil {
+            continue
+        }
+        g.Events = append(g.Events, ev)
+        g.Turns = max(g.Turns, ev.Turn)
+        switch ev.Kind {
+        case EventWin, EventDraw, EventAbort, EventPause:
+            ended := ev.Time
+            g.Ended, g.Outcome = &ended, ev.Kind
+            if ev.Kind == EventWin {
+                g.Winner, g.Team = ev.Player, ev.Team
+            }
+        case EventRestore:
+            g.Ended, g.Outcome, g.Winner, g.Team = nil, "", "", ""
+        }
+    }
+    return t
+}
+
+// events is every event in t, in order
+func (t Transcript) events() []Event {
+    var evs []Event
+    for _, g := range t.Games {
+        evs = append(evs, g.Events...)
+    }
+    return evs
+}
+
+// transcriptWriter is an Observer keeping a transcript file up to date,
+// rewriting it as each game ends
+type transcriptWriter struct {
+    path string
+    evs  []Event
+    err  error
+}
+
+func newTranscriptWriter(path string) *transcriptWriter {
+    return &transcriptWriter{path: path}
+}
+
+func (t *transcriptWriter) OnEvent(ev Event) {
+    t.evs = append(t.evs, ev)
+    switch ev.Kind {
+    case EventWin, EventDraw, EventAbort, EventPause:
+        t.err = writeJSONFile(t.path, transcriptOf(t.evs))
+    }
+}
+
+// Err reports the last failure to write the transcript
+func (t *transcriptWriter) Err() error {
+    return t.err
+}
+
+// readTranscript decodes a transcript, rejecting one from a newer build
+func readTranscript(data []byte) ([]Event, error) {
+    var t Transcript
+    if err := json.Unmarshal(data, &t); err != nil {
+        return nil, err
+    }
+    if err := checkVersion(kindEvents, max(t.Version, 1)); err != nil {
+        return nil, err
+    }
+    return t.events(), nil
+}
+
+// readGameRecord reads any kind of game record: a transcript, move
+// notation, or a -json-events log of one event per line, with any sealed
+// dice unsealed
+func readGameRecord(r io.Reader) ([]Event, error) {
+    data, err := io.ReadAll(r)
+    if err != nil {
+        return nil, err
+    }
+    // a log of more than one event isn't a single JSON value, and no
+    // event has a format
+    var probe struct {
+        Format string `json:"format"`
+    }
+    var evs []Event
+    switch trimmed := bytes.TrimSpace(data); {
+    case json.Unmarshal(data, &probe) == nil && probe.Format == transcriptFormat:
+        evs, err = readTranscript(data)
+    case len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == ';'):
+        evs, err = readNotation(data)
+    default:
+        evs, err = readEventLog(bytes.NewReader(data))
+    }
+    if err != nil {
+        return evs, err
+    }
+    return unsealEvents(evs)
+}
+
+// openGameRecord reads the game record at path
+func openGameRecord(path string) ([]Event, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+    return readGameRecord(f)
+}
+
+// runTranscript implements `transcript FILE [json|csv|notation|summary]`, turning
+// an event log or transcript into a transcript or one of the formats
+// derived from it
+func runTranscript(args []string, out io.Writer, msgs Catalog) error {
+    if len(args) < 1 || len(args) > 2 {
+        return errors.New("usage: transcript <game-record-file> [json|csv|notation|summary]")
+    }
+    evs, err := openGameRecord(args[0])
+    if err != nil {
+        return err
+    }
+    t := transcriptOf(evs)
+    format := "json"
+    if len(args) == 2 {
+        format = args[1]
+    }
+    switch format {
+    case "json":
+        enc := json.NewEncoder(out)
+        enc.SetIndent("", "  ")
+        return enc.Encode(t)
+    case "csv":
+        c := newCSVEvents(out)
+        for _, ev := range t.events() {
+            c.OnEvent(ev)
+        }
+        c.flush()
+    case "notation":
+        return writeNotation(out, t.events())
+    case "summary":
+        for _, g := range t.Games {
+            summarize(g.Events).write(out, msgs)
+        }
+    default:
+        return fmt.Errorf("unknown transcript format %q (want json, csv, notation or summary)", format)
+    }
+    return nil
+}
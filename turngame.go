@@ -0,0 +1,162 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "math/rand"
+    "os"
+    "sort"
+    "strings"
+)
+
+// TurnGame is a turn-based game as the shared tooling sees it: the play
+// command's loop (playTurnGame), bots, and whatever else is written once
+// for every game. Moves are strings in the game's own notation, the same
+// a player types.
+type TurnGame interface {
+    // Players names the seats; a PlayerID indexes it
+    Players() []string
+    // CurrentPlayer is whose move it is
+    CurrentPlayer() PlayerID
+    // LegalMoves lists what the current player may play, none once the
+    // game is over
+    LegalMoves() []string
+    // Apply plays move for the current player, returning what happened
+    Apply(move string) ([]Event, error)
+    // Outcome is Ongoing until the game is won, drawn or abandoned
+    Outcome() Outcome
+    // Render draws the game as it stands
+    Render(w io.Writer)
+}
+
+// turnGames are the games the play command knows, by name. Each gets the
+// arguments after its name on the command line.
+var turnGames = map[string]func(args, names []string, opts Options) (TurnGame, error){
+    "snakes": func(args, names []string, opts Options) (TurnGame, error) {
+        if len(args) > 0 {
+            return nil, errors.New("usage: play snakes (set it up with the usual flags before play)")
+        }
+        g, err := NewGameWithRules(opts.Board, names, opts.Rules, opts.Deps, opts.Observers...)
+        if err != nil {
+            return nil, err
+        }
+        g.SetTheme(opts.Theme.withMessages(opts.Msgs))
+        return g, nil
+    },
+    "tictactoe": newTicTacToeFromArgs,
+    "connect4":  newConnectFourFromArgs,
+    "ludo":      newLudoFromArgs,
+}
+
+// turnGameNames lists turnGames for usage messages
+func turnGameNames() []string {
+    var names []string
+    for n := range turnGames {
+        names = append(names, n)
+    }
+    sort.Strings(names)
+    return names
+}
+
+// playTurnGame plays g at the terminal until it is over or ctx is done,
+// taking each move from the player's controller (the terminal's unless
+// ctls has one for them). An empty answer plays the first legal move and
+// "quit" abandons the game. Of pacing it uses the pauses between events
+// and after each move.
+func playTurnGame(ctx context.Context, g TurnGame, ctls map[string]PlayerController, in *lineInput, clock Clock, pacing Pacing, out io.Writer, msgs Catalog, style Style) (Outcome, error) {
+    names := g.Players()
+    r := newColorRenderer(out, msgs, Theme{}, style)
+    for {
+        switch o := g.Outcome().(type) {
+        case Win:
+            g.Render(out)
+            fmt.Fprintln(out, style.Banner(winLine(msgs, o.Winner.Name, o.Team)))
+            return o, nil
+        case Draw:
+            g.Render(out)
+            fmt.Fprintln(out, style.Banner(msgs.T(MsgDraw)))
+            return o, nil
+        case Abandoned:
+            return o, nil
+        }
+        g.Render(out)
+        name, moves := names[g.CurrentPlayer()], g.LegalMoves()
+        fmt.Fprintln(out, msgs.T(MsgYourMove, style.Player(name), strings.Join(moves, ", ")))
+        var ctl PlayerController = localController{in}
+        if c, ok := ctls[name]; ok {
+            ctl = c
+        }
+        line, _, err := ctl.WaitTurn(ctx, Prompt{Game: g}, clock, 0, r)
+        if err != nil {
+            return abandonTurnGame(g, abortReasonFor(err)), err
+        }
+        switch line {
+        case "quit":
+            fmt.Fprintln(out, msgs.T(MsgQuit))
+            return abandonTurnGame(g, AbortPlayerQuit), nil
+        case "":
+            line = moves[0]
+        }
+        evs, err := g.Apply(line)
+        if err != nil {
+            fmt.Fprintln(out, msgs.T(MsgBadMove, line, err))
+            continue
+        }
+        for i, ev := range evs {
+            if i > 0 {
+                sleepCtx(ctx, pacing.BetweenEvents)
+            }
+            fmt.Fprintln(out, describeEvent(ev))
+        }
+        if err := sleepCtx(ctx, pacing.AfterTurn); err != nil {
+            return abandonTurnGame(g, abortReasonFor(err)), err
+        }
+    }
+}
+
+// abandonTurnGame ends g without a winner, telling g if it keeps track
+func abandonTurnGame(g TurnGame, reason AbortReason) Outcome {
+    if a, ok := g.(interface{ Abort(AbortReason) error }); ok && a.Abort(reason) == nil {
+        return g.Outcome()
+    }
+    return Abandoned{Reason: reason}
+}
+
+// randomMove is a bot's move in a game it has no strategy for
+func randomMove(g TurnGame) string {
+    moves := g.LegalMoves()
+    return moves[rand.Intn(len(moves))]
+}
+
+// runPlay implements `play [game [game flags]]`, which plays the game
+// named (snakes by default) through playTurnGame
+func runPlay(ctx context.Context, args, names []string, opts Options) error {
+    name := "snakes"
+    if len(args) > 0 {
+        name, args = args[0], args[1:]
+    }
+    newGame, ok := turnGames[name]
+    if !ok {
+        return fmt.Errorf("unknown game %q (want %s)", name, strings.Join(turnGameNames(), ", "))
+    }
+    g, err := newGame(args, names, opts)
+    if err != nil {
+        return err
+    }
+    out := opts.Out
+    if out == nil {
+        out = os.Stdout
+    }
+    pacing := opts.Speed.Pacing()
+    if opts.Pacing != nil {
+        pacing = *opts.Pacing
+    }
+    var style Style
+    if opts.Color {
+        style = newStyle(g.Players())
+    }
+    _, err = playTurnGame(ctx, g, opts.Controllers, opts.Input, opts.Deps.withDefaults().Clock, pacing, out, opts.Msgs, style)
+    return err
+}
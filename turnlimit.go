@@ -0,0 +1,54 @@
+package main
+
+import "fmt"
+
+// TurnLimit says how a game that reaches Rules.MaxTurns with nobody home
+// ends, so pathological boards can't go on for ever
+type TurnLimit string
+
+const (
+    // TurnLimitDraw: nobody wins (the default)
+    TurnLimitDraw TurnLimit = "draw"
+    // TurnLimitClosest: whoever is nearest the finish wins; a tie for
+    // nearest is a draw
+    TurnLimitClosest TurnLimit = "closest"
+)
+
+func ParseTurnLimit(s string) (TurnLimit, error) {
+    switch TurnLimit(s) {
+    case "", TurnLimitDraw:
+        return TurnLimitDraw, nil
+    case TurnLimitClosest:
+        return TurnLimitClosest, nil
+    }
+    return "", fmt.Errorf("unknown turn limit rule %q (want draw or closest)", s)
+}
+
+// limitOutcome is how gs ends once it has used up its turns
+func limitOutcome(gs GameState) Outcome {
+    if gs.Rules.TurnLimit != TurnLimitClosest {
+        return Draw{}
+    }
+    best, tied := -1, false
+    progress := func(p Player) int {
+        n := 0
+        for _, sq := range p.tokenSquares() {
+            n += sq.Index
+        }
+        return n
+    }
+    for i, p := range gs.Players {
+        switch {
+        case p.Left:
+        case best < 0 || progress(p) > progress(gs.Players[best]):
+            best, tied = i, false
+        case progress(p) == progress(gs.Players[best]):
+            tied = true
+        }
+    }
+    if best < 0 || tied {
+        return Draw{}
+    }
+    p := gs.Players[best]
+    return Win{Winner: p, Team: p.Team}
+}
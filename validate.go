@@ -0,0 +1,111 @@
+package main
+
+import (
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "math"
+)
+
+// runValidate implements `validate [--strict] <board-file>...`: each board
+// is loaded and built as a game would, solved to check a game on it always
+// finishes, and checked for squares no roll can reach. Warnings only fail
+// the run with --strict, so it can gate a repository of boards in CI.
+func runValidate(args []string, out io.Writer) error {
+    fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+    strict := fs.Bool("strict", false, "fail on warnings too")
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+    if fs.NArg() == 0 {
+        return errors.New("usage: validate [--strict] <board-file>...")
+    }
+    failed := 0
+    for _, path := range fs.Args() {
+        warns, err := validateBoard(path, out)
+        if err != nil {
+            fmt.Fprintf(out, "%s: FAIL\n%v\n", path, err)
+            failed++
+            continue
+        }
+        for _, w := range warns {
+            fmt.Fprintf(out, "%s: warning: %s\n", path, w)
+        }
+        if *strict && len(warns) > 0 {
+            failed++
+        }
+    }
+    if failed > 0 {
+        return fmt.Errorf("%d of %d boards failed", failed, fs.NArg())
+    }
+    return nil
+}
+
+// validateBoard checks the board file at path, reporting how long a game
+// on it lasts, and returns its warnings
+func validateBoard(path string, out io.Writer) ([]string, error) {
+    c, err := LoadBoardConfig(path)
+    if err != nil {
+        return nil, err
+    }
+    b, err := c.Build()
+    if err != nil {
+        return nil, err
+    }
+    st := AnalyzeBoard(b)
+    if math.IsInf(st.ExpectedTurns, 1) {
+        return nil, fmt.Errorf("a game can get stuck: from square 1 it finishes with chance %.4f", st.Finish[1])
+    }
+    fmt.Fprintf(out, "%s: ok, %d squares, %d snakes, %d ladders; %.1f rolls to finish on average\n",
+        path, b.FinalSquare.Index, len(c.Snakes), len(c.Ladders), st.ExpectedTurns)
+    warns := c.Warnings()
+    landed := landableSquares(b)
+    for sq := 2; sq < b.FinalSquare.Index; sq++ {
+        if _, normal := b.Square(sq).(Normal); normal || landed[sq] {
+            continue
+        }
+        kind, to := heatKind(b.Square(sq))
+        if to > 0 {
+            warns = append(warns, fmt.Sprintf("%s %d->%d can never be landed on", kind, sq, to))
+        } else {
+            warns = append(warns, fmt.Sprintf("%s on %d can never be landed on", kind, sq))
+        }
+    }
+    return warns, nil
+}
+
+// landableSquares marks every square of b some roll can land on, in a
+// lone-token game from the start under the classic movement rules
+func landableSquares(b Board) []bool {
+    final := b.FinalSquare.Index
+    landed := make([]bool, final+1)
+    seen := make([]bool, final+1)
+    queue := []int{1} // tokens start on square 1 without taking its jump
+    seen[1] = true
+    for len(queue) > 0 {
+        sq := queue[0]
+        queue = queue[1:]
+        if sq == final {
+            continue
+        }
+        for face := 1; face <= 6; face++ {
+            at := landing(b, mustBP(sq), DieRoll{face}).Index
+            landed[at] = true
+            next := []int{b.Square(at).Dest().Index}
+            if _, ok := b.Square(at).(Teleport); ok {
+                next = next[:0]
+                for to := 1; to < final; to++ {
+                    next = append(next, to)
+                }
+            }
+            for _, to := range next {
+                if !seen[to] {
+                    seen[to] = true
+                    queue = append(queue, to)
+                }
+            }
+        }
+    }
+    return landed
+}
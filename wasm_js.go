@@ -0,0 +1,165 @@
+package main
+
+import (
+    "encoding/json"
+    "errors"
+    "io/fs"
+    "strconv"
+    "strings"
+    "sync"
+    "syscall/js"
+)
+
+// Built for the browser (GOOS=js GOARCH=wasm, loaded with Go's
+// wasm_exec.js), the binary is a library rather than a command: main hands
+// over to exportJS, which puts the engine on the page as a global
+// snakesladders object, so a page can play entirely client-side with the
+// very rule code the CLI runs:
+//
+//    snakesladders.newGame(["Alice", "Bob"], '{"board": "party", "preset": "fast", "rules": "exact_win"}') // -> "1"
+//    snakesladders.roll("1", 0)     // seat 0 rolls; -> JSON list of events, as /events sends them
+//    snakesladders.roll("1", 0, 1)  // ... moving their token 1 (from 0)
+//    snakesladders.getState("1")    // -> JSON state, as /state sends it
+//
+// The options are optional: "board" is a bundled board's name or a board
+// file's contents, and "rules" is as -rules takes them. Failures come back
+// as JavaScript Error values rather than strings.
+
+// jsGames are the games the page has started, by id
+var jsGames = struct {
+    sync.Mutex
+    byID map[string]*Game
+}{byID: map[string]*Game{}}
+
+// jsOptions is newGame's optional second argument
+type jsOptions struct {
+    Board  json.RawMessage `json:"board"`
+    Preset string          `json:"preset"`
+    Rules  string          `json:"rules"`
+}
+
+// exportJS serves the engine to the page and never returns
+func exportJS() bool {
+    api := js.Global().Get("Object").New()
+    api.Set("newGame", jsFunc(jsNewGame))
+    api.Set("roll", jsFunc(jsRoll))
+    api.Set("getState", jsFunc(jsGetState))
+    js.Global().Set("snakesladders", api)
+    select {}
+}
+
+// jsFunc wraps fn for JavaScript, turning its error into an Error
+func jsFunc(fn func(args []js.Value) (string, error)) js.Func {
+    return js.FuncOf(func(this js.Value, args []js.Value) any {
+        out, err := fn(args)
+        if err != nil {
+            return js.Global().Get("Error").New(err.Error())
+        }
+        return out
+    })
+}
+
+func jsNewGame(args []js.Value) (string, error) {
+    if len(args) < 1 || args[0].Type() != js.TypeObject {
+        return "", errors.New("usage: newGame(names[, options])")
+    }
+    names := make([]string, args[0].Length())
+    for i := range names {
+        names[i] = args[0].Index(i).String()
+    }
+    var opts jsOptions
+    if len(args) > 1 && args[1].Type() == js.TypeString {
+        if err := json.Unmarshal([]byte(args[1].String()), &opts); err != nil {
+            return "", err
+        }
+    }
+    board := CreateStandardBoard()
+    var rules Rules
+    if len(opts.Board) > 0 {
+        var name string
+        var err error
+        data := []byte(opts.Board)
+        if json.Unmarshal(opts.Board, &name) == nil {
+            // read straight from the bundle: the page has no files, and a
+            // callback can't wait on the filesystem calls LoadBoard makes
+            if asset := assetBoardName(name); asset == "" {
+                err = errors.New("no bundled board " + name)
+            } else {
+                data, err = fs.ReadFile(bundledFS(), asset)
+            }
+        }
+        if err == nil {
+            var c BoardConfig
+            if err = json.Unmarshal(data, &c); err == nil {
+                board, err = c.Build()
+            }
+            if err == nil {
+                rules, err = c.BoardRules(board)
+            }
+        }
+        if err != nil {
+            return "", err
+        }
+    }
+    spec := RuleSpec{Preset: opts.Preset, Enable: strings.Split(opts.Rules, ",")}
+    if err := spec.Apply(&rules); err != nil {
+        return "", err
+    }
+    g, err := NewGameWithRules(board, names, rules, Deps{})
+    if err != nil {
+        return "", err
+    }
+    jsGames.Lock()
+    defer jsGames.Unlock()
+    id := strconv.Itoa(len(jsGames.byID) + 1)
+    jsGames.byID[id] = g
+    return id, nil
+}
+
+// jsGame is the game args[0] names
+func jsGame(args []js.Value) (*Game, error) {
+    if len(args) < 1 {
+        return nil, errors.New("no game id")
+    }
+    jsGames.Lock()
+    defer jsGames.Unlock()
+    g, ok := jsGames.byID[args[0].String()]
+    if !ok {
+        return nil, errors.New("no game " + args[0].String())
+    }
+    return g, nil
+}
+
+func jsRoll(args []js.Value) (string, error) {
+    g, err := jsGame(args)
+    if err != nil {
+        return "", err
+    }
+    if len(args) < 2 {
+        return "", errors.New("usage: roll(id, player[, token])")
+    }
+    var evs []Event
+    if len(args) > 2 {
+        evs, err = g.RollToken(PlayerID(args[1].Int()), args[2].Int())
+    } else {
+        evs, err = g.Roll(PlayerID(args[1].Int()))
+    }
+    if err != nil {
+        return "", err
+    }
+    out := make([]webEvent, len(evs))
+    for i, ev := range evs {
+        out[i] = webEvent{ev, describeEvent(ev)}
+    }
+    data, err := json.Marshal(out)
+    return string(data), err
+}
+
+func jsGetState(args []js.Value) (string, error) {
+    g, err := jsGame(args)
+    if err != nil {
+        return "", err
+    }
+    data, err := json.Marshal(stateForWeb(g))
+    return string(data), err
+}
@@ -0,0 +1,8 @@
+//go:build !js
+
+package main
+
+// exportJS only does anything in the browser build (see wasm_js.go)
+func exportJS() bool {
+    return false
+}
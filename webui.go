@@ -0,0 +1,810 @@
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "net"
+    "net/http"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+)
+
+// webServer hosts games for the browser front end in assets/web: every
+// player at a table rolls from the same page, which follows the game over
+// a Server-Sent Events stream. The players given on the command line sit
+// at the main table, at /. Other tables are hosted at /games/CODE/, set
+// up for given players (POST /games) or by players meeting in the lobby
+// (/lobby); once idle for idle they are collected, games still being
+// played going to store, if any, to be resumed.
+type webServer struct {
+    board     Board
+    deps      Deps
+    obs       []Observer // the main table's; the others have none
+    takeSeats bool
+    sealDice  bool // send players a commitment to the dice seed, revealing it when the game ends
+    grace     time.Duration
+    idle      time.Duration
+    store     GameStore
+    rolls     *rateLimiter // per client, of rolls, offers and chat
+    creates   *rateLimiter // per client, of tables set up
+    out       io.Writer    // where serveWeb reports
+    tlsCert   string       // certificate and key files to serve HTTPS with, if set
+    tlsKey    string
+    main      *webTable
+    lobby     *lobby
+
+    mu     sync.Mutex
+    tables map[string]*webTable // all but the main table, by code
+}
+
+// webTable is one table's run of games: the one being played, and the
+// seats, which carry over from game to game. Once a game is over, anyone
+// can start the next with the same players. A player claims their seat by
+// joining under its name, getting the token their requests must carry.
+// Players can leave part-way through, and are taken to have left when
+// their event streams stay closed past presence's grace; with the
+// server's takeSeats, whoever joins later takes over a seat left empty,
+// and otherwise just watches.
+type webTable struct {
+    s        *webServer
+    code     string // "" for the main table
+    rules    Rules
+    obs      []Observer
+    tokens   *seatTokens
+    presence *seatPresence
+    active   *activity
+    started  time.Time // the game's
+
+    mu    sync.Mutex
+    names []string
+    game  *Game
+    hub   *spectatorHub
+}
+
+// webState is what the page draws from
+type webState struct {
+    ID      string      `json:"id"`
+    Board   BoardConfig `json:"board"`
+    Width   int         `json:"width"` // squares to a row
+    Cells   [][2]int    `json:"cells"` // the row and column of each square, from square 1
+    Players []webPlayer `json:"players"`
+    Current int         `json:"current"`
+    Turns   int         `json:"turns"`
+    PickDie bool        `json:"pick_die,omitempty"`
+    Winner  string      `json:"winner,omitempty"`
+    Over    bool        `json:"over"`
+    Hash    string      `json:"hash"` // stateHash, matching the latest event's
+}
+
+type webPlayer struct {
+    Name   string     `json:"name"`
+    Tokens []int      `json:"tokens"` // squares, one per token
+    Items  []ItemKind `json:"items,omitempty"`
+    Left   bool       `json:"left,omitempty"`
+    Clock  *float64   `json:"clock,omitempty"` // seconds left, in games with clocks
+}
+
+// webEvent is an Event plus the line the terminal would narrate for it
+type webEvent struct {
+    Event
+    Text string `json:"text"`
+}
+
+// webRoll is the body of a roll request. Token counts from 0 (-1 or
+// absent moves the first not home); Pick is 1 or 2 under pick_die. Like
+// every request for a seat, it carries the seat's token as
+// "Authorization: Bearer TOKEN".
+type webRoll struct {
+    Player PlayerID `json:"player"`
+    Token  *int     `json:"token,omitempty"`
+    Pick   int      `json:"pick,omitempty"`
+}
+
+// webSeat names a seat, in leave requests
+type webSeat struct {
+    Player PlayerID `json:"player"`
+}
+
+// webChat is the body of a chat request: what a seat says to the table
+type webChat struct {
+    Player PlayerID `json:"player"`
+    Text   string   `json:"text"`
+}
+
+// webJoin is the body of a join request, and webJoined the answer: the
+// seat taken and its token, or -1 for a spectator
+type webJoin struct {
+    Name string `json:"name"`
+}
+
+type webJoined struct {
+    Player    PlayerID `json:"player"`
+    Token     string   `json:"token,omitempty"`
+    Spectator bool     `json:"spectator,omitempty"`
+}
+
+// webReady is the body of a lobby ready request; Ready false takes it
+// back
+type webReady struct {
+    Player PlayerID `json:"player"`
+    Ready  *bool    `json:"ready,omitempty"`
+}
+
+func newWebServer(board Board, names []string, rules Rules, deps Deps, obs []Observer) (*webServer, error) {
+    s := &webServer{board: board, deps: deps.withDefaults(), obs: obs, idle: defaultIdleTimeout, tables: map[string]*webTable{}}
+    s.lobby = newLobby(board, s.deps.Clock, s.startTable)
+    var err error
+    s.main, err = s.newTable("", names, rules, &seatTokens{}, obs)
+    return s, err
+}
+
+// configure sets whether latecomers may take seats players have left, how
+// long a disconnected player keeps theirs and how long an idle table is
+// kept (0: for ever); call it before serving
+func (s *webServer) configure(takeSeats bool, grace, idle time.Duration) {
+    s.takeSeats, s.grace, s.idle = takeSeats, grace, idle
+    s.main.presence.grace = grace
+}
+
+// limit caps how many rolls a second and how many new tables an hour
+// each client may ask for (0: no cap); call it before serving
+func (s *webServer) limit(rollsPerSecond, createsPerHour int) {
+    s.rolls = newRateLimiter(s.deps.Clock, rollsPerSecond, time.Second)
+    s.creates = newRateLimiter(s.deps.Clock, createsPerHour, time.Hour)
+}
+
+func (s *webServer) newTable(code string, names []string, rules Rules, tokens *seatTokens, obs []Observer) (*webTable, error) {
+    t := &webTable{s: s, code: code, rules: rules, obs: obs, tokens: tokens, names: names, active: newActivity(s.deps.Clock.Now())}
+    t.presence = newSeatPresence(s.deps.Clock, s.grace, t.dropSeat)
+    return t, t.newGame()
+}
+
+// startTable starts the game of a lobby table
+func (s *webServer) startTable(code string, names []string, rules Rules, tokens *seatTokens) error {
+    t, err := s.newTable(code, names, rules, tokens, nil)
+    if err != nil {
+        return err
+    }
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.tables[code] = t
+    return nil
+}
+
+// dropSeat takes out of the current game a player who hasn't come back
+func (t *webTable) dropSeat(id PlayerID) {
+    g, _ := t.current()
+    if _, err := g.Leave(id); err == nil {
+        t.tokens.revoke(id)
+    }
+}
+
+// newGame replaces the current game; t.mu must be held or t unshared
+func (t *webTable) newGame() error {
+    hub := newSpectatorHub()
+    obs := append(append([]Observer(nil), t.obs...), t.active, serverMetrics.observer(), hub)
+    obs = append(obs, serverTelemetry.observers()...)
+    g, err := NewGameWithRules(t.s.board, t.names, t.rules, t.s.deps, obs...)
+    if err != nil {
+        return err
+    }
+    if t.hub != nil {
+        t.hub.Close(0)
+    }
+    t.game, t.hub, t.started = g, hub, t.s.deps.Clock.Now()
+    return nil
+}
+
+// close ends the table's streams, first saving its game to the server's
+// store if it is still being played. With a reason, a game saved is also
+// suspended, telling its players why.
+func (t *webTable) close(reason string) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    if t.s.store != nil && statusOf(t.game) == GameInProgress {
+        if err := t.s.store.Save(t.game.ID(), saveGame(t.game, t.started)); err != nil {
+            fmt.Fprintf(t.s.out, "saving game %s: %v\n", t.game.ID(), err)
+        } else if reason != "" {
+            t.game.Suspend(reason)
+        }
+    }
+    t.hub.Close(0)
+}
+
+func (t *webTable) current() (*Game, *spectatorHub) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    return t.game, t.hub
+}
+
+// webTableRoutes are a table's pages and API, under its prefix
+var webTableRoutes = []struct {
+    method, path string
+    handle       func(*webTable, http.ResponseWriter, *http.Request)
+}{
+    {"GET", "/{$}", (*webTable).handleIndex},
+    {"GET", "/state", (*webTable).handleState},
+    {"GET", "/events", (*webTable).handleEvents},
+    {"GET", "/offer", (*webTable).handleOffer},
+    {"POST", "/roll", (*webTable).handleRoll},
+    {"POST", "/leave", (*webTable).handleLeave},
+    {"POST", "/chat", (*webTable).handleChat},
+    {"POST", "/join", (*webTable).handleJoin},
+    {"POST", "/new", (*webTable).handleNew},
+}
+
+func (s *webServer) routes() *http.ServeMux {
+    mux := http.NewServeMux()
+    // every route is timed for /metrics but event streams, which last as
+    // long as a client watches and count as connections instead
+    handle := func(pattern string, h http.HandlerFunc) {
+        if !strings.HasSuffix(pattern, "/events") {
+            h = serverMetrics.timed(pattern, h)
+        }
+        mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+            r.Body = http.MaxBytesReader(w, r.Body, maxRequestBody)
+            h(w, r)
+        })
+    }
+    for _, rt := range webTableRoutes {
+        h := s.at(rt.handle)
+        if rt.path == "/roll" || rt.path == "/offer" || rt.path == "/chat" {
+            h = limited(s.rolls, h)
+        }
+        handle(rt.method+" "+rt.path, h)
+        handle(rt.method+" /games/{code}"+rt.path, h)
+    }
+    mux.HandleFunc("GET /metrics", serverMetrics.handle)
+    if serverTelemetry != nil {
+        mux.HandleFunc("GET /telemetry", serverTelemetry.handle) // what is being sent
+    }
+    handle("GET /games", s.handleGames)
+    handle("POST /games", limited(s.creates, s.handleCreate))
+    handle("GET /lobby", s.handleLobby)
+    handle("POST /lobby", limited(s.creates, s.handleOpen))
+    handle("GET /lobby/{code}", s.handleTable)
+    handle("POST /lobby/{code}/join", s.handleLobbyJoin)
+    handle("POST /lobby/{code}/ready", s.handleReady)
+    handle("POST /lobby/{code}/leave", s.handleLobbyLeave)
+    return mux
+}
+
+// at hands a request to the table it is for: the one its path's code
+// names, or the main table. The code may also be the ID of the game a
+// table is playing, as GET /games lists it, so /games/ID/events follows
+// just that game, ending once the table moves on to the next.
+func (s *webServer) at(handle func(*webTable, http.ResponseWriter, *http.Request)) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        t := s.main
+        if code := r.PathValue("code"); code != "" {
+            s.mu.Lock()
+            t = s.tables[strings.ToUpper(code)]
+            s.mu.Unlock()
+            if t == nil {
+                t = s.playing(code)
+            }
+        }
+        if t == nil {
+            http.Error(w, ErrNoTable.Error(), http.StatusNotFound)
+            return
+        }
+        handle(t, w, r)
+    }
+}
+
+func (t *webTable) handleIndex(w http.ResponseWriter, r *http.Request) {
+    page, err := readAsset("web/index.html")
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+    w.Header().Set("Content-Type", "text/html; charset=utf-8")
+    w.Write(page)
+}
+
+func (t *webTable) handleState(w http.ResponseWriter, r *http.Request) {
+    g, _ := t.current()
+    writeJSON(w, http.StatusOK, stateForWeb(g))
+}
+
+func stateForWeb(g *Game) webState {
+    gs := g.State()
+    st := webState{
+        ID:      g.ID(),
+        Board:   ConfigFromBoard("", gs.Board),
+        Current: gs.CurrentPlayerIndex,
+        Turns:   g.Turns(),
+        PickDie: gs.Rules.PickDie,
+        Hash:    stateHash(gs),
+    }
+    grid := GridFor(gs.Board)
+    st.Width = grid.Width
+    for sq := 1; sq <= grid.Squares; sq++ {
+        row, col, _ := PosCell(mustBP(sq), grid.Width, grid.Squares)
+        st.Cells = append(st.Cells, [2]int{row, col})
+    }
+    for _, p := range gs.Players {
+        wp := webPlayer{Name: p.Name, Items: p.Items, Left: p.Left}
+        if gs.Rules.Clock > 0 {
+            left := max(p.Clock, 0).Seconds()
+            wp.Clock = &left
+        }
+        for _, sq := range p.tokenSquares() {
+            wp.Tokens = append(wp.Tokens, sq.Index)
+        }
+        st.Players = append(st.Players, wp)
+    }
+    switch o := g.Outcome().(type) {
+    case Win:
+        st.Over, st.Winner = true, o.Winner.Name
+        if o.Team != "" {
+            st.Winner = o.Team
+        }
+    case Draw, Abandoned:
+        st.Over = true
+    }
+    return st
+}
+
+// handleEvents streams the current game's events, past ones first, until
+// it is replaced or the client goes away; EventSource then reconnects to
+// the next game, or, following a game by its ID, finds it gone and stops.
+// Each event's ID is its place in the game, so a client
+// reconnecting with Last-Event-ID gets a "state" event with the game as
+// it stands and then only the events it missed. Players holding seats
+// send each seat's token as a bearer token, as other requests do, keeping
+// them while the stream is open and for presence's grace after; tokens
+// that aren't any seat's are ignored. Tokens only ever go in headers:
+// in the URL they would end up in access logs and Referer headers. The
+// stream allows no other origins, so in a browser only pages this server
+// serves can follow it.
+func (t *webTable) handleEvents(w http.ResponseWriter, r *http.Request) {
+    flusher, ok := w.(http.Flusher)
+    if !ok {
+        http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+        return
+    }
+    for _, token := range bearers(r) {
+        if id, ok := t.tokens.seatOf(token); ok {
+            defer t.presence.attach(id)()
+        }
+    }
+    defer serverMetrics.connected()()
+    g, hub := t.current()
+    past, ch, cancel := hub.Subscribe()
+    defer cancel()
+    w.Header().Set("Content-Type", "text/event-stream")
+    w.Header().Set("Cache-Control", "no-cache")
+    flusher.Flush() // so the client sees the stream open before the first event
+    seen := streamPos(g.ID(), r.Header.Get("Last-Event-ID"))
+    if seen > 0 {
+        data, err := json.Marshal(stateForWeb(g))
+        if err != nil {
+            return
+        }
+        fmt.Fprintf(w, "event: state\ndata: %s\n\n", data)
+        flusher.Flush()
+    }
+    n := 0
+    var sealer diceSealer
+    send := func(ev Event) bool {
+        if t.s.sealDice {
+            ev = sealer.seal(ev)
+        }
+        if n++; n <= seen {
+            return true
+        }
+        data, err := json.Marshal(webEvent{ev, describeEvent(ev)})
+        if err != nil {
+            return false
+        }
+        if _, err := fmt.Fprintf(w, "id: %s/%d\ndata: %s\n\n", g.ID(), n, data); err != nil {
+            return false
+        }
+        flusher.Flush()
+        return true
+    }
+    for _, ev := range past {
+        if !send(ev) {
+            return
+        }
+    }
+    for {
+        select {
+        case ev, ok := <-ch:
+            if !ok || !send(ev) {
+                return
+            }
+        case <-r.Context().Done():
+            return
+        }
+    }
+}
+
+func (t *webTable) handleOffer(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(r.URL.Query().Get("player"))
+    if err != nil {
+        http.Error(w, "player must be a seat number", http.StatusBadRequest)
+        return
+    }
+    if err := t.authorize(r, PlayerID(id)); err != nil {
+        writeError(w, err)
+        return
+    }
+    g, _ := t.current()
+    choices, err := g.Offer(PlayerID(id))
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writeJSON(w, http.StatusOK, map[string][]int{"choices": dieValues(choices)})
+}
+
+func (t *webTable) handleRoll(w http.ResponseWriter, r *http.Request) {
+    var req webRoll
+    if err := json.NewDecoder(io.LimitReader(r.Body, 1<<10)).Decode(&req); err != nil {
+        http.Error(w, "bad roll request: "+err.Error(), http.StatusBadRequest)
+        return
+    }
+    if err := t.authorize(r, req.Player); err != nil {
+        writeError(w, err)
+        return
+    }
+    token := -1
+    if req.Token != nil {
+        token = *req.Token
+    }
+    g, _ := t.current()
+    var evs []Event
+    var err error
+    switch {
+    case req.Pick != 0:
+        evs, err = g.Pick(req.Player, req.Pick, token)
+    case token >= 0:
+        evs, err = g.RollToken(req.Player, token)
+    default:
+        evs, err = g.Roll(req.Player)
+    }
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writeJSON(w, http.StatusOK, evs)
+}
+
+func (t *webTable) handleLeave(w http.ResponseWriter, r *http.Request) {
+    var req webSeat
+    if err := json.NewDecoder(io.LimitReader(r.Body, 1<<10)).Decode(&req); err != nil {
+        http.Error(w, "bad leave request: "+err.Error(), http.StatusBadRequest)
+        return
+    }
+    if err := t.authorize(r, req.Player); err != nil {
+        writeError(w, err)
+        return
+    }
+    g, _ := t.current()
+    evs, err := g.Leave(req.Player)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    t.tokens.revoke(req.Player)
+    writeJSON(w, http.StatusOK, evs)
+}
+
+// handleChat puts what a player says on the game's event stream, where
+// the table sees it among the moves; it stays out of the game's log
+func (t *webTable) handleChat(w http.ResponseWriter, r *http.Request) {
+    var req webChat
+    if err := json.NewDecoder(io.LimitReader(r.Body, 1<<10)).Decode(&req); err != nil {
+        http.Error(w, "bad chat request: "+err.Error(), http.StatusBadRequest)
+        return
+    }
+    if err := t.authorize(r, req.Player); err != nil {
+        writeError(w, err)
+        return
+    }
+    text := cleanChat(req.Text)
+    if text == "" {
+        http.Error(w, "nothing to say", http.StatusBadRequest)
+        return
+    }
+    g, hub := t.current()
+    if _, ongoing := g.Outcome().(Ongoing); !ongoing {
+        writeError(w, ErrGameOver)
+        return
+    }
+    ev := Event{Kind: EventChat, Time: t.s.deps.Clock.Now(), Turn: g.Turns(), Player: g.Players()[req.Player], Reason: text}
+    hub.OnEvent(ev)
+    writeJSON(w, http.StatusOK, ev)
+}
+
+// handleJoin claims a seat as joinSeat does; anyone without one can watch
+func (t *webTable) handleJoin(w http.ResponseWriter, r *http.Request) {
+    var req webJoin
+    if err := json.NewDecoder(io.LimitReader(r.Body, 1<<10)).Decode(&req); err != nil {
+        http.Error(w, "bad join request: "+err.Error(), http.StatusBadRequest)
+        return
+    }
+    name := strings.TrimSpace(req.Name)
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    id, token, err := joinSeat(t.game, t.tokens, name, t.s.takeSeats)
+    switch {
+    case errors.Is(err, ErrNoSeat), errors.Is(err, ErrGameOver):
+        writeJSON(w, http.StatusOK, webJoined{Player: -1, Spectator: true})
+        return
+    case err != nil:
+        writeError(w, err)
+        return
+    }
+    if t.names[id] != name {
+        // the next game keeps them
+        t.names = append([]string(nil), t.names...)
+        t.names[id] = name
+    }
+    writeJSON(w, http.StatusOK, webJoined{Player: id, Token: token})
+}
+
+// authorize checks r carries seat id's token
+func (t *webTable) authorize(r *http.Request, id PlayerID) error {
+    return t.tokens.check(id, bearer(r))
+}
+
+// bearer is the token r carries
+func bearer(r *http.Request) string {
+    token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+    return token
+}
+
+// bearers are all the tokens r carries, for a client holding several
+// seats: one "Bearer TOKEN" each, in several Authorization headers or
+// comma-separated in one
+func bearers(r *http.Request) []string {
+    var tokens []string
+    for _, h := range r.Header.Values("Authorization") {
+        for _, cred := range strings.Split(h, ",") {
+            if token, ok := strings.CutPrefix(strings.TrimSpace(cred), "Bearer "); ok && token != "" {
+                tokens = append(tokens, token)
+            }
+        }
+    }
+    return tokens
+}
+
+func (t *webTable) handleNew(w http.ResponseWriter, r *http.Request) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    if _, ongoing := t.game.Outcome().(Ongoing); ongoing {
+        http.Error(w, "the current game isn't over", http.StatusConflict)
+        return
+    }
+    if err := t.newGame(); err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+    writeJSON(w, http.StatusOK, stateForWeb(t.game))
+}
+
+// webGame is a hosted table as GET /games lists it
+type webGame struct {
+    Code    string     `json:"code"`
+    ID      string     `json:"id"` // of the game being played
+    Status  GameStatus `json:"status"`
+    Players []string   `json:"players"`
+    Turns   int        `json:"turns"`
+}
+
+// webCreate is the body of a POST /games: the players, in turn order,
+// and the table's settings but for its seats
+type webCreate struct {
+    Players []string `json:"players"`
+    LobbySettings
+}
+
+func (s *webServer) handleGames(w http.ResponseWriter, r *http.Request) {
+    games := []webGame{}
+    for _, t := range s.allTables() {
+        if t.code == "" {
+            continue
+        }
+        g, _ := t.current()
+        games = append(games, webGame{Code: t.code, ID: g.ID(), Status: statusOf(g), Players: g.Players(), Turns: g.Turns()})
+    }
+    sort.Slice(games, func(i, j int) bool { return games[i].Code < games[j].Code })
+    writeJSON(w, http.StatusOK, games)
+}
+
+func (s *webServer) handleCreate(w http.ResponseWriter, r *http.Request) {
+    var req webCreate
+    if err := json.NewDecoder(io.LimitReader(r.Body, 1<<12)).Decode(&req); err != nil {
+        http.Error(w, "bad game request: "+err.Error(), http.StatusBadRequest)
+        return
+    }
+    t, err := s.lobby.Host(req.LobbySettings, req.Players)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writeJSON(w, http.StatusCreated, t)
+}
+
+// sweep collects, as of now, the tables idle for s.idle, and the lobby's
+func (s *webServer) sweep(now time.Time) {
+    var gone []*webTable
+    s.mu.Lock()
+    for code, t := range s.tables {
+        if t.active.idle(now) >= s.idle {
+            delete(s.tables, code)
+            gone = append(gone, t)
+        }
+    }
+    s.mu.Unlock()
+    for _, t := range gone {
+        s.lobby.remove(t.code)
+        t.close("")
+    }
+    s.lobby.sweep(now, s.idle)
+}
+
+func (s *webServer) handleLobby(w http.ResponseWriter, r *http.Request) {
+    tables := s.lobby.List()
+    if tables == nil {
+        tables = []LobbyTable{}
+    }
+    writeJSON(w, http.StatusOK, tables)
+}
+
+func (s *webServer) handleOpen(w http.ResponseWriter, r *http.Request) {
+    var req LobbySettings
+    if err := json.NewDecoder(io.LimitReader(r.Body, 1<<10)).Decode(&req); err != nil {
+        http.Error(w, "bad table settings: "+err.Error(), http.StatusBadRequest)
+        return
+    }
+    t, err := s.lobby.Open(req)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writeJSON(w, http.StatusCreated, t)
+}
+
+func (s *webServer) handleTable(w http.ResponseWriter, r *http.Request) {
+    t, err := s.lobby.Table(r.PathValue("code"))
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writeJSON(w, http.StatusOK, t)
+}
+
+func (s *webServer) handleLobbyJoin(w http.ResponseWriter, r *http.Request) {
+    var req webJoin
+    if err := json.NewDecoder(io.LimitReader(r.Body, 1<<10)).Decode(&req); err != nil {
+        http.Error(w, "bad join request: "+err.Error(), http.StatusBadRequest)
+        return
+    }
+    id, token, err := s.lobby.Join(r.PathValue("code"), strings.TrimSpace(req.Name))
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writeJSON(w, http.StatusOK, webJoined{Player: id, Token: token})
+}
+
+// handleReady marks a seat ready, or not; the answer says whether that
+// started the game, which is then played at /games/CODE/
+func (s *webServer) handleReady(w http.ResponseWriter, r *http.Request) {
+    var req webReady
+    if err := json.NewDecoder(io.LimitReader(r.Body, 1<<10)).Decode(&req); err != nil {
+        http.Error(w, "bad ready request: "+err.Error(), http.StatusBadRequest)
+        return
+    }
+    t, err := s.lobby.Ready(r.PathValue("code"), req.Player, bearer(r), req.Ready == nil || *req.Ready)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writeJSON(w, http.StatusOK, t)
+}
+
+func (s *webServer) handleLobbyLeave(w http.ResponseWriter, r *http.Request) {
+    var req webSeat
+    if err := json.NewDecoder(io.LimitReader(r.Body, 1<<10)).Decode(&req); err != nil {
+        http.Error(w, "bad leave request: "+err.Error(), http.StatusBadRequest)
+        return
+    }
+    if err := s.lobby.Leave(r.PathValue("code"), req.Player, bearer(r)); err != nil {
+        writeError(w, err)
+        return
+    }
+    w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    json.NewEncoder(w).Encode(v)
+}
+
+// writeError maps a Game or lobby error to a status: a bad token is
+// forbidden, an unknown table not found, turn-order, game-over and
+// taken-seat mistakes are conflicts, anything else a bad request
+func writeError(w http.ResponseWriter, err error) {
+    status := http.StatusBadRequest
+    switch {
+    case errors.Is(err, ErrBadToken):
+        status = http.StatusForbidden
+    case errors.Is(err, ErrNoTable):
+        status = http.StatusNotFound
+    case errors.Is(err, ErrNotYourTurn), errors.Is(err, ErrGameOver), errors.Is(err, ErrTimeUp), errors.Is(err, ErrNoToken), errors.Is(err, ErrSeatTaken),
+        errors.Is(err, ErrTableFull), errors.Is(err, ErrGameStarted):
+        status = http.StatusConflict
+    case errors.Is(err, ErrRateLimited):
+        status = http.StatusTooManyRequests
+    }
+    http.Error(w, err.Error(), status)
+}
+
+// playing is the table playing the game with ID id, or nil
+func (s *webServer) playing(id string) *webTable {
+    for _, t := range s.allTables() {
+        if g, _ := t.current(); g.ID() == id {
+            return t
+        }
+    }
+    return nil
+}
+
+// allTables is the main table and every lobby table that has started
+func (s *webServer) allTables() []*webTable {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    all := []*webTable{s.main}
+    for _, t := range s.tables {
+        all = append(all, t)
+    }
+    return all
+}
+
+// serveWeb runs the web front end on addr until ctx is done
+func serveWeb(ctx context.Context, addr string, s *webServer, out io.Writer) error {
+    ln, err := net.Listen("tcp", addr)
+    if err != nil {
+        return err
+    }
+    srv := &http.Server{Handler: s.routes(), ReadHeaderTimeout: 10 * time.Second, MaxHeaderBytes: 16 << 10}
+    s.out = out
+    if s.idle > 0 {
+        go sweepIdle(ctx, s.deps.Clock, s.idle, s.sweep)
+    }
+    stopped := make(chan struct{})
+    go func() {
+        defer close(stopped)
+        <-ctx.Done()
+        for _, t := range s.allTables() {
+            t.close(shutdownReason)
+        }
+        shutdown, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+        defer cancel()
+        srv.Shutdown(shutdown)
+    }()
+    if s.tlsCert != "" || s.tlsKey != "" {
+        fmt.Fprintf(out, "serving the game at https://%s/\n", ln.Addr())
+        err = srv.ServeTLS(ln, s.tlsCert, s.tlsKey)
+    } else {
+        fmt.Fprintf(out, "serving the game at http://%s/\n", ln.Addr())
+        err = srv.Serve(ln)
+    }
+    if !errors.Is(err, http.ErrServerClosed) {
+        return err
+    }
+    <-stopped // Serve returns as Shutdown starts; wait for the streams to drain
+    return nil
+}